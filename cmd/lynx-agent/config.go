@@ -33,6 +33,8 @@ type agentConfig struct {
 	RpcPort      uint16     `yaml:"rpcPort"`
 	OvsCtlPort   uint16     `yaml:"ovsControllerPort"`
 	UplinkInfo   UplinkInfo `yaml:"uplinkInfo"`
+	FlowLog      FlowLog    `yaml:"flowLog"`
+	IPFIX        *IPFIX     `yaml:"ipfix,omitempty"`
 }
 
 type UplinkInfo struct {
@@ -46,9 +48,52 @@ type Link struct {
 	OfPortNo          uint32 `yaml:"ofPortNo"`
 }
 
+// FlowLog configures where records of traffic matching logging rules write to.
+type FlowLog struct {
+	// FilePath is the file records write to, defaults to /var/log/lynx/flow.log.
+	FilePath string `yaml:"filePath"`
+	// MaxSizeMB is the max size of the file before rotated, defaults to 100.
+	MaxSizeMB int `yaml:"maxSizeMB"`
+	// MaxBackups is the max number of rotated files retained, defaults to 3.
+	MaxBackups int `yaml:"maxBackups"`
+	// Syslog sends records to the syslog server if configured.
+	Syslog *SyslogServer `yaml:"syslog,omitempty"`
+}
+
+type SyslogServer struct {
+	// Network is the network to connect syslog server, e.g. udp, tcp. Empty connects to
+	// the local syslog server.
+	Network string `yaml:"network"`
+	Address string `yaml:"address"`
+}
+
+// IPFIX configures sampling of policy rule flows and exporting them to the IPFIX collector.
+type IPFIX struct {
+	// Collector is the address (ip:port) of the IPFIX collector, flows are exported by udp.
+	Collector string `yaml:"collector"`
+	// SamplingProbability is the number of sampled packets out of 65535, defaults to 65535.
+	SamplingProbability uint16 `yaml:"samplingProbability"`
+	// ObsDomainID is the observation domain id of exported records, defaults to 1.
+	ObsDomainID uint32 `yaml:"obsDomainID"`
+	// CollectorSetID is the id of ovs Flow_Sample_Collector_Set created on the bridge, defaults to 1.
+	CollectorSetID uint32 `yaml:"collectorSetID"`
+	// EnterpriseNumber is the private enterprise number of lynx information elements, e.g. policy
+	// rule name, must be the same as the collector configured.
+	EnterpriseNumber uint32 `yaml:"enterpriseNumber"`
+	// OptionsIntervalSeconds is the interval of exporting options records, which map the observation
+	// point id to the policy rule and the ingress interface to the endpoint, defaults to 60.
+	OptionsIntervalSeconds int `yaml:"optionsIntervalSeconds"`
+}
+
 func getAgentConfig() (*agentConfig, error) {
 	var err error
-	agentConfig := agentConfig{}
+	agentConfig := agentConfig{
+		FlowLog: FlowLog{
+			FilePath:   "/var/log/lynx/flow.log",
+			MaxSizeMB:  100,
+			MaxBackups: 3,
+		},
+	}
 
 	configdata, err := ioutil.ReadFile(agentConfigFilePath)
 	if err != nil {
@@ -62,6 +107,21 @@ func getAgentConfig() (*agentConfig, error) {
 		return nil, err
 	}
 
+	if ipfix := agentConfig.IPFIX; ipfix != nil {
+		if ipfix.SamplingProbability == 0 {
+			ipfix.SamplingProbability = 65535
+		}
+		if ipfix.ObsDomainID == 0 {
+			ipfix.ObsDomainID = 1
+		}
+		if ipfix.CollectorSetID == 0 {
+			ipfix.CollectorSetID = 1
+		}
+		if ipfix.OptionsIntervalSeconds == 0 {
+			ipfix.OptionsIntervalSeconds = 60
+		}
+	}
+
 	return &agentConfig, nil
 }
 
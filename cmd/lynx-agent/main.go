@@ -18,9 +18,12 @@ package main
 
 import (
 	"flag"
+	"io"
+	"log/syslog"
 	"net"
 	"time"
 
+	"github.com/contiv/libOpenflow/protocol"
 	"github.com/contiv/ofnet"
 	"github.com/contiv/ofnet/ovsdbDriver"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -29,8 +32,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	"github.com/smartxworks/lynx/pkg/agent/controller/policyrule"
+	"github.com/smartxworks/lynx/pkg/agent/controller/traceflow"
+	"github.com/smartxworks/lynx/pkg/agent/datapath"
+	"github.com/smartxworks/lynx/pkg/agent/flowlog"
+	"github.com/smartxworks/lynx/pkg/agent/ipfix"
+	"github.com/smartxworks/lynx/pkg/agent/ovsctl"
 	agentv1alpha1 "github.com/smartxworks/lynx/pkg/apis/agent/v1alpha1"
 	networkpolicyv1alpha1 "github.com/smartxworks/lynx/pkg/apis/policyrule/v1alpha1"
+	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
 	"github.com/smartxworks/lynx/pkg/monitor"
 )
 
@@ -41,6 +50,7 @@ var (
 func init() {
 	_ = networkpolicyv1alpha1.AddToScheme(scheme)
 	_ = agentv1alpha1.AddToScheme(scheme)
+	_ = securityv1alpha1.AddToScheme(scheme)
 }
 
 func main() {
@@ -76,13 +86,7 @@ func main() {
 		klog.Fatalf("error %v when init ofnetAgent.", err)
 	}
 
-	// We need to wait for long enough to guarantee that datapath completes flowtable initialize. It is a temporary
-	// method.
-	// Implement datapath initialized status Synchronization mechanism. TODO
-	time.Sleep(5 * time.Second)
-
-	// NetworkPolicy controller: watch policyRule crud and update flow
-	mgr, err := startManager(scheme, vlanArpLearnerAgent, stopChan)
+	mgr, err := startManager(scheme, stopChan)
 	if err != nil {
 		klog.Fatalf("error %v when start controller manager.", err)
 	}
@@ -106,12 +110,73 @@ func main() {
 			}
 		},
 	})
+	agentmonitor.RegisterOpenflowStatusFunc(func() (bool, string) {
+		return datapath.IsDatapathReady(vlanArpLearnerAgent, agentConfig.BridgeName)
+	})
 	go agentmonitor.Run(stopChan)
 
+	// Wait for datapath completes flowtable initialize, policyRule installed before that may be lost.
+	klog.Info("waiting for datapath ready")
+	if err = datapath.WaitForDatapathReady(vlanArpLearnerAgent, agentConfig.BridgeName, stopChan); err != nil {
+		klog.Fatalf("error %v when wait for datapath ready.", err)
+	}
+	klog.Info("datapath is ready")
+
+	// Flow sampling must be enabled before policy rules installed
+	if agentConfig.IPFIX != nil {
+		err = ovsctl.SetFlowSampleCollector(agentConfig.BridgeName, agentConfig.IPFIX.CollectorSetID,
+			agentConfig.IPFIX.Collector, agentConfig.IPFIX.ObsDomainID)
+		if err != nil {
+			klog.Fatalf("error %v when config ipfix collector.", err)
+		}
+		datapath.EnableFlowSampling(datapath.FlowSampling{
+			Probability:    agentConfig.IPFIX.SamplingProbability,
+			CollectorSetID: agentConfig.IPFIX.CollectorSetID,
+			ObsDomainID:    agentConfig.IPFIX.ObsDomainID,
+		})
+	}
+
+	// Reject responder answers packets matches reject rules
+	go datapath.NewRejectResponder(agentConfig.BridgeName).Run(stopChan)
+
+	// NetworkPolicy controller: watch policyRule crud and update flow
+	policyRuleReconciler, err := startPolicyRuleController(mgr, vlanArpLearnerAgent, agentConfig.BridgeName)
+	if err != nil {
+		klog.Fatalf("error %v when start policyrule controller.", err)
+	}
+
+	// Traceflow controller traces packets of traceflows assigned to this agent, the listener
+	// receives injected packets captured in the datapath
+	go datapath.RunTraceflowListener(agentConfig.BridgeName, stopChan)
+	if err = (&traceflow.TraceflowReconciler{
+		Client:         mgr.GetClient(),
+		Scheme:         mgr.GetScheme(),
+		AgentName:      policyRuleReconciler.AgentName,
+		BridgeName:     agentConfig.BridgeName,
+		GetOfport:      agentmonitor.GetOfportByExternalID,
+		GetPolicyRules: policyRuleReconciler.GetPolicyRulesByFlow,
+		InjectPacket: func(inPort uint32, pkt *protocol.Ethernet) (*datapath.TraceResult, error) {
+			return datapath.InjectTracePacket(vlanArpLearnerAgent.GetDatapath().GetPolicyAgent(), inPort, pkt)
+		},
+	}).SetupWithManager(mgr); err != nil {
+		klog.Fatalf("error %v when start traceflow controller.", err)
+	}
+
+	// Flow logger writes records of packets matches logging rules
+	flowLogger := newFlowLogger(&agentConfig.FlowLog, policyRuleReconciler.GetPolicyRulesByFlowKey, agentmonitor.GetInterfaceExternalIDs)
+	go datapath.RunFlowLogListener(agentConfig.BridgeName, flowLogger.HandlePacket, stopChan)
+
+	// IPFIX exporter exports options records correlate sampled flows with policy rules and endpoints
+	if agentConfig.IPFIX != nil {
+		go ipfix.NewExporter(agentConfig.IPFIX.Collector, agentConfig.IPFIX.ObsDomainID, agentConfig.IPFIX.EnterpriseNumber,
+			time.Duration(agentConfig.IPFIX.OptionsIntervalSeconds)*time.Second, datapath.SampledRules,
+			policyRuleReconciler.GetPolicyRulesByFlowKey, agentmonitor.ListInterfaceExternalIDs).Run(stopChan)
+	}
+
 	<-stopChan
 }
 
-func startManager(scheme *runtime.Scheme, agent *ofnet.OfnetAgent, stopChan <-chan struct{}) (manager.Manager, error) {
+func startManager(scheme *runtime.Scheme, stopChan <-chan struct{}) (manager.Manager, error) {
 	var metricsAddr string
 	flag.StringVar(&metricsAddr, "metrics-addr", "0", "The address the metric endpoint binds to.")
 	klog.InitFlags(nil)
@@ -127,15 +192,6 @@ func startManager(scheme *runtime.Scheme, agent *ofnet.OfnetAgent, stopChan <-ch
 		return nil, err
 	}
 
-	if err = (&policyrule.PolicyRuleReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-		Agent:  agent,
-	}).SetupWithManager(mgr); err != nil {
-		klog.Errorf("unable to create policyrule controller: %s", err.Error())
-		return nil, err
-	}
-
 	klog.Info("starting manager")
 	go func() {
 		if err := mgr.Start(stopChan); err != nil {
@@ -145,3 +201,47 @@ func startManager(scheme *runtime.Scheme, agent *ofnet.OfnetAgent, stopChan <-ch
 
 	return mgr, nil
 }
+
+// startPolicyRuleController add policyRule controller into the started manager.
+func startPolicyRuleController(mgr manager.Manager, agent *ofnet.OfnetAgent, bridgeName string) (*policyrule.PolicyRuleReconciler, error) {
+	agentName, err := monitor.ReadOrGenerateAgentName()
+	if err != nil {
+		klog.Errorf("unable get agent name: %s", err.Error())
+		return nil, err
+	}
+
+	reconciler := &policyrule.PolicyRuleReconciler{
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		Agent:      agent,
+		AgentName:  agentName,
+		BridgeName: bridgeName,
+	}
+	if err = reconciler.SetupWithManager(mgr); err != nil {
+		klog.Errorf("unable to create policyrule controller: %s", err.Error())
+		return nil, err
+	}
+
+	return reconciler, nil
+}
+
+// newFlowLogger returns flow logger writes records to the file and the syslog server.
+// newFlowLogger creates flow logger writes records into the file and syslog. Flow logging is optional,
+// the agent keeps running without the writers could not be used, e.g. the file is opened on the first
+// record, and errors would be reported by the logger when write records.
+func newFlowLogger(config *FlowLog, getPolicyRules flowlog.PolicyRuleGetter, getEndpoint flowlog.EndpointGetter) *flowlog.Logger {
+	var writers []io.Writer
+
+	writers = append(writers, flowlog.NewRotatingFileWriter(config.FilePath, int64(config.MaxSizeMB)<<20, config.MaxBackups))
+
+	if config.Syslog != nil {
+		syslogWriter, err := syslog.Dial(config.Syslog.Network, config.Syslog.Address, syslog.LOG_INFO|syslog.LOG_LOCAL0, "lynx-flowlog")
+		if err != nil {
+			klog.Errorf("unable connect to syslog server, flow records would not be sent to syslog: %s", err)
+		} else {
+			writers = append(writers, syslogWriter)
+		}
+	}
+
+	return flowlog.NewLogger(getPolicyRules, getEndpoint, writers...)
+}
@@ -31,7 +31,10 @@ import (
 	endpointctrl "github.com/smartxworks/lynx/pkg/controller/endpoint"
 	groupctrl "github.com/smartxworks/lynx/pkg/controller/group"
 	policyctrl "github.com/smartxworks/lynx/pkg/controller/policy"
+	traceflowctrl "github.com/smartxworks/lynx/pkg/controller/traceflow"
 	"github.com/smartxworks/lynx/pkg/webhook"
+	kubernetesplugin "github.com/smartxworks/lynx/plugin/kubernetes/pkg/register"
+	staticplugin "github.com/smartxworks/lynx/plugin/static/pkg/register"
 	towerplugin "github.com/smartxworks/lynx/plugin/tower/pkg/register"
 )
 
@@ -54,6 +57,8 @@ func main() {
 	var serverPort int
 	var leaderElectionNamespace string
 	var towerPluginOptions towerplugin.Options
+	var kubernetesPluginOptions kubernetesplugin.Options
+	var staticPluginOptions staticplugin.Options
 
 	flag.StringVar(&metricsAddr, "metrics-addr", "0", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", true,
@@ -64,6 +69,8 @@ func main() {
 	flag.IntVar(&serverPort, "port", 9443, "The port for the Lynx controller to serve on.")
 	klog.InitFlags(nil)
 	towerplugin.InitFlags(&towerPluginOptions, nil, "plugins.tower.")
+	kubernetesplugin.InitFlags(&kubernetesPluginOptions, nil, "plugins.kubernetes.")
+	staticplugin.InitFlags(&staticPluginOptions, nil, "plugins.static.")
 	flag.Parse()
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
@@ -103,6 +110,14 @@ func main() {
 		klog.Fatalf("unable to create policy controller: %s", err.Error())
 	}
 
+	// traceflow controller assign traceflows to the agent hosting the source endpoint.
+	if err = (&traceflowctrl.TraceflowReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		klog.Fatalf("unable to create traceflow controller: %s", err.Error())
+	}
+
 	// register validate handle
 	if err = (&webhook.ValidateWebhook{
 		Scheme: mgr.GetScheme(),
@@ -116,6 +131,18 @@ func main() {
 		klog.Fatalf("unable register tower plugin: %s", err.Error())
 	}
 
+	// register kubernetes plugin
+	err = kubernetesplugin.AddToManager(&kubernetesPluginOptions, mgr)
+	if err != nil {
+		klog.Fatalf("unable register kubernetes plugin: %s", err.Error())
+	}
+
+	// register static plugin
+	err = staticplugin.AddToManager(&staticPluginOptions, mgr)
+	if err != nil {
+		klog.Fatalf("unable register static plugin: %s", err.Error())
+	}
+
 	klog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		klog.Fatalf("error while running manager: %s", err.Error())
@@ -0,0 +1,163 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	groupv1alpha1 "github.com/smartxworks/lynx/pkg/apis/group/v1alpha1"
+	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	"github.com/smartxworks/lynx/pkg/simulator"
+)
+
+func main() {
+	if err := rootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+type options struct {
+	files    []string
+	src, dst string
+	protocol string
+	srcPort  uint16
+	dstPort  uint16
+	icmpType uint8
+	icmpCode uint8
+	expect   string
+}
+
+func rootCommand() *cobra.Command {
+	var opts options
+
+	rootCmd := &cobra.Command{
+		Use:   "lynx-simulator --src <ip|endpoint> --dst <ip|endpoint> [options]",
+		Short: "Simulate whether a connection would be allowed by security policies",
+		Long: "Simulate the first packet of a connection against Tiers, SecurityPolicies, EndpointGroups and Endpoints, " +
+			"read from the files if any, otherwise from the cluster. Print the rules the packet matches and the verdict.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSimulate(cmd.OutOrStdout(), &opts)
+		},
+	}
+
+	rootCmd.SilenceUsage = true
+	flagSet := rootCmd.Flags()
+	flagSet.AddGoFlagSet(flag.CommandLine)
+	flagSet.StringSliceVarP(&opts.files, "filename", "f", nil, "files or directories contain resources to simulate with, read from cluster if empty")
+	flagSet.StringVar(&opts.src, "src", "", "source ip or endpoint name")
+	flagSet.StringVar(&opts.dst, "dst", "", "destination ip or endpoint name")
+	flagSet.StringVarP(&opts.protocol, "protocol", "p", "TCP", "protocol of the packet, one of TCP, UDP, ICMP and SCTP")
+	flagSet.Uint16Var(&opts.srcPort, "src-port", 0, "source port, only valid when protocol is TCP, UDP or SCTP")
+	flagSet.Uint16Var(&opts.dstPort, "dst-port", 0, "destination port, only valid when protocol is TCP, UDP or SCTP")
+	flagSet.Uint8Var(&opts.icmpType, "icmp-type", 8, "icmp type, only valid when protocol is ICMP")
+	flagSet.Uint8Var(&opts.icmpCode, "icmp-code", 0, "icmp code, only valid when protocol is ICMP")
+	flagSet.StringVar(&opts.expect, "expect", "", "exit with error if the verdict is not the expected one (Forwarded, Dropped or Rejected)")
+	_ = rootCmd.MarkFlagRequired("src")
+	_ = rootCmd.MarkFlagRequired("dst")
+
+	return rootCmd
+}
+
+func runSimulate(out io.Writer, opts *options) error {
+	snapshot, err := loadSnapshot(opts.files)
+	if err != nil {
+		return err
+	}
+
+	sim, err := simulator.New(snapshot)
+	if err != nil {
+		return err
+	}
+
+	packet := &simulator.Packet{
+		Protocol: securityv1alpha1.Protocol(strings.ToUpper(opts.protocol)),
+		SrcPort:  opts.srcPort,
+		DstPort:  opts.dstPort,
+		ICMPType: opts.icmpType,
+		ICMPCode: opts.icmpCode,
+	}
+	switch packet.Protocol {
+	case securityv1alpha1.ProtocolTCP, securityv1alpha1.ProtocolUDP, securityv1alpha1.ProtocolICMP, securityv1alpha1.ProtocolSCTP:
+	default:
+		return fmt.Errorf("unsupport protocol %s", opts.protocol)
+	}
+	if packet.SrcIP, err = sim.ResolveIP(opts.src); err != nil {
+		return err
+	}
+	if packet.DstIP, err = sim.ResolveIP(opts.dst); err != nil {
+		return err
+	}
+
+	result := sim.Simulate(packet)
+	printResult(out, result)
+
+	if opts.expect != "" && !strings.EqualFold(opts.expect, string(result.Verdict)) {
+		return fmt.Errorf("expect verdict %s, got %s", opts.expect, result.Verdict)
+	}
+	return nil
+}
+
+func loadSnapshot(files []string) (*simulator.Snapshot, error) {
+	if len(files) != 0 {
+		return simulator.SnapshotFromFiles(files...)
+	}
+
+	scheme := runtime.NewScheme()
+	_ = securityv1alpha1.AddToScheme(scheme)
+	_ = groupv1alpha1.AddToScheme(scheme)
+
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get kubeconfig: %s", err)
+	}
+	c, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create client: %s", err)
+	}
+	return simulator.SnapshotFromCluster(context.Background(), c)
+}
+
+func printResult(out io.Writer, result *simulator.Result) {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "DIRECTION\tTIER\tTABLE\tPRIORITY\tACTION\tPOLICY/RULE")
+	for _, flow := range result.Chain {
+		var action = string(flow.Spec.Action)
+		if flow.Spec.MonitorOnly {
+			action = fmt.Sprintf("%s(Monitor)", action)
+		}
+		var owners []string
+		for item := range flow.PolicyRules {
+			policy, rule := simulator.PolicyRuleOwner(&flow.PolicyRules[item])
+			owners = append(owners, policy+"/"+rule)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\n", flow.Direction, flow.Tier, flow.Table, flow.Priority, action, strings.Join(owners, ","))
+	}
+	w.Flush()
+	fmt.Fprintf(out, "\nVerdict: %s\n", result.Verdict)
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+
+	agentv1alpha1 "github.com/smartxworks/lynx/pkg/apis/agent/v1alpha1"
+)
+
+func NewAgentCommand(getClient ClientGetter) *cobra.Command {
+	ac := &cobra.Command{
+		Use:   "agent <subcommand>",
+		Short: "Agent related commands",
+	}
+
+	ac.AddCommand(newAgentListCommand(getClient))
+	ac.AddCommand(newAgentDescribeCommand(getClient))
+
+	return ac
+}
+
+func newAgentListCommand(getClient ClientGetter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List all agents with their health",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listAgent(cmd.Context(), getClient, cmd.OutOrStdout())
+		},
+	}
+
+	return cmd
+}
+
+func newAgentDescribeCommand(getClient ClientGetter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "describe <agent name>",
+		Short: "Show conditions and interfaces of an agent",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("agent describe command requires agent name as its argument")
+			}
+			return describeAgent(cmd.Context(), getClient, cmd.OutOrStdout(), args[0])
+		},
+	}
+
+	return cmd
+}
+
+func listAgent(ctx context.Context, getClient ClientGetter, output io.Writer) error {
+	client, err := getClient()
+	if err != nil {
+		return err
+	}
+
+	agentList, err := client.AgentV1alpha1().AgentInfos().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	sort.Slice(agentList.Items, func(i, j int) bool {
+		return agentList.Items[i].Name < agentList.Items[j].Name
+	})
+
+	var table = newTable("name", "hostname", "ovs-version", "interfaces", "last-heartbeat")
+	for _, agent := range agentList.Items {
+		var interfaces int
+		for _, bridge := range agent.OVSInfo.Bridges {
+			for _, port := range bridge.Ports {
+				interfaces += len(port.Interfaces)
+			}
+		}
+		addRow(table, agent.Name, agent.Hostname, orNone(agent.OVSInfo.Version), interfaces, lastHeartbeat(&agent))
+	}
+
+	return printTable(output, table)
+}
+
+func describeAgent(ctx context.Context, getClient ClientGetter, output io.Writer, name string) error {
+	client, err := getClient()
+	if err != nil {
+		return err
+	}
+
+	agent, err := client.AgentV1alpha1().AgentInfos().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	d := newDescriber(output)
+	d.field("Name", agent.Name)
+	d.field("Hostname", agent.Hostname)
+	d.field("OVSVersion", orNone(agent.OVSInfo.Version))
+	d.section("Conditions")
+	_ = d.Flush()
+
+	var conditionTable = newTable("type", "status", "last-heartbeat", "reason", "message")
+	for _, condition := range agent.Conditions {
+		addRow(conditionTable, condition.Type, condition.Status, sinceString(condition.LastHeartbeatTime),
+			condition.Reason, condition.Message)
+	}
+	if err = printTable(output, conditionTable); err != nil {
+		return err
+	}
+
+	d.section("Interfaces")
+	_ = d.Flush()
+
+	var interfaceTable = newTable("bridge", "port", "interface", "ofport", "mac", "ips", "external-ids")
+	for _, bridge := range agent.OVSInfo.Bridges {
+		for _, port := range bridge.Ports {
+			for _, iface := range port.Interfaces {
+				addRow(interfaceTable, bridge.Name, port.Name, iface.Name, iface.Ofport, orNone(iface.Mac),
+					ipsToString(iface.IPs), mapJoin(iface.ExternalIDs, "=", ","))
+			}
+		}
+	}
+
+	return printTable(output, interfaceTable)
+}
+
+// lastHeartbeat returns how long since the agent last reported healthy.
+func lastHeartbeat(agent *agentv1alpha1.AgentInfo) string {
+	for _, condition := range agent.Conditions {
+		if condition.Type == agentv1alpha1.AgentHealthy {
+			return sinceString(condition.LastHeartbeatTime)
+		}
+	}
+	return none
+}
+
+func sinceString(t metav1.Time) string {
+	if t.IsZero() {
+		return none
+	}
+	return duration.HumanDuration(time.Since(t.Time)) + " ago"
+}
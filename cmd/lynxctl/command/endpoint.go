@@ -0,0 +1,398 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	groupv1alpha1 "github.com/smartxworks/lynx/pkg/apis/group/v1alpha1"
+	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	"github.com/smartxworks/lynx/pkg/client/clientset_generated/clientset"
+	lynxctrl "github.com/smartxworks/lynx/pkg/controller"
+	groupctrl "github.com/smartxworks/lynx/pkg/controller/group"
+	"github.com/smartxworks/lynx/pkg/types"
+)
+
+func NewEndpointCommand(getClient ClientGetter) *cobra.Command {
+	ac := &cobra.Command{
+		Use:   "endpoint <subcommand>",
+		Short: "Endpoint related commands",
+	}
+
+	ac.AddCommand(newEndpointListCommand(getClient))
+	ac.AddCommand(newEndpointDescribeCommand(getClient))
+	ac.AddCommand(newEndpointPoliciesCommand(getClient))
+
+	return ac
+}
+
+func newEndpointListCommand(getClient ClientGetter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List all endpoints",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listEndpoint(cmd.Context(), getClient, cmd.OutOrStdout())
+		},
+	}
+
+	return cmd
+}
+
+func newEndpointDescribeCommand(getClient ClientGetter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "describe <endpoint name>",
+		Short: "Show details, groups and agent of an endpoint",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("endpoint describe command requires endpoint name as its argument")
+			}
+			return describeEndpoint(cmd.Context(), getClient, cmd.OutOrStdout(), args[0])
+		},
+	}
+
+	return cmd
+}
+
+func newEndpointPoliciesCommand(getClient ClientGetter) *cobra.Command {
+	var allPeers bool
+
+	cmd := &cobra.Command{
+		Use:   "policies <endpoint name> [options]",
+		Short: "Show the security policies and rules take effect on an endpoint",
+		Long: "Show the security policies applied to an endpoint by AppliedTo, and the rules of other " +
+			"policies which reference the endpoint as a peer by group, endpoint name or ip block.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("endpoint policies command requires endpoint name as its argument")
+			}
+			return listEndpointPolicies(cmd.Context(), getClient, cmd.OutOrStdout(), args[0], allPeers)
+		},
+	}
+
+	cmd.PersistentFlags().BoolVar(&allPeers, "all-peers", false, "also show rules with empty peer, which match all endpoints")
+
+	return cmd
+}
+
+func listEndpoint(ctx context.Context, getClient ClientGetter, output io.Writer) error {
+	client, err := getClient()
+	if err != nil {
+		return err
+	}
+
+	endpointList, err := client.SecurityV1alpha1().Endpoints().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	sort.Slice(endpointList.Items, func(i, j int) bool {
+		return endpointList.Items[i].Name < endpointList.Items[j].Name
+	})
+
+	var table = newTable("name", "reference", "vid", "ips", "mac", "labels")
+	for _, ep := range endpointList.Items {
+		reference := fmt.Sprintf("%s=%s", ep.Spec.Reference.ExternalIDName, ep.Spec.Reference.ExternalIDValue)
+		addRow(table, ep.Name, reference, ep.Spec.VID, ipsToString(ep.Status.IPs), orNone(ep.Status.MacAddress), mapJoin(ep.Labels, "=", ","))
+	}
+
+	return printTable(output, table)
+}
+
+func describeEndpoint(ctx context.Context, getClient ClientGetter, output io.Writer, name string) error {
+	client, err := getClient()
+	if err != nil {
+		return err
+	}
+
+	ep, err := client.SecurityV1alpha1().Endpoints().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	groups, err := endpointGroups(ctx, client, ep)
+	if err != nil {
+		return err
+	}
+	agents, err := endpointAgents(ctx, client, ep)
+	if err != nil {
+		return err
+	}
+
+	d := newDescriber(output)
+	d.field("Name", ep.Name)
+	d.field("Labels", mapJoin(ep.Labels, "=", ","))
+	d.field("ManagePlaneID", orNone(ep.Spec.ManagePlaneID))
+	d.field("Reference", fmt.Sprintf("%s=%s", ep.Spec.Reference.ExternalIDName, ep.Spec.Reference.ExternalIDValue))
+	d.field("VID", ep.Spec.VID)
+	d.field("IPs", ipsToString(ep.Status.IPs))
+	d.field("MacAddress", orNone(ep.Status.MacAddress))
+	d.field("Agents", orNone(strings.Join(agents, ",")))
+	d.field("Host", orNone(ep.Annotations[lynxctrl.HostAnnotation]))
+	d.field("Groups", orNone(strings.Join(groups.List(), ",")))
+
+	if len(ep.Status.Conditions) == 0 {
+		return d.Flush()
+	}
+
+	d.section("Conditions")
+	_ = d.Flush()
+
+	var conditionTable = newTable("type", "status", "last-transition", "reason", "message")
+	for _, condition := range ep.Status.Conditions {
+		addRow(conditionTable, condition.Type, condition.Status, sinceString(condition.LastTransitionTime),
+			condition.Reason, condition.Message)
+	}
+	return printTable(output, conditionTable)
+}
+
+func listEndpointPolicies(ctx context.Context, getClient ClientGetter, output io.Writer, name string, allPeers bool) error {
+	client, err := getClient()
+	if err != nil {
+		return err
+	}
+
+	ep, err := client.SecurityV1alpha1().Endpoints().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	rules, err := effectiveRules(ctx, client, ep, allPeers)
+	if err != nil {
+		return err
+	}
+
+	var table = newTable("policy", "tier", "priority", "mode", "rule", "role", "matched-by")
+	for _, item := range rules {
+		addRow(table, item.policy.Name, item.policy.Spec.Tier, item.policy.Spec.Priority,
+			enforcementMode(item.policy.Spec.EnforcementMode), item.rule, item.role, strings.Join(item.matchedBy, ","))
+	}
+
+	return printTable(output, table)
+}
+
+const (
+	// roleAppliedTo means the endpoint is in AppliedTo of the policy, all rules of the policy take effect.
+	roleAppliedTo = "AppliedTo"
+	// roleSource means the endpoint is a source of the ingress rule.
+	roleSource = "Source"
+	// roleDestination means the endpoint is a destination of the egress rule.
+	roleDestination = "Destination"
+
+	// allRules is the rule name shows all rules of the policy take effect on the endpoint.
+	allRules = "<all>"
+)
+
+// effectiveRule is a policy or a rule of the policy takes effect on an endpoint, matchedBy are
+// the groups, endpoint name or ip blocks in the AppliedTo or peer which contain the endpoint.
+type effectiveRule struct {
+	policy    securityv1alpha1.SecurityPolicy
+	rule      string
+	role      string
+	matchedBy []string
+}
+
+// effectiveRules returns the policies applied to the endpoint, and rules which reference the endpoint
+// as peer. Rules are ordered by tier priority, and by priority from high to low in the same tier. Rules with empty
+// peer would be returned only if allPeers is true.
+func effectiveRules(ctx context.Context, client clientset.Interface, ep *securityv1alpha1.Endpoint, allPeers bool) ([]effectiveRule, error) {
+	groups, err := endpointGroups(ctx, client, ep)
+	if err != nil {
+		return nil, err
+	}
+	policyList, err := client.SecurityV1alpha1().SecurityPolicies().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []effectiveRule
+	for _, policy := range policyList.Items {
+		appliedTo := securityv1alpha1.SecurityPolicyPeer{
+			EndpointGroups:   policy.Spec.AppliedTo.EndpointGroups,
+			Endpoints:        policy.Spec.AppliedTo.Endpoints,
+			EndpointSelector: policy.Spec.AppliedTo.EndpointSelector,
+		}
+		if matchedBy := peerMatchedBy(policy.Labels[lynxctrl.NamespaceLabel], &appliedTo, ep, groups); len(matchedBy) != 0 {
+			rules = append(rules, effectiveRule{policy: policy, rule: allRules, role: roleAppliedTo, matchedBy: matchedBy})
+		}
+
+		for _, rule := range policy.Spec.IngressRules {
+			if matchedBy := peerMatchedBy(policy.Labels[lynxctrl.NamespaceLabel], &rule.From, ep, groups); len(matchedBy) != 0 || (allPeers && isEmptyPeer(&rule.From)) {
+				rules = append(rules, effectiveRule{policy: policy, rule: "ingress." + rule.Name, role: roleSource, matchedBy: orAll(matchedBy)})
+			}
+		}
+		for _, rule := range policy.Spec.EgressRules {
+			if matchedBy := peerMatchedBy(policy.Labels[lynxctrl.NamespaceLabel], &rule.To, ep, groups); len(matchedBy) != 0 || (allPeers && isEmptyPeer(&rule.To)) {
+				rules = append(rules, effectiveRule{policy: policy, rule: "egress." + rule.Name, role: roleDestination, matchedBy: orAll(matchedBy)})
+			}
+		}
+	}
+
+	tierList, err := client.SecurityV1alpha1().Tiers().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var tierPriorities = make(map[string]int32, len(tierList.Items))
+	for _, tier := range tierList.Items {
+		tierPriorities[tier.Name] = tier.Spec.Priority
+	}
+
+	sort.SliceStable(rules, func(i, j int) bool {
+		pi, pj := rules[i].policy, rules[j].policy
+		if pi.Spec.Tier != pj.Spec.Tier {
+			// tier with lower priority value is evaluated first, unknown tiers are listed at last
+			priorityI, knownI := tierPriorities[pi.Spec.Tier]
+			priorityJ, knownJ := tierPriorities[pj.Spec.Tier]
+			if knownI != knownJ {
+				return knownI
+			}
+			if priorityI != priorityJ {
+				return priorityI < priorityJ
+			}
+			return pi.Spec.Tier < pj.Spec.Tier
+		}
+		if pi.Spec.Priority != pj.Spec.Priority {
+			return pi.Spec.Priority > pj.Spec.Priority
+		}
+		return pi.Name < pj.Name
+	})
+
+	return rules, nil
+}
+
+// peerMatchedBy returns the items in the peer which contain the endpoint, namespace is the tenant
+// namespace of the policy the peer belongs to.
+func peerMatchedBy(namespace string, peer *securityv1alpha1.SecurityPolicyPeer, ep *securityv1alpha1.Endpoint, groups sets.String) []string {
+	var matchedBy []string
+	var inNamespace = namespace == "" || ep.Labels[lynxctrl.NamespaceLabel] == namespace
+
+	for _, group := range peer.EndpointGroups {
+		if groups.Has(group) && inNamespace {
+			matchedBy = append(matchedBy, "group:"+group)
+		}
+	}
+	for _, endpoint := range peer.Endpoints {
+		if endpoint == ep.Name && inNamespace {
+			matchedBy = append(matchedBy, "endpoint:"+endpoint)
+		}
+	}
+	if peer.EndpointSelector != nil && groups.Has(groupctrl.SelectorGroupName(namespace, peer.EndpointSelector)) {
+		matchedBy = append(matchedBy, "selector:"+selectorToString(peer.EndpointSelector))
+	}
+	for _, ipBlock := range peer.IPBlocks {
+		if ipBlockContainsAny(ipBlock, ep.Status.IPs) {
+			matchedBy = append(matchedBy, fmt.Sprintf("ipBlock:%s/%d", ipBlock.IP, ipBlock.PrefixLength))
+		}
+	}
+
+	return matchedBy
+}
+
+func isEmptyPeer(peer *securityv1alpha1.SecurityPolicyPeer) bool {
+	return len(peer.EndpointGroups)+len(peer.Endpoints)+len(peer.IPBlocks) == 0 && peer.EndpointSelector == nil
+}
+
+func orAll(matchedBy []string) []string {
+	if len(matchedBy) == 0 {
+		return []string{"<all>"}
+	}
+	return matchedBy
+}
+
+// ipBlockContainsAny returns true if any of the ips in the ipBlock and not in its excepts.
+func ipBlockContainsAny(ipBlock securityv1alpha1.IPBlock, ips []types.IPAddress) bool {
+	_, ipNet, err := net.ParseCIDR(fmt.Sprintf("%s/%d", ipBlock.IP, ipBlock.PrefixLength))
+	if err != nil {
+		return false
+	}
+
+	for _, ipAddr := range ips {
+		ip := net.ParseIP(string(ipAddr))
+		if ip == nil || !ipNet.Contains(ip) {
+			continue
+		}
+		var excepted bool
+		for _, except := range ipBlock.Except {
+			if _, exceptNet, err := net.ParseCIDR(except); err == nil && exceptNet.Contains(ip) {
+				excepted = true
+				break
+			}
+		}
+		if !excepted {
+			return true
+		}
+	}
+
+	return false
+}
+
+// endpointGroups returns names of the groups which members contain the endpoint.
+func endpointGroups(ctx context.Context, client clientset.Interface, ep *securityv1alpha1.Endpoint) (sets.String, error) {
+	var groups = sets.NewString()
+
+	membersList, err := client.GroupV1alpha1().GroupMemberses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	reference := groupv1alpha1.EndpointReference{
+		ExternalIDName:  ep.Spec.Reference.ExternalIDName,
+		ExternalIDValue: ep.Spec.Reference.ExternalIDValue,
+	}
+	for _, members := range membersList.Items {
+		for _, member := range members.GroupMembers {
+			if member.EndpointReference == reference {
+				groups.Insert(members.Name)
+				break
+			}
+		}
+	}
+
+	return groups, nil
+}
+
+// endpointAgents returns names of the agents which have interface of the endpoint.
+func endpointAgents(ctx context.Context, client clientset.Interface, ep *securityv1alpha1.Endpoint) ([]string, error) {
+	var agents []string
+
+	agentList, err := client.AgentV1alpha1().AgentInfos().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, agent := range agentList.Items {
+	bridgeLoop:
+		for _, bridge := range agent.OVSInfo.Bridges {
+			for _, port := range bridge.Ports {
+				for _, iface := range port.Interfaces {
+					if value, ok := iface.ExternalIDs[ep.Spec.Reference.ExternalIDName]; ok && value == ep.Spec.Reference.ExternalIDValue {
+						agents = append(agents, agent.Name)
+						break bridgeLoop
+					}
+				}
+			}
+		}
+	}
+
+	return agents, nil
+}
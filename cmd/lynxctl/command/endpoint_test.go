@@ -0,0 +1,215 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	groupv1alpha1 "github.com/smartxworks/lynx/pkg/apis/group/v1alpha1"
+	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	"github.com/smartxworks/lynx/pkg/client/clientset_generated/clientset"
+	"github.com/smartxworks/lynx/pkg/client/clientset_generated/clientset/fake"
+	"github.com/smartxworks/lynx/pkg/types"
+)
+
+func newTestEndpoint(name string) *securityv1alpha1.Endpoint {
+	ep := &securityv1alpha1.Endpoint{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	ep.Spec.Reference.ExternalIDName = "iface-id"
+	ep.Spec.Reference.ExternalIDValue = name
+	return ep
+}
+
+func newTestGroupMembers(name string, endpoints ...string) *groupv1alpha1.GroupMembers {
+	members := &groupv1alpha1.GroupMembers{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	for _, ep := range endpoints {
+		members.GroupMembers = append(members.GroupMembers, groupv1alpha1.GroupMember{
+			EndpointReference: groupv1alpha1.EndpointReference{ExternalIDName: "iface-id", ExternalIDValue: ep},
+		})
+	}
+	return members
+}
+
+func newTestPolicy(name, tier string, priority int32, groups []string, endpoints []string) *securityv1alpha1.SecurityPolicy {
+	policy := &securityv1alpha1.SecurityPolicy{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	policy.Spec.Tier = tier
+	policy.Spec.Priority = priority
+	policy.Spec.AppliedTo.EndpointGroups = groups
+	policy.Spec.AppliedTo.Endpoints = endpoints
+	return policy
+}
+
+func newTestTier(name string, priority int32) *securityv1alpha1.Tier {
+	tier := &securityv1alpha1.Tier{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	tier.Spec.Priority = priority
+	tier.Spec.TierMode = securityv1alpha1.TierWhiteList
+	return tier
+}
+
+func TestEffectiveRules(t *testing.T) {
+	ep01 := newTestEndpoint("ep01")
+	ep01.Status.IPs = []types.IPAddress{"10.0.0.1"}
+	ep02 := newTestEndpoint("ep02")
+	ep02.Status.IPs = []types.IPAddress{"10.0.1.2"}
+
+	policy05 := newTestPolicy("policy05", "tier2", 10, []string{"group03"}, nil)
+	policy05.Spec.IngressRules = []securityv1alpha1.Rule{
+		{Name: "from-group", From: securityv1alpha1.SecurityPolicyPeer{EndpointGroups: []string{"group02"}}},
+		{Name: "from-ipblock", From: securityv1alpha1.SecurityPolicyPeer{IPBlocks: []securityv1alpha1.IPBlock{
+			{IP: "10.0.0.0", PrefixLength: 16, Except: []string{"10.0.1.0/24"}},
+		}}},
+		{Name: "from-all"},
+	}
+	policy05.Spec.EgressRules = []securityv1alpha1.Rule{
+		{Name: "to-endpoint", To: securityv1alpha1.SecurityPolicyPeer{Endpoints: []string{"ep01", "ep02"}}},
+	}
+
+	client := fake.NewSimpleClientset(
+		newTestTier("tier0", 0),
+		newTestTier("tier1", 10),
+		newTestTier("tier2", 20),
+		ep01,
+		ep02,
+		newTestGroupMembers("group01", "ep01", "ep02"),
+		newTestGroupMembers("group02", "ep02"),
+		newTestPolicy("policy01", "tier0", 10, []string{"group01"}, nil),
+		newTestPolicy("policy02", "tier0", 20, []string{"group02"}, []string{"ep01"}),
+		newTestPolicy("policy03", "tier1", 10, []string{"group01", "group02"}, []string{"ep02"}),
+		newTestPolicy("policy04", "tier0", 50, []string{"group02"}, nil),
+		policy05,
+	)
+
+	testCases := map[string]struct {
+		endpoint    string
+		allPeers    bool
+		expectRules []string
+	}{
+		"should list policies applied by group or endpoint name": {
+			endpoint: "ep01",
+			expectRules: []string{
+				"policy02 <all> AppliedTo endpoint:ep01",
+				"policy01 <all> AppliedTo group:group01",
+				"policy03 <all> AppliedTo group:group01",
+				"policy05 ingress.from-ipblock Source ipBlock:10.0.0.0/16",
+				"policy05 egress.to-endpoint Destination endpoint:ep01",
+			},
+		},
+		"should list policies applied by multiple groups": {
+			endpoint: "ep02",
+			expectRules: []string{
+				"policy04 <all> AppliedTo group:group02",
+				"policy02 <all> AppliedTo group:group02",
+				"policy01 <all> AppliedTo group:group01",
+				"policy03 <all> AppliedTo group:group01,group:group02,endpoint:ep02",
+				"policy05 ingress.from-group Source group:group02",
+				"policy05 egress.to-endpoint Destination endpoint:ep02",
+			},
+		},
+		"should list rules with empty peer if all peers": {
+			endpoint: "ep02",
+			allPeers: true,
+			expectRules: []string{
+				"policy04 <all> AppliedTo group:group02",
+				"policy02 <all> AppliedTo group:group02",
+				"policy01 <all> AppliedTo group:group01",
+				"policy03 <all> AppliedTo group:group01,group:group02,endpoint:ep02",
+				"policy05 ingress.from-group Source group:group02",
+				"policy05 ingress.from-all Source <all>",
+				"policy05 egress.to-endpoint Destination endpoint:ep02",
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ep, err := client.SecurityV1alpha1().Endpoints().Get(context.Background(), tc.endpoint, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpect error: %s", err)
+			}
+			rules, err := effectiveRules(context.Background(), client, ep, tc.allPeers)
+			if err != nil {
+				t.Fatalf("unexpect error: %s", err)
+			}
+
+			var ruleStrings []string
+			for _, item := range rules {
+				ruleStrings = append(ruleStrings, fmt.Sprintf("%s %s %s %s", item.policy.Name, item.rule, item.role, strings.Join(item.matchedBy, ",")))
+			}
+			if !reflect.DeepEqual(ruleStrings, tc.expectRules) {
+				t.Errorf("expect rules %v, got %v", tc.expectRules, ruleStrings)
+			}
+		})
+	}
+}
+
+func TestEffectiveRulesTierOrder(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		newTestTier("baseline", 100),
+		newTestTier("security", 10),
+		newTestEndpoint("ep01"),
+		newTestPolicy("policy01", "baseline", 10, nil, []string{"ep01"}),
+		newTestPolicy("policy02", "security", 10, nil, []string{"ep01"}),
+		newTestPolicy("policy03", "unknown", 10, nil, []string{"ep01"}),
+	)
+	ep, err := client.SecurityV1alpha1().Endpoints().Get(context.Background(), "ep01", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpect error: %s", err)
+	}
+
+	rules, err := effectiveRules(context.Background(), client, ep, false)
+	if err != nil {
+		t.Fatalf("unexpect error: %s", err)
+	}
+	var policies []string
+	for _, item := range rules {
+		policies = append(policies, item.policy.Name)
+	}
+	// tiers should be ordered by priority rather than name
+	if expectPolicies := []string{"policy02", "policy01", "policy03"}; !reflect.DeepEqual(policies, expectPolicies) {
+		t.Errorf("expect policies %v, got %v", expectPolicies, policies)
+	}
+}
+
+func TestListEndpointPolicies(t *testing.T) {
+	var output bytes.Buffer
+	getClient := func() (clientset.Interface, error) {
+		return fake.NewSimpleClientset(
+			newTestEndpoint("ep01"),
+			newTestGroupMembers("group01", "ep01"),
+			newTestPolicy("policy01", "tier0", 10, []string{"group01"}, nil),
+		), nil
+	}
+
+	if err := listEndpointPolicies(context.Background(), getClient, &output, "ep01", false); err != nil {
+		t.Fatalf("unexpect error: %s", err)
+	}
+	for _, expect := range []string{"POLICY", "policy01", "tier0", "Enforce", "AppliedTo", "group:group01"} {
+		if !strings.Contains(output.String(), expect) {
+			t.Errorf("expect output contains %s, got %s", expect, output.String())
+		}
+	}
+
+	if err := listEndpointPolicies(context.Background(), getClient, &output, "ep02", false); err == nil {
+		t.Errorf("expect error for endpoint not found")
+	}
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	groupv1alpha1 "github.com/smartxworks/lynx/pkg/apis/group/v1alpha1"
+	lynxctrl "github.com/smartxworks/lynx/pkg/controller"
+)
+
+func NewGroupCommand(getClient ClientGetter) *cobra.Command {
+	ac := &cobra.Command{
+		Use:   "group <subcommand>",
+		Short: "EndpointGroup related commands",
+	}
+
+	ac.AddCommand(newGroupListCommand(getClient))
+	ac.AddCommand(newGroupDescribeCommand(getClient))
+
+	return ac
+}
+
+func newGroupListCommand(getClient ClientGetter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List all endpoint groups",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listGroup(cmd.Context(), getClient, cmd.OutOrStdout())
+		},
+	}
+
+	return cmd
+}
+
+func newGroupDescribeCommand(getClient ClientGetter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "describe <group name>",
+		Short: "Show members with revision and recent patches of an endpoint group",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("group describe command requires group name as its argument")
+			}
+			return describeGroup(cmd.Context(), getClient, cmd.OutOrStdout(), args[0])
+		},
+	}
+
+	return cmd
+}
+
+func listGroup(ctx context.Context, getClient ClientGetter, output io.Writer) error {
+	client, err := getClient()
+	if err != nil {
+		return err
+	}
+
+	groupList, err := client.GroupV1alpha1().EndpointGroups().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	membersList, err := client.GroupV1alpha1().GroupMemberses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	var membersMap = make(map[string]groupv1alpha1.GroupMembers, len(membersList.Items))
+	for _, members := range membersList.Items {
+		membersMap[members.Name] = members
+	}
+	sort.Slice(groupList.Items, func(i, j int) bool {
+		return groupList.Items[i].Name < groupList.Items[j].Name
+	})
+
+	var table = newTable("name", "selector", "revision", "members")
+	for _, group := range groupList.Items {
+		var revision, count interface{} = none, none
+		if members, ok := membersMap[group.Name]; ok {
+			revision, count = members.Revision, len(members.GroupMembers)
+		}
+		addRow(table, group.Name, selectorToString(group.Spec.Selector), revision, count)
+	}
+
+	return printTable(output, table)
+}
+
+func describeGroup(ctx context.Context, getClient ClientGetter, output io.Writer, name string) error {
+	client, err := getClient()
+	if err != nil {
+		return err
+	}
+
+	group, err := client.GroupV1alpha1().EndpointGroups().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	var ipBlocks []string
+	for _, ipBlock := range group.Spec.IPBlocks {
+		ipBlocks = append(ipBlocks, ipBlockToString(ipBlock))
+	}
+
+	d := newDescriber(output)
+	d.field("Name", group.Name)
+	d.field("Description", orNone(group.Spec.Description))
+	d.field("Selector", selectorToString(group.Spec.Selector))
+	d.field("Groups", orNone(strings.Join(group.Spec.EndpointGroups, ",")))
+	d.field("Endpoints", orNone(strings.Join(group.Spec.Endpoints, ",")))
+	d.field("IPBlocks", orNone(strings.Join(ipBlocks, ",")))
+
+	members, err := client.GroupV1alpha1().GroupMemberses().Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		// groupmembers may not been created by group controller yet
+		d.field("Revision", none)
+		return d.Flush()
+	}
+	if err != nil {
+		return err
+	}
+	selector := labels.SelectorFromSet(labels.Set{lynxctrl.OwnerGroupLabel: name})
+	patchList, err := client.GroupV1alpha1().GroupMembersPatches().List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return err
+	}
+
+	d.field("Revision", members.Revision)
+	d.section("Members")
+	_ = d.Flush()
+
+	var memberTable = newTable("reference", "ips")
+	for _, member := range members.GroupMembers {
+		addRow(memberTable, referenceToString(member.EndpointReference), ipsToString(member.IPs))
+	}
+	if err = printTable(output, memberTable); err != nil {
+		return err
+	}
+
+	d.section("Recent Patches")
+	_ = d.Flush()
+
+	sort.Slice(patchList.Items, func(i, j int) bool {
+		return patchList.Items[i].AppliedToGroupMembers.Revision > patchList.Items[j].AppliedToGroupMembers.Revision
+	})
+	var patchTable = newTable("name", "revision", "added", "updated", "removed")
+	for _, patch := range patchList.Items {
+		addRow(patchTable, patch.Name, patch.AppliedToGroupMembers.Revision, membersToString(patch.AddedGroupMembers),
+			membersToString(patch.UpdatedGroupMembers), membersToString(patch.RemovedGroupMembers))
+	}
+
+	return printTable(output, patchTable)
+}
+
+func referenceToString(reference groupv1alpha1.EndpointReference) string {
+	return fmt.Sprintf("%s=%s", reference.ExternalIDName, reference.ExternalIDValue)
+}
+
+func membersToString(members []groupv1alpha1.GroupMember) string {
+	var items []string
+	for _, member := range members {
+		items = append(items, fmt.Sprintf("%s(%s)", referenceToString(member.EndpointReference), ipsToString(member.IPs)))
+	}
+	return orNone(strings.Join(items, ","))
+}
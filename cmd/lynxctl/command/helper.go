@@ -0,0 +1,141 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	"github.com/smartxworks/lynx/pkg/client/clientset_generated/clientset"
+	"github.com/smartxworks/lynx/pkg/types"
+)
+
+// ClientGetter returns the clientset commands access lynx resources with.
+type ClientGetter func() (clientset.Interface, error)
+
+const none = "<none>"
+
+// mapJoin joins the map items ordered by keys.
+func mapJoin(m map[string]string, connector string, separator string) string {
+	var items []string
+
+	for key, value := range m {
+		items = append(items, fmt.Sprintf("%s%s%s", key, connector, value))
+	}
+	sort.Strings(items)
+
+	return orNone(strings.Join(items, separator))
+}
+
+func orNone(str string) string {
+	if str == "" {
+		return none
+	}
+	return str
+}
+
+func selectorToString(selector *metav1.LabelSelector) string {
+	if selector == nil {
+		return none
+	}
+	str := metav1.FormatLabelSelector(selector)
+	if str == "" {
+		// empty selector matches all endpoints
+		return "<all>"
+	}
+	return str
+}
+
+func ipsToString(ips []types.IPAddress) string {
+	var items []string
+	for _, ip := range ips {
+		items = append(items, string(ip))
+	}
+	return orNone(strings.Join(items, ","))
+}
+
+func peerToString(peer *securityv1alpha1.SecurityPolicyPeer) string {
+	var items []string
+
+	for _, group := range peer.EndpointGroups {
+		items = append(items, "group:"+group)
+	}
+	for _, endpoint := range peer.Endpoints {
+		items = append(items, "endpoint:"+endpoint)
+	}
+	if peer.EndpointSelector != nil {
+		items = append(items, "selector:"+selectorToString(peer.EndpointSelector))
+	}
+	for _, ipBlock := range peer.IPBlocks {
+		items = append(items, ipBlockToString(ipBlock))
+	}
+
+	if len(items) == 0 {
+		return "<all>"
+	}
+	return strings.Join(items, ",")
+}
+
+func ipBlockToString(ipBlock securityv1alpha1.IPBlock) string {
+	str := fmt.Sprintf("%s/%d", ipBlock.IP, ipBlock.PrefixLength)
+	if len(ipBlock.Except) != 0 {
+		str += fmt.Sprintf("(except %s)", strings.Join(ipBlock.Except, ","))
+	}
+	return str
+}
+
+func portsToString(ports []securityv1alpha1.SecurityPolicyPort) string {
+	var items []string
+
+	for _, port := range ports {
+		switch {
+		case port.Protocol == securityv1alpha1.ProtocolICMP && port.ICMPType != nil && port.ICMPCode != nil:
+			items = append(items, fmt.Sprintf("ICMP(type=%d,code=%d)", *port.ICMPType, *port.ICMPCode))
+		case port.Protocol == securityv1alpha1.ProtocolICMP && port.ICMPType != nil:
+			items = append(items, fmt.Sprintf("ICMP(type=%d)", *port.ICMPType))
+		case port.PortRange != "":
+			items = append(items, fmt.Sprintf("%s/%s", port.Protocol, port.PortRange))
+		default:
+			items = append(items, string(port.Protocol))
+		}
+	}
+
+	if len(items) == 0 {
+		return "<all>"
+	}
+	return strings.Join(items, ",")
+}
+
+// ruleAction returns the action of the rule, empty action means allow.
+func ruleAction(action securityv1alpha1.RuleAction) securityv1alpha1.RuleAction {
+	if action == "" {
+		return securityv1alpha1.RuleActionAllow
+	}
+	return action
+}
+
+// enforcementMode returns the enforcement mode of the policy, empty mode means enforce.
+func enforcementMode(mode securityv1alpha1.EnforcementMode) securityv1alpha1.EnforcementMode {
+	if mode == "" {
+		return securityv1alpha1.EnforcementModeEnforce
+	}
+	return mode
+}
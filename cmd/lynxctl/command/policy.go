@@ -0,0 +1,204 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	lynxctrl "github.com/smartxworks/lynx/pkg/controller"
+)
+
+func NewPolicyCommand(getClient ClientGetter) *cobra.Command {
+	ac := &cobra.Command{
+		Use:   "policy <subcommand>",
+		Short: "SecurityPolicy related commands",
+	}
+
+	ac.AddCommand(newPolicyListCommand(getClient))
+	ac.AddCommand(newPolicyDescribeCommand(getClient))
+	ac.AddCommand(newPolicyRulesCommand(getClient))
+
+	return ac
+}
+
+func newPolicyListCommand(getClient ClientGetter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List all security policies",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listPolicy(cmd.Context(), getClient, cmd.OutOrStdout())
+		},
+	}
+
+	return cmd
+}
+
+func newPolicyDescribeCommand(getClient ClientGetter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "describe <policy name>",
+		Short: "Show details and rules of a security policy",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("policy describe command requires policy name as its argument")
+			}
+			return describePolicy(cmd.Context(), getClient, cmd.OutOrStdout(), args[0])
+		},
+	}
+
+	return cmd
+}
+
+func newPolicyRulesCommand(getClient ClientGetter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rules <policy name>",
+		Short: "Show the PolicyRules a security policy expanded into",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("policy rules command requires policy name as its argument")
+			}
+			return listPolicyRules(cmd.Context(), getClient, cmd.OutOrStdout(), args[0])
+		},
+	}
+
+	return cmd
+}
+
+func listPolicy(ctx context.Context, getClient ClientGetter, output io.Writer) error {
+	client, err := getClient()
+	if err != nil {
+		return err
+	}
+
+	policyList, err := client.SecurityV1alpha1().SecurityPolicies().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	return printPolicies(output, policyList.Items)
+}
+
+func printPolicies(output io.Writer, policies []securityv1alpha1.SecurityPolicy) error {
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].Name < policies[j].Name
+	})
+
+	var table = newTable("name", "tier", "priority", "mode", "applied-to", "ingress", "egress")
+	for _, policy := range policies {
+		appliedTo := securityv1alpha1.SecurityPolicyPeer{
+			EndpointGroups:   policy.Spec.AppliedTo.EndpointGroups,
+			Endpoints:        policy.Spec.AppliedTo.Endpoints,
+			EndpointSelector: policy.Spec.AppliedTo.EndpointSelector,
+		}
+		addRow(table, policy.Name, policy.Spec.Tier, policy.Spec.Priority, enforcementMode(policy.Spec.EnforcementMode),
+			peerToString(&appliedTo), len(policy.Spec.IngressRules), len(policy.Spec.EgressRules))
+	}
+
+	return printTable(output, table)
+}
+
+func describePolicy(ctx context.Context, getClient ClientGetter, output io.Writer, name string) error {
+	client, err := getClient()
+	if err != nil {
+		return err
+	}
+
+	policy, err := client.SecurityV1alpha1().SecurityPolicies().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	appliedTo := securityv1alpha1.SecurityPolicyPeer{
+		EndpointGroups:   policy.Spec.AppliedTo.EndpointGroups,
+		Endpoints:        policy.Spec.AppliedTo.Endpoints,
+		EndpointSelector: policy.Spec.AppliedTo.EndpointSelector,
+	}
+
+	d := newDescriber(output)
+	d.field("Name", policy.Name)
+	d.field("Tier", policy.Spec.Tier)
+	d.field("Priority", policy.Spec.Priority)
+	d.field("EnforcementMode", enforcementMode(policy.Spec.EnforcementMode))
+	d.field("SymmetricMode", policy.Spec.SymmetricMode)
+	d.field("AppliedTo", peerToString(&appliedTo))
+	d.section("Rules")
+	_ = d.Flush()
+
+	var table = newTable("name", "direction", "action", "peers", "ports", "logging")
+	for _, rule := range policy.Spec.IngressRules {
+		addRow(table, rule.Name, "Ingress", ruleAction(rule.Action), peerToString(&rule.From), portsToString(rule.Ports), rule.Logging)
+	}
+	for _, rule := range policy.Spec.EgressRules {
+		addRow(table, rule.Name, "Egress", ruleAction(rule.Action), peerToString(&rule.To), portsToString(rule.Ports), rule.Logging)
+	}
+
+	return printTable(output, table)
+}
+
+func listPolicyRules(ctx context.Context, getClient ClientGetter, output io.Writer, name string) error {
+	client, err := getClient()
+	if err != nil {
+		return err
+	}
+
+	selector := labels.SelectorFromSet(labels.Set{lynxctrl.OwnerPolicyLabel: name})
+	ruleList, err := client.PolicyruleV1alpha1().PolicyRules(metav1.NamespaceNone).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return err
+	}
+	sort.Slice(ruleList.Items, func(i, j int) bool {
+		return ruleList.Items[i].Name < ruleList.Items[j].Name
+	})
+
+	var table = newTable("name", "rule", "direction", "action", "src", "dst", "protocol", "src-port", "dst-port", "state", "packets")
+	for _, rule := range ruleList.Items {
+		spec := rule.Spec
+		protocol := spec.IpProtocol
+		if spec.IcmpType != nil {
+			protocol = fmt.Sprintf("%s(type=%d)", protocol, *spec.IcmpType)
+		}
+		if spec.MonitorOnly {
+			spec.Action += "(Monitor)"
+		}
+		addRow(table, rule.Name, rule.Annotations[lynxctrl.OwnerRuleAnnotation], spec.Direction, spec.Action,
+			ipOrAll(spec.SrcIpAddr), ipOrAll(spec.DstIpAddr), ipOrAll(protocol), portOrAll(spec.SrcPort), portOrAll(spec.DstPort),
+			orNone(string(rule.Status.EnforceState)), rule.Status.MatchStatistics)
+	}
+
+	return printTable(output, table)
+}
+
+func ipOrAll(str string) string {
+	if str == "" {
+		return "*"
+	}
+	return str
+}
+
+func portOrAll(port uint16) string {
+	if port == 0 {
+		return "*"
+	}
+	return fmt.Sprint(port)
+}
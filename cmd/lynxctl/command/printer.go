@@ -0,0 +1,69 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+func printTable(output io.Writer, table *metav1.Table) error {
+	printer := printers.NewTablePrinter(printers.PrintOptions{})
+	return printer.PrintObj(table, output)
+}
+
+func newTable(columns ...string) *metav1.Table {
+	var table = &metav1.Table{}
+
+	for _, column := range columns {
+		table.ColumnDefinitions = append(table.ColumnDefinitions, metav1.TableColumnDefinition{
+			Name: column,
+			Type: "string",
+		})
+	}
+
+	return table
+}
+
+func addRow(table *metav1.Table, row ...interface{}) {
+	table.Rows = append(table.Rows, metav1.TableRow{
+		Cells: row,
+	})
+}
+
+// describer prints fields of a resource as aligned "key: value" lines.
+type describer struct {
+	*tabwriter.Writer
+}
+
+func newDescriber(output io.Writer) *describer {
+	return &describer{Writer: tabwriter.NewWriter(output, 0, 8, 2, ' ', 0)}
+}
+
+func (d *describer) field(name string, value interface{}) {
+	fmt.Fprintf(d, "%s:\t%v\n", name, value)
+}
+
+// section prints a title line, the content printed after should be indented by caller.
+func (d *describer) section(title string) {
+	_ = d.Flush()
+	fmt.Fprintf(d, "\n%s:\n", title)
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+)
+
+func NewTierCommand(getClient ClientGetter) *cobra.Command {
+	ac := &cobra.Command{
+		Use:   "tier <subcommand>",
+		Short: "Tier related commands",
+	}
+
+	ac.AddCommand(newTierListCommand(getClient))
+	ac.AddCommand(newTierDescribeCommand(getClient))
+
+	return ac
+}
+
+func newTierListCommand(getClient ClientGetter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List all tiers in the order they take effect",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listTier(cmd.Context(), getClient, cmd.OutOrStdout())
+		},
+	}
+
+	return cmd
+}
+
+func newTierDescribeCommand(getClient ClientGetter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "describe <tier name>",
+		Short: "Show details of a tier and the policies in it",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("tier describe command requires tier name as its argument")
+			}
+			return describeTier(cmd.Context(), getClient, cmd.OutOrStdout(), args[0])
+		},
+	}
+
+	return cmd
+}
+
+func listTier(ctx context.Context, getClient ClientGetter, output io.Writer) error {
+	client, err := getClient()
+	if err != nil {
+		return err
+	}
+
+	tierList, err := client.SecurityV1alpha1().Tiers().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	sort.Slice(tierList.Items, func(i, j int) bool {
+		if tierList.Items[i].Spec.Priority != tierList.Items[j].Spec.Priority {
+			return tierList.Items[i].Spec.Priority < tierList.Items[j].Spec.Priority
+		}
+		return tierList.Items[i].Name < tierList.Items[j].Name
+	})
+
+	var table = newTable("name", "priority", "tiermode", "description")
+	for _, tier := range tierList.Items {
+		addRow(table, tier.Name, tier.Spec.Priority, tier.Spec.TierMode, tier.Spec.Description)
+	}
+
+	return printTable(output, table)
+}
+
+func describeTier(ctx context.Context, getClient ClientGetter, output io.Writer, name string) error {
+	client, err := getClient()
+	if err != nil {
+		return err
+	}
+
+	tier, err := client.SecurityV1alpha1().Tiers().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	policyList, err := client.SecurityV1alpha1().SecurityPolicies().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	d := newDescriber(output)
+	d.field("Name", tier.Name)
+	d.field("Priority", tier.Spec.Priority)
+	d.field("TierMode", tier.Spec.TierMode)
+	d.field("Description", orNone(tier.Spec.Description))
+	d.section("Policies")
+	_ = d.Flush()
+
+	var policies []securityv1alpha1.SecurityPolicy
+	for _, policy := range policyList.Items {
+		if policy.Spec.Tier == tier.Name {
+			policies = append(policies, policy)
+		}
+	}
+
+	return printPolicies(output, policies)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/smartxworks/lynx/cmd/lynxctl/command"
+	"github.com/smartxworks/lynx/pkg/client/clientset_generated/clientset"
+)
+
+func main() {
+	if err := rootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func rootCommand() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "lynxctl",
+		Short: "Lynxctl: inspect lynx resources and how they take effect",
+	}
+
+	rootCmd.Root().SilenceUsage = true
+	rootCmd.Root().SetHelpCommand(&cobra.Command{Hidden: true})
+	// flag kubeconfig registered by controller-runtime
+	rootCmd.PersistentFlags().AddGoFlagSet(flag.CommandLine)
+
+	rootCmd.AddCommand(command.NewTierCommand(getClient))
+	rootCmd.AddCommand(command.NewPolicyCommand(getClient))
+	rootCmd.AddCommand(command.NewEndpointCommand(getClient))
+	rootCmd.AddCommand(command.NewGroupCommand(getClient))
+	rootCmd.AddCommand(command.NewAgentCommand(getClient))
+
+	return rootCmd
+}
+
+func getClient() (clientset.Interface, error) {
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get kubeconfig: %s", err)
+	}
+	return clientset.NewForConfig(config)
+}
@@ -6,9 +6,11 @@ require (
 	github.com/99designs/gqlgen v0.13.0
 	github.com/agiledragon/gomonkey v2.0.2+incompatible
 	github.com/cenk/hub v1.0.1 // indirect
+	github.com/contiv/libOpenflow v0.0.0-20200107061746-e3817550c83b
 	github.com/contiv/libovsdb v0.0.0
 	github.com/contiv/ofnet v0.0.0-00010101000000-000000000000
 	github.com/fatih/color v1.7.0
+	github.com/fsnotify/fsnotify v1.4.9
 	github.com/gertd/go-pluralize v0.1.7
 	github.com/go-logr/logr v0.4.0 // indirect
 	github.com/go-openapi/spec v0.19.3
@@ -0,0 +1,175 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policyrule
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/contiv/ofnet"
+	"github.com/contiv/ofnet/ofctrl"
+	"k8s.io/klog"
+
+	"github.com/smartxworks/lynx/pkg/agent/datapath"
+	"github.com/smartxworks/lynx/pkg/agent/ovsctl"
+	networkpolicyv1alpha1 "github.com/smartxworks/lynx/pkg/apis/policyrule/v1alpha1"
+)
+
+const (
+	datapathSyncInterval = time.Minute
+	// datapathSyncGracePeriod is the time flows of rules installed recently are not treated as missing,
+	// flow mods are sent to ovs asynchronously and may have not been applied yet.
+	datapathSyncGracePeriod = 10 * time.Second
+)
+
+// syncPolicyRules installs all PolicyRules in the cache into datapath. It runs on agent startup,
+// so that flows of existing PolicyRules are known before stale flows are removed from datapath.
+func (r *PolicyRuleReconciler) syncPolicyRules() error {
+	var policyRuleList networkpolicyv1alpha1.PolicyRuleList
+
+	err := r.List(context.Background(), &policyRuleList)
+	if err != nil {
+		return fmt.Errorf("unable to list policyRules: %s", err)
+	}
+
+	for item := range policyRuleList.Items {
+		policyRule := policyRuleList.Items[item]
+		if !policyRule.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if err = r.processPolicyRuleAdd(&policyRule); err != nil {
+			// the policyRule would be retried by the reconciler
+			klog.Errorf("unable to add policyRule %s to datapath: %s", policyRule.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// syncDatapathFlows diffs rules expected in datapath against flows actually installed in the
+// tier tables. Missing rules would be reinstalled, and stale rules would be removed: rules ofnet
+// installed are removed through ofnet, and only flows with cookies allocated by lynx are removed
+// by cookie. Flows left from a previous agent run have cookies of the previous round, which are
+// removed by ofnet after agent restart.
+func (r *PolicyRuleReconciler) syncDatapathFlows() {
+	var policyAgent = r.Agent.GetDatapath().GetPolicyAgent()
+	var tableFlows = make(map[uint8][]*ovsctl.Flow)
+	var tierTables = make(map[uint8]*ofctrl.Table)
+
+	r.flowKeyReferenceMapLock.Lock()
+	defer r.flowKeyReferenceMapLock.Unlock()
+
+	// rules installed by ofnet but not expected, e.g. failed to delete before
+	for _, ruleID := range staleOfnetRules(policyAgent.Rules, r.flowKeyDatapathRuleMap) {
+		klog.Infof("remove stale rule %s from datapath", ruleID)
+		if err := policyAgent.DelRule(policyAgent.Rules[ruleID].Rule, nil); err != nil {
+			klog.Errorf("unable to remove stale rule %s: %s", ruleID, err)
+		}
+	}
+
+	for _, direction := range []uint8{ofnet.POLICY_DIRECTION_OUT, ofnet.POLICY_DIRECTION_IN} {
+		for tier := uint8(0); tier < numOfDatapathTiers; tier++ {
+			table, _, err := policyAgent.GetTierTable(direction, tier)
+			if err != nil {
+				klog.Errorf("unable get tier table of direction %d tier %d: %s", direction, tier, err)
+				return
+			}
+			tableFlows[table.TableId], err = ovsctl.DumpFlows(r.BridgeName, int(table.TableId))
+			if err != nil {
+				klog.Errorf("unable to sync datapath flows: %s", err)
+				return
+			}
+			tierTables[table.TableId] = table
+		}
+	}
+
+	var expectedFlows = make(map[*ovsctl.Flow]bool)
+	for flowKey, datapathRule := range r.flowKeyDatapathRuleMap {
+		table, _, err := policyAgent.GetTierTable(datapathRule.direction, datapathRule.tier)
+		if err != nil {
+			klog.Errorf("unable get table of rule %s: %s", flowKey, err)
+			continue
+		}
+
+		flow := findRuleFlow(datapathRule.rule, tableFlows[table.TableId])
+		if flow != nil {
+			expectedFlows[flow] = true
+			continue
+		}
+
+		if time.Since(r.flowKeyInstallTime[flowKey]) < datapathSyncGracePeriod {
+			// flow of the rule installed recently may have not been applied by ovs yet
+			continue
+		}
+
+		klog.Infof("rule %s missing in datapath, reinstall it", flowKey)
+		if err = r.deletePolicyRuleFromDatapath(flowKey); err != nil {
+			klog.Errorf("unable to reinstall rule %s: %s", flowKey, err)
+			continue
+		}
+		if err = r.addPolicyRuleToDatapath(flowKey, datapathRule); err != nil {
+			klog.Errorf("unable to reinstall rule %s: %s", flowKey, err)
+		}
+	}
+
+	for tableID, flows := range tableFlows {
+		table := tierTables[tableID]
+		for _, flow := range staleRuleFlows(flows, expectedFlows) {
+			if !datapath.IsExtendedRuleCookie(table.Switch, flow.Cookie) {
+				// never remove flows of ofnet or others behind them
+				continue
+			}
+			klog.Infof("remove stale flow %+v from datapath", flow)
+			table.Switch.DeleteFlowByCookie(flow.Cookie, ^uint64(0))
+		}
+	}
+}
+
+// staleOfnetRules returns ids of rules installed by ofnet but not expected to be installed by ofnet.
+func staleOfnetRules(ofnetRules map[string]*ofnet.PolicyRule, expectedRules map[string]*datapathPolicyRule) []string {
+	var staleRules []string
+	for ruleID := range ofnetRules {
+		if expectedRule, ok := expectedRules[ruleID]; !ok || expectedRule.rule.IsExtended() {
+			staleRules = append(staleRules, ruleID)
+		}
+	}
+	return staleRules
+}
+
+// findRuleFlow returns the flow installed for the rule, nil if not found.
+func findRuleFlow(rule *datapath.PolicyRule, flows []*ovsctl.Flow) *ovsctl.Flow {
+	for _, flow := range flows {
+		if ruleFlowMatch(rule, flow) {
+			return flow
+		}
+	}
+	return nil
+}
+
+// staleRuleFlows returns policy rule flows not in the expected flows. Flows lower than
+// policy priority offset (e.g. tier table miss flow) are not policy rule flows.
+func staleRuleFlows(flows []*ovsctl.Flow, expectedFlows map[*ovsctl.Flow]bool) []*ovsctl.Flow {
+	var staleFlows []*ovsctl.Flow
+	for _, flow := range flows {
+		if flow.Priority < ofnet.FLOW_POLICY_PRIORITY_OFFSET || expectedFlows[flow] {
+			continue
+		}
+		staleFlows = append(staleFlows, flow)
+	}
+	return staleFlows
+}
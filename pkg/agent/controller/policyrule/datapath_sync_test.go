@@ -0,0 +1,312 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policyrule
+
+import (
+	"testing"
+
+	"github.com/contiv/ofnet"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/smartxworks/lynx/pkg/agent/datapath"
+	"github.com/smartxworks/lynx/pkg/agent/ovsctl"
+)
+
+func TestFindRuleFlow(t *testing.T) {
+	var icmpTypeEchoRequest, icmpCodeZero uint8 = 8, 0
+	var dumpOutput = `OFPST_FLOW reply (OF1.3) (xid=0x2):
+ cookie=0x1000000000001, duration=12.3s, table=30, n_packets=5, n_bytes=490, priority=110,tcp,nw_src=10.0.0.0/24,tp_dst=80 actions=goto_table:45
+ cookie=0x1000000000002, duration=12.3s, table=30, n_packets=0, n_bytes=0, priority=20,ip,nw_dst=10.0.0.1 actions=drop
+ cookie=0x1000000000003, duration=12.3s, table=30, n_packets=0, n_bytes=0, priority=1 actions=goto_table:31
+ cookie=0x1010000000001, duration=12.3s, table=30, n_packets=3, n_bytes=294, priority=60,icmp,nw_src=10.0.0.2,icmp_type=8 actions=goto_table:45
+ cookie=0x1010000000002, duration=12.3s, table=30, n_packets=0, n_bytes=0, priority=70,sctp,nw_dst=10.0.0.3,tp_dst=3868 actions=goto_table:45
+ cookie=0x1010000000003, duration=12.3s, table=30, n_packets=0, n_bytes=0, priority=80,tcp,nw_dst=10.0.0.4,tp_dst=22 actions=controller(id=19544,meter_id=2)
+ cookie=0x1010000000004, duration=12.3s, table=30, n_packets=7, n_bytes=686, priority=90,reg6=0/0x10,tcp,nw_dst=10.0.0.5,tp_dst=443 actions=load:0x1->NXM_NX_REG6[4],resubmit(,30)
+ cookie=0x1010000000005, duration=12.3s, table=30, n_packets=2, n_bytes=148, priority=100,tcp,nw_dst=10.0.0.6,tp_dst=8080 actions=controller(id=19545,meter_id=1),goto_table:45
+ cookie=0x1010000000006, duration=12.3s, table=30, n_packets=1, n_bytes=74, priority=100,udp,nw_dst=10.0.0.6,tp_dst=53 actions=controller(id=19545,meter_id=1)
+ cookie=0x1010000000007, duration=12.3s, table=30, n_packets=9, n_bytes=882, priority=110,tcp,nw_dst=10.0.0.7,tp_dst=80 actions=sample(probability=65535,collector_set_id=1,obs_domain_id=1,obs_point_id=7),goto_table:45
+ cookie=0x1000000000004, duration=12.3s, table=30, n_packets=0, n_bytes=0, priority=120,tcp,nw_dst=10.0.0.8,tp_dst=80,tcp_flags=+syn-ack actions=goto_table:45
+ cookie=0x1000000000005, duration=12.3s, table=30, n_packets=0, n_bytes=0, priority=120,tcp,nw_dst=10.0.0.8,tp_dst=80 actions=goto_table:45
+`
+	flows, err := ovsctl.ParseFlows(dumpOutput)
+	if err != nil {
+		t.Fatalf("unable parse flows: %s", err)
+	}
+
+	tests := []struct {
+		name         string
+		rule         *datapath.PolicyRule
+		expectCookie uint64
+		expectFound  bool
+	}{
+		{
+			name: "allow rule installed",
+			rule: &datapath.PolicyRule{OfnetPolicyRule: ofnet.OfnetPolicyRule{
+				Priority:   100,
+				SrcIpAddr:  "10.0.0.0/24",
+				IpProtocol: 6,
+				DstPort:    80,
+				Action:     "allow",
+			}},
+			expectCookie: 0x1000000000001,
+			expectFound:  true,
+		},
+		{
+			name: "deny rule installed",
+			rule: &datapath.PolicyRule{OfnetPolicyRule: ofnet.OfnetPolicyRule{
+				Priority:  10,
+				DstIpAddr: "10.0.0.1/32",
+				Action:    "deny",
+			}},
+			expectCookie: 0x1000000000002,
+			expectFound:  true,
+		},
+		{
+			name: "rule installed with different action",
+			rule: &datapath.PolicyRule{OfnetPolicyRule: ofnet.OfnetPolicyRule{
+				Priority:   100,
+				SrcIpAddr:  "10.0.0.0/24",
+				IpProtocol: 6,
+				DstPort:    80,
+				Action:     "deny",
+			}},
+			expectFound: false,
+		},
+		{
+			name: "rule installed with different port",
+			rule: &datapath.PolicyRule{OfnetPolicyRule: ofnet.OfnetPolicyRule{
+				Priority:   100,
+				SrcIpAddr:  "10.0.0.0/24",
+				IpProtocol: 6,
+				DstPort:    443,
+				Action:     "allow",
+			}},
+			expectFound: false,
+		},
+		{
+			name: "icmp rule installed",
+			rule: &datapath.PolicyRule{
+				OfnetPolicyRule: ofnet.OfnetPolicyRule{
+					Priority:   50,
+					SrcIpAddr:  "10.0.0.2",
+					IpProtocol: 1,
+					Action:     "allow",
+				},
+				ICMPType: &icmpTypeEchoRequest,
+			},
+			expectCookie: 0x1010000000001,
+			expectFound:  true,
+		},
+		{
+			name: "icmp rule installed with different code",
+			rule: &datapath.PolicyRule{
+				OfnetPolicyRule: ofnet.OfnetPolicyRule{
+					Priority:   50,
+					SrcIpAddr:  "10.0.0.2",
+					IpProtocol: 1,
+					Action:     "allow",
+				},
+				ICMPType: &icmpTypeEchoRequest,
+				ICMPCode: &icmpCodeZero,
+			},
+			expectFound: false,
+		},
+		{
+			name: "sctp rule installed",
+			rule: &datapath.PolicyRule{OfnetPolicyRule: ofnet.OfnetPolicyRule{
+				Priority:   60,
+				DstIpAddr:  "10.0.0.3",
+				IpProtocol: 132,
+				DstPort:    3868,
+				Action:     "allow",
+			}},
+			expectCookie: 0x1010000000002,
+			expectFound:  true,
+		},
+		{
+			name: "reject rule installed",
+			rule: &datapath.PolicyRule{OfnetPolicyRule: ofnet.OfnetPolicyRule{
+				Priority:   70,
+				DstIpAddr:  "10.0.0.4",
+				IpProtocol: 6,
+				DstPort:    22,
+				Action:     "reject",
+			}},
+			expectCookie: 0x1010000000003,
+			expectFound:  true,
+		},
+		{
+			name: "monitor rule installed",
+			rule: &datapath.PolicyRule{
+				OfnetPolicyRule: ofnet.OfnetPolicyRule{
+					Priority:   80,
+					DstIpAddr:  "10.0.0.5",
+					IpProtocol: 6,
+					DstPort:    443,
+					Action:     "deny",
+				},
+				MonitorOnly: true,
+			},
+			expectCookie: 0x1010000000004,
+			expectFound:  true,
+		},
+		{
+			name: "monitor rule not match enforced rule flow",
+			rule: &datapath.PolicyRule{
+				OfnetPolicyRule: ofnet.OfnetPolicyRule{
+					Priority:   70,
+					DstIpAddr:  "10.0.0.4",
+					IpProtocol: 6,
+					DstPort:    22,
+					Action:     "reject",
+				},
+				MonitorOnly: true,
+			},
+			expectFound: false,
+		},
+		{
+			name: "logging allow rule installed",
+			rule: &datapath.PolicyRule{
+				OfnetPolicyRule: ofnet.OfnetPolicyRule{
+					Priority:   90,
+					DstIpAddr:  "10.0.0.6",
+					IpProtocol: 6,
+					DstPort:    8080,
+					Action:     "allow",
+				},
+				Logging: true,
+			},
+			expectCookie: 0x1010000000005,
+			expectFound:  true,
+		},
+		{
+			name: "logging deny rule installed",
+			rule: &datapath.PolicyRule{
+				OfnetPolicyRule: ofnet.OfnetPolicyRule{
+					Priority:   90,
+					DstIpAddr:  "10.0.0.6",
+					IpProtocol: 17,
+					DstPort:    53,
+					Action:     "deny",
+				},
+				Logging: true,
+			},
+			expectCookie: 0x1010000000006,
+			expectFound:  true,
+		},
+		{
+			name: "sampled rule installed",
+			rule: &datapath.PolicyRule{OfnetPolicyRule: ofnet.OfnetPolicyRule{
+				Priority:   100,
+				DstIpAddr:  "10.0.0.7",
+				IpProtocol: 6,
+				DstPort:    80,
+				Action:     "allow",
+			}},
+			expectCookie: 0x1010000000007,
+			expectFound:  true,
+		},
+		{
+			name: "tcp flags rule installed",
+			rule: &datapath.PolicyRule{OfnetPolicyRule: ofnet.OfnetPolicyRule{
+				Priority:   110,
+				DstIpAddr:  "10.0.0.8",
+				IpProtocol: 6,
+				DstPort:    80,
+				TcpFlags:   "syn,!ack",
+				Action:     "allow",
+			}},
+			expectCookie: 0x1000000000004,
+			expectFound:  true,
+		},
+		{
+			name: "rule without tcp flags installed beside tcp flags rule",
+			rule: &datapath.PolicyRule{OfnetPolicyRule: ofnet.OfnetPolicyRule{
+				Priority:   110,
+				DstIpAddr:  "10.0.0.8",
+				IpProtocol: 6,
+				DstPort:    80,
+				Action:     "allow",
+			}},
+			expectCookie: 0x1000000000005,
+			expectFound:  true,
+		},
+		{
+			name: "rule installed with different tcp flags",
+			rule: &datapath.PolicyRule{OfnetPolicyRule: ofnet.OfnetPolicyRule{
+				Priority:   110,
+				DstIpAddr:  "10.0.0.8",
+				IpProtocol: 6,
+				DstPort:    80,
+				TcpFlags:   "syn",
+				Action:     "allow",
+			}},
+			expectFound: false,
+		},
+		{
+			name: "rule without logging not match logging rule flow",
+			rule: &datapath.PolicyRule{OfnetPolicyRule: ofnet.OfnetPolicyRule{
+				Priority:   90,
+				DstIpAddr:  "10.0.0.6",
+				IpProtocol: 17,
+				DstPort:    53,
+				Action:     "reject",
+			}},
+			expectFound: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flow := findRuleFlow(tt.rule, flows)
+			if (flow != nil) != tt.expectFound {
+				t.Fatalf("expect found %t, got flow %+v", tt.expectFound, flow)
+			}
+			if flow != nil && flow.Cookie != tt.expectCookie {
+				t.Errorf("expect flow cookie %#x, got %#x", tt.expectCookie, flow.Cookie)
+			}
+		})
+	}
+}
+
+func TestStaleRuleFlows(t *testing.T) {
+	var expectedFlow = &ovsctl.Flow{Cookie: 1, Priority: 110}
+	var staleFlow = &ovsctl.Flow{Cookie: 2, Priority: 20}
+	var missFlow = &ovsctl.Flow{Cookie: 3, Priority: ofnet.FLOW_MISS_PRIORITY}
+
+	staleFlows := staleRuleFlows([]*ovsctl.Flow{expectedFlow, staleFlow, missFlow}, map[*ovsctl.Flow]bool{expectedFlow: true})
+	if len(staleFlows) != 1 || staleFlows[0] != staleFlow {
+		t.Errorf("expect only flow %+v stale, got %+v", staleFlow, staleFlows)
+	}
+}
+
+func TestStaleOfnetRules(t *testing.T) {
+	var ofnetRules = map[string]*ofnet.PolicyRule{
+		"expected": {Rule: &ofnet.OfnetPolicyRule{RuleId: "expected"}},
+		"removed":  {Rule: &ofnet.OfnetPolicyRule{RuleId: "removed"}},
+		"extended": {Rule: &ofnet.OfnetPolicyRule{RuleId: "extended"}},
+	}
+	var expectedRules = map[string]*datapathPolicyRule{
+		"expected": {rule: &datapath.PolicyRule{OfnetPolicyRule: ofnet.OfnetPolicyRule{RuleId: "expected", Action: "allow"}}},
+		"extended": {rule: &datapath.PolicyRule{OfnetPolicyRule: ofnet.OfnetPolicyRule{RuleId: "extended", Action: "reject"}}},
+	}
+
+	staleRules := sets.NewString(staleOfnetRules(ofnetRules, expectedRules)...)
+	if !staleRules.Equal(sets.NewString("removed", "extended")) {
+		t.Errorf("expect rules removed and extended stale, got %v", staleRules.List())
+	}
+}
@@ -19,14 +19,19 @@ package policyrule
 import (
 	"context"
 	"fmt"
+	"math"
+	"reflect"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/contiv/ofnet"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	k8stypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -34,9 +39,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	"github.com/smartxworks/lynx/pkg/agent/datapath"
 	networkpolicyv1alpha1 "github.com/smartxworks/lynx/pkg/apis/policyrule/v1alpha1"
+	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
 )
 
 var (
@@ -48,9 +56,25 @@ type PolicyRuleReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 	Agent  *ofnet.OfnetAgent
+	// AgentName is the name of this agent, used as the key of agent status in PolicyRule
+	AgentName string
+	// BridgeName is the bridge which policyRule flows installed on, used to collect flow statistics
+	BridgeName string
 
 	flowKeyReferenceMapLock sync.RWMutex
-	flowKeyReferenceMap     map[string]sets.String // Map flowKey to policyRule names
+	flowKeyReferenceMap     map[string]sets.String         // Map flowKey to policyRule names
+	flowKeyDatapathRuleMap  map[string]*datapathPolicyRule // Map flowKey to the rule installed in datapath
+	flowKeyInstallTime      map[string]time.Time           // Map flowKey to the time the rule installed
+
+	// datapathTiers keeps the datapath position of Tiers
+	datapathTiers tierPlacement
+}
+
+// datapathPolicyRule is the ofnet rule and the position where it has been installed.
+type datapathPolicyRule struct {
+	rule      *datapath.PolicyRule
+	direction uint8
+	tier      uint8
 }
 
 func (r *PolicyRuleReconciler) SetupWithManager(mgr ctrl.Manager) error {
@@ -59,6 +83,8 @@ func (r *PolicyRuleReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}
 
 	r.flowKeyReferenceMap = make(map[string]sets.String)
+	r.flowKeyDatapathRuleMap = make(map[string]*datapathPolicyRule)
+	r.flowKeyInstallTime = make(map[string]time.Time)
 
 	c, err := controller.New("policyrule-controller", mgr, controller.Options{
 		Reconciler: r,
@@ -69,10 +95,44 @@ func (r *PolicyRuleReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 	c.Watch(&source.Kind{Type: &networkpolicyv1alpha1.PolicyRule{}}, &handler.Funcs{
 		CreateFunc: r.addPolicyRule,
+		UpdateFunc: r.updatePolicyRule,
 		DeleteFunc: r.deletePolicyRule,
 	})
 
-	return nil
+	// rules of the tier should be reinstalled when the tier changes
+	c.Watch(&source.Kind{Type: &securityv1alpha1.Tier{}}, &handler.Funcs{
+		CreateFunc: func(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			r.enqueueTierPolicyRules(e.Meta.GetName(), q)
+		},
+		UpdateFunc: func(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			r.enqueueTierPolicyRules(e.MetaNew.GetName(), q)
+		},
+		DeleteFunc: func(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+			r.enqueueTierPolicyRules(e.Meta.GetName(), q)
+		},
+	})
+
+	// periodically sync flow statistics into policyRule status
+	err = mgr.Add(manager.RunnableFunc(func(stopChan <-chan struct{}) error {
+		wait.JitterUntil(r.syncPolicyRuleStatistics, statisticsSyncInterval, statisticsSyncJitterFactor, true, stopChan)
+		return nil
+	}))
+	if err != nil {
+		return err
+	}
+
+	// install existing policyRules on startup, then periodically repair drift between
+	// policyRules and flows in datapath, the first sync removes flows left by previous agent
+	return mgr.Add(manager.RunnableFunc(func(stopChan <-chan struct{}) error {
+		if !mgr.GetCache().WaitForCacheSync(stopChan) {
+			return fmt.Errorf("unable to wait for policyRule cache sync")
+		}
+		if err := r.syncPolicyRules(); err != nil {
+			return err
+		}
+		wait.Until(r.syncDatapathFlows, datapathSyncInterval, stopChan)
+		return nil
+	}))
 }
 
 // +kubebuilder:rbac:groups=networkpolicy.lynx.smartx.com,resources=policyrules,verbs=get;list;watch;create;update;patch;delete
@@ -89,13 +149,35 @@ func (r *PolicyRuleReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error)
 	}
 
 	if errors.IsNotFound(err) || !policyRule.DeletionTimestamp.IsZero() {
-		r.processPolicyRuleDelete(req.Name)
-		return ctrl.Result{}, nil
+		err = r.processPolicyRuleDelete(req.Name)
+		if err != nil {
+			klog.Errorf("unable to delete policyRule %s from datapath: %s", req.Name, err)
+		}
+		return ctrl.Result{}, err
+	}
+
+	err = r.processPolicyRuleAdd(&policyRule)
+	if err != nil {
+		klog.Errorf("unable to add policyRule %s to datapath: %s", req.Name, err)
+		statusErr := r.updateAgentStatus(req.Name, func(status *networkpolicyv1alpha1.AgentPolicyRuleStatus) {
+			status.EnforceState = networkpolicyv1alpha1.PolicyRuleFailedEnforced
+			status.Reason = err.Error()
+		})
+		if statusErr != nil {
+			klog.Errorf("unable to update policyRule %s status: %s", req.Name, statusErr)
+		}
+		return ctrl.Result{}, err
 	}
 
-	r.processPolicyRuleAdd(&policyRule)
+	err = r.updateAgentStatus(req.Name, func(status *networkpolicyv1alpha1.AgentPolicyRuleStatus) {
+		status.EnforceState = networkpolicyv1alpha1.PolicyRuleSuccessEnforced
+		status.Reason = ""
+	})
+	if err != nil {
+		klog.Errorf("unable to update policyRule %s status: %s", req.Name, err)
+	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{}, err
 }
 
 func (r *PolicyRuleReconciler) addPolicyRule(e event.CreateEvent, q workqueue.RateLimitingInterface) {
@@ -116,6 +198,24 @@ func (r *PolicyRuleReconciler) addPolicyRule(e event.CreateEvent, q workqueue.Ra
 	}})
 }
 
+func (r *PolicyRuleReconciler) updatePolicyRule(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	if e.MetaOld == nil || e.MetaNew == nil {
+		klog.Errorf("updatePolicyRule received with no metadata event: %v", e)
+		return
+	}
+
+	// ignore status update from agents, only spec changes and deletion need to reconcile
+	if e.MetaOld.GetGeneration() == e.MetaNew.GetGeneration() &&
+		e.MetaOld.GetDeletionTimestamp().IsZero() == e.MetaNew.GetDeletionTimestamp().IsZero() {
+		return
+	}
+
+	q.Add(ctrl.Request{NamespacedName: k8stypes.NamespacedName{
+		Namespace: e.MetaNew.GetNamespace(),
+		Name:      e.MetaNew.GetName(),
+	}})
+}
+
 func (r *PolicyRuleReconciler) deletePolicyRule(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
 	_, ok := e.Object.(*networkpolicyv1alpha1.PolicyRule)
 	if !ok {
@@ -134,74 +234,233 @@ func (r *PolicyRuleReconciler) deletePolicyRule(e event.DeleteEvent, q workqueue
 	}})
 }
 
-func (r *PolicyRuleReconciler) processPolicyRuleDelete(ruleName string) {
+// enqueueTierPolicyRules enqueues rules of the tier, the existing tiers keep their datapath
+// position when tiers change, unless all the tiers have been rearranged, then all the rules
+// would be enqueued.
+func (r *PolicyRuleReconciler) enqueueTierPolicyRules(tierName string, q workqueue.RateLimitingInterface) {
+	var policyRuleList networkpolicyv1alpha1.PolicyRuleList
+	var tierList securityv1alpha1.TierList
+
+	err := r.List(context.Background(), &tierList)
+	if err != nil {
+		klog.Errorf("unable to list tiers: %s", err)
+		return
+	}
+	r.datapathTiers.sync(tierList.Items)
+	allTiers := r.datapathTiers.takeMoved()
+
+	err = r.List(context.Background(), &policyRuleList)
+	if err != nil {
+		klog.Errorf("unable to list policyRules: %s", err)
+		return
+	}
+
+	for _, policyRule := range policyRuleList.Items {
+		if !allTiers && policyRule.Spec.Tier != tierName {
+			continue
+		}
+		q.Add(ctrl.Request{NamespacedName: k8stypes.NamespacedName{
+			Namespace: policyRule.Namespace,
+			Name:      policyRule.Name,
+		}})
+	}
+}
+
+func (r *PolicyRuleReconciler) processPolicyRuleDelete(ruleName string) error {
 	r.flowKeyReferenceMapLock.Lock()
 	defer r.flowKeyReferenceMapLock.Unlock()
 
 	var flowKey = flowKeyFromRuleName(ruleName)
 	if r.flowKeyReferenceMap[flowKey] == nil {
 		// already deleted
-		return
+		return nil
 	}
 
 	r.flowKeyReferenceMap[flowKey].Delete(ruleName)
 
 	if r.flowKeyReferenceMap[flowKey].Len() == 0 {
-		delete(r.flowKeyReferenceMap, flowKey)
+		if r.flowKeyDatapathRuleMap[flowKey] != nil {
+			klog.Infof("remove rule %s from datapath", flowKey)
+			if err := r.deletePolicyRuleFromDatapath(flowKey); err != nil {
+				// keep the reference, so the rule would be removed when retry
+				r.flowKeyReferenceMap[flowKey].Insert(ruleName)
+				return err
+			}
+		}
 
-		klog.Infof("remove rule %s from datapath", flowKey)
-		r.deletePolicyRuleFromDatapath(flowKey)
+		delete(r.flowKeyReferenceMap, flowKey)
 	}
+
+	return nil
 }
 
-func (r *PolicyRuleReconciler) processPolicyRuleAdd(policyRule *networkpolicyv1alpha1.PolicyRule) {
+func (r *PolicyRuleReconciler) processPolicyRuleAdd(policyRule *networkpolicyv1alpha1.PolicyRule) error {
+	// fetch tiers out of lock, tiers come from the informer cache
+	datapathRule, err := r.toDatapathPolicyRule(flowKeyFromRuleName(policyRule.Name), &policyRule.Spec)
+	if err != nil {
+		return err
+	}
+
 	r.flowKeyReferenceMapLock.Lock()
 	defer r.flowKeyReferenceMapLock.Unlock()
 
 	var flowKey = flowKeyFromRuleName(policyRule.Name)
+	var installedRule = r.flowKeyDatapathRuleMap[flowKey]
+
+	// reinstall the rule when it's position in datapath changes
+	if installedRule != nil && !datapathRuleIsSame(installedRule, datapathRule) {
+		klog.Infof("remove rule %s from datapath for reinstall", flowKey)
+		if err := r.deletePolicyRuleFromDatapath(flowKey); err != nil {
+			return err
+		}
+	}
 
-	if r.flowKeyReferenceMap[flowKey] == nil {
-		r.flowKeyReferenceMap[flowKey] = sets.NewString()
-
+	if datapathRule != nil && r.flowKeyDatapathRuleMap[flowKey] == nil {
 		klog.Infof("add rule %s to datapath", flowKey)
-		r.addPolicyRuleToDatapath(flowKey, &policyRule.Spec)
+		if err := r.addPolicyRuleToDatapath(flowKey, datapathRule); err != nil {
+			return err
+		}
 	}
 
+	if r.flowKeyReferenceMap[flowKey] == nil {
+		r.flowKeyReferenceMap[flowKey] = sets.NewString()
+	}
 	r.flowKeyReferenceMap[flowKey].Insert(policyRule.Name)
+
+	return nil
 }
 
-func (r *PolicyRuleReconciler) deletePolicyRuleFromDatapath(flowKey string) {
-	var err error
-	ofnetPolicyRule := &ofnet.OfnetPolicyRule{
-		RuleId: flowKey,
+// toDatapathPolicyRule converts PolicyRule to the rule installed in datapath, returns nil
+// if the rule needs not to be installed.
+func (r *PolicyRuleReconciler) toDatapathPolicyRule(ruleId string, rule *networkpolicyv1alpha1.PolicyRuleSpec) (*datapathPolicyRule, error) {
+	var tierList securityv1alpha1.TierList
+
+	err := r.List(context.Background(), &tierList)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list tiers: %s", err)
+	}
+
+	tier, priority, install, err := ruleFlowPosition(r.datapathTiers.sync(tierList.Items), rule)
+	if err != nil || !install {
+		return nil, err
 	}
 
-	datapath := r.Agent.GetDatapath()
-	err = datapath.GetPolicyAgent().DelRule(ofnetPolicyRule, nil)
+	ofnetPolicyRule, err := toOfnetPolicyRule(ruleId, rule)
 	if err != nil {
-		// Update policyRule enforce status for statistics and display. TODO
-		klog.Fatalf("del ofnetPolicyRule %v failed,", ofnetPolicyRule)
+		return nil, err
 	}
+	ofnetPolicyRule.Priority = priority
+
+	ruleDirection, err := getRuleDirection(rule.Direction)
+	if err != nil {
+		return nil, err
+	}
+
+	icmpType, icmpCode, err := getRuleICMPMatch(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	return &datapathPolicyRule{
+		rule: &datapath.PolicyRule{
+			OfnetPolicyRule: *ofnetPolicyRule,
+			ICMPType:        icmpType,
+			ICMPCode:        icmpCode,
+			MonitorOnly:     rule.MonitorOnly,
+			Logging:         rule.Logging || (rule.DefaultPolicyRule && tierDefaultRuleLogging(tierList.Items, rule.Tier)),
+		},
+		direction: ruleDirection,
+		tier:      tier,
+	}, nil
 }
 
-func (r *PolicyRuleReconciler) addPolicyRuleToDatapath(ruleId string, rule *networkpolicyv1alpha1.PolicyRuleSpec) {
-	// Process PolicyRule: convert it to ofnetPolicyRule, filter illegal PolicyRule; install ofnetPolicyRule flow
-	var err error
-	ofnetPolicyRule := toOfnetPolicyRule(ruleId, rule)
-	ruleDirection := getRuleDirection(rule.Direction)
-	ruleTier := getRuleTier(rule.Tier)
+func datapathRuleIsSame(r1, r2 *datapathPolicyRule) bool {
+	if r1 == nil || r2 == nil {
+		return r1 == r2
+	}
+	return reflect.DeepEqual(r1, r2)
+}
+
+func (r *PolicyRuleReconciler) deletePolicyRuleFromDatapath(flowKey string) error {
+	var installedRule = r.flowKeyDatapathRuleMap[flowKey]
+	if installedRule == nil {
+		return nil
+	}
+
+	policyAgent := r.Agent.GetDatapath().GetPolicyAgent()
+	err := datapath.DeletePolicyRule(policyAgent, installedRule.rule, installedRule.direction, installedRule.tier)
+	if err != nil {
+		return fmt.Errorf("del ofnetPolicyRule %s failed: %s", flowKey, err)
+	}
+	delete(r.flowKeyDatapathRuleMap, flowKey)
+	delete(r.flowKeyInstallTime, flowKey)
+
+	return nil
+}
 
-	datapath := r.Agent.GetDatapath()
-	err = datapath.GetPolicyAgent().AddRuleToTier(ofnetPolicyRule, ruleDirection, ruleTier)
+func (r *PolicyRuleReconciler) addPolicyRuleToDatapath(ruleId string, rule *datapathPolicyRule) error {
+	policyAgent := r.Agent.GetDatapath().GetPolicyAgent()
+	err := datapath.AddPolicyRule(policyAgent, rule.rule, rule.direction, rule.tier)
 	if err != nil {
-		// Update policyRule enforce status for statistics and display. TODO
-		klog.Fatalf("add ofnetPolicyRule %v failed,", ofnetPolicyRule)
+		return fmt.Errorf("add ofnetPolicyRule %+v failed: %s", rule.rule, err)
 	}
+
+	r.flowKeyDatapathRuleMap[ruleId] = rule
+	r.flowKeyInstallTime[ruleId] = time.Now()
+
+	return nil
 }
 
-func toOfnetPolicyRule(ruleId string, rule *networkpolicyv1alpha1.PolicyRuleSpec) *ofnet.OfnetPolicyRule {
-	ipProtoNo := protocolToInt(rule.IpProtocol)
-	ruleAction := getRuleAction(rule.Action)
+// GetPolicyRulesByFlowKey returns the PolicyRules which installed as the datapath rule flowKey.
+func (r *PolicyRuleReconciler) GetPolicyRulesByFlowKey(flowKey string) []networkpolicyv1alpha1.PolicyRule {
+	r.flowKeyReferenceMapLock.RLock()
+	ruleNames := r.flowKeyReferenceMap[flowKey].List()
+	r.flowKeyReferenceMapLock.RUnlock()
+
+	var policyRules []networkpolicyv1alpha1.PolicyRule
+	for _, ruleName := range ruleNames {
+		var policyRule networkpolicyv1alpha1.PolicyRule
+		if err := r.Get(context.Background(), k8stypes.NamespacedName{Name: ruleName}, &policyRule); err != nil {
+			klog.Errorf("unable to get policyRule %s: %s", ruleName, err)
+			continue
+		}
+		policyRules = append(policyRules, policyRule)
+	}
+
+	return policyRules
+}
+
+// updateAgentStatus updates status of this agent in the policyRule with updateFunc, and
+// recalculate the aggregated status. The policyRule is updated only when status changed.
+func (r *PolicyRuleReconciler) updateAgentStatus(ruleName string, updateFunc func(status *networkpolicyv1alpha1.AgentPolicyRuleStatus)) error {
+	var ctx = context.Background()
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var policyRule = networkpolicyv1alpha1.PolicyRule{}
+
+		err := r.Get(ctx, k8stypes.NamespacedName{Name: ruleName}, &policyRule)
+		if err != nil {
+			// ignore not found error, the policyRule may have been deleted
+			return client.IgnoreNotFound(err)
+		}
+
+		if !policyRule.Status.SetAgentStatus(r.AgentName, updateFunc) {
+			return nil
+		}
+
+		return r.Status().Update(ctx, &policyRule)
+	})
+}
+
+func toOfnetPolicyRule(ruleId string, rule *networkpolicyv1alpha1.PolicyRuleSpec) (*ofnet.OfnetPolicyRule, error) {
+	ipProtoNo, err := protocolToInt(rule.IpProtocol)
+	if err != nil {
+		return nil, err
+	}
+	ruleAction, err := getRuleAction(rule.Action)
+	if err != nil {
+		return nil, err
+	}
 
 	var rulePriority int
 	if rule.DefaultPolicyRule {
@@ -222,10 +481,10 @@ func toOfnetPolicyRule(ruleId string, rule *networkpolicyv1alpha1.PolicyRuleSpec
 		Action:     ruleAction,
 	}
 
-	return ofnetPolicyRule
+	return ofnetPolicyRule, nil
 }
 
-func protocolToInt(ipProtocol string) uint8 {
+func protocolToInt(ipProtocol string) (uint8, error) {
 	var protoNo uint8
 	switch ipProtocol {
 	case "ICMP":
@@ -234,29 +493,66 @@ func protocolToInt(ipProtocol string) uint8 {
 		protoNo = 6
 	case "UDP":
 		protoNo = 17
+	case "SCTP":
+		protoNo = 132
 	case "":
 		protoNo = 0
 	default:
-		klog.Fatalf("unsupport ipProtocol %s in policyRule", ipProtocol)
+		return 0, fmt.Errorf("unsupport ipProtocol %s in policyRule", ipProtocol)
 	}
-	return protoNo
+	return protoNo, nil
 }
 
-func getRuleAction(ruleAction networkpolicyv1alpha1.RuleAction) string {
+func getRuleAction(ruleAction networkpolicyv1alpha1.RuleAction) (string, error) {
 	var action string
 	switch ruleAction {
 	case networkpolicyv1alpha1.RuleActionAllow:
 		action = "allow"
 	case networkpolicyv1alpha1.RuleActionDrop:
 		action = "deny"
+	case networkpolicyv1alpha1.RuleActionReject:
+		action = "reject"
 	default:
-		klog.Fatalf("unsupport ruleAction %s in policyrule.", ruleAction)
-		return action
+		return "", fmt.Errorf("unsupport ruleAction %s in policyRule", ruleAction)
 	}
-	return action
+	return action, nil
 }
 
-func getRuleDirection(ruleDir networkpolicyv1alpha1.RuleDirection) uint8 {
+// getRuleICMPMatch returns icmp type and code the rule matches, nil matches all.
+func getRuleICMPMatch(rule *networkpolicyv1alpha1.PolicyRuleSpec) (*uint8, *uint8, error) {
+	if rule.IcmpType == nil && rule.IcmpCode == nil {
+		return nil, nil, nil
+	}
+	if rule.IpProtocol != "ICMP" {
+		return nil, nil, fmt.Errorf("icmpType and icmpCode only support with ICMP protocol in policyRule")
+	}
+	if rule.IcmpType == nil {
+		return nil, nil, fmt.Errorf("icmpCode must be used with icmpType in policyRule")
+	}
+
+	icmpType, err := toUint8(*rule.IcmpType)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unsupport icmpType in policyRule: %s", err)
+	}
+	if rule.IcmpCode == nil {
+		return &icmpType, nil, nil
+	}
+
+	icmpCode, err := toUint8(*rule.IcmpCode)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unsupport icmpCode in policyRule: %s", err)
+	}
+	return &icmpType, &icmpCode, nil
+}
+
+func toUint8(value int32) (uint8, error) {
+	if value < 0 || value > math.MaxUint8 {
+		return 0, fmt.Errorf("value %d out of range [0, %d]", value, math.MaxUint8)
+	}
+	return uint8(value), nil
+}
+
+func getRuleDirection(ruleDir networkpolicyv1alpha1.RuleDirection) (uint8, error) {
 	var direction uint8
 	switch ruleDir {
 	case networkpolicyv1alpha1.RuleDirectionOut:
@@ -264,24 +560,9 @@ func getRuleDirection(ruleDir networkpolicyv1alpha1.RuleDirection) uint8 {
 	case networkpolicyv1alpha1.RuleDirectionIn:
 		direction = 1
 	default:
-		klog.Fatalf("unsupport ruleDirection %s in policyRule.", ruleDir)
-	}
-	return direction
-}
-
-func getRuleTier(ruleTier string) uint8 {
-	var tier uint8
-	switch ruleTier {
-	case "tier0":
-		tier = 0
-	case "tier1":
-		tier = 1
-	case "tier2":
-		tier = 2
-	default:
-		klog.Fatalf("unsupport ruleTier %s in policyRule.", ruleTier)
+		return 0, fmt.Errorf("unsupport ruleDirection %s in policyRule", ruleDir)
 	}
-	return tier
+	return direction, nil
 }
 
 func flowKeyFromRuleName(ruleName string) string {
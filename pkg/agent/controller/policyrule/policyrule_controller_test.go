@@ -22,6 +22,7 @@ import (
 	"net"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/contiv/ofnet"
 	"github.com/contiv/ofnet/ovsdbDriver"
@@ -36,6 +37,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	networkpolicyv1alpha1 "github.com/smartxworks/lynx/pkg/apis/policyrule/v1alpha1"
+	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
 )
 
 var (
@@ -111,6 +113,19 @@ var (
 		},
 		Status: networkpolicyv1alpha1.PolicyRuleStatus{},
 	}
+	tier0 = &securityv1alpha1.Tier{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Tier",
+			APIVersion: "security.lynx.smartx.com/v1alpha1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name: "tier0",
+		},
+		Spec: securityv1alpha1.TierSpec{
+			Priority: 0,
+			TierMode: securityv1alpha1.TierWhiteList,
+		},
+	}
 	policyRule2Updated = &networkpolicyv1alpha1.PolicyRule{
 		TypeMeta: v1.TypeMeta{
 			Kind:       "PolicyRule",
@@ -156,7 +171,7 @@ func TestMain(m *testing.M) {
 	agent.WaitForSwitchConnection()
 
 	queue = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
-	reconciler = newFakeReconciler(agent, policyRule1, policyRule2)
+	reconciler = newFakeReconciler(agent, tier0, policyRule1, policyRule2)
 
 	exitCode := m.Run()
 	os.Exit(exitCode)
@@ -166,12 +181,17 @@ func newFakeReconciler(agent *ofnet.OfnetAgent, initObjs ...runtime.Object) *Pol
 	// Add scheme
 	scheme := runtime.NewScheme()
 	_ = networkpolicyv1alpha1.AddToScheme(scheme)
+	_ = securityv1alpha1.AddToScheme(scheme)
 
 	return &PolicyRuleReconciler{
-		Client:              fakeclient.NewFakeClientWithScheme(scheme, initObjs...),
-		Scheme:              scheme,
-		Agent:               agent,
-		flowKeyReferenceMap: make(map[string]sets.String),
+		Client:                 fakeclient.NewFakeClientWithScheme(scheme, initObjs...),
+		Scheme:                 scheme,
+		Agent:                  agent,
+		AgentName:              "agent-test",
+		BridgeName:             BridgeName,
+		flowKeyReferenceMap:    make(map[string]sets.String),
+		flowKeyDatapathRuleMap: make(map[string]*datapathPolicyRule),
+		flowKeyInstallTime:     make(map[string]time.Time),
 	}
 }
 
@@ -241,3 +261,245 @@ func TestProcessPolicyRule(t *testing.T) {
 		}
 	})
 }
+
+func TestSetAgentStatus(t *testing.T) {
+	status := &networkpolicyv1alpha1.PolicyRuleStatus{}
+
+	changed := status.SetAgentStatus("agent01", func(status *networkpolicyv1alpha1.AgentPolicyRuleStatus) {
+		status.EnforceState = networkpolicyv1alpha1.PolicyRuleSuccessEnforced
+		status.MatchPackets = 10
+	})
+	if !changed || status.EnforceState != networkpolicyv1alpha1.PolicyRuleSuccessEnforced || status.MatchStatistics != 10 {
+		t.Errorf("unexpect status %+v after agent01 enforced", status)
+	}
+
+	changed = status.SetAgentStatus("agent02", func(status *networkpolicyv1alpha1.AgentPolicyRuleStatus) {
+		status.EnforceState = networkpolicyv1alpha1.PolicyRuleFailedEnforced
+		status.Reason = "unsupport ipProtocol"
+		status.MatchPackets = 5
+	})
+	if !changed || status.EnforceState != networkpolicyv1alpha1.PolicyRuleFailedEnforced || status.MatchStatistics != 15 {
+		t.Errorf("unexpect status %+v after agent02 failed", status)
+	}
+
+	changed = status.SetAgentStatus("agent01", func(status *networkpolicyv1alpha1.AgentPolicyRuleStatus) {
+		status.MatchPackets = 10
+	})
+	if changed {
+		t.Errorf("status should not changed when agent01 statistics unchanged")
+	}
+	if len(status.AgentStatuses) != 2 {
+		t.Errorf("expect 2 agent statuses, got %+v", status.AgentStatuses)
+	}
+}
+
+func TestStatisticsChanged(t *testing.T) {
+	testCases := map[string]struct {
+		reportedPackets int64
+		currentPackets  int64
+		expectChanged   bool
+	}{
+		"should not report unchanged statistics": {reportedPackets: 100, currentPackets: 100, expectChanged: false},
+		"should report the first hit":            {reportedPackets: 0, currentPackets: 1, expectChanged: true},
+		"should report counters reset":           {reportedPackets: 100, currentPackets: 10, expectChanged: true},
+		"should not report slightly changes":     {reportedPackets: 1000, currentPackets: 1099, expectChanged: false},
+		"should report significant changes":      {reportedPackets: 1000, currentPackets: 1100, expectChanged: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if changed := statisticsChanged(tc.reportedPackets, tc.currentPackets); changed != tc.expectChanged {
+				t.Errorf("expect changed %t from %d to %d, got %t", tc.expectChanged, tc.reportedPackets, tc.currentPackets, changed)
+			}
+		})
+	}
+}
+
+func TestBuildDatapathTiers(t *testing.T) {
+	newTier := func(name string, priority int32, mode securityv1alpha1.TierMode) securityv1alpha1.Tier {
+		tier := securityv1alpha1.Tier{}
+		tier.Name = name
+		tier.Spec.Priority = priority
+		tier.Spec.TierMode = mode
+		return tier
+	}
+
+	t.Run("each tier use a datapath tier", func(t *testing.T) {
+		tiers := buildDatapathTiers([]securityv1alpha1.Tier{
+			newTier("platform-baseline", 10, securityv1alpha1.TierBlackList),
+			newTier("tier0", 0, securityv1alpha1.TierWhiteList),
+		})
+		if tiers["tier0"].tier != 0 || tiers["platform-baseline"].tier != 1 {
+			t.Errorf("unexpect datapath tiers %+v", tiers)
+		}
+		if tiers["platform-baseline"].mode != securityv1alpha1.TierBlackList {
+			t.Errorf("unexpect platform-baseline tier mode %s", tiers["platform-baseline"].mode)
+		}
+	})
+
+	t.Run("tiers share datapath tier", func(t *testing.T) {
+		tiers := buildDatapathTiers([]securityv1alpha1.Tier{
+			newTier("tier0", 0, securityv1alpha1.TierWhiteList),
+			newTier("tier1", 1, securityv1alpha1.TierWhiteList),
+			newTier("tier2", 2, securityv1alpha1.TierWhiteList),
+			newTier("tier3", 3, securityv1alpha1.TierWhiteList),
+		})
+		if tiers["tier0"].tier != 0 || tiers["tier1"].tier != 0 || tiers["tier2"].tier != 1 || tiers["tier3"].tier != 2 {
+			t.Errorf("unexpect datapath tiers %+v", tiers)
+		}
+
+		tier0Priority, err := tiers["tier0"].rulePriority(0)
+		if err != nil {
+			t.Errorf("unexpect error: %s", err)
+		}
+		tier1Priority, err := tiers["tier1"].rulePriority(tiers["tier1"].priorityRange - 1)
+		if err != nil {
+			t.Errorf("unexpect error: %s", err)
+		}
+		if tier0Priority <= tier1Priority {
+			t.Errorf("rules in tier0 should have higher priority than tier1")
+		}
+		if _, err = tiers["tier1"].rulePriority(tiers["tier1"].priorityRange); err == nil {
+			t.Errorf("priority out of range should return error")
+		}
+	})
+}
+
+func TestTierPlacement(t *testing.T) {
+	newTier := func(name string, priority int32) securityv1alpha1.Tier {
+		tier := securityv1alpha1.Tier{}
+		tier.Name = name
+		tier.Spec.Priority = priority
+		tier.Spec.TierMode = securityv1alpha1.TierWhiteList
+		return tier
+	}
+	expectMatchOrder := func(t *testing.T, datapathTiers map[string]datapathTier, names ...string) {
+		for index := 1; index < len(names); index++ {
+			if datapathTiers[names[index-1]].end() > datapathTiers[names[index]].start() {
+				t.Errorf("tier %s should be matched before tier %s: %+v", names[index-1], names[index], datapathTiers)
+			}
+		}
+	}
+
+	var placement tierPlacement
+	var tiers = []securityv1alpha1.Tier{newTier("tier0", 0), newTier("tier10", 10), newTier("tier20", 20)}
+	var placed = placement.sync(tiers)
+	expectMatchOrder(t, placed, "tier0", "tier10", "tier20")
+
+	t.Run("existing tiers keep position when add tier", func(t *testing.T) {
+		tiers = append(tiers, newTier("tier5", 5), newTier("tier15", 15), newTier("tier30", 30))
+		datapathTiers := placement.sync(tiers)
+		for name, datapathTier := range placed {
+			if datapathTiers[name] != datapathTier {
+				t.Errorf("tier %s position changed from %+v to %+v", name, datapathTier, datapathTiers[name])
+			}
+		}
+		if placement.takeMoved() {
+			t.Errorf("existing tiers should not be moved")
+		}
+		expectMatchOrder(t, datapathTiers, "tier0", "tier5", "tier10", "tier15", "tier20", "tier30")
+		placed = datapathTiers
+	})
+
+	t.Run("existing tiers keep position when remove tier", func(t *testing.T) {
+		tiers = tiers[1:]
+		datapathTiers := placement.sync(tiers)
+		if _, ok := datapathTiers["tier0"]; ok {
+			t.Errorf("removed tier0 should not be placed")
+		}
+		for _, tier := range tiers {
+			if datapathTiers[tier.Name] != placed[tier.Name] {
+				t.Errorf("tier %s position changed from %+v to %+v", tier.Name, placed[tier.Name], datapathTiers[tier.Name])
+			}
+		}
+		if placement.takeMoved() {
+			t.Errorf("existing tiers should not be moved")
+		}
+	})
+
+	t.Run("rearrange tiers when no room for new tier", func(t *testing.T) {
+		for priority := int32(11); priority < 15; priority++ {
+			tiers = append(tiers, newTier(fmt.Sprintf("tier%d", priority), priority))
+		}
+		datapathTiers := placement.sync(tiers)
+		if !placement.takeMoved() {
+			t.Errorf("existing tiers should be moved when rearrange tiers")
+		}
+		expectMatchOrder(t, datapathTiers, "tier5", "tier10", "tier11", "tier12", "tier13", "tier14", "tier15", "tier20", "tier30")
+		for _, tier := range tiers {
+			if datapathTiers[tier.Name].priorityRange < minPriorityRange {
+				t.Errorf("tier %s priority range %d too small", tier.Name, datapathTiers[tier.Name].priorityRange)
+			}
+		}
+	})
+}
+
+func TestGetRuleICMPMatch(t *testing.T) {
+	int32Ptr := func(value int32) *int32 { return &value }
+
+	tests := []struct {
+		name         string
+		rule         networkpolicyv1alpha1.PolicyRuleSpec
+		expectType   string
+		expectCode   string
+		expectHasErr bool
+	}{
+		{
+			name: "icmp rule without type and code",
+			rule: networkpolicyv1alpha1.PolicyRuleSpec{IpProtocol: "ICMP"},
+		},
+		{
+			name:       "icmp rule with type",
+			rule:       networkpolicyv1alpha1.PolicyRuleSpec{IpProtocol: "ICMP", IcmpType: int32Ptr(8)},
+			expectType: "8",
+		},
+		{
+			name:       "icmp rule with type and code",
+			rule:       networkpolicyv1alpha1.PolicyRuleSpec{IpProtocol: "ICMP", IcmpType: int32Ptr(3), IcmpCode: int32Ptr(4)},
+			expectType: "3",
+			expectCode: "4",
+		},
+		{
+			name:         "icmp rule with code only",
+			rule:         networkpolicyv1alpha1.PolicyRuleSpec{IpProtocol: "ICMP", IcmpCode: int32Ptr(4)},
+			expectHasErr: true,
+		},
+		{
+			name:         "tcp rule with icmp type",
+			rule:         networkpolicyv1alpha1.PolicyRuleSpec{IpProtocol: "TCP", IcmpType: int32Ptr(8)},
+			expectHasErr: true,
+		},
+		{
+			name:         "icmp type out of range",
+			rule:         networkpolicyv1alpha1.PolicyRuleSpec{IpProtocol: "ICMP", IcmpType: int32Ptr(256)},
+			expectHasErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			icmpType, icmpCode, err := getRuleICMPMatch(&tt.rule)
+			if (err != nil) != tt.expectHasErr {
+				t.Fatalf("expect has error %t, got error %v", tt.expectHasErr, err)
+			}
+			if uint8PtrString(icmpType) != tt.expectType || uint8PtrString(icmpCode) != tt.expectCode {
+				t.Errorf("expect icmp type %q code %q, got type %q code %q", tt.expectType, tt.expectCode,
+					uint8PtrString(icmpType), uint8PtrString(icmpCode))
+			}
+		})
+	}
+}
+
+func TestTierDefaultRuleLogging(t *testing.T) {
+	var tiers = []securityv1alpha1.Tier{
+		{ObjectMeta: v1.ObjectMeta{Name: "logged"}, Spec: securityv1alpha1.TierSpec{TierMode: securityv1alpha1.TierWhiteList, DefaultRuleLogging: true}},
+		{ObjectMeta: v1.ObjectMeta{Name: "unlogged"}, Spec: securityv1alpha1.TierSpec{TierMode: securityv1alpha1.TierWhiteList}},
+	}
+
+	if !tierDefaultRuleLogging(tiers, "logged") {
+		t.Errorf("expect default rules of tier logged logging")
+	}
+	if tierDefaultRuleLogging(tiers, "unlogged") || tierDefaultRuleLogging(tiers, "not-exist") {
+		t.Errorf("expect default rules of tier unlogged and not-exist not logging")
+	}
+}
@@ -0,0 +1,318 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policyrule
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/contiv/ofnet"
+	"k8s.io/klog"
+
+	"github.com/smartxworks/lynx/pkg/agent/datapath"
+	"github.com/smartxworks/lynx/pkg/agent/ovsctl"
+	networkpolicyv1alpha1 "github.com/smartxworks/lynx/pkg/apis/policyrule/v1alpha1"
+)
+
+const (
+	// every agent writes statistics into the shared policyRules, sync statistics on a slow
+	// cadence with jitter to avoid agents writing the same policyRules at the same time.
+	statisticsSyncInterval     = 5 * time.Minute
+	statisticsSyncJitterFactor = 0.5
+	// statisticsChangeRatio is the minimum ratio of packets changed since the last report that
+	// makes the statistics report again.
+	statisticsChangeRatio = 0.1
+
+	// monitorMarkField is the match field of monitor mark ovs-ofctl shows in the flow match.
+	monitorMarkField = "reg6"
+)
+
+// ruleStatistics is the number of packets and bytes matched a datapath rule.
+type ruleStatistics struct {
+	packets int64
+	bytes   int64
+}
+
+// syncPolicyRuleStatistics collects rule flow statistics from datapath, and updates them into
+// status of the policyRules which reference the flow, when they changed significantly.
+func (r *PolicyRuleReconciler) syncPolicyRuleStatistics() {
+	flowKeyStatistics, err := r.collectRuleStatistics()
+	if err != nil {
+		klog.Errorf("unable to collect policyRule statistics: %s", err)
+		return
+	}
+
+	r.flowKeyReferenceMapLock.RLock()
+	var ruleStatisticsMap = make(map[string]ruleStatistics)
+	for flowKey, statistics := range flowKeyStatistics {
+		for _, ruleName := range r.flowKeyReferenceMap[flowKey].List() {
+			ruleStatisticsMap[ruleName] = statistics
+		}
+	}
+	r.flowKeyReferenceMapLock.RUnlock()
+
+	// update status out of lock, avoid block policyRule reconcile
+	for ruleName, statistics := range ruleStatisticsMap {
+		err = r.updateAgentStatus(ruleName, func(status *networkpolicyv1alpha1.AgentPolicyRuleStatus) {
+			if !statisticsChanged(status.MatchPackets, statistics.packets) {
+				return
+			}
+			status.MatchPackets = statistics.packets
+			status.MatchBytes = statistics.bytes
+		})
+		if err != nil {
+			klog.Errorf("unable to update policyRule %s statistics: %s", ruleName, err)
+		}
+	}
+}
+
+// statisticsChanged returns true if the matched packets should be reported again. The first hit and
+// counters reset (e.g. flows reinstalled) are always reported, others are reported only when changed
+// by statisticsChangeRatio of the last reported.
+func statisticsChanged(reportedPackets, currentPackets int64) bool {
+	switch {
+	case currentPackets == reportedPackets:
+		return false
+	case reportedPackets == 0, currentPackets < reportedPackets:
+		return true
+	default:
+		return float64(currentPackets-reportedPackets) >= float64(reportedPackets)*statisticsChangeRatio
+	}
+}
+
+// collectRuleStatistics dumps flows from the tier tables, returns the statistics of each flowKey.
+func (r *PolicyRuleReconciler) collectRuleStatistics() (map[string]ruleStatistics, error) {
+	var policyAgent = r.Agent.GetDatapath().GetPolicyAgent()
+	var tableFlows = make(map[uint8][]*ovsctl.Flow)
+	var flowKeyStatistics = make(map[string]ruleStatistics)
+
+	r.flowKeyReferenceMapLock.RLock()
+	defer r.flowKeyReferenceMapLock.RUnlock()
+
+	for flowKey, datapathRule := range r.flowKeyDatapathRuleMap {
+		table, _, err := policyAgent.GetTierTable(datapathRule.direction, datapathRule.tier)
+		if err != nil {
+			return nil, fmt.Errorf("unable get table of rule %s: %s", flowKey, err)
+		}
+
+		if _, ok := tableFlows[table.TableId]; !ok {
+			tableFlows[table.TableId], err = ovsctl.DumpFlows(r.BridgeName, int(table.TableId))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, flow := range tableFlows[table.TableId] {
+			if ruleFlowMatch(datapathRule.rule, flow) {
+				flowKeyStatistics[flowKey] = ruleStatistics{packets: flow.Packets, bytes: flow.Bytes}
+				break
+			}
+		}
+	}
+
+	return flowKeyStatistics, nil
+}
+
+// GetPolicyRulesByFlow returns names of the PolicyRules installed as the flow with the cookie
+// in the table, returns empty if the flow is not a policy rule flow.
+func (r *PolicyRuleReconciler) GetPolicyRulesByFlow(table int, cookie uint64) ([]string, error) {
+	var policyAgent = r.Agent.GetDatapath().GetPolicyAgent()
+
+	flows, err := ovsctl.DumpFlows(r.BridgeName, table)
+	if err != nil {
+		return nil, err
+	}
+
+	var tableFlow *ovsctl.Flow
+	for _, flow := range flows {
+		if flow.Cookie == cookie {
+			tableFlow = flow
+			break
+		}
+	}
+	if tableFlow == nil {
+		return nil, nil
+	}
+
+	r.flowKeyReferenceMapLock.RLock()
+	defer r.flowKeyReferenceMapLock.RUnlock()
+
+	for flowKey, datapathRule := range r.flowKeyDatapathRuleMap {
+		ruleTable, _, err := policyAgent.GetTierTable(datapathRule.direction, datapathRule.tier)
+		if err != nil || int(ruleTable.TableId) != table {
+			continue
+		}
+		if ruleFlowMatch(datapathRule.rule, tableFlow) {
+			return r.flowKeyReferenceMap[flowKey].List(), nil
+		}
+	}
+
+	return nil, nil
+}
+
+// ruleFlowMatch returns true if the flow is the one installed for the ofnet rule.
+func ruleFlowMatch(rule *datapath.PolicyRule, flow *ovsctl.Flow) bool {
+	if flow.Priority != ofnet.FLOW_POLICY_PRIORITY_OFFSET+rule.Priority {
+		return false
+	}
+
+	// all match fields of the flow must be the same as the rule, except the monitor mark checked later,
+	// otherwise rules differ only in some fields (e.g. tcp flags) would match the same flow
+	var flowMatch = make(map[string]string, len(flow.Match))
+	for field, value := range flow.Match {
+		if field != monitorMarkField {
+			flowMatch[field] = value
+		}
+	}
+	if !reflect.DeepEqual(flowMatch, expectedFlowMatch(rule)) {
+		return false
+	}
+
+	var actions = flow.Actions
+	if strings.HasPrefix(actions, "sample(") {
+		// flow samples packets to IPFIX collectors before other actions when flow sampling enabled
+		actions = strings.TrimPrefix(actions[strings.Index(actions, ")")+1:], ",")
+	}
+	if rule.Logging {
+		// logging rule flow sends packets to flow logger before other actions
+		if !strings.HasPrefix(actions, datapath.ControllerActionString(datapath.FlowLogControllerID)) {
+			return false
+		}
+		actions = strings.TrimPrefix(strings.TrimPrefix(actions, datapath.ControllerActionString(datapath.FlowLogControllerID)), ",")
+		if actions == "" {
+			actions = "drop"
+		}
+	}
+
+	// monitor rule flow matches the monitor mark, and resubmits packets to the table
+	if _, marked := flow.Match[monitorMarkField]; marked != rule.MonitorOnly {
+		return false
+	}
+	if rule.MonitorOnly {
+		return strings.Contains(actions, "resubmit(")
+	}
+
+	switch rule.Action {
+	case "allow":
+		return strings.HasPrefix(actions, "goto_table:")
+	case "deny":
+		return actions == "drop"
+	case "reject":
+		return actions == datapath.ControllerActionString(datapath.RejectControllerID)
+	default:
+		return false
+	}
+}
+
+// expectedFlowMatch returns match fields ovs-ofctl shows for the rule flow, without the monitor mark.
+func expectedFlowMatch(rule *datapath.PolicyRule) map[string]string {
+	var expectMatch = map[string]string{
+		protocolKeyword(rule.IpProtocol): "",
+	}
+	var setMatch = func(field, value string) {
+		if value != "" {
+			expectMatch[field] = value
+		}
+	}
+
+	setMatch("nw_src", normalizeIPAddr(rule.SrcIpAddr))
+	setMatch("nw_dst", normalizeIPAddr(rule.DstIpAddr))
+	if rule.IpProtocol == 6 || rule.IpProtocol == 17 || rule.IpProtocol == 132 {
+		setMatch("tp_src", portString(rule.SrcPort))
+		setMatch("tp_dst", portString(rule.DstPort))
+	}
+	if rule.IpProtocol == 6 {
+		setMatch("tcp_flags", tcpFlagsString(rule.TcpFlags))
+	}
+	if rule.IpProtocol == 1 {
+		setMatch("icmp_type", uint8PtrString(rule.ICMPType))
+		setMatch("icmp_code", uint8PtrString(rule.ICMPCode))
+	}
+
+	return expectMatch
+}
+
+// tcpFlagsString formats tcp flags of ofnet rule as ovs-ofctl shows, flags not all masked show
+// as "+flag" and "-flag" from the lowest bit.
+func tcpFlagsString(tcpFlags string) string {
+	switch tcpFlags {
+	case "":
+		return ""
+	case "syn":
+		return "+syn"
+	case "ack":
+		return "+ack"
+	case "syn,ack":
+		return "+syn+ack"
+	case "syn,!ack":
+		return "+syn-ack"
+	case "!syn,ack":
+		return "-syn+ack"
+	default:
+		return tcpFlags
+	}
+}
+
+// protocolKeyword returns the protocol keyword ovs-ofctl shows in the flow match.
+func protocolKeyword(ipProtocol uint8) string {
+	switch ipProtocol {
+	case 1:
+		return "icmp"
+	case 6:
+		return "tcp"
+	case 17:
+		return "udp"
+	case 132:
+		return "sctp"
+	default:
+		return "ip"
+	}
+}
+
+// normalizeIPAddr formats ip address as ovs-ofctl shows, host address shows without prefix length.
+func normalizeIPAddr(ipAddr string) string {
+	if !strings.Contains(ipAddr, "/") {
+		return ipAddr
+	}
+
+	_, ipNet, err := net.ParseCIDR(ipAddr)
+	if err != nil {
+		return ipAddr
+	}
+
+	if ones, bits := ipNet.Mask.Size(); ones == bits {
+		return ipNet.IP.String()
+	}
+	return ipNet.String()
+}
+
+func portString(port uint16) string {
+	if port == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d", port)
+}
+
+func uint8PtrString(value *uint8) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d", *value)
+}
@@ -0,0 +1,281 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policyrule
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/contiv/ofnet"
+
+	networkpolicyv1alpha1 "github.com/smartxworks/lynx/pkg/apis/policyrule/v1alpha1"
+	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+)
+
+const (
+	// numOfDatapathTiers is the number of tier tables datapath supports in each direction.
+	numOfDatapathTiers = 3
+	// maxRulePriority is the max rule priority could be installed into datapath, the
+	// datapath flow priority is an uint16 with policy priority offset.
+	maxRulePriority = math.MaxUint16 - ofnet.FLOW_POLICY_PRIORITY_OFFSET
+	// tablePriorityRange is the number of rule priorities in each datapath tier table.
+	tablePriorityRange = maxRulePriority + 1
+	// minPriorityRange is the least number of rule priorities a new Tier would be placed with,
+	// all the Tiers would be rearranged if there is no such room for the new Tier.
+	minPriorityRange = 1024
+)
+
+// datapathTier is the position of a Tier in datapath. Tiers would share the datapath tier table when
+// there are more Tiers than datapath tier tables, each of them use a separate range of flow priority.
+type datapathTier struct {
+	// tier is the datapath tier table number
+	tier uint8
+	// priorityBase is the lowest rule priority of the Tier in the datapath tier table
+	priorityBase int
+	// priorityRange is the number of rule priorities the Tier could use
+	priorityRange int
+	mode          securityv1alpha1.TierMode
+}
+
+// buildDatapathTiers arranges Tiers into datapath tier tables by Tier priority, Tier with lower
+// priority value would be matched first. Tiers would be evenly distributed into datapath tier tables,
+// each Tier takes the middle half of its share, leaves room for Tiers added later.
+func buildDatapathTiers(tiers []securityv1alpha1.Tier) map[string]datapathTier {
+	var datapathTiers = make(map[string]datapathTier, len(tiers))
+	var tableTiers = make([][]securityv1alpha1.Tier, numOfDatapathTiers)
+
+	sortTiers(tiers)
+
+	for index, tier := range tiers {
+		table := index * numOfDatapathTiers / len(tiers)
+		if len(tiers) <= numOfDatapathTiers {
+			table = index
+		}
+		tableTiers[table] = append(tableTiers[table], tier)
+	}
+
+	for table, tiersInTable := range tableTiers {
+		if len(tiersInTable) == 0 {
+			continue
+		}
+		shareRange := tablePriorityRange / len(tiersInTable)
+		for index, tier := range tiersInTable {
+			start := table*tablePriorityRange + index*shareRange
+			datapathTiers[tier.Name] = newDatapathTier(start+shareRange/4, start+shareRange/4+shareRange/2, tier.Spec.TierMode)
+		}
+	}
+
+	return datapathTiers
+}
+
+// newDatapathTier returns the datapathTier takes positions [start, end) of the datapath, positions
+// are numbered in match order, e.g. the highest priority of the first tier table is position 0.
+// The positions must be in the same datapath tier table.
+func newDatapathTier(start, end int, mode securityv1alpha1.TierMode) datapathTier {
+	table := start / tablePriorityRange
+	return datapathTier{
+		tier:          uint8(table),
+		priorityBase:  (table+1)*tablePriorityRange - end,
+		priorityRange: end - start,
+		mode:          mode,
+	}
+}
+
+// start returns the first position of the datapathTier in match order.
+func (t datapathTier) start() int {
+	return (int(t.tier)+1)*tablePriorityRange - t.priorityBase - t.priorityRange
+}
+
+// end returns the position next to the last position of the datapathTier in match order.
+func (t datapathTier) end() int {
+	return (int(t.tier)+1)*tablePriorityRange - t.priorityBase
+}
+
+func sortTiers(tiers []securityv1alpha1.Tier) {
+	sort.Slice(tiers, func(i, j int) bool {
+		if tiers[i].Spec.Priority != tiers[j].Spec.Priority {
+			return tiers[i].Spec.Priority < tiers[j].Spec.Priority
+		}
+		return tiers[i].Name < tiers[j].Name
+	})
+}
+
+// tierPlacement keeps the datapath position of Tiers. A Tier keeps the position assigned when
+// it was added, so rules of the existing Tiers needs not to be reinstalled when Tiers change.
+type tierPlacement struct {
+	lock          sync.Mutex
+	datapathTiers map[string]datapathTier
+	// moved is true if any of the existing Tiers has changed its position since last takeMoved
+	moved bool
+}
+
+// sync places the new Tiers into the free positions between their neighbors and forgets the
+// removed Tiers. All the Tiers would be rearranged when there is no room for a new Tier.
+func (p *tierPlacement) sync(tiers []securityv1alpha1.Tier) map[string]datapathTier {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	tiers = append([]securityv1alpha1.Tier(nil), tiers...)
+	sortTiers(tiers)
+
+	datapathTiers := make(map[string]datapathTier, len(tiers))
+	for _, tier := range tiers {
+		if placed, ok := p.datapathTiers[tier.Name]; ok {
+			placed.mode = tier.Spec.TierMode
+			datapathTiers[tier.Name] = placed
+		}
+	}
+
+	for index, tier := range tiers {
+		if _, ok := datapathTiers[tier.Name]; ok {
+			continue
+		}
+		placed, ok := placeBetween(datapathTiers, tiers[:index], tiers[index+1:])
+		if !ok {
+			datapathTiers = buildDatapathTiers(tiers)
+			for name, placed := range p.datapathTiers {
+				current, ok := datapathTiers[name]
+				if ok && (current.start() != placed.start() || current.end() != placed.end()) {
+					p.moved = true
+				}
+			}
+			break
+		}
+		placed.mode = tier.Spec.TierMode
+		datapathTiers[tier.Name] = placed
+	}
+
+	p.datapathTiers = datapathTiers
+	return copyDatapathTiers(datapathTiers)
+}
+
+// takeMoved returns whether any of the existing Tiers has changed its position, and resets it.
+func (p *tierPlacement) takeMoved() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	moved := p.moved
+	p.moved = false
+	return moved
+}
+
+// placeBetween finds the position for a Tier matched after the former Tiers and before the latter
+// Tiers, it takes the middle half of the largest free range in a datapath tier table.
+func placeBetween(datapathTiers map[string]datapathTier, former, latter []securityv1alpha1.Tier) (datapathTier, bool) {
+	var freeStart, freeEnd = 0, numOfDatapathTiers * tablePriorityRange
+
+	for index := len(former) - 1; index >= 0; index-- {
+		if placed, ok := datapathTiers[former[index].Name]; ok {
+			freeStart = placed.end()
+			break
+		}
+	}
+	for index := 0; index < len(latter); index++ {
+		if placed, ok := datapathTiers[latter[index].Name]; ok {
+			freeEnd = placed.start()
+			break
+		}
+	}
+
+	var start, end int
+	for tableStart := 0; tableStart < freeEnd; tableStart += tablePriorityRange {
+		rangeStart, rangeEnd := tableStart, tableStart+tablePriorityRange
+		if rangeStart < freeStart {
+			rangeStart = freeStart
+		}
+		if rangeEnd > freeEnd {
+			rangeEnd = freeEnd
+		}
+		if rangeEnd-rangeStart > end-start {
+			start, end = rangeStart, rangeEnd
+		}
+	}
+
+	length := end - start
+	if length/2 < minPriorityRange {
+		return datapathTier{}, false
+	}
+	return newDatapathTier(start+length/4, start+length/4+length/2, ""), true
+}
+
+func copyDatapathTiers(datapathTiers map[string]datapathTier) map[string]datapathTier {
+	var copied = make(map[string]datapathTier, len(datapathTiers))
+	for name, placed := range datapathTiers {
+		copied[name] = placed
+	}
+	return copied
+}
+
+// rulePriority returns the rule priority installed into the datapath tier table.
+func (t datapathTier) rulePriority(priority int) (int, error) {
+	if priority < 0 || priority >= t.priorityRange {
+		return 0, fmt.Errorf("priority %d out of range [0, %d) in tier", priority, t.priorityRange)
+	}
+	return t.priorityBase + priority, nil
+}
+
+// RuleFlowPosition returns the datapath tier table and flow priority the PolicyRule would be installed
+// with, install is false if the rule needs not to be installed.
+func RuleFlowPosition(tiers []securityv1alpha1.Tier, rule *networkpolicyv1alpha1.PolicyRuleSpec) (tier uint8, priority int, install bool, err error) {
+	return ruleFlowPosition(buildDatapathTiers(tiers), rule)
+}
+
+func ruleFlowPosition(datapathTiers map[string]datapathTier, rule *networkpolicyv1alpha1.PolicyRuleSpec) (tier uint8, priority int, install bool, err error) {
+	ruleTier, ok := datapathTiers[rule.Tier]
+	if !ok {
+		return 0, 0, false, fmt.Errorf("unsupport ruleTier %s in policyRule: tier not found", rule.Tier)
+	}
+
+	if rule.DefaultPolicyRule && ruleTier.mode == securityv1alpha1.TierBlackList {
+		// traffics default allowed in blacklist tier, needs not to install default drop rules
+		return 0, 0, false, nil
+	}
+
+	priority = rulePriorityOf(rule)
+	if rule.DefaultPolicyRule {
+		priority = defaultRulePriority
+	}
+	priority, err = ruleTier.rulePriority(priority)
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("unsupport priority in tier %s: %s", rule.Tier, err)
+	}
+
+	return ruleTier.tier, priority, true, nil
+}
+
+// rulePriorityOf returns the priority of the rule in its Tier. Each rule priority takes two flow
+// priorities, so that drop and reject rules take precedence over allow rules with the same priority,
+// the lowest priority is left for default rules.
+func rulePriorityOf(rule *networkpolicyv1alpha1.PolicyRuleSpec) int {
+	priority := 2*int(rule.Priority) + 1
+	if rule.Action != networkpolicyv1alpha1.RuleActionAllow {
+		priority++
+	}
+	return priority
+}
+
+// tierDefaultRuleLogging returns true if traffic matching default rules of the tier should be logged.
+func tierDefaultRuleLogging(tiers []securityv1alpha1.Tier, tierName string) bool {
+	for _, tier := range tiers {
+		if tier.Name == tierName {
+			return tier.Spec.DefaultRuleLogging
+		}
+	}
+	return false
+}
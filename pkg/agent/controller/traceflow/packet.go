@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package traceflow
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+
+	"github.com/contiv/libOpenflow/protocol"
+	"github.com/contiv/libOpenflow/util"
+
+	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	"github.com/smartxworks/lynx/pkg/types"
+)
+
+const (
+	icmpTypeEchoRequest = 8
+	tcpFlagSYN          = 0x02
+	ephemeralPort       = 34567
+)
+
+// newTracePacket builds the packet of the traceflow. Checksums are left zero, the datapath fills
+// them when the packet injected.
+func newTracePacket(spec *securityv1alpha1.TraceflowPacket, srcMac, dstMac string, srcIP, dstIP net.IP) (*protocol.Ethernet, error) {
+	if srcIP.To4() == nil || dstIP.To4() == nil {
+		return nil, fmt.Errorf("source ip %s and destination ip %s must be ipv4", srcIP, dstIP)
+	}
+	srcHwAddr, err := net.ParseMAC(srcMac)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source mac address %q: %s", srcMac, err)
+	}
+	dstHwAddr, err := net.ParseMAC(dstMac)
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination mac address %q: %s", dstMac, err)
+	}
+
+	ipv4 := protocol.NewIPv4()
+	ipv4.Version = 4
+	ipv4.TTL = 64
+	ipv4.NWSrc = srcIP.To4()
+	ipv4.NWDst = dstIP.To4()
+
+	srcPort, dstPort := uint16(spec.SrcPort), uint16(spec.DstPort)
+	if srcPort == 0 {
+		srcPort = ephemeralPort
+	}
+
+	switch spec.Protocol {
+	case securityv1alpha1.ProtocolTCP:
+		tcp := protocol.NewTCP()
+		tcp.PortSrc, tcp.PortDst = srcPort, dstPort
+		tcp.HdrLen = 5
+		tcp.Code = tcpFlagSYN
+		tcp.WinSize = 0xffff
+		ipv4.Protocol, ipv4.Data = protocol.Type_TCP, tcp
+	case securityv1alpha1.ProtocolUDP:
+		udp := protocol.NewUDP()
+		udp.PortSrc, udp.PortDst = srcPort, dstPort
+		udp.Length = udp.Len()
+		ipv4.Protocol, ipv4.Data = protocol.Type_UDP, udp
+	case securityv1alpha1.ProtocolSCTP:
+		// sctp common header: ports, verification tag and checksum
+		header := make([]byte, 12)
+		binary.BigEndian.PutUint16(header[0:], srcPort)
+		binary.BigEndian.PutUint16(header[2:], dstPort)
+		ipv4.Protocol, ipv4.Data = 132, util.NewBuffer(header)
+	case securityv1alpha1.ProtocolICMP:
+		icmp := protocol.NewICMP()
+		icmp.Type, icmp.Code = icmpTypeEchoRequest, uint8(spec.ICMPCode)
+		if spec.ICMPType != nil {
+			icmp.Type = uint8(*spec.ICMPType)
+		}
+		// identifier and sequence number of echo
+		icmp.Data = make([]byte, 4)
+		ipv4.Protocol, ipv4.Data = protocol.Type_ICMP, icmp
+	default:
+		return nil, fmt.Errorf("unsupported protocol %s", spec.Protocol)
+	}
+	ipv4.Length = ipv4.Len()
+
+	pkt := protocol.NewEthernet()
+	pkt.HWSrc = srcHwAddr
+	pkt.HWDst = dstHwAddr
+	pkt.Ethertype = protocol.IPv4_MSG
+	pkt.Data = ipv4
+	return pkt, nil
+}
+
+func firstIPv4(ips []types.IPAddress) net.IP {
+	for _, ip := range ips {
+		if parsed := net.ParseIP(ip.String()); parsed.To4() != nil {
+			return parsed
+		}
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package traceflow
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/contiv/libOpenflow/protocol"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/smartxworks/lynx/pkg/agent/datapath"
+	"github.com/smartxworks/lynx/pkg/agent/ovsctl"
+	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+)
+
+// OfportGetter returns ofport of the interface has the external id.
+type OfportGetter func(externalIDName, externalIDValue string) (uint32, bool)
+
+// PolicyRuleGetter returns names of PolicyRules installed as the flow with the cookie in the table.
+type PolicyRuleGetter func(table int, cookie uint64) ([]string, error)
+
+// PacketInjector injects the trace packet from the ofport into the datapath, and returns where the
+// packet finally went.
+type PacketInjector func(inPort uint32, pkt *protocol.Ethernet) (*datapath.TraceResult, error)
+
+// TraceflowReconciler traces packets of traceflows assigned to this agent, and reports the
+// observations and verdict into traceflow status. The flows the packet matches are traced by
+// ofproto/trace, and the verdict comes from the packet injected into the datapath.
+type TraceflowReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// AgentName is the name of this agent, only traceflows assigned to it would be traced
+	AgentName string
+	// BridgeName is the bridge which packets would be traced on
+	BridgeName string
+
+	GetOfport      OfportGetter
+	GetPolicyRules PolicyRuleGetter
+	InjectPacket   PacketInjector
+}
+
+// Reconcile receive traceflow from work queue, traces the packet if the traceflow assigned to this agent.
+func (r *TraceflowReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+
+	traceflow := securityv1alpha1.Traceflow{}
+	if err := r.Get(ctx, req.NamespacedName, &traceflow); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if traceflow.Status.Phase != securityv1alpha1.TraceflowRunning || traceflow.Status.Agent != r.AgentName {
+		return ctrl.Result{}, nil
+	}
+
+	klog.Infof("tracing packet of traceflow %s", traceflow.Name)
+	traceflow.Status.Reason = ""
+	if err := r.trace(ctx, &traceflow); err != nil {
+		klog.Errorf("unable trace packet of traceflow %s: %s", traceflow.Name, err)
+		traceflow.Status.Phase = securityv1alpha1.TraceflowFailed
+		traceflow.Status.Reason = err.Error()
+	} else {
+		traceflow.Status.Phase = securityv1alpha1.TraceflowSucceeded
+	}
+
+	if err := r.Status().Update(ctx, &traceflow); err != nil {
+		return ctrl.Result{}, fmt.Errorf("unable update traceflow %s status: %s", traceflow.Name, err)
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager create and add Traceflow Controller to the manager.
+func (r *TraceflowReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if mgr == nil {
+		return fmt.Errorf("can't setup with nil manager")
+	}
+
+	c, err := controller.New("traceflow-controller", mgr, controller.Options{
+		MaxConcurrentReconciles: 1,
+		Reconciler:              r,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.Watch(&source.Kind{Type: &securityv1alpha1.Traceflow{}}, &handler.EnqueueRequestForObject{})
+}
+
+func (r *TraceflowReconciler) trace(ctx context.Context, traceflow *securityv1alpha1.Traceflow) error {
+	var source, destination securityv1alpha1.Endpoint
+
+	if err := r.Get(ctx, k8stypes.NamespacedName{Name: traceflow.Spec.Source}, &source); err != nil {
+		return fmt.Errorf("unable get source endpoint %s: %s", traceflow.Spec.Source, err)
+	}
+	inPort, ok := r.GetOfport(source.Spec.Reference.ExternalIDName, source.Spec.Reference.ExternalIDValue)
+	if !ok {
+		return fmt.Errorf("interface of source endpoint %s not found", source.Name)
+	}
+
+	var dstIP = net.ParseIP(traceflow.Spec.Destination.IP.String())
+	var dstMac = ""
+	if traceflow.Spec.Destination.Endpoint != "" {
+		err := r.Get(ctx, k8stypes.NamespacedName{Name: traceflow.Spec.Destination.Endpoint}, &destination)
+		if err != nil {
+			return fmt.Errorf("unable get destination endpoint %s: %s", traceflow.Spec.Destination.Endpoint, err)
+		}
+		dstIP, dstMac = firstIPv4(destination.Status.IPs), destination.Status.MacAddress
+	} else if dstIP != nil {
+		var err error
+		if dstMac, err = r.resolveMac(ctx, dstIP); err != nil {
+			return err
+		}
+	}
+
+	pkt, err := newTracePacket(&traceflow.Spec.Packet, source.Status.MacAddress, dstMac, firstIPv4(source.Status.IPs), dstIP)
+	if err != nil {
+		return err
+	}
+	data, err := pkt.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("unable marshal packet: %s", err)
+	}
+	packet := hex.EncodeToString(data)
+
+	trace, err := ovsctl.TracePacket(r.BridgeName, inPort, packet)
+	if err != nil {
+		return err
+	}
+	traceflow.Status.Observations, err = r.toObservations(trace)
+	if err != nil {
+		return err
+	}
+	predicted, verdictPolicyRule := getVerdict(trace, traceflow.Status.Observations)
+
+	result, err := r.InjectPacket(inPort, pkt)
+	if err != nil {
+		return fmt.Errorf("unable inject packet: %s", err)
+	}
+	traceflow.Status.Verdict = toVerdict(result.Verdict)
+	if result.Verdict == datapath.TraceRejected {
+		// the reject rule flow sends the packet to the controller
+		policyRules, err := r.GetPolicyRules(int(result.Table), result.Cookie)
+		if err != nil {
+			return err
+		}
+		if len(policyRules) != 0 {
+			verdictPolicyRule = policyRules[0]
+		}
+	}
+	traceflow.Status.VerdictPolicyRule = verdictPolicyRule
+
+	if predicted != traceflow.Status.Verdict {
+		// e.g. flows changed between the trace and the injection
+		traceflow.Status.Reason = fmt.Sprintf("injected packet %s, but ofproto/trace predicted %s",
+			traceflow.Status.Verdict, predicted)
+	}
+
+	return nil
+}
+
+// resolveMac returns mac address of the endpoint has the ip, the mac is required by the packet.
+func (r *TraceflowReconciler) resolveMac(ctx context.Context, ip net.IP) (string, error) {
+	var endpointList securityv1alpha1.EndpointList
+	if err := r.List(ctx, &endpointList); err != nil {
+		return "", fmt.Errorf("unable list endpoints: %s", err)
+	}
+	for _, endpoint := range endpointList.Items {
+		for _, endpointIP := range endpoint.Status.IPs {
+			if ip.Equal(net.ParseIP(endpointIP.String())) && endpoint.Status.MacAddress != "" {
+				return endpoint.Status.MacAddress, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unable resolve mac address of destination %s, no endpoint has the ip", ip)
+}
+
+func (r *TraceflowReconciler) toObservations(trace *ovsctl.Trace) ([]securityv1alpha1.TraceflowObservation, error) {
+	var observations []securityv1alpha1.TraceflowObservation
+
+	for _, step := range trace.Steps {
+		observation := securityv1alpha1.TraceflowObservation{
+			Table:   int32(step.Table),
+			Flow:    step.Flow,
+			Actions: step.Actions,
+		}
+		if step.Cookie != 0 {
+			policyRules, err := r.GetPolicyRules(step.Table, step.Cookie)
+			if err != nil {
+				return nil, err
+			}
+			observation.PolicyRules = policyRules
+		}
+		observations = append(observations, observation)
+	}
+
+	return observations, nil
+}
+
+// getVerdict returns what ofproto/trace predicts would happen to the packet, and the PolicyRule decides it.
+func getVerdict(trace *ovsctl.Trace, observations []securityv1alpha1.TraceflowObservation) (securityv1alpha1.TraceflowVerdict, string) {
+	var verdictPolicyRule string
+	var rejected bool
+
+	for _, observation := range observations {
+		if len(observation.PolicyRules) != 0 {
+			verdictPolicyRule = observation.PolicyRules[0]
+		}
+		if strings.Contains(observation.Actions, datapath.ControllerActionString(datapath.RejectControllerID)) {
+			rejected = true
+		}
+	}
+
+	switch {
+	case rejected:
+		return securityv1alpha1.TraceflowVerdictRejected, verdictPolicyRule
+	case trace.DatapathActions == "drop":
+		return securityv1alpha1.TraceflowVerdictDropped, verdictPolicyRule
+	default:
+		return securityv1alpha1.TraceflowVerdictForwarded, verdictPolicyRule
+	}
+}
+
+// toVerdict returns the traceflow verdict of the injected packet.
+func toVerdict(verdict datapath.TraceVerdict) securityv1alpha1.TraceflowVerdict {
+	switch verdict {
+	case datapath.TraceForwarded:
+		return securityv1alpha1.TraceflowVerdictForwarded
+	case datapath.TraceRejected:
+		return securityv1alpha1.TraceflowVerdictRejected
+	default:
+		return securityv1alpha1.TraceflowVerdictDropped
+	}
+}
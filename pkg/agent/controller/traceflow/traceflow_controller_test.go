@@ -0,0 +1,169 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package traceflow
+
+import (
+	"net"
+	"testing"
+
+	"github.com/contiv/libOpenflow/protocol"
+
+	"github.com/smartxworks/lynx/pkg/agent/ovsctl"
+	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+)
+
+func TestNewTracePacket(t *testing.T) {
+	var srcIP, dstIP = net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2")
+	var icmpTypeEchoReply int32 = 0
+
+	tests := []struct {
+		name        string
+		spec        securityv1alpha1.TraceflowPacket
+		dstIP       net.IP
+		dstMac      string
+		expectError bool
+		expectProto uint8
+		// expectICMPType is checked for icmp packet only
+		expectICMPType uint8
+	}{
+		{
+			name:        "tcp packet",
+			spec:        securityv1alpha1.TraceflowPacket{Protocol: securityv1alpha1.ProtocolTCP, DstPort: 80},
+			dstIP:       dstIP,
+			dstMac:      "00:00:00:00:00:02",
+			expectProto: protocol.Type_TCP,
+		},
+		{
+			name:        "udp packet",
+			spec:        securityv1alpha1.TraceflowPacket{Protocol: securityv1alpha1.ProtocolUDP, DstPort: 53},
+			dstIP:       dstIP,
+			dstMac:      "00:00:00:00:00:02",
+			expectProto: protocol.Type_UDP,
+		},
+		{
+			name:           "icmp packet",
+			spec:           securityv1alpha1.TraceflowPacket{Protocol: securityv1alpha1.ProtocolICMP},
+			dstIP:          dstIP,
+			dstMac:         "00:00:00:00:00:02",
+			expectProto:    protocol.Type_ICMP,
+			expectICMPType: icmpTypeEchoRequest,
+		},
+		{
+			name:           "icmp echo reply packet",
+			spec:           securityv1alpha1.TraceflowPacket{Protocol: securityv1alpha1.ProtocolICMP, ICMPType: &icmpTypeEchoReply},
+			dstIP:          dstIP,
+			dstMac:         "00:00:00:00:00:02",
+			expectProto:    protocol.Type_ICMP,
+			expectICMPType: 0,
+		},
+		{
+			name:        "destination without ipv4",
+			spec:        securityv1alpha1.TraceflowPacket{Protocol: securityv1alpha1.ProtocolTCP},
+			dstIP:       nil,
+			dstMac:      "00:00:00:00:00:02",
+			expectError: true,
+		},
+		{
+			name:        "destination without mac",
+			spec:        securityv1alpha1.TraceflowPacket{Protocol: securityv1alpha1.ProtocolTCP},
+			dstIP:       dstIP,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkt, err := newTracePacket(&tt.spec, "00:00:00:00:00:01", tt.dstMac, srcIP, tt.dstIP)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("expect error %t, got %v", tt.expectError, err)
+			}
+			if err != nil {
+				return
+			}
+
+			data, err := pkt.MarshalBinary()
+			if err != nil {
+				t.Fatalf("unable marshal packet: %s", err)
+			}
+			var decoded protocol.Ethernet
+			if err = decoded.UnmarshalBinary(data); err != nil {
+				t.Fatalf("unable unmarshal packet: %s", err)
+			}
+			ipv4, ok := decoded.Data.(*protocol.IPv4)
+			if !ok {
+				t.Fatalf("expect ipv4 packet, got %+v", decoded.Data)
+			}
+			if ipv4.Protocol != tt.expectProto {
+				t.Errorf("unexpect protocol %d", ipv4.Protocol)
+			}
+			if !ipv4.NWSrc.Equal(srcIP) || !ipv4.NWDst.Equal(tt.dstIP) {
+				t.Errorf("unexpect src %s or dst %s", ipv4.NWSrc, ipv4.NWDst)
+			}
+			if icmp, ok := ipv4.Data.(*protocol.ICMP); ok && icmp.Type != tt.expectICMPType {
+				t.Errorf("expect icmp type %d, got %d", tt.expectICMPType, icmp.Type)
+			}
+		})
+	}
+}
+
+func TestGetVerdict(t *testing.T) {
+	tests := []struct {
+		name              string
+		trace             *ovsctl.Trace
+		observations      []securityv1alpha1.TraceflowObservation
+		expectVerdict     securityv1alpha1.TraceflowVerdict
+		expectVerdictRule string
+	}{
+		{
+			name:  "forwarded by allow rule",
+			trace: &ovsctl.Trace{DatapathActions: "2"},
+			observations: []securityv1alpha1.TraceflowObservation{
+				{Table: 0, Actions: "goto_table:10"},
+				{Table: 30, Actions: "goto_table:45", PolicyRules: []string{"policy1-ingress-xxx"}},
+			},
+			expectVerdict:     securityv1alpha1.TraceflowVerdictForwarded,
+			expectVerdictRule: "policy1-ingress-xxx",
+		},
+		{
+			name:  "dropped by default rule",
+			trace: &ovsctl.Trace{DatapathActions: "drop"},
+			observations: []securityv1alpha1.TraceflowObservation{
+				{Table: 30, Actions: "drop", PolicyRules: []string{"default-ingress-xxx"}},
+			},
+			expectVerdict:     securityv1alpha1.TraceflowVerdictDropped,
+			expectVerdictRule: "default-ingress-xxx",
+		},
+		{
+			name:  "rejected by reject rule",
+			trace: &ovsctl.Trace{DatapathActions: "userspace(pid=0,controller(reason=1))"},
+			observations: []securityv1alpha1.TraceflowObservation{
+				{Table: 30, Actions: "controller(id=19544,meter_id=2)", PolicyRules: []string{"policy2-egress-xxx"}},
+			},
+			expectVerdict:     securityv1alpha1.TraceflowVerdictRejected,
+			expectVerdictRule: "policy2-egress-xxx",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict, verdictRule := getVerdict(tt.trace, tt.observations)
+			if verdict != tt.expectVerdict || verdictRule != tt.expectVerdictRule {
+				t.Errorf("expect verdict %s by %s, got %s by %s", tt.expectVerdict, tt.expectVerdictRule, verdict, verdictRule)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/libOpenflow/protocol"
+	"github.com/contiv/libOpenflow/util"
+	"k8s.io/klog"
+)
+
+// FlowLogControllerID is the openflow controller id of flow logger. Packets matches logging
+// rules would be copied to the controller with this id.
+const FlowLogControllerID = 0x4c59
+
+// FlowLogHandler handles packet matches the logging rule ruleID, inPort is the port where the
+// packet comes from.
+type FlowLogHandler func(ruleID string, inPort uint32, pkt *protocol.Ethernet)
+
+// loggingRules maps flow cookies of logging rules to the rule ids.
+var loggingRules = newRuleRegistry()
+
+// RunFlowLogListener calls handler for each packet matches logging rules on the bridge until
+// stopChan closed.
+func RunFlowLogListener(bridgeName string, handler FlowLogHandler, stopChan <-chan struct{}) {
+	runPacketInListener(bridgeName, FlowLogControllerID, func(pkt *openflow13.PacketIn) util.Message {
+		if traceTag(pkt) != 0 {
+			// packets injected by traceflow are not real traffic
+			return nil
+		}
+		ruleID, ok := loggingRules.lookup(pkt.Cookie)
+		if !ok {
+			klog.V(4).Infof("flow logger received packet of unknown cookie %#x", pkt.Cookie)
+			return nil
+		}
+		inPort, _ := packetInPort(pkt)
+		handler(ruleID, inPort, &pkt.Data)
+		return nil
+	}, stopChan)
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/contiv/libOpenflow/common"
+	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/ofnet/ofctrl"
+)
+
+const (
+	// flowLogMeterID, rejectMeterID and traceflowMeterID are the meters limit the rate of packets
+	// sent to flow logger, reject responder and traceflow listener, so that packets flooding never
+	// overwhelm ovs-vswitchd and the agent.
+	flowLogMeterID   = 1
+	rejectMeterID    = 2
+	traceflowMeterID = 3
+
+	// flowLogMeterRate and flowLogMeterBurst are the packets per second and burst size sent to flow
+	// logger, exceeding packets would not be logged.
+	flowLogMeterRate  = 1000
+	flowLogMeterBurst = 2000
+	// traceflowMeterRate and traceflowMeterBurst are the packets per second and burst size sent to
+	// traceflow listener, only a packet is injected each time.
+	traceflowMeterRate  = 10
+	traceflowMeterBurst = 10
+
+	// ofpmcAdd and ofpmcModify are the openflow meter mod commands.
+	ofpmcAdd    = 0
+	ofpmcModify = 1
+	// ofpmfPktps and ofpmfBurst are the openflow meter flags, rate in packets per second
+	// and burst size specified.
+	ofpmfPktps = 1 << 1
+	ofpmfBurst = 1 << 2
+	// ofpmbtDrop is the openflow meter band type drops packets exceeding the rate.
+	ofpmbtDrop = 1
+	// meterModLength is the length of meter mod message with a single band.
+	meterModLength = 32
+
+	// nxastController2 is the nicira extension action subtype of controller2.
+	nxastController2 = 37
+	// nxActionController2Length is the length of nicira controller2 action with properties
+	// controller id, reason and meter id.
+	nxActionController2Length = 40
+	// nxac2ptControllerID, nxac2ptReason and nxac2ptMeterID are the property types of
+	// nicira controller2 action.
+	nxac2ptControllerID = 1
+	nxac2ptReason       = 2
+	nxac2ptMeterID      = 5
+)
+
+// controllerMeter is the meter limits the rate of packets sent to the controller.
+type controllerMeter struct {
+	meterID uint32
+	rate    uint32
+	burst   uint32
+}
+
+// controllerMeters maps controller ids to the meters limit packets sent to them.
+var controllerMeters = map[uint16]controllerMeter{
+	FlowLogControllerID:   {meterID: flowLogMeterID, rate: flowLogMeterRate, burst: flowLogMeterBurst},
+	RejectControllerID:    {meterID: rejectMeterID, rate: rejectResponseQPS, burst: rejectResponseBurst},
+	TraceflowControllerID: {meterID: traceflowMeterID, rate: traceflowMeterRate, burst: traceflowMeterBurst},
+}
+
+var (
+	meteredSwitchesLock sync.Mutex
+	meteredSwitches     = make(map[*ofctrl.OFSwitch]bool)
+)
+
+// ControllerActionString returns the action sends packets to the controller id, in the format
+// ovs-ofctl shows it.
+func ControllerActionString(controllerID uint16) string {
+	if meter, ok := controllerMeters[controllerID]; ok {
+		return fmt.Sprintf("controller(id=%d,meter_id=%d)", controllerID, meter.meterID)
+	}
+	return fmt.Sprintf("controller(id=%d)", controllerID)
+}
+
+// ensureControllerMeters installs meters of the controllers into the switch, meters would be
+// installed again after the switch reconnected. Meter would be added if not exists, or modified
+// otherwise, ovs answers error for the one not applicable.
+func ensureControllerMeters(sw *ofctrl.OFSwitch) {
+	meteredSwitchesLock.Lock()
+	defer meteredSwitchesLock.Unlock()
+
+	if meteredSwitches[sw] {
+		return
+	}
+	for _, meter := range controllerMeters {
+		sw.Send(newMeterMod(ofpmcAdd, meter))
+		sw.Send(newMeterMod(ofpmcModify, meter))
+	}
+	meteredSwitches[sw] = true
+}
+
+// meterMod is the openflow meter mod message with a single drop band, libOpenflow doesn't
+// support it.
+type meterMod struct {
+	common.Header
+	Command uint16
+	Flags   uint16
+	MeterID uint32
+	Rate    uint32
+	Burst   uint32
+}
+
+func newMeterMod(command uint16, meter controllerMeter) *meterMod {
+	return &meterMod{
+		Header:  openflow13.NewOfp13Header(),
+		Command: command,
+		Flags:   ofpmfPktps | ofpmfBurst,
+		MeterID: meter.meterID,
+		Rate:    meter.rate,
+		Burst:   meter.burst,
+	}
+}
+
+func (m *meterMod) Len() uint16 {
+	return meterModLength
+}
+
+func (m *meterMod) MarshalBinary() ([]byte, error) {
+	m.Header.Type = openflow13.Type_MeterMod
+	m.Header.Length = meterModLength
+	header, err := m.Header.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, meterModLength)
+	n := copy(data, header)
+	binary.BigEndian.PutUint16(data[n:], m.Command)
+	binary.BigEndian.PutUint16(data[n+2:], m.Flags)
+	binary.BigEndian.PutUint32(data[n+4:], m.MeterID)
+	// the drop band, with 4 bytes padding at the end
+	binary.BigEndian.PutUint16(data[n+8:], ofpmbtDrop)
+	binary.BigEndian.PutUint16(data[n+10:], meterModLength-16)
+	binary.BigEndian.PutUint32(data[n+12:], m.Rate)
+	binary.BigEndian.PutUint32(data[n+16:], m.Burst)
+	return data, nil
+}
+
+func (m *meterMod) UnmarshalBinary(data []byte) error {
+	if len(data) < meterModLength {
+		return errors.New("the []byte is too short to unmarshal a full MeterMod message")
+	}
+	if err := m.Header.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	n := int(m.Header.Len())
+	m.Command = binary.BigEndian.Uint16(data[n:])
+	m.Flags = binary.BigEndian.Uint16(data[n+2:])
+	m.MeterID = binary.BigEndian.Uint32(data[n+4:])
+	m.Rate = binary.BigEndian.Uint32(data[n+12:])
+	m.Burst = binary.BigEndian.Uint32(data[n+16:])
+	return nil
+}
+
+// nxActionController2 is nicira extension action sends packets to the controller id, rate limited
+// by the meter, libOpenflow doesn't support it.
+type nxActionController2 struct {
+	*openflow13.NXActionHeader
+	ControllerID uint16
+	Reason       uint8
+	MeterID      uint32
+}
+
+func (a *nxActionController2) Len() uint16 {
+	return nxActionController2Length
+}
+
+func (a *nxActionController2) MarshalBinary() ([]byte, error) {
+	header, err := a.NXActionHeader.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	// header is followed by 6 bytes padding, each property is padded to 8 bytes
+	data := make([]byte, nxActionController2Length)
+	n := copy(data, header) + 6
+	binary.BigEndian.PutUint16(data[n:], nxac2ptControllerID)
+	binary.BigEndian.PutUint16(data[n+2:], 6)
+	binary.BigEndian.PutUint16(data[n+4:], a.ControllerID)
+	binary.BigEndian.PutUint16(data[n+8:], nxac2ptReason)
+	binary.BigEndian.PutUint16(data[n+10:], 5)
+	data[n+12] = a.Reason
+	binary.BigEndian.PutUint16(data[n+16:], nxac2ptMeterID)
+	binary.BigEndian.PutUint16(data[n+18:], 8)
+	binary.BigEndian.PutUint32(data[n+20:], a.MeterID)
+	return data, nil
+}
+
+func (a *nxActionController2) UnmarshalBinary(data []byte) error {
+	if len(data) < nxActionController2Length {
+		return errors.New("the []byte is too short to unmarshal a full NXActionController2 message")
+	}
+	a.NXActionHeader = new(openflow13.NXActionHeader)
+	if err := a.NXActionHeader.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	n := openflow13.NxActionHeaderLength + 6
+	a.ControllerID = binary.BigEndian.Uint16(data[n+4:])
+	a.Reason = data[n+12]
+	a.MeterID = binary.BigEndian.Uint32(data[n+20:])
+	return nil
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNXActionController2(t *testing.T) {
+	action := newSendToControllerAction(RejectControllerID)
+	data, err := action.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unable marshal controller2 action: %s", err)
+	}
+
+	expectData := []byte{
+		0xff, 0xff, 0x00, 0x28, // type experimenter, length 40
+		0x00, 0x00, 0x23, 0x20, // nicira vendor id
+		0x00, 0x25, // subtype controller2
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // padding
+		0x00, 0x01, 0x00, 0x06, 0x4c, 0x58, 0x00, 0x00, // property controller id
+		0x00, 0x02, 0x00, 0x05, 0x01, 0x00, 0x00, 0x00, // property reason action
+		0x00, 0x05, 0x00, 0x08, 0x00, 0x00, 0x00, 0x02, // property meter id
+	}
+	if !bytes.Equal(data, expectData) {
+		t.Fatalf("expect controller2 action %v, got %v", expectData, data)
+	}
+
+	var decoded nxActionController2
+	if err = decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unable unmarshal controller2 action: %s", err)
+	}
+	if decoded.ControllerID != RejectControllerID || decoded.MeterID != rejectMeterID {
+		t.Errorf("unexpect decoded controller2 action %+v", decoded)
+	}
+}
+
+func TestMeterMod(t *testing.T) {
+	meterMod := newMeterMod(ofpmcAdd, controllerMeters[FlowLogControllerID])
+	meterMod.Xid = 1
+	data, err := meterMod.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unable marshal meter mod: %s", err)
+	}
+
+	expectData := []byte{
+		0x04, 0x1d, 0x00, 0x20, 0x00, 0x00, 0x00, 0x01, // openflow13 meter mod, length 32
+		0x00, 0x00, 0x00, 0x06, // command add, flags pktps and burst
+		0x00, 0x00, 0x00, 0x01, // meter id
+		0x00, 0x01, 0x00, 0x10, // band type drop, length 16
+		0x00, 0x00, 0x03, 0xe8, // rate
+		0x00, 0x00, 0x07, 0xd0, // burst size
+		0x00, 0x00, 0x00, 0x00, // padding
+	}
+	if !bytes.Equal(data, expectData) {
+		t.Fatalf("expect meter mod %v, got %v", expectData, data)
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"fmt"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/contiv/libOpenflow/common"
+	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/libOpenflow/util"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+)
+
+// ovsRunDir is where ovs-vswitchd creates the bridge management sockets.
+const ovsRunDir = "/var/run/openvswitch"
+
+// packetInHandler handles packet sent to the controller, the returned message (if not nil)
+// would be sent back to the bridge.
+type packetInHandler func(pkt *openflow13.PacketIn) util.Message
+
+// runPacketInListener connects to the bridge management socket, and calls handler for each
+// packet sent to the controller id until stopChan closed. Ofnet drops ip packets sent to it,
+// so a dedicated connection would be used. The connection would be reestablished if lost,
+// e.g. ovs-vswitchd restarted.
+func runPacketInListener(bridgeName string, controllerID uint16, handler packetInHandler, stopChan <-chan struct{}) {
+	newPacketInListener(bridgeName, controllerID, handler).run(stopChan)
+}
+
+type packetInListener struct {
+	bridgeName   string
+	controllerID uint16
+	handler      packetInHandler
+	// connected is 1 if the listener is connected to the bridge, accessed atomically
+	connected int32
+}
+
+func newPacketInListener(bridgeName string, controllerID uint16, handler packetInHandler) *packetInListener {
+	return &packetInListener{
+		bridgeName:   bridgeName,
+		controllerID: controllerID,
+		handler:      handler,
+	}
+}
+
+func (l *packetInListener) run(stopChan <-chan struct{}) {
+	wait.Until(func() {
+		if err := l.serve(stopChan); err != nil {
+			klog.Errorf("packet in listener %#x of bridge %s: %s", l.controllerID, l.bridgeName, err)
+		}
+	}, time.Second, stopChan)
+}
+
+// isConnected returns true if the listener is receiving packets from the bridge.
+func (l *packetInListener) isConnected() bool {
+	return atomic.LoadInt32(&l.connected) == 1
+}
+
+func (l *packetInListener) serve(stopChan <-chan struct{}) error {
+	conn, err := net.Dial("unix", fmt.Sprintf("%s/%s.mgmt", ovsRunDir, l.bridgeName))
+	if err != nil {
+		return fmt.Errorf("unable connect to bridge: %s", err)
+	}
+
+	stream := util.NewMessageStream(conn, l)
+	defer func() { stream.Shutdown <- true }()
+	defer atomic.StoreInt32(&l.connected, 0)
+
+	hello, err := common.NewHello(openflow13.VERSION)
+	if err != nil {
+		return err
+	}
+	stream.Outbound <- hello
+
+	for {
+		select {
+		case msg := <-stream.Inbound:
+			switch m := msg.(type) {
+			case *common.Hello:
+				if m.Version != openflow13.VERSION {
+					return fmt.Errorf("unsupported openflow version %d", m.Version)
+				}
+				stream.Version = m.Version
+				// receive only packets sent to the controller id
+				stream.Outbound <- openflow13.NewSetControllerID(l.controllerID)
+				// management connection receives no packet in unless miss_send_len set
+				setConfig := openflow13.NewSetConfig()
+				setConfig.MissSendLen = openflow13.OFPCML_NO_BUFFER
+				stream.Outbound <- setConfig
+				atomic.StoreInt32(&l.connected, 1)
+				klog.Infof("packet in listener %#x connected to bridge %s", l.controllerID, l.bridgeName)
+			case *common.Header:
+				if m.Type == openflow13.Type_EchoRequest {
+					reply := openflow13.NewEchoReply()
+					reply.Xid = m.Xid
+					stream.Outbound <- reply
+				}
+			case *openflow13.PacketIn:
+				if reply := l.handler(m); reply != nil {
+					stream.Outbound <- reply
+				}
+			case *openflow13.ErrorMsg:
+				klog.Errorf("packet in listener %#x received openflow error: %+v", l.controllerID, *m)
+			}
+		case err := <-stream.Error:
+			return fmt.Errorf("connection lost: %s", err)
+		case <-stopChan:
+			return nil
+		}
+	}
+}
+
+// Parse implements util.Parser, parses messages received from the bridge.
+func (l *packetInListener) Parse(b []byte) (util.Message, error) {
+	if b[0] != openflow13.VERSION {
+		return nil, fmt.Errorf("unsupported openflow version %d", b[0])
+	}
+	return openflow13.Parse(b)
+}
+
+// packetInPort returns the port where the packet comes from.
+func packetInPort(pkt *openflow13.PacketIn) (uint32, bool) {
+	for _, field := range pkt.Match.Fields {
+		if inPortField, ok := field.Value.(*openflow13.InPortField); ok {
+			return inPortField.InPort, true
+		}
+	}
+	return 0, false
+}
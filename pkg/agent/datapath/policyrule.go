@@ -0,0 +1,362 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/libOpenflow/util"
+	"github.com/contiv/ofnet"
+	"github.com/contiv/ofnet/ofctrl"
+	"github.com/contiv/ofnet/ofctrl/cookie"
+)
+
+const (
+	// extendedFlowIDBase is the first flow id of extended rule flows, leaves the lower ids
+	// for flows allocated by ofnet, so that the cookies never conflict.
+	extendedFlowIDBase = 1 << 40
+
+	// numOfTierTables is the number of tier tables in each direction.
+	numOfTierTables = 3
+
+	// monitorMarkReg is the register marks packets have been counted by monitor rules. Each tier table
+	// uses a separate bit, so packets could be counted again in the other tier tables.
+	monitorMarkReg = 6
+
+	protocolICMP = 1
+	protocolTCP  = 6
+	protocolUDP  = 17
+	protocolSCTP = 132
+)
+
+var extendedFlowID uint64 = extendedFlowIDBase
+
+// PolicyRule is an ofnet policy rule with matches or actions ofnet doesn't support (e.g. icmp
+// type, sctp port and reject action). Rule without extended matches or actions would be installed
+// by ofnet, otherwise flow would be built by lynx.
+type PolicyRule struct {
+	ofnet.OfnetPolicyRule
+
+	// ICMPType is the icmp type to match, nil matches all icmp types.
+	ICMPType *uint8
+	// ICMPCode is the icmp code to match, nil matches all icmp codes.
+	ICMPCode *uint8
+	// MonitorOnly rule counts matching packets without applying the action on them.
+	MonitorOnly bool
+	// Logging rule sends matching packets to the flow logger before applying the action.
+	Logging bool
+}
+
+// IsExtended returns true if the rule flow is built by lynx instead of ofnet.
+func (rule *PolicyRule) IsExtended() bool {
+	// ofnet ignores sctp ports, install the flow by lynx
+	return rule.ICMPType != nil || rule.ICMPCode != nil || rule.IpProtocol == protocolSCTP ||
+		rule.Action == "reject" || rule.MonitorOnly || rule.Logging || flowSampling != nil
+}
+
+// AddPolicyRule installs the rule into the tier table of the direction.
+func AddPolicyRule(policyAgent *ofnet.PolicyAgent, rule *PolicyRule, direction, tier uint8) error {
+	if !rule.IsExtended() {
+		return policyAgent.AddRuleToTier(&rule.OfnetPolicyRule, direction, tier)
+	}
+
+	table, nextTable, err := policyAgent.GetTierTable(direction, tier)
+	if err != nil {
+		return fmt.Errorf("unable get table of direction %d tier %d: %s", direction, tier, err)
+	}
+
+	flowMod, err := newPolicyRuleFlowMod(table, rule, direction, tier)
+	if err != nil {
+		return err
+	}
+	flowMod.Command = openflow13.FC_ADD
+	flowMod.Cookie = allocateCookie(table.Switch)
+	flowMod.CookieMask = ^uint64(0)
+
+	if rule.Logging || rule.Action == "reject" {
+		// packets sent to the controllers are rate limited by meters
+		ensureControllerMeters(table.Switch)
+	}
+
+	var applyActions = openflow13.NewInstrApplyActions()
+	var gotoNextTable bool
+	var pointID uint32
+
+	if flowSampling != nil {
+		// sample packets to IPFIX collectors with the observation point id of the rule
+		pointID = allocateObsPointID()
+		_ = applyActions.AddAction(newSampleAction(pointID), false)
+	}
+	if rule.Logging {
+		// send a copy of the packet to flow logger, which finds the rule by the flow cookie
+		_ = applyActions.AddAction(newSendToControllerAction(FlowLogControllerID), false)
+	}
+
+	switch {
+	case rule.MonitorOnly:
+		// mark the packet as counted, and match the table again without monitor rules
+		markBit := monitorMarkBit(direction, tier)
+		markField, _ := openflow13.FindFieldHeaderByName(fmt.Sprintf("NXM_NX_REG%d", monitorMarkReg), false)
+		_ = applyActions.AddAction(openflow13.NewNXActionRegLoad(markBit.ToOfsBits(), markField, 1), false)
+		_ = applyActions.AddAction(openflow13.NewNXActionResubmitTableAction(openflow13.OFPP_IN_PORT, table.TableId), false)
+	case rule.Action == "allow":
+		gotoNextTable = true
+	case rule.Action == "deny":
+		// flow without instructions drops the packet
+	case rule.Action == "reject":
+		// send the packet to reject responder, which answers the packet source
+		_ = applyActions.AddAction(newSendToControllerAction(RejectControllerID), false)
+	default:
+		return fmt.Errorf("unknown action %s in rule %s", rule.Action, rule.RuleId)
+	}
+
+	// ovs requires apply actions instruction before goto table instruction
+	if len(applyActions.Actions) != 0 {
+		flowMod.AddInstruction(applyActions)
+	}
+	if gotoNextTable {
+		flowMod.AddInstruction(openflow13.NewInstrGotoTable(nextTable.TableId))
+	}
+	if rule.Logging {
+		loggingRules.register(flowMod.Cookie, rule.RuleId)
+	}
+	if flowSampling != nil {
+		sampledRules.register(uint64(pointID), rule.RuleId)
+	}
+
+	table.Switch.Send(flowMod)
+	return nil
+}
+
+// DeletePolicyRule removes the rule from the tier table of the direction.
+func DeletePolicyRule(policyAgent *ofnet.PolicyAgent, rule *PolicyRule, direction, tier uint8) error {
+	if !rule.IsExtended() {
+		return policyAgent.DelRule(&rule.OfnetPolicyRule, nil)
+	}
+
+	table, _, err := policyAgent.GetTierTable(direction, tier)
+	if err != nil {
+		return fmt.Errorf("unable get table of direction %d tier %d: %s", direction, tier, err)
+	}
+
+	flowMod, err := newPolicyRuleFlowMod(table, rule, direction, tier)
+	if err != nil {
+		return err
+	}
+	// strict delete the flow with the same match and priority, no matter what the cookie is
+	flowMod.Command = openflow13.FC_DELETE_STRICT
+	flowMod.OutPort = openflow13.P_ANY
+	flowMod.OutGroup = openflow13.OFPG_ANY
+
+	table.Switch.Send(flowMod)
+	loggingRules.unregister(rule.RuleId)
+	sampledRules.unregister(rule.RuleId)
+	return nil
+}
+
+// IsExtendedRuleCookie returns true if the cookie is allocated for extended rule flows in the current
+// round of the switch. Flows with other cookies are owned by ofnet, or left from previous rounds which
+// ofnet would remove.
+func IsExtendedRuleCookie(sw *ofctrl.OFSwitch, flowCookie uint64) bool {
+	if flowCookie&cookie.FlowIdMask < extendedFlowIDBase {
+		return false
+	}
+	if sw.CookieAllocator == nil {
+		return flowCookie&cookie.RoundNumMask == 0
+	}
+	return flowCookie&cookie.RoundNumMask == sw.CookieAllocator.RequestCookie(0).RawId()&cookie.RoundNumMask
+}
+
+func allocateCookie(sw *ofctrl.OFSwitch) uint64 {
+	flowID := atomic.AddUint64(&extendedFlowID, 1)
+	if sw.CookieAllocator == nil {
+		return flowID
+	}
+	return sw.CookieAllocator.RequestCookie(flowID).RawId()
+}
+
+// newSendToControllerAction returns action sends the whole packet to the controller id, rate
+// limited by the meter of the controller.
+func newSendToControllerAction(controllerID uint16) openflow13.Action {
+	return &nxActionController2{
+		NXActionHeader: newNXActionHeader(nxastController2, nxActionController2Length),
+		ControllerID:   controllerID,
+		Reason:         openflow13.R_ACTION,
+		MeterID:        controllerMeters[controllerID].meterID,
+	}
+}
+
+// monitorMarkBit returns the bit of monitor mark register used by the tier table. The bit 0
+// is never used, NXRange treats range [0, 0] as the whole register.
+func monitorMarkBit(direction, tier uint8) *openflow13.NXRange {
+	bit := 1 + int(direction)*numOfTierTables + int(tier)
+	return openflow13.NewNXRange(bit, bit)
+}
+
+// newPolicyRuleFlowMod builds flowMod with the match and priority of the rule.
+func newPolicyRuleFlowMod(table *ofctrl.Table, rule *PolicyRule, direction, tier uint8) (*openflow13.FlowMod, error) {
+	var match = openflow13.NewMatch()
+
+	if rule.IpProtocol != protocolICMP && (rule.ICMPType != nil || rule.ICMPCode != nil) {
+		return nil, fmt.Errorf("icmp type and code only valid for icmp protocol in rule %s", rule.RuleId)
+	}
+	if rule.TcpFlags != "" {
+		return nil, fmt.Errorf("tcp flags not support in extended rule %s", rule.RuleId)
+	}
+
+	match.AddField(*openflow13.NewEthTypeField(0x0800))
+
+	if rule.SrcIpAddr != "" {
+		ip, mask, err := ofnet.ParseIPAddrMaskString(rule.SrcIpAddr)
+		if err != nil {
+			return nil, fmt.Errorf("unable parse src ip %s: %s", rule.SrcIpAddr, err)
+		}
+		match.AddField(*openflow13.NewIpv4SrcField(*ip, mask))
+	}
+
+	if rule.DstIpAddr != "" {
+		ip, mask, err := ofnet.ParseIPAddrMaskString(rule.DstIpAddr)
+		if err != nil {
+			return nil, fmt.Errorf("unable parse dst ip %s: %s", rule.DstIpAddr, err)
+		}
+		match.AddField(*openflow13.NewIpv4DstField(*ip, mask))
+	}
+
+	if rule.IpProtocol != 0 {
+		match.AddField(*openflow13.NewIpProtoField(rule.IpProtocol))
+	}
+
+	for _, field := range newPortMatchFields(rule.IpProtocol, rule.SrcPort, rule.DstPort) {
+		match.AddField(*field)
+	}
+
+	if rule.ICMPType != nil {
+		match.AddField(*newMatchField(openflow13.OXM_FIELD_ICMPV4_TYPE, &openflow13.IcmpTypeField{Type: *rule.ICMPType}))
+	}
+	if rule.ICMPCode != nil {
+		match.AddField(*newMatchField(openflow13.OXM_FIELD_ICMPV4_CODE, &openflow13.IcmpCodeField{Code: *rule.ICMPCode}))
+	}
+
+	if rule.MonitorOnly {
+		// packets have been counted by monitor rules in the table would not match again
+		match.AddField(*openflow13.NewRegMatchField(monitorMarkReg, 0, monitorMarkBit(direction, tier)))
+	}
+
+	flowMod := openflow13.NewFlowMod()
+	flowMod.TableId = table.TableId
+	flowMod.Priority = uint16(ofnet.FLOW_POLICY_PRIORITY_OFFSET + rule.Priority)
+	flowMod.Match = *match
+
+	return flowMod, nil
+}
+
+// newPortMatchFields returns match fields of the transport ports, zero port matches all ports.
+func newPortMatchFields(ipProtocol uint8, srcPort, dstPort uint16) []*openflow13.MatchField {
+	var fields []*openflow13.MatchField
+
+	switch ipProtocol {
+	case protocolTCP:
+		if srcPort != 0 {
+			fields = append(fields, openflow13.NewTcpSrcField(srcPort))
+		}
+		if dstPort != 0 {
+			fields = append(fields, openflow13.NewTcpDstField(dstPort))
+		}
+	case protocolUDP:
+		if srcPort != 0 {
+			fields = append(fields, openflow13.NewUdpSrcField(srcPort))
+		}
+		if dstPort != 0 {
+			fields = append(fields, openflow13.NewUdpDstField(dstPort))
+		}
+	case protocolSCTP:
+		if srcPort != 0 {
+			fields = append(fields, openflow13.NewSctpSrcField(srcPort))
+		}
+		if dstPort != 0 {
+			fields = append(fields, openflow13.NewSctpDstField(dstPort))
+		}
+	}
+
+	return fields
+}
+
+func newMatchField(field uint8, value util.Message) *openflow13.MatchField {
+	return &openflow13.MatchField{
+		Class:  openflow13.OXM_CLASS_OPENFLOW_BASIC,
+		Field:  field,
+		Length: uint8(value.Len()),
+		Value:  value,
+	}
+}
+
+// ruleRegistry maps ids (e.g. flow cookies) of installed rules to the rule ids, and rule ids to the ids.
+type ruleRegistry struct {
+	lock     sync.RWMutex
+	idToRule map[uint64]string
+	ruleToID map[string]uint64
+}
+
+func newRuleRegistry() *ruleRegistry {
+	return &ruleRegistry{
+		idToRule: make(map[uint64]string),
+		ruleToID: make(map[string]uint64),
+	}
+}
+
+func (r *ruleRegistry) register(id uint64, ruleID string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if oldID, ok := r.ruleToID[ruleID]; ok {
+		delete(r.idToRule, oldID)
+	}
+	r.idToRule[id] = ruleID
+	r.ruleToID[ruleID] = id
+}
+
+func (r *ruleRegistry) unregister(ruleID string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if id, ok := r.ruleToID[ruleID]; ok {
+		delete(r.idToRule, id)
+		delete(r.ruleToID, ruleID)
+	}
+}
+
+func (r *ruleRegistry) lookup(id uint64) (string, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	ruleID, ok := r.idToRule[id]
+	return ruleID, ok
+}
+
+func (r *ruleRegistry) list() map[uint64]string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	rules := make(map[uint64]string, len(r.idToRule))
+	for id, ruleID := range r.idToRule {
+		rules[id] = ruleID
+	}
+	return rules
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"testing"
+
+	"github.com/contiv/ofnet/ofctrl"
+	"github.com/contiv/ofnet/ofctrl/cookie"
+)
+
+func TestIsExtendedRuleCookie(t *testing.T) {
+	sw := &ofctrl.OFSwitch{CookieAllocator: cookie.NewAllocator(2)}
+
+	testCases := map[string]struct {
+		cookie         uint64
+		expectExtended bool
+	}{
+		"should own cookie allocated for extended rule":    {cookie: allocateCookie(sw), expectExtended: true},
+		"should not own cookie allocated by ofnet":         {cookie: sw.CookieAllocator.RequestCookie(10).RawId(), expectExtended: false},
+		"should not own cookie of previous round":          {cookie: cookie.NewAllocator(1).RequestCookie(extendedFlowIDBase + 1).RawId(), expectExtended: false},
+		"should not own cookie of flows added out of band": {cookie: 0, expectExtended: false},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if extended := IsExtendedRuleCookie(sw, tc.cookie); extended != tc.expectExtended {
+				t.Errorf("expect cookie %#x extended %t, got %t", tc.cookie, tc.expectExtended, extended)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/contiv/ofnet"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+
+	"github.com/smartxworks/lynx/pkg/agent/ovsctl"
+)
+
+const (
+	readinessCheckInterval = time.Second
+)
+
+// defaultFlowTables are the tables which must have the default miss flow before datapath ready,
+// the MacDest table is the last table initialized by the datapath.
+var defaultFlowTables = []int{
+	ofnet.EGRESS_TIER0_TBL_ID,
+	ofnet.EGRESS_TIER1_TBL_ID,
+	ofnet.EGRESS_TIER2_TBL_ID,
+	ofnet.INGRESS_TIER0_TBL_ID,
+	ofnet.INGRESS_TIER1_TBL_ID,
+	ofnet.INGRESS_TIER2_TBL_ID,
+	ofnet.MAC_DEST_TBL_ID,
+}
+
+// IsDatapathReady returns true if the openflow connection is up and the default flows
+// have been installed into the bridge, otherwise returns the reason why not ready.
+func IsDatapathReady(agent *ofnet.OfnetAgent, bridgeName string) (bool, string) {
+	if !agent.IsSwitchConnected() {
+		return false, fmt.Sprintf("openflow connection to bridge %s is not up", bridgeName)
+	}
+
+	for _, table := range defaultFlowTables {
+		flows, err := ovsctl.DumpFlows(bridgeName, table)
+		if err != nil {
+			return false, err.Error()
+		}
+		if !hasDefaultFlow(flows) {
+			return false, fmt.Sprintf("default flow of table %d has not been installed", table)
+		}
+	}
+
+	return true, ""
+}
+
+// WaitForDatapathReady blocks until the datapath is ready or stopChan closed.
+func WaitForDatapathReady(agent *ofnet.OfnetAgent, bridgeName string, stopChan <-chan struct{}) error {
+	return wait.PollImmediateUntil(readinessCheckInterval, func() (bool, error) {
+		ready, reason := IsDatapathReady(agent, bridgeName)
+		if !ready {
+			klog.V(2).Infof("waiting for datapath ready: %s", reason)
+		}
+		return ready, nil
+	}, stopChan)
+}
+
+func hasDefaultFlow(flows []*ovsctl.Flow) bool {
+	for _, flow := range flows {
+		if flow.Priority == ofnet.FLOW_MISS_PRIORITY && len(flow.Match) == 0 {
+			return true
+		}
+	}
+	return false
+}
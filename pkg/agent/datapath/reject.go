@@ -0,0 +1,274 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"encoding/binary"
+	"net"
+
+	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/libOpenflow/protocol"
+	"github.com/contiv/libOpenflow/util"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/klog"
+)
+
+const (
+	// RejectControllerID is the openflow controller id of reject responder. Packets matches reject
+	// rules would be sent only to the controller with this id, never to ofnet.
+	RejectControllerID = 0x4c58
+
+	// rejectResponseQPS and rejectResponseBurst limit the rate of reject responses, protect agent
+	// from packets flooding.
+	rejectResponseQPS   = 100
+	rejectResponseBurst = 200
+
+	tcpFlagFIN = 0x01
+	tcpFlagSYN = 0x02
+	tcpFlagRST = 0x04
+	tcpFlagACK = 0x10
+
+	icmpTypeDestUnreachable = 3
+	icmpCodePortUnreachable = 3
+	icmpCodeAdminProhibited = 13
+)
+
+// RejectResponder answers packets rejected by policy rules. TCP packets would be answered with
+// TCP RST, UDP packets with ICMP port unreachable, and other packets with ICMP administratively
+// prohibited.
+type RejectResponder struct {
+	bridgeName  string
+	rateLimiter flowcontrol.RateLimiter
+}
+
+// NewRejectResponder returns a RejectResponder of the bridge.
+func NewRejectResponder(bridgeName string) *RejectResponder {
+	return &RejectResponder{
+		bridgeName:  bridgeName,
+		rateLimiter: flowcontrol.NewTokenBucketRateLimiter(rejectResponseQPS, rejectResponseBurst),
+	}
+}
+
+// Run answers rejected packets until stopChan closed.
+func (r *RejectResponder) Run(stopChan <-chan struct{}) {
+	runPacketInListener(r.bridgeName, RejectControllerID, func(pkt *openflow13.PacketIn) util.Message {
+		if packetOut := r.handlePacketIn(pkt); packetOut != nil {
+			return packetOut
+		}
+		return nil
+	}, stopChan)
+}
+
+func (r *RejectResponder) handlePacketIn(pkt *openflow13.PacketIn) *openflow13.PacketOut {
+	if tag := traceTag(pkt); tag != 0 {
+		// packets injected by traceflow are reported to the trace, never answered
+		tracedPackets.deliver(tag, &TraceResult{Verdict: TraceRejected, Table: pkt.TableId, Cookie: pkt.Cookie})
+		return nil
+	}
+
+	inPort, found := packetInPort(pkt)
+	if !found {
+		klog.Errorf("reject responder received packet without in_port: %+v", pkt.Match)
+		return nil
+	}
+
+	reply, err := newRejectReply(&pkt.Data)
+	if err != nil {
+		klog.Errorf("unable build reject reply: %s", err)
+		return nil
+	}
+	if reply == nil || !r.rateLimiter.TryAccept() {
+		return nil
+	}
+
+	// send the reply back to the port the rejected packet comes from
+	packetOut := openflow13.NewPacketOut()
+	packetOut.InPort = openflow13.P_CONTROLLER
+	packetOut.AddAction(openflow13.NewActionOutput(inPort))
+	packetOut.Data = reply
+	return packetOut
+}
+
+// newRejectReply builds the reply of the rejected packet, returns nil if the packet should
+// not be answered, e.g. tcp reset or icmp error.
+func newRejectReply(pkt *protocol.Ethernet) (*protocol.Ethernet, error) {
+	ipv4, ok := pkt.Data.(*protocol.IPv4)
+	if pkt.Ethertype != protocol.IPv4_MSG || !ok {
+		return nil, nil
+	}
+	if ipv4.IHL != 5 || ipv4.FragmentOffset != 0 {
+		// ignores packets with ip options and non-first fragments
+		return nil, nil
+	}
+	if ipv4.NWDst.IsMulticast() || ipv4.NWDst.Equal(net.IPv4bcast) {
+		return nil, nil
+	}
+
+	var replyIP = protocol.NewIPv4()
+	var err error
+
+	switch ipv4.Protocol {
+	case protocolTCP:
+		replyIP.Data, err = newTCPReset(ipv4)
+	case protocolUDP:
+		replyIP.Data, err = newICMPUnreachable(ipv4, icmpCodePortUnreachable)
+	case protocolICMP:
+		if icmp, ok := ipv4.Data.(*protocol.ICMP); !ok || isICMPError(icmp.Type) {
+			// never answers icmp errors, avoids icmp error storm
+			return nil, nil
+		}
+		replyIP.Data, err = newICMPUnreachable(ipv4, icmpCodeAdminProhibited)
+	default:
+		replyIP.Data, err = newICMPUnreachable(ipv4, icmpCodeAdminProhibited)
+	}
+	if err != nil || replyIP.Data == nil {
+		return nil, err
+	}
+
+	replyIP.Version = 4
+	replyIP.TTL = 64
+	replyIP.NWSrc = ipv4.NWDst
+	replyIP.NWDst = ipv4.NWSrc
+	if _, ok := replyIP.Data.(*protocol.ICMP); ok {
+		replyIP.Protocol = protocolICMP
+	} else {
+		replyIP.Protocol = protocolTCP
+	}
+	replyIP.Length = replyIP.Len()
+
+	header, err := replyIP.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	replyIP.Checksum = checksum(header[:replyIP.IHL*4], 0)
+
+	reply := protocol.NewEthernet()
+	reply.HWSrc = pkt.HWDst
+	reply.HWDst = pkt.HWSrc
+	reply.VLANID = pkt.VLANID
+	reply.Ethertype = protocol.IPv4_MSG
+	reply.Data = replyIP
+
+	return reply, nil
+}
+
+// newTCPReset returns the tcp reset segment of the tcp packet as RFC 793 describes.
+func newTCPReset(ipv4 *protocol.IPv4) (util.Message, error) {
+	data, err := ipv4.Data.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	var tcp protocol.TCP
+	if err = tcp.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	if tcp.Code&tcpFlagRST != 0 {
+		return nil, nil
+	}
+
+	var reset = protocol.NewTCP()
+	reset.PortSrc = tcp.PortDst
+	reset.PortDst = tcp.PortSrc
+	reset.HdrLen = 5
+
+	if tcp.Code&tcpFlagACK != 0 {
+		reset.SeqNum = tcp.AckNum
+		reset.Code = tcpFlagRST
+	} else {
+		// ethernet frame may be padded, calculate the segment length from ip total length
+		segmentLen := uint32(ipv4.Length) - uint32(ipv4.IHL)*4 - uint32(tcp.HdrLen)*4
+		if tcp.Code&tcpFlagSYN != 0 {
+			segmentLen++
+		}
+		if tcp.Code&tcpFlagFIN != 0 {
+			segmentLen++
+		}
+		reset.AckNum = tcp.SeqNum + segmentLen
+		reset.Code = tcpFlagRST | tcpFlagACK
+	}
+
+	segment, err := reset.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	// checksum with the pseudo header of source, destination, protocol and tcp length
+	pseudoHeader := make([]byte, 12)
+	copy(pseudoHeader[0:4], ipv4.NWDst.To4())
+	copy(pseudoHeader[4:8], ipv4.NWSrc.To4())
+	pseudoHeader[9] = protocolTCP
+	binary.BigEndian.PutUint16(pseudoHeader[10:12], uint16(len(segment)))
+	reset.Checksum = checksum(segment, sum(pseudoHeader))
+
+	return reset, nil
+}
+
+// newICMPUnreachable returns icmp destination unreachable with the code, which contains
+// the ip header and the first 8 bytes of the ip payload as RFC 792 describes.
+func newICMPUnreachable(ipv4 *protocol.IPv4, code uint8) (util.Message, error) {
+	data, err := ipv4.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > int(ipv4.IHL)*4+8 {
+		data = data[:int(ipv4.IHL)*4+8]
+	}
+
+	var icmp = protocol.NewICMP()
+	icmp.Type = icmpTypeDestUnreachable
+	icmp.Code = code
+	// four bytes unused field before the original datagram
+	icmp.Data = append(make([]byte, 4), data...)
+
+	message, err := icmp.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	icmp.Checksum = checksum(message, 0)
+
+	return icmp, nil
+}
+
+func isICMPError(icmpType uint8) bool {
+	switch icmpType {
+	// destination unreachable, source quench, redirect, time exceeded, parameter problem
+	case 3, 4, 5, 11, 12:
+		return true
+	default:
+		return false
+	}
+}
+
+// checksum returns the internet checksum of the data, initial is the sum of extra data
+// (e.g. tcp pseudo header).
+func checksum(data []byte, initial uint32) uint16 {
+	s := initial + sum(data)
+	for s>>16 != 0 {
+		s = (s & 0xffff) + (s >> 16)
+	}
+	return ^uint16(s)
+}
+
+func sum(data []byte) uint32 {
+	var s uint32
+	for i := 0; i+1 < len(data); i += 2 {
+		s += uint32(binary.BigEndian.Uint16(data[i:]))
+	}
+	if len(data)%2 == 1 {
+		s += uint32(data[len(data)-1]) << 8
+	}
+	return s
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/contiv/libOpenflow/protocol"
+	"github.com/contiv/libOpenflow/util"
+)
+
+var (
+	testSrcMac = net.HardwareAddr{0x00, 0x00, 0x00, 0x00, 0x00, 0x01}
+	testDstMac = net.HardwareAddr{0x00, 0x00, 0x00, 0x00, 0x00, 0x02}
+	testSrcIP  = net.ParseIP("10.0.0.1").To4()
+	testDstIP  = net.ParseIP("10.0.0.2").To4()
+)
+
+func TestNewRejectReply(t *testing.T) {
+	tests := []struct {
+		name        string
+		ipProtocol  uint8
+		payload     util.Message
+		expectReply bool
+		checkReply  func(t *testing.T, ipv4 *protocol.IPv4)
+	}{
+		{
+			name:        "tcp syn should reply reset",
+			ipProtocol:  protocolTCP,
+			payload:     newTestTCPSegment(tcpFlagSYN, 100, 0),
+			expectReply: true,
+			checkReply: func(t *testing.T, ipv4 *protocol.IPv4) {
+				segment, _ := ipv4.Data.MarshalBinary()
+				if segment[13] != tcpFlagRST|tcpFlagACK {
+					t.Errorf("expect flags rst and ack, got %#x", segment[13])
+				}
+				if ack := binary.BigEndian.Uint32(segment[8:12]); ack != 101 {
+					t.Errorf("expect ack number 101, got %d", ack)
+				}
+				if binary.BigEndian.Uint16(segment[0:2]) != 80 || binary.BigEndian.Uint16(segment[2:4]) != 34567 {
+					t.Errorf("expect ports swapped, got segment %v", segment[:4])
+				}
+				pseudoHeader := make([]byte, 12)
+				copy(pseudoHeader[0:4], ipv4.NWSrc)
+				copy(pseudoHeader[4:8], ipv4.NWDst)
+				pseudoHeader[9] = protocolTCP
+				binary.BigEndian.PutUint16(pseudoHeader[10:12], uint16(len(segment)))
+				if checksum(segment, sum(pseudoHeader)) != 0 {
+					t.Errorf("tcp checksum of reset %v is incorrect", segment)
+				}
+			},
+		},
+		{
+			name:        "tcp ack should reply reset with ack number as sequence",
+			ipProtocol:  protocolTCP,
+			payload:     newTestTCPSegment(tcpFlagACK, 100, 200),
+			expectReply: true,
+			checkReply: func(t *testing.T, ipv4 *protocol.IPv4) {
+				segment, _ := ipv4.Data.MarshalBinary()
+				if segment[13] != tcpFlagRST {
+					t.Errorf("expect flags rst, got %#x", segment[13])
+				}
+				if seq := binary.BigEndian.Uint32(segment[4:8]); seq != 200 {
+					t.Errorf("expect sequence number 200, got %d", seq)
+				}
+			},
+		},
+		{
+			name:        "tcp reset should not reply",
+			ipProtocol:  protocolTCP,
+			payload:     newTestTCPSegment(tcpFlagRST, 100, 0),
+			expectReply: false,
+		},
+		{
+			name:        "udp should reply port unreachable",
+			ipProtocol:  protocolUDP,
+			payload:     util.NewBuffer([]byte{0x87, 0x07, 0x00, 0x35, 0x00, 0x0c, 0x00, 0x00, 0x01, 0x02, 0x03, 0x04}),
+			expectReply: true,
+			checkReply: func(t *testing.T, ipv4 *protocol.IPv4) {
+				icmp, ok := ipv4.Data.(*protocol.ICMP)
+				if !ok {
+					t.Fatalf("expect icmp reply, got %+v", ipv4.Data)
+				}
+				if icmp.Type != icmpTypeDestUnreachable || icmp.Code != icmpCodePortUnreachable {
+					t.Errorf("expect port unreachable, got type %d code %d", icmp.Type, icmp.Code)
+				}
+				// unused field, original ip header and the first 8 bytes of the payload
+				if len(icmp.Data) != 4+20+8 {
+					t.Errorf("unexpect icmp data length %d", len(icmp.Data))
+				}
+				message, _ := icmp.MarshalBinary()
+				if checksum(message, 0) != 0 {
+					t.Errorf("icmp checksum of reply %v is incorrect", message)
+				}
+			},
+		},
+		{
+			name:        "icmp echo request should reply admin prohibited",
+			ipProtocol:  protocolICMP,
+			payload:     &protocol.ICMP{Type: 8, Data: []byte{0x00, 0x01, 0x00, 0x01}},
+			expectReply: true,
+			checkReply: func(t *testing.T, ipv4 *protocol.IPv4) {
+				icmp, ok := ipv4.Data.(*protocol.ICMP)
+				if !ok || icmp.Code != icmpCodeAdminProhibited {
+					t.Errorf("expect admin prohibited, got %+v", ipv4.Data)
+				}
+			},
+		},
+		{
+			name:        "icmp error should not reply",
+			ipProtocol:  protocolICMP,
+			payload:     &protocol.ICMP{Type: icmpTypeDestUnreachable, Code: icmpCodePortUnreachable},
+			expectReply: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkt := newTestPacket(tt.ipProtocol, tt.payload)
+			reply, err := newRejectReply(pkt)
+			if err != nil {
+				t.Fatalf("unexpect error: %s", err)
+			}
+			if (reply != nil) != tt.expectReply {
+				t.Fatalf("expect reply %t, got %+v", tt.expectReply, reply)
+			}
+			if reply == nil {
+				return
+			}
+
+			if reply.HWSrc.String() != testDstMac.String() || reply.HWDst.String() != testSrcMac.String() {
+				t.Errorf("expect mac addresses swapped, got src %s dst %s", reply.HWSrc, reply.HWDst)
+			}
+			ipv4 := reply.Data.(*protocol.IPv4)
+			if !ipv4.NWSrc.Equal(testDstIP) || !ipv4.NWDst.Equal(testSrcIP) {
+				t.Errorf("expect ip addresses swapped, got src %s dst %s", ipv4.NWSrc, ipv4.NWDst)
+			}
+			header, _ := ipv4.MarshalBinary()
+			if checksum(header[:20], 0) != 0 {
+				t.Errorf("ip header checksum of reply %v is incorrect", header[:20])
+			}
+			tt.checkReply(t, ipv4)
+		})
+	}
+}
+
+func newTestTCPSegment(flags uint8, seq, ack uint32) util.Message {
+	tcp := protocol.NewTCP()
+	tcp.PortSrc = 34567
+	tcp.PortDst = 80
+	tcp.SeqNum = seq
+	tcp.AckNum = ack
+	tcp.HdrLen = 5
+	tcp.Code = flags
+	data, _ := tcp.MarshalBinary()
+	return util.NewBuffer(data)
+}
+
+func newTestPacket(ipProtocol uint8, payload util.Message) *protocol.Ethernet {
+	ipv4 := protocol.NewIPv4()
+	ipv4.Version = 4
+	ipv4.TTL = 64
+	ipv4.Protocol = ipProtocol
+	ipv4.NWSrc = testSrcIP
+	ipv4.NWDst = testDstIP
+	ipv4.Data = payload
+	ipv4.Length = ipv4.Len()
+
+	pkt := protocol.NewEthernet()
+	pkt.HWSrc = testSrcMac
+	pkt.HWDst = testDstMac
+	pkt.Data = ipv4
+	return pkt
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync/atomic"
+
+	"github.com/contiv/libOpenflow/openflow13"
+)
+
+const (
+	// nxastSample is the nicira extension action subtype of sample.
+	nxastSample = 29
+	// nxActionSampleLength is the length of nicira sample action.
+	nxActionSampleLength = 24
+)
+
+// FlowSampling configures per-flow sampling of policy rule flows. Sampled packets would be
+// exported by ovs to the IPFIX collectors of the flow sample collector set, with the
+// observation point id of the rule.
+type FlowSampling struct {
+	// Probability is the number of sampled packets out of 65535.
+	Probability uint16
+	// CollectorSetID is the id of ovs Flow_Sample_Collector_Set.
+	CollectorSetID uint32
+	// ObsDomainID is the IPFIX observation domain id of sampled packets.
+	ObsDomainID uint32
+}
+
+var (
+	flowSampling *FlowSampling
+	sampledRules = newRuleRegistry()
+	obsPointID   uint32
+)
+
+// EnableFlowSampling samples packets matches policy rules installed afterwards. It must be
+// called before any policy rule installed.
+func EnableFlowSampling(sampling FlowSampling) {
+	flowSampling = &sampling
+}
+
+// SampledRules returns IPFIX observation point ids of sampled rules mapping to the rule ids.
+func SampledRules() map[uint32]string {
+	rules := make(map[uint32]string)
+	for id, ruleID := range sampledRules.list() {
+		rules[uint32(id)] = ruleID
+	}
+	return rules
+}
+
+// allocateObsPointID returns an unused IPFIX observation point id.
+func allocateObsPointID() uint32 {
+	return atomic.AddUint32(&obsPointID, 1)
+}
+
+// newSampleAction returns action samples packets with the observation point id.
+func newSampleAction(pointID uint32) openflow13.Action {
+	return &nxActionSample{
+		NXActionHeader: newNXActionHeader(nxastSample, nxActionSampleLength),
+		Probability:    flowSampling.Probability,
+		CollectorSetID: flowSampling.CollectorSetID,
+		ObsDomainID:    flowSampling.ObsDomainID,
+		ObsPointID:     pointID,
+	}
+}
+
+func newNXActionHeader(subtype uint16, length uint16) *openflow13.NXActionHeader {
+	header := openflow13.NewNxActionHeader(subtype)
+	header.Length = length
+	return header
+}
+
+// nxActionSample is nicira extension action samples packets to the flow sample collector set,
+// libOpenflow doesn't support it.
+type nxActionSample struct {
+	*openflow13.NXActionHeader
+	Probability    uint16
+	CollectorSetID uint32
+	ObsDomainID    uint32
+	ObsPointID     uint32
+}
+
+func (a *nxActionSample) Len() uint16 {
+	return nxActionSampleLength
+}
+
+func (a *nxActionSample) MarshalBinary() ([]byte, error) {
+	header, err := a.NXActionHeader.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, nxActionSampleLength)
+	n := copy(data, header)
+	binary.BigEndian.PutUint16(data[n:], a.Probability)
+	binary.BigEndian.PutUint32(data[n+2:], a.CollectorSetID)
+	binary.BigEndian.PutUint32(data[n+6:], a.ObsDomainID)
+	binary.BigEndian.PutUint32(data[n+10:], a.ObsPointID)
+	return data, nil
+}
+
+func (a *nxActionSample) UnmarshalBinary(data []byte) error {
+	if len(data) < nxActionSampleLength {
+		return errors.New("the []byte is too short to unmarshal a full NXActionSample message")
+	}
+	a.NXActionHeader = new(openflow13.NXActionHeader)
+	if err := a.NXActionHeader.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	n := openflow13.NxActionHeaderLength
+	a.Probability = binary.BigEndian.Uint16(data[n:])
+	a.CollectorSetID = binary.BigEndian.Uint32(data[n+2:])
+	a.ObsDomainID = binary.BigEndian.Uint32(data[n+6:])
+	a.ObsPointID = binary.BigEndian.Uint32(data[n+10:])
+	return nil
+}
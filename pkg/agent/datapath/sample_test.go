@@ -0,0 +1,54 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNXActionSample(t *testing.T) {
+	EnableFlowSampling(FlowSampling{Probability: 65535, CollectorSetID: 1, ObsDomainID: 2})
+	defer func() { flowSampling = nil }()
+
+	action := newSampleAction(3)
+	data, err := action.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unable marshal sample action: %s", err)
+	}
+
+	expectData := []byte{
+		0xff, 0xff, 0x00, 0x18, // type experimenter, length 24
+		0x00, 0x00, 0x23, 0x20, // nicira vendor id
+		0x00, 0x1d, // subtype sample
+		0xff, 0xff, // probability
+		0x00, 0x00, 0x00, 0x01, // collector set id
+		0x00, 0x00, 0x00, 0x02, // observation domain id
+		0x00, 0x00, 0x00, 0x03, // observation point id
+	}
+	if !bytes.Equal(data, expectData) {
+		t.Fatalf("expect sample action %v, got %v", expectData, data)
+	}
+
+	var decoded nxActionSample
+	if err = decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unable unmarshal sample action: %s", err)
+	}
+	if decoded.Probability != 65535 || decoded.CollectorSetID != 1 || decoded.ObsDomainID != 2 || decoded.ObsPointID != 3 {
+		t.Errorf("unexpect decoded sample action %+v", decoded)
+	}
+}
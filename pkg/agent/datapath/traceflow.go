@@ -0,0 +1,280 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/libOpenflow/protocol"
+	"github.com/contiv/libOpenflow/util"
+	"github.com/contiv/ofnet"
+)
+
+const (
+	// TraceflowControllerID is the openflow controller id of traceflow listener. Injected trace
+	// packets reach the forwarding table would be sent to the controller with this id.
+	TraceflowControllerID = 0x4c5a
+
+	// traceflowMarkReg is the register carries the tag of injected trace packets, it's loaded by
+	// the packet out and kept through all tables, includes conntrack recirculation.
+	traceflowMarkReg = 7
+	// traceflowPriority is the priority of flows capture trace packets in the forwarding table,
+	// higher than any forwarding flows.
+	traceflowPriority = 0xfff0
+	// traceflowTimeout is how long to wait for the injected packet, the packet is considered
+	// dropped if neither captured nor rejected in time.
+	traceflowTimeout = 2 * time.Second
+)
+
+// TraceVerdict is what finally happened to the injected trace packet.
+type TraceVerdict string
+
+const (
+	// TraceForwarded means the packet reached the forwarding table, where it would be forwarded.
+	TraceForwarded TraceVerdict = "Forwarded"
+	// TraceRejected means the packet matched a reject rule and was sent to reject responder.
+	TraceRejected TraceVerdict = "Rejected"
+	// TraceDropped means the packet was neither forwarded nor rejected in time.
+	TraceDropped TraceVerdict = "Dropped"
+)
+
+// TraceResult is the observation of the injected trace packet.
+type TraceResult struct {
+	Verdict TraceVerdict
+	// Table and Cookie of the flow sent the packet to the controller, zero if dropped.
+	Table  uint8
+	Cookie uint64
+}
+
+var (
+	// traceflowTag is the last tag allocated to trace packets, tag zero means not a trace packet.
+	traceflowTag uint32
+	// tracedPackets maps tags of trace packets in flight to the channels waiting for them.
+	tracedPackets = &traceRegistry{pending: make(map[uint32]chan *TraceResult)}
+	// traceflowListener receives trace packets reach the forwarding table.
+	traceflowListener *packetInListener
+	// traceflowLock protects traceflowListener, and serializes traces so that at most one capture
+	// flow in the bridge.
+	traceflowLock sync.Mutex
+)
+
+// RunTraceflowListener receives injected trace packets captured on the bridge until stopChan closed,
+// InjectTracePacket fails if the listener is not connected.
+func RunTraceflowListener(bridgeName string, stopChan <-chan struct{}) {
+	listener := newPacketInListener(bridgeName, TraceflowControllerID, func(pkt *openflow13.PacketIn) util.Message {
+		if tag := traceTag(pkt); tag != 0 {
+			tracedPackets.deliver(tag, &TraceResult{Verdict: TraceForwarded, Table: pkt.TableId, Cookie: pkt.Cookie})
+		}
+		return nil
+	})
+
+	traceflowLock.Lock()
+	traceflowListener = listener
+	traceflowLock.Unlock()
+
+	listener.run(stopChan)
+}
+
+// InjectTracePacket sends the packet into the bridge as if it comes from the inPort, and returns
+// where the packet finally went. The packet is tagged by a register, and captured before forwarded,
+// so it never reaches the destination. Checksums of the packet would be filled before sent.
+func InjectTracePacket(policyAgent *ofnet.PolicyAgent, inPort uint32, pkt *protocol.Ethernet) (*TraceResult, error) {
+	traceflowLock.Lock()
+	defer traceflowLock.Unlock()
+
+	if traceflowListener == nil || !traceflowListener.isConnected() {
+		return nil, fmt.Errorf("traceflow listener not connected to bridge")
+	}
+	if err := setChecksums(pkt); err != nil {
+		return nil, fmt.Errorf("unable fill checksums of trace packet: %s", err)
+	}
+
+	// any table of the policy agent shares the bridge switch
+	table, _, err := policyAgent.GetTierTable(ofnet.POLICY_DIRECTION_OUT, ofnet.POLICY_TIER0)
+	if err != nil {
+		return nil, fmt.Errorf("unable get switch of bridge: %s", err)
+	}
+	sw := table.Switch
+
+	tag := atomic.AddUint32(&traceflowTag, 1)
+	if tag == 0 {
+		tag = atomic.AddUint32(&traceflowTag, 1)
+	}
+	resultChan := tracedPackets.add(tag)
+	defer tracedPackets.remove(tag)
+
+	ensureControllerMeters(sw)
+	captureFlow := newTraceCaptureFlowMod(tag)
+	captureFlow.Command = openflow13.FC_ADD
+	captureFlow.Cookie = allocateCookie(sw)
+	sw.Send(captureFlow)
+	defer func() {
+		deleteFlow := newTraceCaptureFlowMod(tag)
+		deleteFlow.Command = openflow13.FC_DELETE_STRICT
+		deleteFlow.OutPort = openflow13.P_ANY
+		deleteFlow.OutGroup = openflow13.OFPG_ANY
+		sw.Send(deleteFlow)
+	}()
+
+	// ovs handles messages of the connection in order, the capture flow installed before the packet out
+	markField, _ := openflow13.FindFieldHeaderByName(fmt.Sprintf("NXM_NX_REG%d", traceflowMarkReg), false)
+	packetOut := openflow13.NewPacketOut()
+	packetOut.InPort = inPort
+	packetOut.AddAction(openflow13.NewNXActionRegLoad(openflow13.NewNXRange(0, 31).ToOfsBits(), markField, uint64(tag)))
+	packetOut.AddAction(openflow13.NewNXActionResubmitTableAction(openflow13.OFPP_IN_PORT, 0))
+	packetOut.Data = pkt
+	sw.Send(packetOut)
+
+	select {
+	case result := <-resultChan:
+		return result, nil
+	case <-time.After(traceflowTimeout):
+		return &TraceResult{Verdict: TraceDropped}, nil
+	}
+}
+
+// newTraceCaptureFlowMod builds flowMod in the forwarding table, which sends the trace packet with
+// the tag to traceflow listener instead of forwarding it.
+func newTraceCaptureFlowMod(tag uint32) *openflow13.FlowMod {
+	var match = openflow13.NewMatch()
+	match.AddField(*openflow13.NewRegMatchField(traceflowMarkReg, tag, nil))
+
+	var applyActions = openflow13.NewInstrApplyActions()
+	_ = applyActions.AddAction(newSendToControllerAction(TraceflowControllerID), false)
+
+	flowMod := openflow13.NewFlowMod()
+	flowMod.TableId = ofnet.MAC_DEST_TBL_ID
+	flowMod.Priority = traceflowPriority
+	flowMod.CookieMask = ^uint64(0)
+	flowMod.Match = *match
+	flowMod.AddInstruction(applyActions)
+	return flowMod
+}
+
+// traceTag returns the tag of the trace packet, zero if the packet is not injected by traceflow.
+func traceTag(pkt *openflow13.PacketIn) uint32 {
+	for _, field := range pkt.Match.Fields {
+		if field.Class != openflow13.OXM_CLASS_NXM_1 || field.Field != openflow13.NXM_NX_REG0+traceflowMarkReg {
+			continue
+		}
+		if value, ok := field.Value.(*openflow13.Uint32Message); ok {
+			return value.Data
+		}
+	}
+	return 0
+}
+
+// setChecksums fills the ip header checksum and the transport layer checksum of the ipv4 packet.
+func setChecksums(pkt *protocol.Ethernet) error {
+	ipv4, ok := pkt.Data.(*protocol.IPv4)
+	if pkt.Ethertype != protocol.IPv4_MSG || !ok {
+		return fmt.Errorf("only ipv4 packet supported")
+	}
+	ipv4.Checksum = 0
+	ipv4.Length = ipv4.Len()
+
+	switch transport := ipv4.Data.(type) {
+	case *protocol.TCP:
+		transport.Checksum = 0
+		segment, err := transport.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		transport.Checksum = checksum(segment, sum(pseudoHeader(ipv4, len(segment))))
+	case *protocol.UDP:
+		transport.Checksum = 0
+		datagram, err := transport.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		transport.Checksum = checksum(datagram, sum(pseudoHeader(ipv4, len(datagram))))
+		if transport.Checksum == 0 {
+			// zero means no checksum in udp, transmitted as all ones instead
+			transport.Checksum = 0xffff
+		}
+	case *protocol.ICMP:
+		transport.Checksum = 0
+		message, err := transport.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		transport.Checksum = checksum(message, 0)
+	case *util.Buffer:
+		if ipv4.Protocol == protocolSCTP && transport.Len() >= 12 {
+			// sctp checksum is crc32c of the whole packet with checksum field zero, as RFC 4960 describes
+			data := transport.Bytes()
+			binary.LittleEndian.PutUint32(data[8:], 0)
+			binary.LittleEndian.PutUint32(data[8:], crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli)))
+		}
+	}
+
+	header, err := ipv4.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	ipv4.Checksum = checksum(header[:ipv4.IHL*4], 0)
+	return nil
+}
+
+// pseudoHeader returns the tcp and udp pseudo header of source, destination, protocol and length.
+func pseudoHeader(ipv4 *protocol.IPv4, length int) []byte {
+	header := make([]byte, 12)
+	copy(header[0:4], ipv4.NWSrc.To4())
+	copy(header[4:8], ipv4.NWDst.To4())
+	header[9] = ipv4.Protocol
+	binary.BigEndian.PutUint16(header[10:12], uint16(length))
+	return header
+}
+
+// traceRegistry maps tags of trace packets in flight to the channels waiting for the results.
+type traceRegistry struct {
+	lock    sync.Mutex
+	pending map[uint32]chan *TraceResult
+}
+
+func (r *traceRegistry) add(tag uint32) chan *TraceResult {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	// buffered, so that delivering never blocks the packet in listener
+	resultChan := make(chan *TraceResult, 1)
+	r.pending[tag] = resultChan
+	return resultChan
+}
+
+func (r *traceRegistry) remove(tag uint32) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.pending, tag)
+}
+
+// deliver sends the result to the trace waiting for the tag, only the first result is kept.
+func (r *traceRegistry) deliver(tag uint32, result *TraceResult) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if resultChan, ok := r.pending[tag]; ok {
+		select {
+		case resultChan <- result:
+		default:
+		}
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datapath
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+
+	"github.com/contiv/libOpenflow/openflow13"
+	"github.com/contiv/libOpenflow/protocol"
+	"github.com/contiv/libOpenflow/util"
+)
+
+func TestSetChecksums(t *testing.T) {
+	tcp := protocol.NewTCP()
+	tcp.PortSrc, tcp.PortDst, tcp.HdrLen, tcp.Code = 34567, 80, 5, tcpFlagSYN
+	udp := protocol.NewUDP()
+	udp.PortSrc, udp.PortDst = 34567, 53
+	udp.Length = udp.Len()
+
+	tests := []struct {
+		name       string
+		ipProtocol uint8
+		payload    util.Message
+		// verify returns true if the transport layer checksum is correct
+		verify func(ipv4 *protocol.IPv4, payload []byte) bool
+	}{
+		{
+			name:       "tcp segment",
+			ipProtocol: protocolTCP,
+			payload:    tcp,
+			verify: func(ipv4 *protocol.IPv4, payload []byte) bool {
+				return checksum(payload, sum(pseudoHeader(ipv4, len(payload)))) == 0
+			},
+		},
+		{
+			name:       "udp datagram",
+			ipProtocol: protocolUDP,
+			payload:    udp,
+			verify: func(ipv4 *protocol.IPv4, payload []byte) bool {
+				return checksum(payload, sum(pseudoHeader(ipv4, len(payload)))) == 0
+			},
+		},
+		{
+			name:       "icmp echo request",
+			ipProtocol: protocolICMP,
+			payload:    &protocol.ICMP{Type: 8, Data: []byte{0x00, 0x01, 0x00, 0x01}},
+			verify: func(ipv4 *protocol.IPv4, payload []byte) bool {
+				return checksum(payload, 0) == 0
+			},
+		},
+		{
+			name:       "sctp common header",
+			ipProtocol: protocolSCTP,
+			payload:    util.NewBuffer([]byte{0x87, 0x07, 0x00, 0x50, 0, 0, 0, 0, 0, 0, 0, 0}),
+			verify: func(ipv4 *protocol.IPv4, payload []byte) bool {
+				data := append([]byte{}, payload...)
+				expect := binary.LittleEndian.Uint32(data[8:])
+				binary.LittleEndian.PutUint32(data[8:], 0)
+				return expect != 0 && crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli)) == expect
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkt := newTestPacket(tt.ipProtocol, tt.payload)
+			pkt.Ethertype = protocol.IPv4_MSG
+			if err := setChecksums(pkt); err != nil {
+				t.Fatalf("unexpect error: %s", err)
+			}
+
+			ipv4 := pkt.Data.(*protocol.IPv4)
+			header, _ := ipv4.MarshalBinary()
+			if checksum(header[:20], 0) != 0 {
+				t.Errorf("ip header checksum of %v is incorrect", header[:20])
+			}
+			payload, _ := ipv4.Data.MarshalBinary()
+			if !tt.verify(ipv4, payload) {
+				t.Errorf("checksum of payload %v is incorrect", payload)
+			}
+		})
+	}
+}
+
+func TestTraceTag(t *testing.T) {
+	pkt := openflow13.NewPacketIn()
+	pkt.Match.AddField(*openflow13.NewInPortField(3))
+	if tag := traceTag(pkt); tag != 0 {
+		t.Errorf("expect packet without mark has no tag, got %d", tag)
+	}
+
+	pkt.Match.AddField(*openflow13.NewRegMatchField(traceflowMarkReg, 12, nil))
+	if tag := traceTag(pkt); tag != 12 {
+		t.Errorf("expect tag 12 of the marked packet, got %d", tag)
+	}
+}
+
+func TestTraceRegistry(t *testing.T) {
+	registry := &traceRegistry{pending: make(map[uint32]chan *TraceResult)}
+	resultChan := registry.add(1)
+
+	// results of unknown tags are ignored, and only the first result is kept
+	registry.deliver(2, &TraceResult{Verdict: TraceForwarded})
+	registry.deliver(1, &TraceResult{Verdict: TraceRejected})
+	registry.deliver(1, &TraceResult{Verdict: TraceForwarded})
+
+	select {
+	case result := <-resultChan:
+		if result.Verdict != TraceRejected {
+			t.Errorf("expect the first result rejected, got %s", result.Verdict)
+		}
+	default:
+		t.Fatalf("expect result delivered")
+	}
+
+	registry.remove(1)
+	if len(registry.pending) != 0 {
+		t.Errorf("expect no pending traces, got %v", registry.pending)
+	}
+}
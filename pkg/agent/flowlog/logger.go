@@ -0,0 +1,223 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flowlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/contiv/libOpenflow/protocol"
+	"k8s.io/apimachinery/pkg/util/cache"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/klog"
+
+	"github.com/smartxworks/lynx/pkg/apis/policyrule/v1alpha1"
+	lynxctrl "github.com/smartxworks/lynx/pkg/controller"
+)
+
+const (
+	// connectionCacheSize and connectionCacheTTL limit how many and how long the logged
+	// connections without tcp handshake (e.g. udp, icmp) would be remembered.
+	connectionCacheSize = 4096
+	connectionCacheTTL  = time.Minute
+
+	// recordQPS and recordBurst limit the rate of records written.
+	recordQPS   = 100
+	recordBurst = 500
+
+	protocolICMP = 1
+	protocolTCP  = 6
+	protocolUDP  = 17
+
+	tcpFlagSYN = 0x02
+	tcpFlagACK = 0x10
+)
+
+// PolicyRuleGetter returns the PolicyRules installed as the datapath rule.
+type PolicyRuleGetter func(ruleID string) []v1alpha1.PolicyRule
+
+// EndpointGetter returns the external ids of the endpoint connected to the ofport.
+type EndpointGetter func(ofport uint32) map[string]string
+
+// Record is the log record of the first packet of a connection matches the logging rule.
+type Record struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Policy      string    `json:"policy,omitempty"`
+	Rule        string    `json:"rule,omitempty"`
+	PolicyRule  string    `json:"policyRule"`
+	Tier        string    `json:"tier"`
+	Direction   string    `json:"direction"`
+	Action      string    `json:"action"`
+	MonitorOnly bool      `json:"monitorOnly,omitempty"`
+
+	SrcIP    string `json:"srcIP"`
+	DstIP    string `json:"dstIP"`
+	Protocol string `json:"protocol"`
+	SrcPort  uint16 `json:"srcPort,omitempty"`
+	DstPort  uint16 `json:"dstPort,omitempty"`
+
+	InPort   uint32            `json:"inPort"`
+	Endpoint map[string]string `json:"endpoint,omitempty"`
+}
+
+// Logger writes records of packets matches logging rules. For tcp, only the first packet
+// of the handshake would be logged, for other protocols, the packet would be logged once
+// in a while for the same five tuple.
+type Logger struct {
+	writers         []io.Writer
+	getPolicyRules  PolicyRuleGetter
+	getEndpoint     EndpointGetter
+	connectionCache *cache.LRUExpireCache
+	rateLimiter     flowcontrol.RateLimiter
+	now             func() time.Time
+}
+
+// NewLogger returns a Logger writes records to the writers.
+func NewLogger(getPolicyRules PolicyRuleGetter, getEndpoint EndpointGetter, writers ...io.Writer) *Logger {
+	return &Logger{
+		writers:         writers,
+		getPolicyRules:  getPolicyRules,
+		getEndpoint:     getEndpoint,
+		connectionCache: cache.NewLRUExpireCache(connectionCacheSize),
+		rateLimiter:     flowcontrol.NewTokenBucketRateLimiter(recordQPS, recordBurst),
+		now:             time.Now,
+	}
+}
+
+// HandlePacket logs the packet matches the datapath rule ruleID, implements datapath.FlowLogHandler.
+func (l *Logger) HandlePacket(ruleID string, inPort uint32, pkt *protocol.Ethernet) {
+	tuple, first, err := parseConnection(pkt)
+	if err != nil {
+		klog.V(4).Infof("flow logger ignores packet: %s", err)
+		return
+	}
+
+	connKey := fmt.Sprintf("%s/%s", ruleID, tuple)
+	if !first {
+		if _, ok := l.connectionCache.Get(connKey); ok {
+			return
+		}
+		l.connectionCache.Add(connKey, struct{}{}, connectionCacheTTL)
+	}
+
+	if !l.rateLimiter.TryAccept() {
+		return
+	}
+
+	for _, policyRule := range l.getPolicyRules(ruleID) {
+		record := l.newRecord(&policyRule, tuple, inPort)
+		if err := l.write(record); err != nil {
+			klog.Errorf("unable write flow log record %+v: %s", record, err)
+		}
+	}
+}
+
+func (l *Logger) newRecord(policyRule *v1alpha1.PolicyRule, tuple *fiveTuple, inPort uint32) *Record {
+	record := &Record{
+		Timestamp:   l.now(),
+		Policy:      policyRule.Labels[lynxctrl.OwnerPolicyLabel],
+		Rule:        policyRule.Annotations[lynxctrl.OwnerRuleAnnotation],
+		PolicyRule:  policyRule.Name,
+		Tier:        policyRule.Spec.Tier,
+		Direction:   string(policyRule.Spec.Direction),
+		Action:      string(policyRule.Spec.Action),
+		MonitorOnly: policyRule.Spec.MonitorOnly,
+		SrcIP:       tuple.srcIP,
+		DstIP:       tuple.dstIP,
+		Protocol:    tuple.protocol,
+		SrcPort:     tuple.srcPort,
+		DstPort:     tuple.dstPort,
+		InPort:      inPort,
+	}
+	if l.getEndpoint != nil {
+		record.Endpoint = l.getEndpoint(inPort)
+	}
+	return record
+}
+
+func (l *Logger) write(record *Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	// a failing writer should not stop records written into the others
+	var errs []error
+	for _, writer := range l.writers {
+		if _, err = writer.Write(line); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+type fiveTuple struct {
+	srcIP    string
+	dstIP    string
+	protocol string
+	srcPort  uint16
+	dstPort  uint16
+}
+
+func (t *fiveTuple) String() string {
+	return fmt.Sprintf("%s:%s:%d-%s:%d", t.protocol, t.srcIP, t.srcPort, t.dstIP, t.dstPort)
+}
+
+// parseConnection returns five tuple of the packet, first is true if the packet is the first
+// packet of a tcp connection.
+func parseConnection(pkt *protocol.Ethernet) (*fiveTuple, bool, error) {
+	ipv4, ok := pkt.Data.(*protocol.IPv4)
+	if pkt.Ethertype != protocol.IPv4_MSG || !ok {
+		return nil, false, fmt.Errorf("unsupported ethertype %#x", pkt.Ethertype)
+	}
+
+	tuple := &fiveTuple{
+		srcIP:    ipv4.NWSrc.String(),
+		dstIP:    ipv4.NWDst.String(),
+		protocol: fmt.Sprintf("%d", ipv4.Protocol),
+	}
+
+	switch ipv4.Protocol {
+	case protocolTCP:
+		data, err := ipv4.Data.MarshalBinary()
+		if err != nil {
+			return nil, false, err
+		}
+		var tcp protocol.TCP
+		if err = tcp.UnmarshalBinary(data); err != nil {
+			return nil, false, err
+		}
+		if tcp.Code&tcpFlagSYN == 0 || tcp.Code&tcpFlagACK != 0 {
+			return nil, false, fmt.Errorf("tcp packet %s is not connection request", tuple)
+		}
+		tuple.protocol, tuple.srcPort, tuple.dstPort = "TCP", tcp.PortSrc, tcp.PortDst
+		return tuple, true, nil
+	case protocolUDP:
+		if udp, ok := ipv4.Data.(*protocol.UDP); ok {
+			tuple.srcPort, tuple.dstPort = udp.PortSrc, udp.PortDst
+		}
+		tuple.protocol = "UDP"
+	case protocolICMP:
+		tuple.protocol = "ICMP"
+	}
+
+	return tuple, false, nil
+}
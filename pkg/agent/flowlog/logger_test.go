@@ -0,0 +1,169 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flowlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/contiv/libOpenflow/protocol"
+	"github.com/contiv/libOpenflow/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/smartxworks/lynx/pkg/apis/policyrule/v1alpha1"
+	lynxctrl "github.com/smartxworks/lynx/pkg/controller"
+)
+
+func TestHandlePacket(t *testing.T) {
+	var policyRule = v1alpha1.PolicyRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "policy1-ingress-xxxxxx",
+			Labels:      map[string]string{lynxctrl.OwnerPolicyLabel: "policy1"},
+			Annotations: map[string]string{lynxctrl.OwnerRuleAnnotation: "ingress.rule1"},
+		},
+		Spec: v1alpha1.PolicyRuleSpec{
+			Direction: v1alpha1.RuleDirectionIn,
+			Tier:      "tier1",
+			Action:    v1alpha1.RuleActionAllow,
+		},
+	}
+	var getPolicyRules = func(ruleID string) []v1alpha1.PolicyRule {
+		if ruleID == "rule1" {
+			return []v1alpha1.PolicyRule{policyRule}
+		}
+		return nil
+	}
+	var getEndpoint = func(ofport uint32) map[string]string {
+		return map[string]string{"iface-id": "ep1"}
+	}
+
+	tests := []struct {
+		name          string
+		ruleID        string
+		packets       []*protocol.Ethernet
+		expectRecords int
+	}{
+		{
+			name:          "tcp syn should be logged",
+			ruleID:        "rule1",
+			packets:       []*protocol.Ethernet{newTestTCPPacket(tcpFlagSYN), newTestTCPPacket(tcpFlagSYN)},
+			expectRecords: 2,
+		},
+		{
+			name:          "tcp packets after handshake should not be logged",
+			ruleID:        "rule1",
+			packets:       []*protocol.Ethernet{newTestTCPPacket(tcpFlagSYN | tcpFlagACK), newTestTCPPacket(tcpFlagACK)},
+			expectRecords: 0,
+		},
+		{
+			name:          "udp packets of the same connection should be logged once",
+			ruleID:        "rule1",
+			packets:       []*protocol.Ethernet{newTestUDPPacket(53), newTestUDPPacket(53), newTestUDPPacket(54)},
+			expectRecords: 2,
+		},
+		{
+			name:          "packet of unknown rule should not be logged",
+			ruleID:        "rule2",
+			packets:       []*protocol.Ethernet{newTestTCPPacket(tcpFlagSYN)},
+			expectRecords: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			logger := NewLogger(getPolicyRules, getEndpoint, &buffer)
+			for _, pkt := range tt.packets {
+				logger.HandlePacket(tt.ruleID, 5, pkt)
+			}
+
+			lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+			if buffer.Len() == 0 {
+				lines = nil
+			}
+			if len(lines) != tt.expectRecords {
+				t.Fatalf("expect %d records, got %v", tt.expectRecords, lines)
+			}
+			for _, line := range lines {
+				var record Record
+				if err := json.Unmarshal([]byte(line), &record); err != nil {
+					t.Fatalf("unable unmarshal record %s: %s", line, err)
+				}
+				if record.Policy != "policy1" || record.Rule != "ingress.rule1" || record.PolicyRule != policyRule.Name {
+					t.Errorf("unexpect rule in record %+v", record)
+				}
+				if record.SrcIP != "10.0.0.1" || record.DstIP != "10.0.0.2" || record.InPort != 5 || record.Endpoint["iface-id"] != "ep1" {
+					t.Errorf("unexpect packet in record %+v", record)
+				}
+			}
+		})
+	}
+}
+
+type failingWriter string
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New(string(w) + " closed")
+}
+
+func TestWriteAllWriters(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := NewLogger(nil, nil, failingWriter("writer1"), &buffer, failingWriter("writer2"))
+
+	err := logger.write(&Record{Policy: "policy1"})
+	if err == nil || !strings.Contains(err.Error(), "writer1 closed") || !strings.Contains(err.Error(), "writer2 closed") {
+		t.Errorf("expect errors of both failing writers, got %v", err)
+	}
+	if !strings.Contains(buffer.String(), "policy1") {
+		t.Errorf("record should be written after failing writer, got %q", buffer.String())
+	}
+}
+
+func newTestTCPPacket(flags uint8) *protocol.Ethernet {
+	tcp := protocol.NewTCP()
+	tcp.PortSrc = 34567
+	tcp.PortDst = 80
+	tcp.HdrLen = 5
+	tcp.Code = flags
+	data, _ := tcp.MarshalBinary()
+	return newTestPacket(protocolTCP, util.NewBuffer(data))
+}
+
+func newTestUDPPacket(dstPort uint16) *protocol.Ethernet {
+	udp := protocol.NewUDP()
+	udp.PortSrc = 34567
+	udp.PortDst = dstPort
+	return newTestPacket(protocolUDP, udp)
+}
+
+func newTestPacket(ipProtocol uint8, payload util.Message) *protocol.Ethernet {
+	ipv4 := protocol.NewIPv4()
+	ipv4.Version = 4
+	ipv4.Protocol = ipProtocol
+	ipv4.NWSrc = net.ParseIP("10.0.0.1").To4()
+	ipv4.NWDst = net.ParseIP("10.0.0.2").To4()
+	ipv4.Data = payload
+
+	pkt := protocol.NewEthernet()
+	pkt.Ethertype = protocol.IPv4_MSG
+	pkt.Data = ipv4
+	return pkt
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flowlog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// RotatingFileWriter writes to the file, and rotates the file when its size exceeds maxSize.
+// Rotated files are renamed with suffix .1, .2 ... and at most maxBackups files are kept. The file
+// is opened on the first write, so nothing would be created if no records written.
+type RotatingFileWriter struct {
+	lock       sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	// file is nil until the first write, or after failed to open
+	file *os.File
+	size int64
+}
+
+// NewRotatingFileWriter returns the writer appends to the file, the file would be created if not exists.
+func NewRotatingFileWriter(path string, maxSize int64, maxBackups int) *RotatingFileWriter {
+	return &RotatingFileWriter{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+}
+
+// Write implements io.Writer.
+func (w *RotatingFileWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.file == nil {
+		// open again on the next write if failed
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close closes the file.
+func (w *RotatingFileWriter) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+func (w *RotatingFileWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.path), 0755); err != nil {
+		return fmt.Errorf("unable create directory of %s: %s", w.path, err)
+	}
+
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("unable open file %s: %s", w.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("unable stat file %s: %s", w.path, err)
+	}
+
+	w.file, w.size = file, info.Size()
+	return nil
+}
+
+func (w *RotatingFileWriter) rotate() error {
+	err := w.file.Close()
+	w.file = nil
+	if err != nil {
+		return err
+	}
+
+	// remove the oldest backup, and shift the others
+	_ = os.Remove(w.backupPath(w.maxBackups))
+	for index := w.maxBackups - 1; index >= 1; index-- {
+		_ = os.Rename(w.backupPath(index), w.backupPath(index+1))
+	}
+	if w.maxBackups > 0 {
+		if err := os.Rename(w.path, w.backupPath(1)); err != nil {
+			return fmt.Errorf("unable rotate file %s: %s", w.path, err)
+		}
+	} else {
+		_ = os.Remove(w.path)
+	}
+
+	return w.open()
+}
+
+func (w *RotatingFileWriter) backupPath(index int) string {
+	return fmt.Sprintf("%s.%d", w.path, index)
+}
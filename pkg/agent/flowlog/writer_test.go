@@ -0,0 +1,90 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flowlog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotatingFileWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flowlog")
+	if err != nil {
+		t.Fatalf("unable create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "flow.log")
+	writer := NewRotatingFileWriter(path, 10, 2)
+	defer writer.Close()
+
+	for _, line := range []string{"record-1\n", "record-2\n", "record-3\n", "record-4\n"} {
+		if _, err = writer.Write([]byte(line)); err != nil {
+			t.Fatalf("unable write: %s", err)
+		}
+	}
+
+	expectFiles := map[string]string{
+		path:        "record-4\n",
+		path + ".1": "record-3\n",
+		path + ".2": "record-2\n",
+	}
+	for file, expectContent := range expectFiles {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatalf("unable read %s: %s", file, err)
+		}
+		if string(content) != expectContent {
+			t.Errorf("expect %s content %q, got %q", file, expectContent, content)
+		}
+	}
+	if _, err = os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expect at most 2 backups, got %s", path+".3")
+	}
+}
+
+func TestRotatingFileWriterOpenOnWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flowlog")
+	if err != nil {
+		t.Fatalf("unable create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the parent of the directory is a file, so the directory could never be created
+	blocker := filepath.Join(dir, "blocker")
+	if err = ioutil.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatalf("unable create file %s: %s", blocker, err)
+	}
+	writer := NewRotatingFileWriter(filepath.Join(blocker, "lynx", "flow.log"), 10, 2)
+	defer writer.Close()
+
+	if _, err = writer.Write([]byte("record-1\n")); err == nil {
+		t.Errorf("expect error when write into directory not exists")
+	}
+
+	path := filepath.Join(dir, "lynx", "flow.log")
+	writer = NewRotatingFileWriter(path, 10, 2)
+	defer writer.Close()
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expect file %s not created before write", path)
+	}
+	if _, err = writer.Write([]byte("record-1\n")); err != nil {
+		t.Errorf("unable write: %s", err)
+	}
+}
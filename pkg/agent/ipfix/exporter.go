@@ -0,0 +1,156 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipfix
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog"
+
+	"github.com/smartxworks/lynx/pkg/apis/policyrule/v1alpha1"
+	lynxctrl "github.com/smartxworks/lynx/pkg/controller"
+)
+
+// SampledRulesGetter returns observation point ids of sampled datapath rules mapping to the rule ids.
+type SampledRulesGetter func() map[uint32]string
+
+// PolicyRuleGetter returns the PolicyRules installed as the datapath rule.
+type PolicyRuleGetter func(ruleID string) []v1alpha1.PolicyRule
+
+// EndpointsGetter returns the external ids of endpoints mapping by their ofports.
+type EndpointsGetter func() map[uint32]map[string]string
+
+// Exporter exports IPFIX options records to the collector, which enrich the flow records exported
+// by ovs. Flow records could be correlated with the rule options records by observationPointId, and
+// with the endpoint options records by ingressInterface.
+type Exporter struct {
+	collector        string
+	obsDomainID      uint32
+	enterpriseNumber uint32
+	interval         time.Duration
+
+	getSampledRules SampledRulesGetter
+	getPolicyRules  PolicyRuleGetter
+	getEndpoints    EndpointsGetter
+
+	sequence uint32
+}
+
+// NewExporter returns an Exporter exports options records to the collector (ip:port) by udp every interval.
+func NewExporter(collector string, obsDomainID, enterpriseNumber uint32, interval time.Duration,
+	getSampledRules SampledRulesGetter, getPolicyRules PolicyRuleGetter, getEndpoints EndpointsGetter) *Exporter {
+	return &Exporter{
+		collector:        collector,
+		obsDomainID:      obsDomainID,
+		enterpriseNumber: enterpriseNumber,
+		interval:         interval,
+		getSampledRules:  getSampledRules,
+		getPolicyRules:   getPolicyRules,
+		getEndpoints:     getEndpoints,
+	}
+}
+
+// Run exports options records periodically until stopChan closed.
+func (e *Exporter) Run(stopChan <-chan struct{}) {
+	wait.Until(func() {
+		if err := e.export(); err != nil {
+			klog.Errorf("unable export ipfix options records to %s: %s", e.collector, err)
+		}
+	}, e.interval, stopChan)
+}
+
+func (e *Exporter) export() error {
+	conn, err := net.Dial("udp", e.collector)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, message := range e.buildMessages(time.Now()) {
+		if _, err = conn.Write(message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildMessages returns messages contain templates and options records of all sampled rules and endpoints.
+func (e *Exporter) buildMessages(exportTime time.Time) [][]byte {
+	var ruleRecords, endpointRecords []record
+
+	sampledRules := e.getSampledRules()
+	for _, pointID := range sortedKeys(sampledRules) {
+		for _, policyRule := range e.getPolicyRules(sampledRules[pointID]) {
+			ruleRecords = append(ruleRecords, record{
+				uint32Value(pointID),
+				stringValue(sampledRules[pointID]),
+				stringValue(policyRule.Name),
+				stringValue(policyRule.Labels[lynxctrl.OwnerPolicyLabel]),
+				stringValue(policyRule.Annotations[lynxctrl.OwnerRuleAnnotation]),
+				stringValue(string(policyRule.Spec.Action)),
+			})
+		}
+	}
+
+	endpoints := e.getEndpoints()
+	for _, ofport := range sortedKeys(endpoints) {
+		endpointRecords = append(endpointRecords, record{
+			uint32Value(ofport),
+			stringValue(formatExternalIDs(endpoints[ofport])),
+		})
+	}
+
+	builder := newMessageBuilder(e.obsDomainID, exportTime, &e.sequence, []*template{
+		newRuleTemplate(e.enterpriseNumber),
+		newEndpointTemplate(e.enterpriseNumber),
+	})
+	builder.addRecords(ruleTemplateID, ruleRecords)
+	builder.addRecords(endpointTemplateID, endpointRecords)
+
+	return builder.finish()
+}
+
+func sortedKeys(m interface{}) []uint32 {
+	var keys []uint32
+	switch m := m.(type) {
+	case map[uint32]string:
+		for key := range m {
+			keys = append(keys, key)
+		}
+	case map[uint32]map[string]string:
+		for key := range m {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// formatExternalIDs formats external ids as sorted key=value pairs separated by comma.
+func formatExternalIDs(externalIDs map[string]string) string {
+	var pairs []string
+	for key, value := range externalIDs {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipfix
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// IPFIX message format as RFC 7011 describes.
+const (
+	ipfixVersion          = 10
+	messageHeaderLength   = 16
+	setHeaderLength       = 4
+	optionsTemplateSetID  = 3
+	maxMessageLength      = 1400
+	variableLength        = 0xffff
+	enterpriseBit         = 0x8000
+	maxShortVariableValue = 255
+
+	ruleTemplateID     = 256
+	endpointTemplateID = 257
+
+	// information elements assigned by IANA
+	ieIngressInterface   = 10
+	ieObservationPointID = 138
+)
+
+// lynx information elements under the enterprise number.
+const (
+	ieLynxFlowKey uint16 = iota + 1
+	ieLynxPolicyRuleName
+	ieLynxSecurityPolicyName
+	ieLynxRuleName
+	ieLynxRuleAction
+	ieLynxEndpointExternalIDs
+)
+
+// field is the field specifier, enterprise field is defined by lynx under the enterprise number.
+type field struct {
+	id               uint16
+	length           uint16
+	enterprise       bool
+	enterpriseNumber uint32
+}
+
+func lynxField(id uint16, enterpriseNumber uint32) field {
+	return field{id: id, length: variableLength, enterprise: true, enterpriseNumber: enterpriseNumber}
+}
+
+// template is an options template with scope fields at the beginning.
+type template struct {
+	id              uint16
+	scopeFieldCount uint16
+	fields          []field
+}
+
+func newRuleTemplate(enterpriseNumber uint32) *template {
+	return &template{
+		id:              ruleTemplateID,
+		scopeFieldCount: 1,
+		fields: []field{
+			{id: ieObservationPointID, length: 4},
+			lynxField(ieLynxFlowKey, enterpriseNumber),
+			lynxField(ieLynxPolicyRuleName, enterpriseNumber),
+			lynxField(ieLynxSecurityPolicyName, enterpriseNumber),
+			lynxField(ieLynxRuleName, enterpriseNumber),
+			lynxField(ieLynxRuleAction, enterpriseNumber),
+		},
+	}
+}
+
+func newEndpointTemplate(enterpriseNumber uint32) *template {
+	return &template{
+		id:              endpointTemplateID,
+		scopeFieldCount: 1,
+		fields: []field{
+			{id: ieIngressInterface, length: 4},
+			lynxField(ieLynxEndpointExternalIDs, enterpriseNumber),
+		},
+	}
+}
+
+func (t *template) marshal() []byte {
+	data := make([]byte, 6, 6+len(t.fields)*8)
+	binary.BigEndian.PutUint16(data[0:], t.id)
+	binary.BigEndian.PutUint16(data[2:], uint16(len(t.fields)))
+	binary.BigEndian.PutUint16(data[4:], t.scopeFieldCount)
+
+	for _, f := range t.fields {
+		specifier := make([]byte, 4, 8)
+		binary.BigEndian.PutUint16(specifier[0:], f.id)
+		binary.BigEndian.PutUint16(specifier[2:], f.length)
+		if f.enterprise {
+			binary.BigEndian.PutUint16(specifier[0:], f.id|enterpriseBit)
+			specifier = append(specifier, 0, 0, 0, 0)
+			binary.BigEndian.PutUint32(specifier[4:], f.enterpriseNumber)
+		}
+		data = append(data, specifier...)
+	}
+	return data
+}
+
+// record is a data record, contains encoded values of the template fields.
+type record [][]byte
+
+func uint32Value(value uint32) []byte {
+	data := make([]byte, 4)
+	binary.BigEndian.PutUint32(data, value)
+	return data
+}
+
+// stringValue encodes the string as variable length value.
+func stringValue(value string) []byte {
+	if len(value) < maxShortVariableValue {
+		return append([]byte{byte(len(value))}, value...)
+	}
+	if len(value) > 0xffff {
+		value = value[:0xffff]
+	}
+	data := []byte{maxShortVariableValue, 0, 0}
+	binary.BigEndian.PutUint16(data[1:], uint16(len(value)))
+	return append(data, value...)
+}
+
+func (r record) length() int {
+	var length int
+	for _, value := range r {
+		length += len(value)
+	}
+	return length
+}
+
+// messageBuilder splits records into messages not longer than maxMessageLength, each message
+// starts with the templates, so that collector could decode every message.
+type messageBuilder struct {
+	obsDomainID uint32
+	exportTime  time.Time
+	sequence    *uint32
+	templateSet []byte
+
+	messages [][]byte
+	current  []byte
+	// currentSet is the offset of the data set header in the current message, -1 if no data set opened
+	currentSet      int
+	currentTemplate uint16
+	currentRecords  uint32
+}
+
+func newMessageBuilder(obsDomainID uint32, exportTime time.Time, sequence *uint32, templates []*template) *messageBuilder {
+	templateSet := make([]byte, setHeaderLength)
+	for _, t := range templates {
+		templateSet = append(templateSet, t.marshal()...)
+	}
+	binary.BigEndian.PutUint16(templateSet[0:], optionsTemplateSetID)
+	binary.BigEndian.PutUint16(templateSet[2:], uint16(len(templateSet)))
+
+	return &messageBuilder{
+		obsDomainID: obsDomainID,
+		exportTime:  exportTime,
+		sequence:    sequence,
+		templateSet: templateSet,
+		currentSet:  -1,
+	}
+}
+
+func (b *messageBuilder) addRecords(templateID uint16, records []record) {
+	for _, r := range records {
+		if b.current == nil || len(b.current)+setHeaderLength+r.length() > maxMessageLength {
+			b.flush()
+			b.current = make([]byte, messageHeaderLength, maxMessageLength)
+			b.current = append(b.current, b.templateSet...)
+		}
+		if b.currentSet < 0 || b.currentTemplate != templateID {
+			b.closeSet()
+			b.currentSet, b.currentTemplate = len(b.current), templateID
+			b.current = append(b.current, make([]byte, setHeaderLength)...)
+		}
+		for _, value := range r {
+			b.current = append(b.current, value...)
+		}
+		b.currentRecords++
+	}
+}
+
+func (b *messageBuilder) closeSet() {
+	if b.currentSet < 0 {
+		return
+	}
+	binary.BigEndian.PutUint16(b.current[b.currentSet:], b.currentTemplate)
+	binary.BigEndian.PutUint16(b.current[b.currentSet+2:], uint16(len(b.current)-b.currentSet))
+	b.currentSet = -1
+}
+
+func (b *messageBuilder) flush() {
+	if b.current == nil {
+		return
+	}
+	b.closeSet()
+
+	binary.BigEndian.PutUint16(b.current[0:], ipfixVersion)
+	binary.BigEndian.PutUint16(b.current[2:], uint16(len(b.current)))
+	binary.BigEndian.PutUint32(b.current[4:], uint32(b.exportTime.Unix()))
+	// sequence number is the count of data records sent before this message
+	binary.BigEndian.PutUint32(b.current[8:], *b.sequence)
+	binary.BigEndian.PutUint32(b.current[12:], b.obsDomainID)
+
+	*b.sequence += b.currentRecords
+	b.messages = append(b.messages, b.current)
+	b.current, b.currentRecords = nil, 0
+}
+
+// finish returns all messages built, message with templates only would be returned if no records.
+func (b *messageBuilder) finish() [][]byte {
+	if b.current == nil && len(b.messages) == 0 {
+		b.current = make([]byte, messageHeaderLength, maxMessageLength)
+		b.current = append(b.current, b.templateSet...)
+	}
+	b.flush()
+	return b.messages
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipfix
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/smartxworks/lynx/pkg/apis/policyrule/v1alpha1"
+	lynxctrl "github.com/smartxworks/lynx/pkg/controller"
+)
+
+func TestBuildMessages(t *testing.T) {
+	var newExporter = func(ruleCount int) *Exporter {
+		sampledRules := make(map[uint32]string)
+		for i := 1; i <= ruleCount; i++ {
+			sampledRules[uint32(i)] = fmt.Sprintf("flowkey-%d", i)
+		}
+		return NewExporter("127.0.0.1:4739", 1, 12345, time.Minute,
+			func() map[uint32]string { return sampledRules },
+			func(ruleID string) []v1alpha1.PolicyRule {
+				return []v1alpha1.PolicyRule{{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "policy1-ingress-" + ruleID,
+						Labels:      map[string]string{lynxctrl.OwnerPolicyLabel: "policy1"},
+						Annotations: map[string]string{lynxctrl.OwnerRuleAnnotation: "ingress.rule1"},
+					},
+					Spec: v1alpha1.PolicyRuleSpec{Action: v1alpha1.RuleActionAllow},
+				}}
+			},
+			func() map[uint32]map[string]string {
+				return map[uint32]map[string]string{5: {"iface-id": "ep1"}}
+			},
+		)
+	}
+
+	tests := []struct {
+		name           string
+		ruleCount      int
+		expectMessages int
+		expectRecords  int
+	}{
+		{
+			name:           "only templates without sampled rules",
+			ruleCount:      0,
+			expectMessages: 1,
+			expectRecords:  1,
+		},
+		{
+			name:           "records in one message",
+			ruleCount:      3,
+			expectMessages: 1,
+			expectRecords:  4,
+		},
+		{
+			name:           "records split into messages",
+			ruleCount:      100,
+			expectMessages: 6,
+			expectRecords:  101,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := newExporter(tt.ruleCount)
+			messages := exporter.buildMessages(time.Unix(1600000000, 0))
+			if len(messages) != tt.expectMessages {
+				t.Fatalf("expect %d messages, got %d", tt.expectMessages, len(messages))
+			}
+
+			var records, sequence uint32
+			for _, message := range messages {
+				if len(message) > maxMessageLength {
+					t.Errorf("message length %d exceeds %d", len(message), maxMessageLength)
+				}
+				if binary.BigEndian.Uint16(message[0:]) != ipfixVersion || int(binary.BigEndian.Uint16(message[2:])) != len(message) {
+					t.Fatalf("unexpect message header %v", message[:messageHeaderLength])
+				}
+				if binary.BigEndian.Uint32(message[8:]) != sequence {
+					t.Errorf("expect sequence %d, got %d", sequence, binary.BigEndian.Uint32(message[8:]))
+				}
+				count := countRecords(t, message)
+				records += count
+				sequence += count
+			}
+			if int(records) != tt.expectRecords {
+				t.Errorf("expect %d records, got %d", tt.expectRecords, records)
+			}
+			if exporter.sequence != sequence {
+				t.Errorf("expect exporter sequence %d, got %d", sequence, exporter.sequence)
+			}
+		})
+	}
+}
+
+// countRecords decodes the message with lynx templates, returns number of data records.
+func countRecords(t *testing.T, message []byte) uint32 {
+	var count uint32
+	var offset = messageHeaderLength
+
+	for offset < len(message) {
+		setID := binary.BigEndian.Uint16(message[offset:])
+		setEnd := offset + int(binary.BigEndian.Uint16(message[offset+2:]))
+		data := message[offset+setHeaderLength : setEnd]
+
+		switch setID {
+		case optionsTemplateSetID:
+			if binary.BigEndian.Uint16(data[0:]) != ruleTemplateID {
+				t.Errorf("expect first template %d, got %d", ruleTemplateID, binary.BigEndian.Uint16(data[0:]))
+			}
+		case ruleTemplateID, endpointTemplateID:
+			variableFields := 5
+			if setID == endpointTemplateID {
+				variableFields = 1
+			}
+			for len(data) > 0 {
+				data = data[4:]
+				for i := 0; i < variableFields; i++ {
+					value := string(data[1 : 1+data[0]])
+					if setID == endpointTemplateID && !strings.Contains(value, "iface-id=ep1") {
+						t.Errorf("unexpect endpoint external ids %s", value)
+					}
+					data = data[1+data[0]:]
+				}
+				count++
+			}
+		default:
+			t.Fatalf("unexpect set id %d", setID)
+		}
+		offset = setEnd
+	}
+
+	return count
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ovsctl
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultFlowPriority is the priority openflow used when priority not specified,
+	// ovs-ofctl would omit the priority field in this case.
+	defaultFlowPriority = 32768
+)
+
+// Flow is an openflow entry parsed from ovs-ofctl dump-flows output.
+type Flow struct {
+	Cookie   uint64
+	Table    int
+	Priority int
+	// Match contains all match fields of the flow, field without value
+	// (e.g. protocol keyword "tcp") has empty value.
+	Match   map[string]string
+	Packets int64
+	Bytes   int64
+	Actions string
+}
+
+// DumpFlows returns flows in the given table of the bridge.
+func DumpFlows(bridgeName string, table int) ([]*Flow, error) {
+	out, err := exec.Command("ovs-ofctl", "-O", "OpenFlow13", "dump-flows", bridgeName, fmt.Sprintf("table=%d", table)).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("unable dump flows of bridge %s table %d: %s: %s", bridgeName, table, err, strings.TrimSpace(string(out)))
+	}
+	return ParseFlows(string(out))
+}
+
+// ParseFlows parses ovs-ofctl dump-flows output, the reply header and empty lines are ignored.
+func ParseFlows(output string) ([]*Flow, error) {
+	var flows []*Flow
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "cookie=") {
+			continue
+		}
+		flow, err := ParseFlow(line)
+		if err != nil {
+			return nil, err
+		}
+		flows = append(flows, flow)
+	}
+
+	return flows, nil
+}
+
+// ParseFlow parses one line of ovs-ofctl dump-flows output, the line format like:
+// cookie=0x0, duration=1.5s, table=10, n_packets=2, n_bytes=196, priority=110,tcp,nw_src=10.0.0.1,tp_dst=80 actions=goto_table:45
+func ParseFlow(line string) (*Flow, error) {
+	var flow = &Flow{
+		Priority: defaultFlowPriority,
+		Match:    make(map[string]string),
+	}
+
+	actionsIndex := strings.Index(line, " actions=")
+	if actionsIndex == -1 {
+		return nil, fmt.Errorf("flow %s without actions", line)
+	}
+	flow.Actions = strings.TrimPrefix(line[actionsIndex:], " actions=")
+
+	fields := strings.Split(strings.TrimRight(line[:actionsIndex], ", "), ", ")
+	for _, field := range fields {
+		key, value := splitKeyValue(field)
+		var err error
+
+		switch key {
+		case "cookie":
+			flow.Cookie, err = strconv.ParseUint(strings.TrimPrefix(value, "0x"), 16, 64)
+		case "table":
+			flow.Table, err = strconv.Atoi(value)
+		case "n_packets":
+			flow.Packets, err = strconv.ParseInt(value, 10, 64)
+		case "n_bytes":
+			flow.Bytes, err = strconv.ParseInt(value, 10, 64)
+		case "duration", "idle_age", "hard_age", "idle_timeout", "hard_timeout", "importance":
+			// ignore flow metadata we don't care
+		default:
+			err = parseFlowMatch(flow, field)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("unable parse field %s of flow %s: %s", field, line, err)
+		}
+	}
+
+	return flow, nil
+}
+
+// parseFlowMatch parses priority and match fields like "priority=110,tcp,tp_dst=80", the
+// field may be prefixed with flow flags (e.g. "reset_counts priority=110,tcp").
+func parseFlowMatch(flow *Flow, field string) error {
+	words := strings.Fields(field)
+	if len(words) == 0 {
+		return nil
+	}
+
+	for _, match := range strings.Split(words[len(words)-1], ",") {
+		key, value := splitKeyValue(match)
+		switch key {
+		case "":
+		case "priority":
+			priority, err := strconv.Atoi(value)
+			if err != nil {
+				return err
+			}
+			flow.Priority = priority
+		default:
+			flow.Match[key] = value
+		}
+	}
+
+	return nil
+}
+
+func splitKeyValue(field string) (string, string) {
+	kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+	if len(kv) == 1 {
+		return kv[0], ""
+	}
+	return kv[0], kv[1]
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ovsctl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFlow(t *testing.T) {
+	testCases := map[string]struct {
+		line string
+
+		expectError bool
+		expectFlow  *Flow
+	}{
+		"should parse flow with match and statistics": {
+			line: "cookie=0x1000000000003, duration=12.345s, table=10, n_packets=5, n_bytes=490, priority=110,tcp,nw_src=10.10.10.1,nw_dst=10.10.20.0/24,tp_src=80,tp_dst=443 actions=goto_table:45",
+			expectFlow: &Flow{
+				Cookie:   0x1000000000003,
+				Table:    10,
+				Priority: 110,
+				Match:    map[string]string{"tcp": "", "nw_src": "10.10.10.1", "nw_dst": "10.10.20.0/24", "tp_src": "80", "tp_dst": "443"},
+				Packets:  5,
+				Bytes:    490,
+				Actions:  "goto_table:45",
+			},
+		},
+		"should parse flow with flags and age": {
+			line: "cookie=0x0, duration=1.001s, table=30, n_packets=0, n_bytes=0, idle_age=1, reset_counts priority=10,ip actions=drop",
+			expectFlow: &Flow{
+				Table:    30,
+				Priority: 10,
+				Match:    map[string]string{"ip": ""},
+				Actions:  "drop",
+			},
+		},
+		"should parse flow without priority and match": {
+			line: "cookie=0x0, duration=1.001s, table=0, n_packets=1, n_bytes=60, actions=NORMAL",
+			expectFlow: &Flow{
+				Priority: defaultFlowPriority,
+				Match:    map[string]string{},
+				Packets:  1,
+				Bytes:    60,
+				Actions:  "NORMAL",
+			},
+		},
+		"should not parse flow without actions": {
+			line:        "cookie=0x0, duration=1.001s, table=0, n_packets=1, n_bytes=60",
+			expectError: true,
+		},
+		"should not parse flow with wrong statistics": {
+			line:        "cookie=0x0, duration=1.001s, table=0, n_packets=x, n_bytes=60, priority=10 actions=drop",
+			expectError: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		flow, err := ParseFlow(tc.line)
+		if tc.expectError && err == nil {
+			t.Errorf("test %s expect error but not", name)
+		}
+		if !tc.expectError && err != nil {
+			t.Errorf("test %s unexpect error: %s", name, err)
+		}
+		if !tc.expectError && !reflect.DeepEqual(flow, tc.expectFlow) {
+			t.Errorf("test %s expect flow %+v, got %+v", name, tc.expectFlow, flow)
+		}
+	}
+}
+
+func TestParseFlows(t *testing.T) {
+	output := `OFPST_FLOW reply (OF1.3) (xid=0x2):
+ cookie=0x0, duration=2.1s, table=10, n_packets=0, n_bytes=0, priority=110,udp,tp_dst=53 actions=goto_table:45
+ cookie=0x0, duration=2.1s, table=10, n_packets=3, n_bytes=180, priority=1 actions=goto_table:45
+`
+	flows, err := ParseFlows(output)
+	if err != nil {
+		t.Fatalf("unexpect error: %s", err)
+	}
+	if len(flows) != 2 {
+		t.Fatalf("expect 2 flows, got %d", len(flows))
+	}
+	if flows[1].Priority != 1 || flows[1].Packets != 3 {
+		t.Errorf("unexpect flow %+v", flows[1])
+	}
+}
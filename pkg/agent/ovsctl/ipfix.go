@@ -0,0 +1,59 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ovsctl
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// SetFlowSampleCollector creates or replaces the Flow_Sample_Collector_Set with the id on the bridge,
+// which exports packets sampled by sample actions to the IPFIX collector target (ip:port).
+func SetFlowSampleCollector(bridgeName string, collectorSetID uint32, target string, obsDomainID uint32) error {
+	if err := DeleteFlowSampleCollector(collectorSetID); err != nil {
+		return err
+	}
+
+	args := []string{
+		"--", "--id=@br", "get", "Bridge", bridgeName,
+		"--", "--id=@ipfix", "create", "IPFIX", fmt.Sprintf("targets=%q", target), fmt.Sprintf("obs_domain_id=%d", obsDomainID),
+		"--", "create", "Flow_Sample_Collector_Set", fmt.Sprintf("id=%d", collectorSetID), "bridge=@br", "ipfix=@ipfix",
+	}
+	out, err := exec.Command("ovs-vsctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("unable create flow sample collector set %d: %s: %s", collectorSetID, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// DeleteFlowSampleCollector deletes the Flow_Sample_Collector_Set with the id, the referenced
+// IPFIX would be garbage collected by ovsdb.
+func DeleteFlowSampleCollector(collectorSetID uint32) error {
+	out, err := exec.Command("ovs-vsctl", "--columns=_uuid", "--bare", "find", "Flow_Sample_Collector_Set", fmt.Sprintf("id=%d", collectorSetID)).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("unable find flow sample collector set %d: %s: %s", collectorSetID, err, strings.TrimSpace(string(out)))
+	}
+
+	for _, uuid := range strings.Fields(string(out)) {
+		out, err = exec.Command("ovs-vsctl", "destroy", "Flow_Sample_Collector_Set", uuid).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("unable delete flow sample collector set %s: %s: %s", uuid, err, strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ovsctl
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TraceStep is a flow the packet matched in ofproto/trace output.
+type TraceStep struct {
+	Table int
+	// Flow is the match and priority of the flow, "No match." if no flow matched in the table.
+	Flow    string
+	Cookie  uint64
+	Actions string
+}
+
+// Trace is the parsed ofproto/trace output.
+type Trace struct {
+	Steps []TraceStep
+	// DatapathActions is what finally happens to the packet, e.g. drop, output port.
+	DatapathActions string
+}
+
+var traceStepRegexp = regexp.MustCompile(`^\s*(\d+)\. (.*)$`)
+
+// TracePacket traces the packet (hex string) comes from the inPort through the bridge flow tables.
+func TracePacket(bridgeName string, inPort uint32, packet string) (*Trace, error) {
+	out, err := exec.Command("ovs-appctl", "ofproto/trace", bridgeName, fmt.Sprintf("in_port=%d", inPort), packet).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("unable trace packet on bridge %s: %s: %s", bridgeName, err, strings.TrimSpace(string(out)))
+	}
+	return ParseTrace(string(out))
+}
+
+// ParseTrace parses ofproto/trace output, each step like:
+//  30. tcp,nw_dst=10.0.0.4,tp_dst=22, priority 80, cookie 0x1010000000003
+//      controller(id=19544)
+func ParseTrace(output string) (*Trace, error) {
+	var trace = &Trace{}
+	var current *TraceStep
+
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "Datapath actions:") {
+			trace.DatapathActions = strings.TrimSpace(strings.TrimPrefix(line, "Datapath actions:"))
+			break
+		}
+
+		if match := traceStepRegexp.FindStringSubmatch(line); match != nil {
+			table, _ := strconv.Atoi(match[1])
+			step := TraceStep{Table: table, Flow: match[2]}
+			if index := strings.LastIndex(step.Flow, ", cookie "); index != -1 {
+				cookie, err := strconv.ParseUint(strings.TrimPrefix(step.Flow[index+len(", cookie "):], "0x"), 16, 64)
+				if err != nil {
+					return nil, fmt.Errorf("unable parse cookie of trace step %s: %s", line, err)
+				}
+				step.Flow, step.Cookie = step.Flow[:index], cookie
+			}
+			trace.Steps = append(trace.Steps, step)
+			current = &trace.Steps[len(trace.Steps)-1]
+			continue
+		}
+
+		line = strings.TrimSpace(line)
+		if current == nil || line == "" || strings.HasPrefix(line, "-") || strings.HasPrefix(line, "bridge(") ||
+			strings.HasPrefix(line, "Final flow:") || strings.HasPrefix(line, "Megaflow:") {
+			// separators and summary lines between steps and datapath actions
+			current = nil
+			continue
+		}
+		if strings.HasPrefix(line, ">>") || strings.HasPrefix(line, "->") {
+			// notes of ofproto/trace, e.g. ">> Resubmitted packet"
+			continue
+		}
+		if current.Actions != "" {
+			current.Actions += ","
+		}
+		current.Actions += line
+	}
+
+	if trace.DatapathActions == "" {
+		return nil, fmt.Errorf("datapath actions not found in trace output")
+	}
+	return trace, nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ovsctl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTrace(t *testing.T) {
+	testCases := map[string]struct {
+		output string
+
+		expectError bool
+		expectTrace *Trace
+	}{
+		"should parse trace of dropped packet": {
+			output: `Flow: tcp,in_port=5,vlan_tci=0x0000,dl_src=00:00:00:00:00:01,dl_dst=00:00:00:00:00:02,nw_src=10.0.0.1,nw_dst=10.0.0.4,nw_tos=252,nw_ecn=0,nw_ttl=64,tp_src=34567,tp_dst=22,tcp_flags=syn
+
+bridge("vlanLearnBridge")
+-------------------------
+ 0. in_port=5, priority 100, cookie 0x1000000000001
+    goto_table:10
+10. priority 0, cookie 0x1000000000002
+    goto_table:30
+30. tcp,nw_dst=10.0.0.4,tp_dst=22, priority 80, cookie 0x1010000000003
+    controller(id=19544)
+
+Final flow: unchanged
+Megaflow: recirc_id=0,eth,tcp,in_port=5,nw_dst=10.0.0.4,nw_frag=no,tp_dst=22
+Datapath actions: userspace(pid=0,controller(reason=1,dont_send=0,continuation=0,recirc_id=1,rule_cookie=0x1010000000003,controller_id=19544,max_len=65535))
+`,
+			expectTrace: &Trace{
+				Steps: []TraceStep{
+					{Table: 0, Flow: "in_port=5, priority 100", Cookie: 0x1000000000001, Actions: "goto_table:10"},
+					{Table: 10, Flow: "priority 0", Cookie: 0x1000000000002, Actions: "goto_table:30"},
+					{Table: 30, Flow: "tcp,nw_dst=10.0.0.4,tp_dst=22, priority 80", Cookie: 0x1010000000003, Actions: "controller(id=19544)"},
+				},
+				DatapathActions: "userspace(pid=0,controller(reason=1,dont_send=0,continuation=0,recirc_id=1,rule_cookie=0x1010000000003,controller_id=19544,max_len=65535))",
+			},
+		},
+		"should parse trace with no match table": {
+			output: `bridge("br0")
+-------------
+ 0. No match.
+    drop
+
+Final flow: unchanged
+Datapath actions: drop
+`,
+			expectTrace: &Trace{
+				Steps:           []TraceStep{{Table: 0, Flow: "No match.", Actions: "drop"}},
+				DatapathActions: "drop",
+			},
+		},
+		"should error without datapath actions": {
+			output:      "ovs-vswitchd: no bridge named br0",
+			expectError: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			trace, err := ParseTrace(tc.output)
+			if tc.expectError != (err != nil) {
+				t.Fatalf("expect error %t, got error %v", tc.expectError, err)
+			}
+			if !tc.expectError && !reflect.DeepEqual(trace, tc.expectTrace) {
+				t.Errorf("expect trace %+v, got %+v", tc.expectTrace, trace)
+			}
+		})
+	}
+}
@@ -17,8 +17,10 @@ limitations under the License.
 package v1alpha1
 
 import (
-	"github.com/smartxworks/lynx/pkg/types"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	"github.com/smartxworks/lynx/pkg/types"
 )
 
 // +genclient
@@ -42,7 +44,8 @@ type GroupMembers struct {
 type GroupMember struct {
 	// EndpointReference maintains the reference to the Endpoint.
 	EndpointReference EndpointReference `json:"endpointReference"`
-	IPs               []types.IPAddress `json:"ips,omitempty"`
+	// IPs of the Endpoint, for members from IPBlocks of the group, it's the CIDR of the IPBlock.
+	IPs []types.IPAddress `json:"ips,omitempty"`
 }
 
 type EndpointReference struct {
@@ -101,6 +104,12 @@ type GroupMembersPatchList struct {
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Cluster
 
+// EndpointGroup defines a set of Endpoints, selected by labels or listed explicitly.
+//
+// An EndpointGroup labeled namespace.label.lynx.smartx.com belongs to the tenant namespace, it only
+// selects Endpoints of the namespace, and tenants could only write EndpointGroups of their namespaces.
+// EndpointGroup is still cluster scoped rather than namespaced: names are shared by all tenants, and RBAC
+// on EndpointGroups is cluster wide, so reading is not isolated between tenants.
 type EndpointGroup struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -116,6 +125,14 @@ type EndpointGroupSpec struct {
 	// Selector specifies a selector for Endpoint. An empty label selector
 	// matches all endpoints. A null label selector matches no endpoints.
 	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// EndpointGroups are names of other groups, members of the groups are
+	// also members of this group. Cyclic references are not allowed.
+	EndpointGroups []string `json:"endpointGroups,omitempty"`
+	// Endpoints are names of the endpoints in this group.
+	Endpoints []string `json:"endpoints,omitempty"`
+	// IPBlocks are static CIDRs in this group, e.g. subnets of external
+	// appliances not managed as endpoints.
+	IPBlocks []securityv1alpha1.IPBlock `json:"ipBlocks,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
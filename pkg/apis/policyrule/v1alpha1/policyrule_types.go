@@ -56,15 +56,20 @@ type PolicyRuleSpec struct {
 	IpProtocol        string        `json:"ipProtocol"`
 	SrcPort           uint16        `json:"srcPort,omitempty"`
 	DstPort           uint16        `json:"dstPort,omitempty"`
+	IcmpType          *int32        `json:"icmpType,omitempty"`
+	IcmpCode          *int32        `json:"icmpCode,omitempty"`
 	TcpFlags          string        `json:"tcpFlags"`
 	Action            RuleAction    `json:"action"`
+	MonitorOnly       bool          `json:"monitorOnly,omitempty"`
+	Logging           bool          `json:"logging,omitempty"`
 }
 
 type RuleAction string
 
 const (
-	RuleActionAllow RuleAction = "Allow"
-	RuleActionDrop  RuleAction = "Drop"
+	RuleActionAllow  RuleAction = "Allow"
+	RuleActionDrop   RuleAction = "Drop"
+	RuleActionReject RuleAction = "Reject"
 )
 
 type RuleDirection string
@@ -83,6 +88,28 @@ const (
 
 // PolicyRuleStatus defines the observed state of PolicyRule
 type PolicyRuleStatus struct {
-	EnforceState    PolicyRuleEnforceState `json:"enforceState"`
-	MatchStatistics int64                  `json:"matchStatistics"`
+	// EnforceState is the aggregated enforce state of all agents, it would be
+	// FailedEnforced if any agent failed to enforce this rule.
+	EnforceState PolicyRuleEnforceState `json:"enforceState"`
+	// MatchStatistics is the total number of packets matched this rule on all agents.
+	MatchStatistics int64 `json:"matchStatistics"`
+	// AgentStatuses is the enforce state and flow statistics reported by each agent.
+	// Every agent enforced the rule writes into the same PolicyRule status: once when the enforce
+	// state changes, and at most once per 5 minutes (with jitter) when matched packets changed by
+	// more than 10%. So the write rate of a PolicyRule is about agents/5min, and conflicts between
+	// agents are retried with the latest PolicyRule.
+	AgentStatuses []AgentPolicyRuleStatus `json:"agentStatuses,omitempty"`
+}
+
+// AgentPolicyRuleStatus is the observed state of PolicyRule on an agent.
+type AgentPolicyRuleStatus struct {
+	// AgentName is the name of the agent which report this status.
+	AgentName    string                 `json:"agentName"`
+	EnforceState PolicyRuleEnforceState `json:"enforceState"`
+	// Reason is a brief message indicating why enforce failed.
+	Reason string `json:"reason,omitempty"`
+	// MatchPackets is the number of packets matched the rule flow on the agent.
+	MatchPackets int64 `json:"matchPackets"`
+	// MatchBytes is the number of bytes matched the rule flow on the agent.
+	MatchBytes int64 `json:"matchBytes"`
 }
@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// SetAgentStatus updates status of the agent with updateFunc, adds the agent status if not exists,
+// and recalculates the aggregated status. Returns true if status changed.
+func (in *PolicyRuleStatus) SetAgentStatus(agentName string, updateFunc func(status *AgentPolicyRuleStatus)) bool {
+	var agentStatus = AgentPolicyRuleStatus{AgentName: agentName}
+	var index = -1
+
+	for i := range in.AgentStatuses {
+		if in.AgentStatuses[i].AgentName == agentName {
+			agentStatus = in.AgentStatuses[i]
+			index = i
+			break
+		}
+	}
+
+	var oldAgentStatus = agentStatus
+	updateFunc(&agentStatus)
+	if index != -1 && oldAgentStatus == agentStatus {
+		return false
+	}
+
+	if index == -1 {
+		in.AgentStatuses = append(in.AgentStatuses, agentStatus)
+	} else {
+		in.AgentStatuses[index] = agentStatus
+	}
+
+	in.aggregateAgentStatuses()
+	return true
+}
+
+// RemoveAgentStatuses removes statuses of agents which removeFunc returns true, and recalculates the
+// aggregated status. Returns true if any status removed.
+func (in *PolicyRuleStatus) RemoveAgentStatuses(removeFunc func(agentName string) bool) bool {
+	var agentStatuses []AgentPolicyRuleStatus
+	for _, agentStatus := range in.AgentStatuses {
+		if !removeFunc(agentStatus.AgentName) {
+			agentStatuses = append(agentStatuses, agentStatus)
+		}
+	}
+	if len(agentStatuses) == len(in.AgentStatuses) {
+		return false
+	}
+
+	in.AgentStatuses = agentStatuses
+	in.aggregateAgentStatuses()
+	return true
+}
+
+// aggregateAgentStatuses recalculates the aggregated status from all agents.
+func (in *PolicyRuleStatus) aggregateAgentStatuses() {
+	in.EnforceState = PolicyRuleSuccessEnforced
+	in.MatchStatistics = 0
+	for _, item := range in.AgentStatuses {
+		if item.EnforceState == PolicyRuleFailedEnforced {
+			in.EnforceState = PolicyRuleFailedEnforced
+		}
+		in.MatchStatistics += item.MatchPackets
+	}
+}
@@ -24,13 +24,29 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentPolicyRuleStatus) DeepCopyInto(out *AgentPolicyRuleStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentPolicyRuleStatus.
+func (in *AgentPolicyRuleStatus) DeepCopy() *AgentPolicyRuleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentPolicyRuleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PolicyRule) DeepCopyInto(out *PolicyRule) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -88,6 +104,16 @@ func (in *PolicyRuleList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PolicyRuleSpec) DeepCopyInto(out *PolicyRuleSpec) {
 	*out = *in
+	if in.IcmpType != nil {
+		in, out := &in.IcmpType, &out.IcmpType
+		*out = new(int32)
+		**out = **in
+	}
+	if in.IcmpCode != nil {
+		in, out := &in.IcmpCode, &out.IcmpCode
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
@@ -104,6 +130,11 @@ func (in *PolicyRuleSpec) DeepCopy() *PolicyRuleSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PolicyRuleStatus) DeepCopyInto(out *PolicyRuleStatus) {
 	*out = *in
+	if in.AgentStatuses != nil {
+		in, out := &in.AgentStatuses, &out.AgentStatuses
+		*out = make([]AgentPolicyRuleStatus, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
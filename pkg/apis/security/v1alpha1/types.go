@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	"github.com/smartxworks/lynx/pkg/types"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -29,6 +30,12 @@ import (
 // +kubebuilder:resource:scope=Cluster
 // +kubebuilder:subresource:status
 
+// SecurityPolicy is a set of rules applied to the Endpoints selected by AppliedTo.
+//
+// A SecurityPolicy labeled namespace.label.lynx.smartx.com belongs to the tenant namespace, it only
+// selects Endpoints and uses EndpointGroups of the namespace, and tenants could only write SecurityPolicies
+// of their namespaces. SecurityPolicy is still cluster scoped rather than namespaced: names are shared by
+// all tenants, and RBAC on SecurityPolicies is cluster wide, so reading is not isolated between tenants.
 type SecurityPolicy struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -45,6 +52,16 @@ type SecurityPolicySpec struct {
 	// Defaults to false.
 	SymmetricMode bool `json:"symmetricMode,omitempty"`
 
+	// EnforcementMode of the policy, Enforce or Monitor. Rules of policy in Monitor mode
+	// only count the traffic they match, but never affect the traffic. Defaults to Enforce.
+	EnforcementMode EnforcementMode `json:"enforcementMode,omitempty"`
+
+	// PolicyTypes are the directions of traffic the policy limits, Ingress and/or Egress. Traffic
+	// in the direction not listed is not affected by the policy: the default drop rule of the
+	// direction is not installed, and rules of the direction are not allowed. Defaults to both
+	// directions.
+	PolicyTypes []PolicyType `json:"policyTypes,omitempty"`
+
 	// Object to be applied to list of ingress rule and egress rule
 	AppliedTo AppliedTo `json:"appliedTo"`
 
@@ -57,12 +74,38 @@ type SecurityPolicySpec struct {
 	EgressRules []Rule `json:"egressRules,omitempty"`
 }
 
+// +kubebuilder:validation:Enum=Enforce;Monitor
+// EnforcementMode describes how rules of the policy are applied on traffic.
+type EnforcementMode string
+
+const (
+	// EnforcementModeEnforce applies the rule actions on matching traffic.
+	EnforcementModeEnforce EnforcementMode = "Enforce"
+	// EnforcementModeMonitor only counts matching traffic, the traffic would be handled
+	// as if the policy does not exist.
+	EnforcementModeMonitor EnforcementMode = "Monitor"
+)
+
+// +kubebuilder:validation:Enum=Ingress;Egress
+// PolicyType is a direction of traffic the policy limits.
+type PolicyType string
+
+const (
+	// PolicyTypeIngress limits the traffic to the endpoints the policy applied to.
+	PolicyTypeIngress PolicyType = "Ingress"
+	// PolicyTypeEgress limits the traffic from the endpoints the policy applied to.
+	PolicyTypeEgress PolicyType = "Egress"
+)
+
 type AppliedTo struct {
 	// List of groups which SecurityPolicy applied to. Each item in this list is
 	// combined using a logical OR. This field must not empty.
 	EndpointGroups []string `json:"endpointGroups,omitempty"`
 	// Endpoint which SecurityPolicy applied to
 	Endpoints []string `json:"endpoints,omitempty"`
+	// EndpointSelector selects endpoints which SecurityPolicy applied to, the
+	// selected endpoints are combined with groups and endpoints using a logical OR.
+	EndpointSelector *metav1.LabelSelector `json:"endpointSelector,omitempty"`
 }
 
 // SecurityPolicyPhase defines the phase in which a SecurityPolicy is.
@@ -106,6 +149,15 @@ type Rule struct {
 	// If this field is empty or missing, this rule matches all destinations. This field
 	// only works when rule is egress.
 	To SecurityPolicyPeer `json:"to,omitempty"`
+
+	// Action specifies the action to be applied on traffic matching the rule, Allow,
+	// Drop or Reject. If this field is empty or missing, the traffic would be allowed.
+	// Drop rules are useful in Blacklist tier, which allows all traffic by default.
+	Action RuleAction `json:"action,omitempty"`
+
+	// Logging enables logging of traffic matching the rule. The agent would write a log
+	// record for the first packet of each connection. Defaults to false.
+	Logging bool `json:"logging,omitempty"`
 }
 
 // SecurityPolicyPeer describes the grouping selector of workloads.
@@ -113,6 +165,9 @@ type SecurityPolicyPeer struct {
 	IPBlocks       []IPBlock `json:"ipBlocks,omitempty"`
 	EndpointGroups []string  `json:"endpointGroups,omitempty"`
 	Endpoints      []string  `json:"endpoints,omitempty"`
+	// EndpointSelector selects endpoints by labels without an EndpointGroup, an
+	// empty selector selects all endpoints.
+	EndpointSelector *metav1.LabelSelector `json:"endpointSelector,omitempty"`
 }
 
 // IPBlock describes a particular CIDR.
@@ -121,11 +176,14 @@ type IPBlock struct {
 	// PrefixLength defines prefix length of ip address. If ipv4, prefixLength must be
 	// any value between 0 and 32. If ipv6 prefixLength must be any value between 0 and 128.
 	PrefixLength int32 `json:"prefixLength"`
+	// Except is a list of CIDRs that should not be included within the IPBlock, e.g.
+	// "10.20.0.0/16". Except values will be rejected if they are outside the IPBlock.
+	Except []string `json:"except,omitempty"`
 }
 
 // SecurityPolicyPort describes the port and protocol to match in a rule.
 type SecurityPolicyPort struct {
-	// The protocol (TCP, UDP or ICMP) which traffic must match.
+	// The protocol (TCP, UDP, ICMP or SCTP) which traffic must match.
 	Protocol Protocol `json:"protocol"`
 	// PortRange is a range of port. If you want match all ports, you should set empty. If you
 	// want match single port, you should write like 22. If you want match a range of port, you
@@ -133,12 +191,19 @@ type SecurityPolicyPort struct {
 	// +kubebuilder:validation:Pattern="^(\\d{1,5}-\\d{1,5})|(\\d{1,5})|()$"
 	PortRange string `json:"portRange,omitempty"` // only valid when Protocol is not ICMP
 
-	// ICMP type and code is not support in alpha1.
-	// ICMPType  *int32 `json:"icmpType,omitempty"`  // only valid when Protocol is ICMP
-	// ICMPCode  *int32 `json:"icmpCode,omitempty"`  // only valid when Protocol is ICMP
+	// ICMPType is the type of ICMP message to match, e.g. 8 for echo-request. If this field
+	// is empty or missing, all ICMP messages matches.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=255
+	ICMPType *int32 `json:"icmpType,omitempty"` // only valid when Protocol is ICMP
+	// ICMPCode is the code of ICMP message to match, e.g. 4 for fragmentation-needed of
+	// destination-unreachable. If this field is empty or missing, all codes of ICMPType matches.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=255
+	ICMPCode *int32 `json:"icmpCode,omitempty"` // only valid when ICMPType is specified
 }
 
-// +kubebuilder:validation:Enum=TCP;UDP;ICMP
+// +kubebuilder:validation:Enum=TCP;UDP;ICMP;SCTP
 type Protocol string
 
 const (
@@ -148,13 +213,11 @@ const (
 	ProtocolUDP Protocol = "UDP"
 	// ProtocolICMP is the ICMP protocol.
 	ProtocolICMP Protocol = "ICMP"
-
-	// ProtocolSCTP is not support in alpha1.
 	// ProtocolSCTP is the SCTP protocol.
-	// ProtocolSCTP Protocol = "SCTP"
+	ProtocolSCTP Protocol = "SCTP"
 )
 
-// +kubebuilder:validation:Enum=Allow;Drop
+// +kubebuilder:validation:Enum=Allow;Drop;Reject
 // RuleAction describes the action to be applied on traffic matching a rule.
 // Default action is allow
 type RuleAction string
@@ -164,6 +227,10 @@ const (
 	RuleActionAllow RuleAction = "Allow"
 	// RuleActionDrop describes that rule matching traffic must be dropped.
 	RuleActionDrop RuleAction = "Drop"
+	// RuleActionReject describes that rule matching traffic must be rejected, tcp
+	// connections would be reset, and other traffic would be answered with icmp
+	// destination unreachable.
+	RuleActionReject RuleAction = "Reject"
 )
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -197,6 +264,10 @@ type TierSpec struct {
 
 	Priority int32    `json:"priority"`
 	TierMode TierMode `json:"tierMode"`
+
+	// DefaultRuleLogging sends records of traffic matching default drop rules of policies in the
+	// tier to the flow logger. Only Whitelist tier has default drop rules. Defaults to false.
+	DefaultRuleLogging bool `json:"defaultRuleLogging,omitempty"`
 }
 
 // +kubebuilder:validation:Enum=Whitelist;Blacklist
@@ -238,6 +309,9 @@ type EndpointSpec struct {
 	ManagePlaneID string            `json:"managePlaneID,omitempty"`
 	VID           uint32            `json:"vid"`
 	Reference     EndpointReference `json:"reference"`
+	// ExpectedIPs are static ips of the endpoint, they will add into status ips. It is used for
+	// endpoints whose ips could not learned by agents, e.g. physical appliances or bare-metal hosts.
+	ExpectedIPs []types.IPAddress `json:"expectedIPs,omitempty"`
 }
 
 type EndpointReference struct {
@@ -246,8 +320,26 @@ type EndpointReference struct {
 }
 
 type EndpointStatus struct {
-	IPs        []types.IPAddress `json:"ips,omitempty"`
-	MacAddress string            `json:"macAddress,omitempty"`
+	IPs        []types.IPAddress   `json:"ips,omitempty"`
+	MacAddress string              `json:"macAddress,omitempty"`
+	Conditions []EndpointCondition `json:"conditions,omitempty"`
+}
+
+type EndpointConditionType string
+
+const (
+	// EndpointPlaced is set on endpoints with expected host. Status True/False is used to mark
+	// whether the endpoint interface has been found on the agents of the expected host, Unknown
+	// if no agent reports on the expected host.
+	EndpointPlaced EndpointConditionType = "Placed"
+)
+
+type EndpointCondition struct {
+	Type               EndpointConditionType  `json:"type"`
+	Status             corev1.ConditionStatus `json:"status"`
+	LastTransitionTime metav1.Time            `json:"lastTransitionTime,omitempty"`
+	Reason             string                 `json:"reason,omitempty"`
+	Message            string                 `json:"message,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -258,3 +350,113 @@ type EndpointList struct {
 	metav1.ListMeta `json:"metadata,omitempty"`
 	Items           []Endpoint `json:"items"`
 }
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+
+// Traceflow injects a synthetic packet from the source endpoint into the datapath of the agent
+// hosting it, and reports the tables and policy rules the packet traverses. The packet is marked,
+// and captured by the agent before forwarded, so it never reaches the destination.
+type Traceflow struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TraceflowSpec   `json:"spec"`
+	Status TraceflowStatus `json:"status,omitempty"`
+}
+
+type TraceflowSpec struct {
+	// Source is the name of the Endpoint the packet sent from.
+	Source string `json:"source"`
+	// Destination of the packet, either an Endpoint or an IP address.
+	Destination TraceflowDestination `json:"destination"`
+	// Packet describes the transport layer of the packet.
+	Packet TraceflowPacket `json:"packet"`
+}
+
+type TraceflowDestination struct {
+	// Endpoint is the name of the destination Endpoint.
+	Endpoint string `json:"endpoint,omitempty"`
+	// IP is the destination IP address, used when Endpoint not specified. The IP must belong to an
+	// Endpoint, whose mac address would be the destination mac address of the packet.
+	IP types.IPAddress `json:"ip,omitempty"`
+}
+
+type TraceflowPacket struct {
+	Protocol Protocol `json:"protocol"`
+	// SrcPort and DstPort of TCP, UDP or SCTP packet.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	SrcPort int32 `json:"srcPort,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	DstPort int32 `json:"dstPort,omitempty"`
+	// ICMPType and ICMPCode of ICMP packet, ICMPType defaults to echo request if missing.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=255
+	ICMPType *int32 `json:"icmpType,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=255
+	ICMPCode int32 `json:"icmpCode,omitempty"`
+}
+
+type TraceflowPhase string
+
+const (
+	// TraceflowPending means the traceflow has not been assigned to an agent.
+	TraceflowPending TraceflowPhase = "Pending"
+	// TraceflowRunning means the assigned agent is tracing the packet.
+	TraceflowRunning TraceflowPhase = "Running"
+	// TraceflowSucceeded means the observations and verdict have been reported.
+	TraceflowSucceeded TraceflowPhase = "Succeeded"
+	// TraceflowFailed means the packet could not be traced, see Reason for details.
+	TraceflowFailed TraceflowPhase = "Failed"
+)
+
+type TraceflowVerdict string
+
+const (
+	TraceflowVerdictForwarded TraceflowVerdict = "Forwarded"
+	TraceflowVerdictDropped   TraceflowVerdict = "Dropped"
+	TraceflowVerdictRejected  TraceflowVerdict = "Rejected"
+)
+
+type TraceflowStatus struct {
+	Phase  TraceflowPhase `json:"phase,omitempty"`
+	Reason string         `json:"reason,omitempty"`
+	// Agent is the name of the agent hosting the source endpoint, which traces the packet.
+	Agent string `json:"agent,omitempty"`
+	// Observations are the flows the packet matched in order, traced by ofproto/trace.
+	Observations []TraceflowObservation `json:"observations,omitempty"`
+	// Verdict is what finally happened to the injected packet. Reason notes when it differs from
+	// what the observations predict.
+	Verdict TraceflowVerdict `json:"verdict,omitempty"`
+	// VerdictPolicyRule is the PolicyRule drops or rejects the packet, or the last PolicyRule
+	// allows the packet.
+	VerdictPolicyRule string `json:"verdictPolicyRule,omitempty"`
+}
+
+type TraceflowObservation struct {
+	// Table is the openflow table id of the flow.
+	Table int32 `json:"table"`
+	// Flow is the match and priority of the flow.
+	Flow string `json:"flow,omitempty"`
+	// Actions of the flow.
+	Actions string `json:"actions,omitempty"`
+	// PolicyRules installed as the flow, empty if the flow is not a policy rule.
+	PolicyRules []string `json:"policyRules,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TraceflowList contains a list of Traceflow
+type TraceflowList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Traceflow `json:"items"`
+}
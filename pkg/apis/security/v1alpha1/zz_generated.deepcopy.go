@@ -22,6 +22,7 @@ package v1alpha1
 
 import (
 	types "github.com/smartxworks/lynx/pkg/types"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -38,6 +39,11 @@ func (in *AppliedTo) DeepCopyInto(out *AppliedTo) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.EndpointSelector != nil {
+		in, out := &in.EndpointSelector, &out.EndpointSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -56,7 +62,7 @@ func (in *Endpoint) DeepCopyInto(out *Endpoint) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 	return
 }
@@ -79,6 +85,23 @@ func (in *Endpoint) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointCondition) DeepCopyInto(out *EndpointCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointCondition.
+func (in *EndpointCondition) DeepCopy() *EndpointCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EndpointList) DeepCopyInto(out *EndpointList) {
 	*out = *in
@@ -132,6 +155,11 @@ func (in *EndpointReference) DeepCopy() *EndpointReference {
 func (in *EndpointSpec) DeepCopyInto(out *EndpointSpec) {
 	*out = *in
 	out.Reference = in.Reference
+	if in.ExpectedIPs != nil {
+		in, out := &in.ExpectedIPs, &out.ExpectedIPs
+		*out = make([]types.IPAddress, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -153,6 +181,13 @@ func (in *EndpointStatus) DeepCopyInto(out *EndpointStatus) {
 		*out = make([]types.IPAddress, len(*in))
 		copy(*out, *in)
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]EndpointCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -169,6 +204,11 @@ func (in *EndpointStatus) DeepCopy() *EndpointStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IPBlock) DeepCopyInto(out *IPBlock) {
 	*out = *in
+	if in.Except != nil {
+		in, out := &in.Except, &out.Except
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -188,7 +228,9 @@ func (in *Rule) DeepCopyInto(out *Rule) {
 	if in.Ports != nil {
 		in, out := &in.Ports, &out.Ports
 		*out = make([]SecurityPolicyPort, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	in.From.DeepCopyInto(&out.From)
 	in.To.DeepCopyInto(&out.To)
@@ -272,7 +314,9 @@ func (in *SecurityPolicyPeer) DeepCopyInto(out *SecurityPolicyPeer) {
 	if in.IPBlocks != nil {
 		in, out := &in.IPBlocks, &out.IPBlocks
 		*out = make([]IPBlock, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.EndpointGroups != nil {
 		in, out := &in.EndpointGroups, &out.EndpointGroups
@@ -284,6 +328,11 @@ func (in *SecurityPolicyPeer) DeepCopyInto(out *SecurityPolicyPeer) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.EndpointSelector != nil {
+		in, out := &in.EndpointSelector, &out.EndpointSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -300,6 +349,16 @@ func (in *SecurityPolicyPeer) DeepCopy() *SecurityPolicyPeer {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecurityPolicyPort) DeepCopyInto(out *SecurityPolicyPort) {
 	*out = *in
+	if in.ICMPType != nil {
+		in, out := &in.ICMPType, &out.ICMPType
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ICMPCode != nil {
+		in, out := &in.ICMPCode, &out.ICMPCode
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
@@ -316,6 +375,11 @@ func (in *SecurityPolicyPort) DeepCopy() *SecurityPolicyPort {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecurityPolicySpec) DeepCopyInto(out *SecurityPolicySpec) {
 	*out = *in
+	if in.PolicyTypes != nil {
+		in, out := &in.PolicyTypes, &out.PolicyTypes
+		*out = make([]PolicyType, len(*in))
+		copy(*out, *in)
+	}
 	in.AppliedTo.DeepCopyInto(&out.AppliedTo)
 	if in.IngressRules != nil {
 		in, out := &in.IngressRules, &out.IngressRules
@@ -435,3 +499,163 @@ func (in *TierSpec) DeepCopy() *TierSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Traceflow) DeepCopyInto(out *Traceflow) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Traceflow.
+func (in *Traceflow) DeepCopy() *Traceflow {
+	if in == nil {
+		return nil
+	}
+	out := new(Traceflow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Traceflow) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TraceflowDestination) DeepCopyInto(out *TraceflowDestination) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TraceflowDestination.
+func (in *TraceflowDestination) DeepCopy() *TraceflowDestination {
+	if in == nil {
+		return nil
+	}
+	out := new(TraceflowDestination)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TraceflowList) DeepCopyInto(out *TraceflowList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Traceflow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TraceflowList.
+func (in *TraceflowList) DeepCopy() *TraceflowList {
+	if in == nil {
+		return nil
+	}
+	out := new(TraceflowList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TraceflowList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TraceflowObservation) DeepCopyInto(out *TraceflowObservation) {
+	*out = *in
+	if in.PolicyRules != nil {
+		in, out := &in.PolicyRules, &out.PolicyRules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TraceflowObservation.
+func (in *TraceflowObservation) DeepCopy() *TraceflowObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(TraceflowObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TraceflowPacket) DeepCopyInto(out *TraceflowPacket) {
+	*out = *in
+	if in.ICMPType != nil {
+		in, out := &in.ICMPType, &out.ICMPType
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TraceflowPacket.
+func (in *TraceflowPacket) DeepCopy() *TraceflowPacket {
+	if in == nil {
+		return nil
+	}
+	out := new(TraceflowPacket)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TraceflowSpec) DeepCopyInto(out *TraceflowSpec) {
+	*out = *in
+	out.Destination = in.Destination
+	in.Packet.DeepCopyInto(&out.Packet)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TraceflowSpec.
+func (in *TraceflowSpec) DeepCopy() *TraceflowSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TraceflowSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TraceflowStatus) DeepCopyInto(out *TraceflowStatus) {
+	*out = *in
+	if in.Observations != nil {
+		in, out := &in.Observations, &out.Observations
+		*out = make([]TraceflowObservation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TraceflowStatus.
+func (in *TraceflowStatus) DeepCopy() *TraceflowStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TraceflowStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -40,6 +40,10 @@ func (c *FakeSecurityV1alpha1) Tiers() v1alpha1.TierInterface {
 	return &FakeTiers{c}
 }
 
+func (c *FakeSecurityV1alpha1) Traceflows() v1alpha1.TraceflowInterface {
+	return &FakeTraceflows{c}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeSecurityV1alpha1) RESTClient() rest.Interface {
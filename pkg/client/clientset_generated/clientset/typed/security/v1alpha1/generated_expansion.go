@@ -23,3 +23,5 @@ type EndpointExpansion interface{}
 type SecurityPolicyExpansion interface{}
 
 type TierExpansion interface{}
+
+type TraceflowExpansion interface{}
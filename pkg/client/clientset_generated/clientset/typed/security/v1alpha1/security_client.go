@@ -29,6 +29,7 @@ type SecurityV1alpha1Interface interface {
 	EndpointsGetter
 	SecurityPoliciesGetter
 	TiersGetter
+	TraceflowsGetter
 }
 
 // SecurityV1alpha1Client is used to interact with features provided by the security.lynx.smartx.com group.
@@ -48,6 +49,10 @@ func (c *SecurityV1alpha1Client) Tiers() TierInterface {
 	return newTiers(c)
 }
 
+func (c *SecurityV1alpha1Client) Traceflows() TraceflowInterface {
+	return newTraceflows(c)
+}
+
 // NewForConfig creates a new SecurityV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*SecurityV1alpha1Client, error) {
 	config := *c
@@ -30,6 +30,8 @@ type Interface interface {
 	SecurityPolicies() SecurityPolicyInformer
 	// Tiers returns a TierInformer.
 	Tiers() TierInformer
+	// Traceflows returns a TraceflowInformer.
+	Traceflows() TraceflowInformer
 }
 
 type version struct {
@@ -57,3 +59,8 @@ func (v *version) SecurityPolicies() SecurityPolicyInformer {
 func (v *version) Tiers() TierInformer {
 	return &tierInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
+
+// Traceflows returns a TraceflowInformer.
+func (v *version) Traceflows() TraceflowInformer {
+	return &traceflowInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
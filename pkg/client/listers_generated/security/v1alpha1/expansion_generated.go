@@ -29,3 +29,7 @@ type SecurityPolicyListerExpansion interface{}
 // TierListerExpansion allows custom methods to be added to
 // TierLister.
 type TierListerExpansion interface{}
+
+// TraceflowListerExpansion allows custom methods to be added to
+// TraceflowLister.
+type TraceflowListerExpansion interface{}
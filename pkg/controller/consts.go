@@ -21,5 +21,19 @@ const (
 	NumOfRetainedGroupMembersPatches = 3
 	DependentsCleanFinalizer         = "dependentsclean.finalizer.lynx.smartx.com"
 	OwnerGroupLabel                  = "ownergroup.label.lynx.smartx.com"
+	SelectorGroupLabel               = "selectorgroup.label.lynx.smartx.com"
 	OwnerPolicyLabel                 = "ownerpolicy.label.lynx.smartx.com"
+	OwnerRuleAnnotation              = "ownerrule.annotation.lynx.smartx.com"
+
+	// NamespaceLabel is the tenant namespace of Endpoints, EndpointGroups and SecurityPolicies.
+	// EndpointGroups and SecurityPolicies with the label only select Endpoints in the namespace.
+	NamespaceLabel = "namespace.label.lynx.smartx.com"
+	// TenantTierLabel marks the Tiers tenant SecurityPolicies could use, tenant Tiers always
+	// have lower precedence than the other Tiers.
+	TenantTierLabel = "tenanttier.label.lynx.smartx.com"
+
+	// HostAnnotation is the hostname of the host an Endpoint expected on, set by the manage plane
+	// of the Endpoint. Endpoint controller compares it with the hostname of the agents reported the
+	// Endpoint interface, and sets the Placed condition of the Endpoint.
+	HostAnnotation = "host.annotation.lynx.smartx.com"
 )
@@ -19,10 +19,15 @@ package endpoint
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
@@ -49,11 +54,18 @@ type EndpointReconciler struct {
 
 	ifaceCacheLock sync.RWMutex
 	ifaceCache     cache.Indexer
+	// agentHostnames maps agent name to the hostname it reports, protected by ifaceCacheLock
+	agentHostnames map[string]string
 }
 
 const (
 	externalIDIndex = "externalIDIndex"
 	agentIndex      = "agentIndex"
+
+	// reasons of the endpoint Placed condition
+	ReasonInterfaceFound    = "InterfaceFound"
+	ReasonInterfaceNotFound = "InterfaceNotFound"
+	ReasonAgentNotFound     = "AgentNotFound"
 )
 
 // Reconcile receive endpoint from work queue, synchronize the endpoint status
@@ -77,6 +89,14 @@ func (r *EndpointReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		klog.Errorf("while fetch endpoint status: %s", err.Error())
 		return ctrl.Result{}, err
 	}
+	// Static ips of the endpoint are always in its status.
+	expectStatus.IPs = appendIPs(expectStatus.IPs, endpoint.Spec.ExpectedIPs...)
+	// Check the endpoint interface on the expected host, e.g. vm migration may fail and leave
+	// the vm nic absent from the bridge, the endpoint should be marked as not placed.
+	if condition := r.fetchEndpointPlacedCondition(endpoint); condition != nil {
+		expectStatus.Conditions = []securityv1alpha1.EndpointCondition{*condition}
+	}
+	keepTransitionTime(endpoint.Status.Conditions, expectStatus.Conditions)
 
 	// Skip if none change for this endpoint.
 	if EqualEndpointStatus(endpoint.Status, *expectStatus) {
@@ -113,6 +133,9 @@ func (r *EndpointReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			externalIDIndex: externalIDIndexFunc,
 		})
 	}
+	if r.agentHostnames == nil {
+		r.agentHostnames = make(map[string]string)
+	}
 
 	err = c.Watch(&source.Kind{Type: &agentv1alpha1.AgentInfo{}}, &handler.Funcs{
 		CreateFunc: r.addAgentInfo,
@@ -125,6 +148,7 @@ func (r *EndpointReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 	err = c.Watch(&source.Kind{Type: &securityv1alpha1.Endpoint{}}, &handler.Funcs{
 		CreateFunc: r.addEndpoint,
+		UpdateFunc: r.updateEndpoint,
 	})
 	if err != nil {
 		return err
@@ -145,6 +169,26 @@ func (r *EndpointReconciler) addEndpoint(e event.CreateEvent, q workqueue.RateLi
 	}})
 }
 
+func (r *EndpointReconciler) updateEndpoint(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	newEndpoint, newOK := e.ObjectNew.(*securityv1alpha1.Endpoint)
+	oldEndpoint, oldOK := e.ObjectOld.(*securityv1alpha1.Endpoint)
+	if !newOK || !oldOK {
+		klog.Errorf("UpdateEndpoint received with unavailable object event: %v", e)
+		return
+	}
+
+	// only expected ips in spec and expected host would change the endpoint status
+	if utils.EqualIPs(newEndpoint.Spec.ExpectedIPs, oldEndpoint.Spec.ExpectedIPs) &&
+		newEndpoint.Annotations[lynxctrl.HostAnnotation] == oldEndpoint.Annotations[lynxctrl.HostAnnotation] {
+		return
+	}
+
+	q.Add(ctrl.Request{NamespacedName: k8stypes.NamespacedName{
+		Namespace: newEndpoint.GetNamespace(),
+		Name:      newEndpoint.GetName(),
+	}})
+}
+
 func (r *EndpointReconciler) addAgentInfo(e event.CreateEvent, q workqueue.RateLimitingInterface) {
 	agentInfo, ok := e.Object.(*agentv1alpha1.AgentInfo)
 	if !ok {
@@ -158,6 +202,7 @@ func (r *EndpointReconciler) addAgentInfo(e event.CreateEvent, q workqueue.RateL
 	r.ifaceCacheLock.Lock()
 	defer r.ifaceCacheLock.Unlock()
 
+	r.agentHostnames[agentInfo.Name] = agentInfo.Hostname
 	for _, bridge := range agentInfo.OVSInfo.Bridges {
 		for _, port := range bridge.Ports {
 			for _, ovsIface := range port.Interfaces {
@@ -191,6 +236,7 @@ func (r *EndpointReconciler) updateAgentInfo(e event.UpdateEvent, q workqueue.Ra
 	for _, iface := range ifaces {
 		_ = r.ifaceCache.Delete(iface)
 	}
+	r.agentHostnames[newAgentInfo.Name] = newAgentInfo.Hostname
 	for _, bridge := range newAgentInfo.OVSInfo.Bridges {
 		for _, port := range bridge.Ports {
 			for _, ovsIface := range port.Interfaces {
@@ -226,11 +272,23 @@ func (r *EndpointReconciler) deleteAgentInfo(e event.DeleteEvent, q workqueue.Ra
 	for _, iface := range ifaces {
 		_ = r.ifaceCache.Delete(iface)
 	}
+	delete(r.agentHostnames, agentInfo.Name)
 }
 
-// If an endpoint reference matches iface externalIDs on the agentinfo, the endpoint should be returned.
+// If an endpoint reference matches iface externalIDs on the agentinfo, or the endpoint expected on the host
+// of the agentinfo, the endpoint should be returned.
 func (r *EndpointReconciler) enqueueEndpointsOnAgentLocked(epList securityv1alpha1.EndpointList, agentName string, queue workqueue.Interface) {
+	hostname, hostnameKnown := r.agentHostnames[agentName]
+
 	for _, ep := range epList.Items {
+		if expectHost, ok := ep.Annotations[lynxctrl.HostAnnotation]; ok && hostnameKnown && expectHost == hostname {
+			queue.Add(ctrl.Request{NamespacedName: k8stypes.NamespacedName{
+				Name:      ep.GetName(),
+				Namespace: ep.GetNamespace(),
+			}})
+			continue
+		}
+
 		ifaces, _ := r.ifaceCache.ByIndex(externalIDIndex, GetEndpointID(ep).String())
 		for _, cacheIface := range ifaces {
 			if cacheIface.(*iface).agentName == agentName {
@@ -271,13 +329,117 @@ func (r *EndpointReconciler) fetchEndpointStatusFromAgentInfo(id ctrltypes.Exter
 	}
 }
 
+// fetchEndpointPlacedCondition returns the Placed condition of the endpoint, or nil if the endpoint
+// has no expected host. Agents are matched with the expected host by the hostname they reported.
+func (r *EndpointReconciler) fetchEndpointPlacedCondition(endpoint securityv1alpha1.Endpoint) *securityv1alpha1.EndpointCondition {
+	expectHost, ok := endpoint.Annotations[lynxctrl.HostAnnotation]
+	if !ok || expectHost == "" {
+		return nil
+	}
+
+	r.ifaceCacheLock.RLock()
+	defer r.ifaceCacheLock.RUnlock()
+
+	hostAgents := sets.NewString()
+	for agentName, hostname := range r.agentHostnames {
+		if hostname == expectHost {
+			hostAgents.Insert(agentName)
+		}
+	}
+
+	placedAgents := sets.NewString()
+	ifaces, _ := r.ifaceCache.ByIndex(externalIDIndex, GetEndpointID(endpoint).String())
+	for _, item := range ifaces {
+		placedAgents.Insert(item.(*iface).agentName)
+	}
+
+	condition := &securityv1alpha1.EndpointCondition{
+		Type:               securityv1alpha1.EndpointPlaced,
+		LastTransitionTime: metav1.Now(),
+	}
+
+	switch {
+	case hostAgents.Len() == 0:
+		condition.Status = corev1.ConditionUnknown
+		condition.Reason = ReasonAgentNotFound
+		condition.Message = fmt.Sprintf("no agent reports on host %s", expectHost)
+	case hostAgents.HasAny(placedAgents.UnsortedList()...):
+		condition.Status = corev1.ConditionTrue
+		condition.Reason = ReasonInterfaceFound
+		condition.Message = fmt.Sprintf("interface found on agent %s", strings.Join(hostAgents.Intersection(placedAgents).List(), ","))
+	default:
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = ReasonInterfaceNotFound
+		condition.Message = fmt.Sprintf("interface not found on agent %s of host %s", strings.Join(hostAgents.List(), ","), expectHost)
+		if placedAgents.Len() != 0 {
+			condition.Message += fmt.Sprintf(", but found on agent %s", strings.Join(placedAgents.List(), ","))
+		}
+	}
+
+	return condition
+}
+
+// keepTransitionTime keeps the transition time of conditions whose status not changes.
+func keepTransitionTime(oldConditions []securityv1alpha1.EndpointCondition, newConditions []securityv1alpha1.EndpointCondition) {
+	for i := range newConditions {
+		for _, oldCondition := range oldConditions {
+			if oldCondition.Type == newConditions[i].Type && oldCondition.Status == newConditions[i].Status {
+				newConditions[i].LastTransitionTime = oldCondition.LastTransitionTime
+			}
+		}
+	}
+}
+
+// appendIPs appends ips not in the list.
+func appendIPs(list []types.IPAddress, ips ...types.IPAddress) []types.IPAddress {
+	for _, ip := range ips {
+		if !containsIP(list, ip) {
+			list = append(list, ip)
+		}
+	}
+	return list
+}
+
+func containsIP(list []types.IPAddress, ip types.IPAddress) bool {
+	for _, item := range list {
+		if item == ip {
+			return true
+		}
+	}
+	return false
+}
+
 // EqualEndpointStatus return true if and only if the two endpoint has the same
 // status.
 func EqualEndpointStatus(s securityv1alpha1.EndpointStatus, e securityv1alpha1.EndpointStatus) bool {
 	macEqual := s.MacAddress == e.MacAddress
 	ipsEqual := utils.EqualIPs(s.IPs, e.IPs)
 
-	return macEqual && ipsEqual
+	return macEqual && ipsEqual && equalConditions(s.Conditions, e.Conditions)
+}
+
+// equalConditions return true if conditions are the same regardless of their order and transition time.
+func equalConditions(s []securityv1alpha1.EndpointCondition, e []securityv1alpha1.EndpointCondition) bool {
+	if len(s) != len(e) {
+		return false
+	}
+
+	var toStrings = func(conditions []securityv1alpha1.EndpointCondition) []string {
+		var list []string
+		for _, c := range conditions {
+			list = append(list, fmt.Sprintf("%s/%s/%s/%s", c.Type, c.Status, c.Reason, c.Message))
+		}
+		sort.Strings(list)
+		return list
+	}
+
+	sList, eList := toStrings(s), toStrings(e)
+	for i := range sList {
+		if sList[i] != eList[i] {
+			return false
+		}
+	}
+	return true
 }
 
 // GetEndpointID return ID of an endpoint, it's unique in one cluster.
@@ -20,6 +20,7 @@ import (
 	"context"
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	k8stypes "k8s.io/apimachinery/pkg/types"
@@ -36,6 +37,7 @@ import (
 	groupv1alpha1 "github.com/smartxworks/lynx/pkg/apis/group/v1alpha1"
 	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
 	"github.com/smartxworks/lynx/pkg/client/clientset_generated/clientset/scheme"
+	lynxctrl "github.com/smartxworks/lynx/pkg/controller"
 	"github.com/smartxworks/lynx/pkg/types"
 )
 
@@ -150,6 +152,7 @@ func newFakeReconciler(initObjs ...runtime.Object) *EndpointReconciler {
 			agentIndex:      agentIndexFunc,
 			externalIDIndex: externalIDIndexFunc,
 		}),
+		agentHostnames: make(map[string]string),
 	}
 }
 
@@ -249,3 +252,144 @@ func TestProcessAgentInfo(t *testing.T) {
 		}
 	})
 }
+
+func TestProcessExpectedIPs(t *testing.T) {
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	endpoint := fakeEndpointA.DeepCopy()
+	endpoint.Spec.ExpectedIPs = []types.IPAddress{"10.0.0.1"}
+	r := newFakeReconciler(fakeAgentInfoA, endpoint)
+
+	t.Run("endpoint-added", func(t *testing.T) {
+		r.addAgentInfo(event.CreateEvent{
+			Meta:   fakeAgentInfoA.GetObjectMeta(),
+			Object: fakeAgentInfoA,
+		}, queue)
+
+		// process agentinfo create request from queue
+		if err := processQueue(r, queue); err != nil {
+			t.Errorf("failed to process add agentinfo request")
+		}
+
+		expectStatus := *ovsPortStatusA.DeepCopy()
+		expectStatus.IPs = append(expectStatus.IPs, "10.0.0.1")
+		endpointStatus := getFakeEndpoint(r.Client, endpoint.Name).Status
+		if !EqualEndpointStatus(expectStatus, endpointStatus) {
+			t.Errorf("unmatch endpoint status, get %v, want %v", endpointStatus, expectStatus)
+		}
+	})
+
+	t.Run("endpoint-expected-ips-updated", func(t *testing.T) {
+		newEndpoint := getFakeEndpoint(r.Client, endpoint.Name)
+		newEndpoint.Spec.ExpectedIPs = []types.IPAddress{"10.0.0.2"}
+		if err := r.Update(context.Background(), &newEndpoint); err != nil {
+			t.Fatalf("failed to update endpoint: %s", err)
+		}
+		r.updateEndpoint(event.UpdateEvent{
+			MetaOld:   endpoint.GetObjectMeta(),
+			ObjectOld: endpoint,
+			MetaNew:   newEndpoint.GetObjectMeta(),
+			ObjectNew: &newEndpoint,
+		}, queue)
+
+		// process endpoint update request from queue
+		if err := processQueue(r, queue); err != nil {
+			t.Errorf("failed to process update endpoint request")
+		}
+
+		expectStatus := *ovsPortStatusA.DeepCopy()
+		expectStatus.IPs = append(expectStatus.IPs, "10.0.0.2")
+		endpointStatus := getFakeEndpoint(r.Client, endpoint.Name).Status
+		if !EqualEndpointStatus(expectStatus, endpointStatus) {
+			t.Errorf("unmatch endpoint status, get %v, want %v", endpointStatus, expectStatus)
+		}
+	})
+}
+
+func TestProcessEndpointPlacement(t *testing.T) {
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	endpoint := fakeEndpointA.DeepCopy()
+	endpoint.Annotations = map[string]string{lynxctrl.HostAnnotation: fakeAgentInfoA.Hostname}
+	r := newFakeReconciler(fakeAgentInfoA, endpoint)
+
+	fakeAgentInfoC := &agentv1alpha1.AgentInfo{
+		ObjectMeta: v1.ObjectMeta{
+			Name: "fakeAgentInfoC",
+		},
+		Hostname: "node02",
+	}
+
+	var expectPlaced = func(status corev1.ConditionStatus, reason string) {
+		conditions := getFakeEndpoint(r.Client, endpoint.Name).Status.Conditions
+		if len(conditions) != 1 || conditions[0].Type != securityv1alpha1.EndpointPlaced {
+			t.Fatalf("expect endpoint has only Placed condition, got %+v", conditions)
+		}
+		if conditions[0].Status != status || conditions[0].Reason != reason {
+			t.Errorf("expect Placed condition status %s reason %s, got %+v", status, reason, conditions[0])
+		}
+	}
+
+	t.Run("endpoint-found-on-expected-host", func(t *testing.T) {
+		r.addAgentInfo(event.CreateEvent{
+			Meta:   fakeAgentInfoA.GetObjectMeta(),
+			Object: fakeAgentInfoA,
+		}, queue)
+
+		if err := processQueue(r, queue); err != nil {
+			t.Errorf("failed to process add agentinfo request")
+		}
+		expectPlaced(corev1.ConditionTrue, ReasonInterfaceFound)
+	})
+
+	t.Run("expected-host-without-agent", func(t *testing.T) {
+		newEndpoint := getFakeEndpoint(r.Client, endpoint.Name)
+		newEndpoint.Annotations[lynxctrl.HostAnnotation] = fakeAgentInfoC.Hostname
+		if err := r.Update(context.Background(), &newEndpoint); err != nil {
+			t.Fatalf("failed to update endpoint: %s", err)
+		}
+		r.updateEndpoint(event.UpdateEvent{
+			MetaOld:   endpoint.GetObjectMeta(),
+			ObjectOld: endpoint,
+			MetaNew:   newEndpoint.GetObjectMeta(),
+			ObjectNew: &newEndpoint,
+		}, queue)
+
+		if err := processQueue(r, queue); err != nil {
+			t.Errorf("failed to process update endpoint request")
+		}
+		expectPlaced(corev1.ConditionUnknown, ReasonAgentNotFound)
+	})
+
+	t.Run("endpoint-not-found-on-expected-host", func(t *testing.T) {
+		r.addAgentInfo(event.CreateEvent{
+			Meta:   fakeAgentInfoC.GetObjectMeta(),
+			Object: fakeAgentInfoC,
+		}, queue)
+
+		if err := processQueue(r, queue); err != nil {
+			t.Errorf("failed to process add agentinfo request")
+		}
+		expectPlaced(corev1.ConditionFalse, ReasonInterfaceNotFound)
+	})
+
+	t.Run("endpoint-without-expected-host", func(t *testing.T) {
+		oldEndpoint := getFakeEndpoint(r.Client, endpoint.Name)
+		newEndpoint := oldEndpoint.DeepCopy()
+		newEndpoint.Annotations = nil
+		if err := r.Update(context.Background(), newEndpoint); err != nil {
+			t.Fatalf("failed to update endpoint: %s", err)
+		}
+		r.updateEndpoint(event.UpdateEvent{
+			MetaOld:   oldEndpoint.GetObjectMeta(),
+			ObjectOld: &oldEndpoint,
+			MetaNew:   newEndpoint.GetObjectMeta(),
+			ObjectNew: newEndpoint,
+		}, queue)
+
+		if err := processQueue(r, queue); err != nil {
+			t.Errorf("failed to process update endpoint request")
+		}
+		if conditions := getFakeEndpoint(r.Client, endpoint.Name).Status.Conditions; len(conditions) != 0 {
+			t.Errorf("expect endpoint without expected host has no conditions, got %+v", conditions)
+		}
+	})
+}
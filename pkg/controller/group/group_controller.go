@@ -19,6 +19,7 @@ package group
 import (
 	"context"
 	"fmt"
+	"reflect"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -33,6 +34,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	groupv1alpha1 "github.com/smartxworks/lynx/pkg/apis/group/v1alpha1"
@@ -44,6 +46,7 @@ import (
 
 // GroupReconciler watch endpoints and endpointgroups resources, create, update
 // or delete groupmembers and groupmemberspatches according to group members changes.
+// It also manages endpointgroups for the endpoint selectors in securitypolicies.
 type GroupReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
@@ -109,6 +112,29 @@ func (r *GroupReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
+	selectorController, err := controller.New("selector-group-controller", mgr, controller.Options{
+		MaxConcurrentReconciles: lynxctrl.DefaultMaxConcurrentReconciles,
+		Reconciler:              reconcile.Func(r.ReconcileSelectorGroups),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = selectorController.Watch(&source.Kind{Type: &securityv1alpha1.SecurityPolicy{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(selectorGroupsSyncRequest),
+	})
+	if err != nil {
+		return err
+	}
+
+	// resync when selector groups changed, e.g. deleted by mistake
+	err = selectorController.Watch(&source.Kind{Type: &groupv1alpha1.EndpointGroup{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(selectorGroupsSyncRequest),
+	})
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -184,14 +210,13 @@ func (r *GroupReconciler) addEndpointGroup(e event.CreateEvent, q workqueue.Rate
 		return
 	}
 
-	q.Add(ctrl.Request{NamespacedName: k8stypes.NamespacedName{
-		Namespace: e.Meta.GetNamespace(),
-		Name:      e.Meta.GetName(),
-	}})
+	// groups contain the new group also need to update members
+	r.enqueueGroupAncestors(context.Background(), q, e.Meta.GetName())
 }
 
 // updateEndpointGroup enqueue endpointgroup if endpointgroup need
-// to delete or selector update.
+// to delete or spec update. Groups contain the endpointgroup are also
+// enqueued when the spec or tenant namespace updated.
 func (r *GroupReconciler) updateEndpointGroup(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
 	newGroup, newOK := e.ObjectNew.(*groupv1alpha1.EndpointGroup)
 	oldGroup, oldOK := e.ObjectOld.(*groupv1alpha1.EndpointGroup)
@@ -209,11 +234,9 @@ func (r *GroupReconciler) updateEndpointGroup(e event.UpdateEvent, q workqueue.R
 		return
 	}
 
-	if newGroup.Spec.Selector.String() != oldGroup.Spec.Selector.String() {
-		q.Add(ctrl.Request{NamespacedName: k8stypes.NamespacedName{
-			Namespace: newGroup.Namespace,
-			Name:      newGroup.Name,
-		}})
+	if !reflect.DeepEqual(newGroup.Spec, oldGroup.Spec) ||
+		newGroup.Labels[lynxctrl.NamespaceLabel] != oldGroup.Labels[lynxctrl.NamespaceLabel] {
+		r.enqueueGroupAncestors(context.Background(), q, newGroup.Name)
 	}
 }
 
@@ -223,19 +246,40 @@ func (r *GroupReconciler) deleteEndpointGroup(e event.DeleteEvent, q workqueue.R
 		return
 	}
 
-	q.Add(ctrl.Request{NamespacedName: k8stypes.NamespacedName{
-		Namespace: e.Meta.GetNamespace(),
-		Name:      e.Meta.GetName(),
-	}})
+	r.enqueueGroupAncestors(context.Background(), q, e.Meta.GetName())
+}
+
+// enqueueGroupAncestors enqueue the group and the groups contain it directly or indirectly.
+func (r *GroupReconciler) enqueueGroupAncestors(ctx context.Context, q workqueue.RateLimitingInterface, groupName string) {
+	groupList := groupv1alpha1.EndpointGroupList{}
+	_ = r.List(ctx, &groupList)
+
+	for name := range GroupAncestors(groupList.Items, sets.NewString(groupName)) {
+		q.Add(ctrl.Request{NamespacedName: k8stypes.NamespacedName{
+			Namespace: metav1.NamespaceNone,
+			Name:      name,
+		}})
+	}
 }
 
-// filterEndpointGroups filter endpointgroups which match endpoint labels.
+// filterEndpointGroups filter endpointgroups which match endpoint labels or contain
+// the endpoint by name in the same tenant namespace, and the groups contain them
+// directly or indirectly.
 func (r *GroupReconciler) filterEndpointGroups(ctx context.Context, endpoint *securityv1alpha1.Endpoint) sets.String {
 	groupNameSet := sets.String{}
 	groupList := groupv1alpha1.EndpointGroupList{}
 	_ = r.List(ctx, &groupList)
 
 	for _, group := range groupList.Items {
+		if !inGroupNamespace(&group, endpoint.Labels) {
+			continue
+		}
+
+		if sets.NewString(group.Spec.Endpoints...).Has(endpoint.Name) {
+			groupNameSet.Insert(group.Name)
+			continue
+		}
+
 		selector, err := metav1.LabelSelectorAsSelector(group.Spec.Selector)
 		if err != nil {
 			continue
@@ -246,7 +290,7 @@ func (r *GroupReconciler) filterEndpointGroups(ctx context.Context, endpoint *se
 		}
 	}
 
-	return groupNameSet
+	return GroupAncestors(groupList.Items, groupNameSet)
 }
 
 func (r *GroupReconciler) isNewEndpointGroup(group *groupv1alpha1.EndpointGroup) bool {
@@ -348,35 +392,23 @@ func (r *GroupReconciler) processEndpointGroupUpdate(ctx context.Context, group
 	return ctrl.Result{}, nil
 }
 
-// fetchCurrGroupMembers get endpoints by selector, and return as GroupMembers
+// fetchCurrGroupMembers get endpoints, ipBlocks and members of nested groups, and return as GroupMembers
 func (r *GroupReconciler) fetchCurrGroupMembers(ctx context.Context, group *groupv1alpha1.EndpointGroup) (*groupv1alpha1.GroupMembers, error) {
-	selector, err := metav1.LabelSelectorAsSelector(group.Spec.Selector)
+	groupList := groupv1alpha1.EndpointGroupList{}
+	err := r.List(ctx, &groupList)
 	if err != nil {
 		return nil, err
 	}
 
 	epList := securityv1alpha1.EndpointList{}
-	err = r.List(ctx, &epList, client.MatchingLabelsSelector{Selector: selector})
+	err = r.List(ctx, &epList)
 	if err != nil {
 		return nil, err
 	}
 
-	// conversion endpoint list to member list
-	memberList := make([]groupv1alpha1.GroupMember, 0, len(epList.Items))
-	for _, ep := range epList.Items {
-		if len(ep.Status.IPs) == 0 {
-			// skip ep with empty ip addresses
-			continue
-		}
-
-		member := groupv1alpha1.GroupMember{
-			EndpointReference: groupv1alpha1.EndpointReference{
-				ExternalIDName:  ep.Spec.Reference.ExternalIDName,
-				ExternalIDValue: ep.Spec.Reference.ExternalIDValue,
-			},
-			IPs: ep.Status.IPs,
-		}
-		memberList = append(memberList, member)
+	memberList, err := CalculateGroupMembers(group, groupList.Items, epList.Items)
+	if err != nil {
+		return nil, err
 	}
 
 	return &groupv1alpha1.GroupMembers{GroupMembers: memberList}, nil
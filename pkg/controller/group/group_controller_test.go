@@ -32,6 +32,7 @@ import (
 	groupv1alpha1 "github.com/smartxworks/lynx/pkg/apis/group/v1alpha1"
 	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
 	lynxctrl "github.com/smartxworks/lynx/pkg/controller"
+	groupctrl "github.com/smartxworks/lynx/pkg/controller/group"
 	"github.com/smartxworks/lynx/pkg/types"
 )
 
@@ -226,6 +227,99 @@ var _ = Describe("GroupController", func() {
 		})
 	})
 
+	Context("an endpointgroup contains another endpointgroup has been created", func() {
+		var childGroup, parentGroup *groupv1alpha1.EndpointGroup
+		BeforeEach(func() {
+			childGroup = newTestEndpointGroup(map[string]string{"label.key": "label.value"})
+			parentGroup = newTestEndpointGroup(map[string]string{"label.key": "none"})
+			parentGroup.Spec.EndpointGroups = []string{childGroup.Name}
+			parentGroup.Spec.IPBlocks = []securityv1alpha1.IPBlock{{IP: "10.0.0.0", PrefixLength: 24}}
+
+			By(fmt.Sprintf("create endpointgroup %s contains endpointgroup %s", parentGroup.Name, childGroup.Name))
+			Expect(k8sClient.Create(ctx, childGroup)).Should(Succeed())
+			Expect(k8sClient.Create(ctx, parentGroup)).Should(Succeed())
+		})
+		It("should create groupmembers contains the ipBlock", func() {
+			assertHasGroupMembers(parentGroup, groupv1alpha1.GroupMembers{GroupMembers: []groupv1alpha1.GroupMember{ipBlockToGroupMember("10.0.0.0/24")}})
+		})
+
+		When("create a endpoint in the nested group", func() {
+			var ep *securityv1alpha1.Endpoint
+			BeforeEach(func() {
+				ep = newTestEndpoint(map[string]string{"label.key": "label.value"}, "192.168.1.1")
+
+				By(fmt.Sprintf("create endpoint %s with labels %v", ep.Name, ep.Labels))
+				Expect(k8sClient.Create(ctx, ep)).Should(Succeed())
+				Expect(k8sClient.Status().Update(ctx, ep)).Should(Succeed())
+			})
+			It("should create patch add the endpoint for the group", func() {
+				assertHasPatch(parentGroup, groupv1alpha1.GroupMembersPatch{AddedGroupMembers: []groupv1alpha1.GroupMember{endpointToGroupMember(ep)}})
+			})
+			It("should update groupmembers contains the endpoint and the ipBlock", func() {
+				assertHasGroupMembers(parentGroup, groupv1alpha1.GroupMembers{GroupMembers: []groupv1alpha1.GroupMember{
+					endpointToGroupMember(ep), ipBlockToGroupMember("10.0.0.0/24"),
+				}})
+			})
+		})
+	})
+
+	Context("a policy with endpoint selector has been created", func() {
+		var policy *securityv1alpha1.SecurityPolicy
+		var selectorGroup *groupv1alpha1.EndpointGroup
+
+		BeforeEach(func() {
+			policy = newTestPolicy(&metav1.LabelSelector{MatchLabels: map[string]string{"label.key": "label.value"}})
+			selectorGroup = &groupv1alpha1.EndpointGroup{}
+			selectorGroup.Name = groupctrl.SelectorGroupName("", policy.Spec.AppliedTo.EndpointSelector)
+
+			By(fmt.Sprintf("create policy %s with endpoint selector %v", policy.Name, policy.Spec.AppliedTo.EndpointSelector))
+			Expect(k8sClient.Create(ctx, policy)).Should(Succeed())
+		})
+		AfterEach(func() {
+			By("delete all test policies")
+			Expect(k8sClient.DeleteAllOf(ctx, &securityv1alpha1.SecurityPolicy{}, client.MatchingLabels{TestLabelKey: TestLabelValue})).Should(Succeed())
+		})
+
+		It("should create endpointgroup for the selector", func() {
+			Eventually(func() error {
+				return k8sClient.Get(ctx, client.ObjectKey{Name: selectorGroup.Name}, selectorGroup)
+			}, timeout, interval).Should(Succeed())
+			Expect(selectorGroup.Labels).Should(HaveKey(lynxctrl.SelectorGroupLabel))
+			Expect(selectorGroup.Spec.Selector).Should(Equal(policy.Spec.AppliedTo.EndpointSelector))
+		})
+
+		When("create a endpoint selected by the policy", func() {
+			var ep *securityv1alpha1.Endpoint
+			BeforeEach(func() {
+				ep = newTestEndpoint(map[string]string{"label.key": "label.value"}, "192.168.1.1")
+
+				By(fmt.Sprintf("create endpoint %s with labels %v", ep.Name, ep.Labels))
+				Expect(k8sClient.Create(ctx, ep)).Should(Succeed())
+				Expect(k8sClient.Status().Update(ctx, ep)).Should(Succeed())
+			})
+			It("should update groupmembers of the selector contains the endpoint", func() {
+				assertHasGroupMembers(selectorGroup, groupv1alpha1.GroupMembers{GroupMembers: []groupv1alpha1.GroupMember{endpointToGroupMember(ep)}})
+			})
+		})
+
+		When("delete the policy", func() {
+			BeforeEach(func() {
+				Eventually(func() error {
+					return k8sClient.Get(ctx, client.ObjectKey{Name: selectorGroup.Name}, &groupv1alpha1.EndpointGroup{})
+				}, timeout, interval).Should(Succeed())
+
+				By(fmt.Sprintf("delete policy %s", policy.Name))
+				Expect(k8sClient.Delete(ctx, policy)).Should(Succeed())
+			})
+			It("should remove endpointgroup of the selector", func() {
+				Eventually(func() bool {
+					err := k8sClient.Get(ctx, client.ObjectKey{Name: selectorGroup.Name}, &groupv1alpha1.EndpointGroup{})
+					return apierrors.IsNotFound(err)
+				}, timeout, interval).Should(BeTrue())
+			})
+		})
+	})
+
 	Context("none endpointgroup has been created", func() {
 		When("create an endpointgroup", func() {
 			var epGroup *groupv1alpha1.EndpointGroup
@@ -263,6 +357,17 @@ func endpointToGroupMember(ep *securityv1alpha1.Endpoint) groupv1alpha1.GroupMem
 	}
 }
 
+// ipBlockToGroupMember conversion cidr to GroupMember.
+func ipBlockToGroupMember(cidr string) groupv1alpha1.GroupMember {
+	return groupv1alpha1.GroupMember{
+		EndpointReference: groupv1alpha1.EndpointReference{
+			ExternalIDName:  groupctrl.IPBlockExternalIDName,
+			ExternalIDValue: cidr,
+		},
+		IPs: []types.IPAddress{types.IPAddress(cidr)},
+	}
+}
+
 // getLatestPatch return the latest revision of patch
 func getLatestPatch(patchList groupv1alpha1.GroupMembersPatchList) *groupv1alpha1.GroupMembersPatch {
 	var patch *groupv1alpha1.GroupMembersPatch
@@ -317,6 +422,23 @@ func newTestEndpointGroup(matchLabels map[string]string) *groupv1alpha1.Endpoint
 	}
 }
 
+func newTestPolicy(appliedTo *metav1.LabelSelector) *securityv1alpha1.SecurityPolicy {
+	name := "policy-test-" + string(uuid.NewUUID())
+
+	return &securityv1alpha1.SecurityPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{TestLabelKey: TestLabelValue},
+		},
+		Spec: securityv1alpha1.SecurityPolicySpec{
+			Tier: "tier0",
+			AppliedTo: securityv1alpha1.AppliedTo{
+				EndpointSelector: appliedTo,
+			},
+		},
+	}
+}
+
 func newTestPatch(groupName string, revision int32) *groupv1alpha1.GroupMembersPatch {
 	name := "patch-test-" + string(uuid.NewUUID())
 
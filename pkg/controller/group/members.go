@@ -0,0 +1,166 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package group
+
+import (
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	groupv1alpha1 "github.com/smartxworks/lynx/pkg/apis/group/v1alpha1"
+	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	lynxctrl "github.com/smartxworks/lynx/pkg/controller"
+	policycache "github.com/smartxworks/lynx/pkg/controller/policy/cache"
+	"github.com/smartxworks/lynx/pkg/types"
+)
+
+// IPBlockExternalIDName is the ExternalIDName of group members from IPBlocks of the group,
+// the ExternalIDValue of the members are the CIDRs.
+const IPBlockExternalIDName = "ipblock.group.lynx.smartx.com"
+
+// CalculateGroupMembers returns members of the group, which are the union of endpoints selected by
+// the selector, endpoints in the endpoint names, CIDRs of the ipBlocks and members of the nested groups.
+// Nested groups not exist are ignored, error returns if groups are referenced cyclically. Groups in a
+// tenant namespace only contain endpoints and nested groups in the same namespace.
+func CalculateGroupMembers(group *groupv1alpha1.EndpointGroup, groups []groupv1alpha1.EndpointGroup, endpoints []securityv1alpha1.Endpoint) ([]groupv1alpha1.GroupMember, error) {
+	var groupMap = make(map[string]*groupv1alpha1.EndpointGroup, len(groups))
+	var memberMap = make(map[groupv1alpha1.EndpointReference]groupv1alpha1.GroupMember)
+
+	for item := range groups {
+		groupMap[groups[item].Name] = &groups[item]
+	}
+	// always use the giving group, groups may contain an outdated one
+	groupMap[group.Name] = group
+
+	err := collectGroupMembers(group, groupMap, endpoints, sets.NewString(), memberMap)
+	if err != nil {
+		return nil, err
+	}
+
+	memberList := make([]groupv1alpha1.GroupMember, 0, len(memberMap))
+	for _, member := range memberMap {
+		memberList = append(memberList, member)
+	}
+	// keep members in stable order
+	sort.Slice(memberList, func(i, j int) bool {
+		if memberList[i].EndpointReference.ExternalIDName != memberList[j].EndpointReference.ExternalIDName {
+			return memberList[i].EndpointReference.ExternalIDName < memberList[j].EndpointReference.ExternalIDName
+		}
+		return memberList[i].EndpointReference.ExternalIDValue < memberList[j].EndpointReference.ExternalIDValue
+	})
+
+	return memberList, nil
+}
+
+// collectGroupMembers adds members of the group into memberMap, path is the groups from the top
+// group to the current one, which used to find out cyclic references.
+func collectGroupMembers(group *groupv1alpha1.EndpointGroup, groupMap map[string]*groupv1alpha1.EndpointGroup,
+	endpoints []securityv1alpha1.Endpoint, path sets.String, memberMap map[groupv1alpha1.EndpointReference]groupv1alpha1.GroupMember) error {
+	if path.Has(group.Name) {
+		return fmt.Errorf("group %s referenced cyclically: %v", group.Name, path.List())
+	}
+	path.Insert(group.Name)
+	defer path.Delete(group.Name)
+
+	selector, err := metav1.LabelSelectorAsSelector(group.Spec.Selector)
+	if err != nil {
+		return fmt.Errorf("group %s has invalid selector: %s", group.Name, err)
+	}
+	endpointNames := sets.NewString(group.Spec.Endpoints...)
+
+	for _, ep := range endpoints {
+		if len(ep.Status.IPs) == 0 {
+			// skip ep with empty ip addresses
+			continue
+		}
+		if !inGroupNamespace(group, ep.Labels) {
+			continue
+		}
+		if !selector.Matches(labels.Set(ep.Labels)) && !endpointNames.Has(ep.Name) {
+			continue
+		}
+		member := groupv1alpha1.GroupMember{
+			EndpointReference: groupv1alpha1.EndpointReference{
+				ExternalIDName:  ep.Spec.Reference.ExternalIDName,
+				ExternalIDValue: ep.Spec.Reference.ExternalIDValue,
+			},
+			IPs: ep.Status.IPs,
+		}
+		memberMap[member.EndpointReference] = member
+	}
+
+	for _, ipBlock := range group.Spec.IPBlocks {
+		cidrs, err := policycache.SubtractCIDRs(fmt.Sprintf("%s/%d", ipBlock.IP, ipBlock.PrefixLength), ipBlock.Except)
+		if err != nil {
+			return fmt.Errorf("group %s has invalid ipBlock: %s", group.Name, err)
+		}
+		for _, cidr := range cidrs {
+			member := groupv1alpha1.GroupMember{
+				EndpointReference: groupv1alpha1.EndpointReference{
+					ExternalIDName:  IPBlockExternalIDName,
+					ExternalIDValue: cidr,
+				},
+				IPs: []types.IPAddress{types.IPAddress(cidr)},
+			}
+			memberMap[member.EndpointReference] = member
+		}
+	}
+
+	for _, groupName := range group.Spec.EndpointGroups {
+		nestedGroup, ok := groupMap[groupName]
+		if !ok || !inGroupNamespace(group, nestedGroup.Labels) {
+			continue
+		}
+		if err := collectGroupMembers(nestedGroup, groupMap, endpoints, path, memberMap); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// inGroupNamespace returns true if the object with the labels is in the tenant namespace of the
+// group, groups not in any tenant namespace contain objects of all namespaces.
+func inGroupNamespace(group *groupv1alpha1.EndpointGroup, objLabels map[string]string) bool {
+	namespace := group.Labels[lynxctrl.NamespaceLabel]
+	return namespace == "" || objLabels[lynxctrl.NamespaceLabel] == namespace
+}
+
+// GroupAncestors returns names of the groups which contain any of the giving groups directly or
+// indirectly, including the giving groups themselves.
+func GroupAncestors(groups []groupv1alpha1.EndpointGroup, groupNames sets.String) sets.String {
+	var ancestors = sets.NewString(groupNames.UnsortedList()...)
+	var queue = groupNames.List()
+
+	for len(queue) != 0 {
+		groupName := queue[0]
+		queue = queue[1:]
+
+		for _, group := range groups {
+			if ancestors.Has(group.Name) || !sets.NewString(group.Spec.EndpointGroups...).Has(groupName) {
+				continue
+			}
+			ancestors.Insert(group.Name)
+			queue = append(queue, group.Name)
+		}
+	}
+
+	return ancestors
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package group_test
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	groupv1alpha1 "github.com/smartxworks/lynx/pkg/apis/group/v1alpha1"
+	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	lynxctrl "github.com/smartxworks/lynx/pkg/controller"
+	groupctrl "github.com/smartxworks/lynx/pkg/controller/group"
+	"github.com/smartxworks/lynx/pkg/types"
+)
+
+func TestCalculateGroupMembers(t *testing.T) {
+	endpoints := []securityv1alpha1.Endpoint{
+		newMembersTestEndpoint("ep01", map[string]string{"app": "web"}, "10.0.0.1"),
+		newMembersTestEndpoint("ep02", map[string]string{"app": "db"}, "10.0.0.2"),
+		newMembersTestEndpoint("ep03", nil, "10.0.0.3"),
+		newMembersTestEndpoint("ep04", map[string]string{"app": "web"}),
+		newMembersTestEndpoint("ep05", map[string]string{"app": "web", lynxctrl.NamespaceLabel: "tenant-a"}, "10.0.0.5"),
+	}
+	groups := []groupv1alpha1.EndpointGroup{
+		newMembersTestGroup("web", groupv1alpha1.EndpointGroupSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		}),
+		newMembersTestGroup("db", groupv1alpha1.EndpointGroupSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			IPBlocks: []securityv1alpha1.IPBlock{{IP: "192.168.0.0", PrefixLength: 24, Except: []string{"192.168.0.0/25"}}},
+		}),
+		newMembersTestGroup("all", groupv1alpha1.EndpointGroupSpec{
+			EndpointGroups: []string{"web", "db", "not-exist"},
+			Endpoints:      []string{"ep03", "ep01"},
+		}),
+		newMembersTestGroup("tenant-web", groupv1alpha1.EndpointGroupSpec{
+			Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			EndpointGroups: []string{"db"},
+			Endpoints:      []string{"ep03"},
+		}, "tenant-a"),
+		newMembersTestGroup("cycle-a", groupv1alpha1.EndpointGroupSpec{EndpointGroups: []string{"cycle-b"}}),
+		newMembersTestGroup("cycle-b", groupv1alpha1.EndpointGroupSpec{EndpointGroups: []string{"cycle-a"}}),
+	}
+
+	testCases := map[string]struct {
+		group         string
+		expectError   bool
+		expectMembers []string
+	}{
+		"should calculate members of selector": {
+			group:         "web",
+			expectMembers: []string{"ep01=10.0.0.1", "ep05=10.0.0.5"},
+		},
+		"should calculate members of selector and ipBlocks": {
+			group:         "db",
+			expectMembers: []string{"ep02=10.0.0.2", "192.168.0.128/25"},
+		},
+		"should calculate union members of nested groups and endpoints": {
+			group:         "all",
+			expectMembers: []string{"ep01=10.0.0.1", "ep02=10.0.0.2", "ep03=10.0.0.3", "ep05=10.0.0.5", "192.168.0.128/25"},
+		},
+		"should only calculate members in the tenant namespace": {
+			group:         "tenant-web",
+			expectMembers: []string{"ep05=10.0.0.5"},
+		},
+		"should return error for cyclic referenced groups": {
+			group:       "cycle-a",
+			expectError: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var group *groupv1alpha1.EndpointGroup
+			for item := range groups {
+				if groups[item].Name == tc.group {
+					group = &groups[item]
+				}
+			}
+
+			members, err := groupctrl.CalculateGroupMembers(group, groups, endpoints)
+			if tc.expectError != (err != nil) {
+				t.Fatalf("expect error %t, got error %v", tc.expectError, err)
+			}
+
+			var memberSet = sets.NewString()
+			for _, member := range members {
+				id := member.EndpointReference.ExternalIDValue
+				if member.EndpointReference.ExternalIDName == groupctrl.IPBlockExternalIDName {
+					memberSet.Insert(id)
+					continue
+				}
+				for _, ip := range member.IPs {
+					memberSet.Insert(id + "=" + string(ip))
+				}
+			}
+			if !tc.expectError && !reflect.DeepEqual(memberSet, sets.NewString(tc.expectMembers...)) {
+				t.Fatalf("expect members %v, got %v", tc.expectMembers, memberSet.List())
+			}
+		})
+	}
+}
+
+func TestGroupAncestors(t *testing.T) {
+	groups := []groupv1alpha1.EndpointGroup{
+		newMembersTestGroup("a", groupv1alpha1.EndpointGroupSpec{EndpointGroups: []string{"b"}}),
+		newMembersTestGroup("b", groupv1alpha1.EndpointGroupSpec{EndpointGroups: []string{"c"}}),
+		newMembersTestGroup("c", groupv1alpha1.EndpointGroupSpec{}),
+		newMembersTestGroup("d", groupv1alpha1.EndpointGroupSpec{EndpointGroups: []string{"a"}}),
+		newMembersTestGroup("e", groupv1alpha1.EndpointGroupSpec{}),
+	}
+
+	ancestors := groupctrl.GroupAncestors(groups, sets.NewString("c"))
+	if !ancestors.Equal(sets.NewString("a", "b", "c", "d")) {
+		t.Fatalf("unexpect ancestors %v", ancestors.List())
+	}
+}
+
+func newMembersTestEndpoint(name string, labels map[string]string, ips ...types.IPAddress) securityv1alpha1.Endpoint {
+	return securityv1alpha1.Endpoint{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec: securityv1alpha1.EndpointSpec{
+			Reference: securityv1alpha1.EndpointReference{ExternalIDName: "iface-id", ExternalIDValue: name},
+		},
+		Status: securityv1alpha1.EndpointStatus{IPs: ips},
+	}
+}
+
+func newMembersTestGroup(name string, spec groupv1alpha1.EndpointGroupSpec, namespace ...string) groupv1alpha1.EndpointGroup {
+	group := groupv1alpha1.EndpointGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       spec,
+	}
+	if len(namespace) != 0 {
+		group.Labels = map[string]string{lynxctrl.NamespaceLabel: namespace[0]}
+	}
+	return group
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package group
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	groupv1alpha1 "github.com/smartxworks/lynx/pkg/apis/group/v1alpha1"
+	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	lynxctrl "github.com/smartxworks/lynx/pkg/controller"
+)
+
+// selectorGroupsSyncKey is the only request of selector group controller, all selector
+// groups are synced together for any policy changes.
+const selectorGroupsSyncKey = "selector-groups"
+
+// SelectorGroupName returns name of the EndpointGroup managed for the endpoint selector in
+// policies of the tenant namespace, empty namespace for policies not in any tenant namespace.
+// Equivalent selectors in the same namespace always have the same name, so they share the same group.
+func SelectorGroupName(namespace string, selector *metav1.LabelSelector) string {
+	var key = selector.String()
+	if s, err := metav1.LabelSelectorAsSelector(selector); err == nil {
+		key = s.String()
+	}
+	if namespace != "" {
+		key = namespace + "/" + key
+	}
+
+	hash := sha256.Sum256([]byte(key))
+	return fmt.Sprintf("selector-%s", hex.EncodeToString(hash[:])[:20])
+}
+
+// PolicySelectorGroups returns EndpointGroups of the endpoint selectors in the policy appliedTo
+// and rule peers, keyed by the group name. Groups of policies in a tenant namespace are in the same
+// namespace, so they only select endpoints of the namespace.
+func PolicySelectorGroups(policy *securityv1alpha1.SecurityPolicy) map[string]*groupv1alpha1.EndpointGroup {
+	var groups = make(map[string]*groupv1alpha1.EndpointGroup)
+	var namespace = policy.Labels[lynxctrl.NamespaceLabel]
+	var selectors = []*metav1.LabelSelector{policy.Spec.AppliedTo.EndpointSelector}
+
+	for _, rule := range policy.Spec.IngressRules {
+		selectors = append(selectors, rule.From.EndpointSelector)
+	}
+	for _, rule := range policy.Spec.EgressRules {
+		selectors = append(selectors, rule.To.EndpointSelector)
+	}
+
+	for _, selector := range selectors {
+		if selector == nil {
+			continue
+		}
+		name := SelectorGroupName(namespace, selector)
+		groupLabels := map[string]string{lynxctrl.SelectorGroupLabel: "true"}
+		if namespace != "" {
+			groupLabels[lynxctrl.NamespaceLabel] = namespace
+		}
+		groups[name] = &groupv1alpha1.EndpointGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: groupLabels,
+			},
+			Spec: groupv1alpha1.EndpointGroupSpec{
+				Selector: selector.DeepCopy(),
+			},
+		}
+	}
+
+	return groups
+}
+
+// ReconcileSelectorGroups creates EndpointGroups for endpoint selectors in policies, and removes
+// the groups no longer used by any policy. Members of the groups are maintained the same as the
+// other EndpointGroups.
+func (r *GroupReconciler) ReconcileSelectorGroups(req ctrl.Request) (ctrl.Result, error) {
+	var ctx = context.Background()
+	var expectGroups = make(map[string]*groupv1alpha1.EndpointGroup)
+	var errList []error
+
+	policyList := securityv1alpha1.SecurityPolicyList{}
+	if err := r.List(ctx, &policyList); err != nil {
+		klog.Errorf("unable to list policies: %s", err)
+		return ctrl.Result{}, err
+	}
+	for item := range policyList.Items {
+		for name, group := range PolicySelectorGroups(&policyList.Items[item]) {
+			expectGroups[name] = group
+		}
+	}
+
+	groupList := groupv1alpha1.EndpointGroupList{}
+	if err := r.List(ctx, &groupList, client.MatchingLabels{lynxctrl.SelectorGroupLabel: "true"}); err != nil {
+		klog.Errorf("unable to list selector groups: %s", err)
+		return ctrl.Result{}, err
+	}
+
+	for item := range groupList.Items {
+		group := &groupList.Items[item]
+		if _, ok := expectGroups[group.Name]; ok {
+			delete(expectGroups, group.Name)
+			continue
+		}
+		if r.isDeletingEndpointGroup(group) {
+			continue
+		}
+
+		klog.Infof("remove selector group %s no longer used by any policy", group.Name)
+		if err := r.Delete(ctx, group); client.IgnoreNotFound(err) != nil {
+			errList = append(errList, fmt.Errorf("delete selector group %s: %s", group.Name, err))
+		}
+	}
+
+	for _, group := range expectGroups {
+		klog.Infof("create selector group %s for selector %s", group.Name, group.Spec.Selector)
+		if err := r.Create(ctx, group); err != nil && !apierrors.IsAlreadyExists(err) {
+			errList = append(errList, fmt.Errorf("create selector group %s: %s", group.Name, err))
+		}
+	}
+
+	return ctrl.Result{}, errors.NewAggregate(errList)
+}
+
+// selectorGroupsSyncRequest maps policies and selector groups to the sync request.
+func selectorGroupsSyncRequest(obj handler.MapObject) []reconcile.Request {
+	if _, ok := obj.Object.(*groupv1alpha1.EndpointGroup); ok && obj.Meta.GetLabels()[lynxctrl.SelectorGroupLabel] == "" {
+		// ignore EndpointGroups not managed for selectors
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: k8stypes.NamespacedName{
+		Namespace: metav1.NamespaceNone,
+		Name:      selectorGroupsSyncKey,
+	}}}
+}
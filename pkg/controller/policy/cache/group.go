@@ -237,3 +237,20 @@ func (cache *GroupCache) ListGroupIPBlocks(groupName string) (revision int32, ip
 
 	return membership.revision, ipBlocks, true
 }
+
+// ListGroupEndpoints return a list of EndpointReferences of the group members.
+func (cache *GroupCache) ListGroupEndpoints(groupName string) (endpoints []groupv1alpha1.EndpointReference, exist bool) {
+	cache.lock.RLock()
+	defer cache.lock.RUnlock()
+
+	membership, ok := cache.members[groupName]
+	if !ok {
+		return nil, false
+	}
+
+	for endpointReference := range membership.endpoints {
+		endpoints = append(endpoints, endpointReference)
+	}
+
+	return endpoints, true
+}
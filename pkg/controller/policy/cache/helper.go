@@ -20,6 +20,7 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"net"
 	"reflect"
 	"regexp"
 	"strconv"
@@ -36,9 +37,13 @@ const (
 	allowRunes   = "abcdefghijklmnopqrstuvwxyz1234567890"
 )
 
+// GetIPCidr returns the cidr of the ip address, the address returns as it is if it's already a cidr.
 func GetIPCidr(ip types.IPAddress) string {
 	var ipCidr string
 
+	if strings.Contains(string(ip), "/") {
+		return string(ip)
+	}
 	if regexp.MustCompile(matchIPV4).Match([]byte(ip)) {
 		ipCidr = fmt.Sprintf("%s/%d", ip, 32)
 	} else {
@@ -85,6 +90,63 @@ func UnmarshalPortRange(portRange string) (uint16, uint16, error) {
 	return begin, end, nil
 }
 
+// SubtractCIDRs returns CIDRs which covers addresses in cidr but not in any of the excepts.
+func SubtractCIDRs(cidr string, excepts []string) ([]string, error) {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse cidr %s: %s", cidr, err)
+	}
+
+	var exceptNets []*net.IPNet
+	for _, except := range excepts {
+		_, exceptNet, err := net.ParseCIDR(except)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse except cidr %s: %s", except, err)
+		}
+		exceptNets = append(exceptNets, exceptNet)
+	}
+
+	var cidrs []string
+	for _, item := range subtractIPNets(ipNet, exceptNets) {
+		cidrs = append(cidrs, item.String())
+	}
+	return cidrs, nil
+}
+
+// subtractIPNets splits ipNet into halves until each part contains none of the excepts,
+// the part covered by any except would be removed.
+func subtractIPNets(ipNet *net.IPNet, excepts []*net.IPNet) []*net.IPNet {
+	var ones, bits = ipNet.Mask.Size()
+	var overlapExcepts []*net.IPNet
+
+	for _, except := range excepts {
+		exceptOnes, exceptBits := except.Mask.Size()
+		if exceptBits != bits {
+			// ignore except of different ip family
+			continue
+		}
+		if exceptOnes <= ones && except.Contains(ipNet.IP) {
+			// ipNet totally covered by the except
+			return nil
+		}
+		if exceptOnes > ones && ipNet.Contains(except.IP) {
+			overlapExcepts = append(overlapExcepts, except)
+		}
+	}
+
+	if len(overlapExcepts) == 0 {
+		return []*net.IPNet{ipNet}
+	}
+
+	var mask = net.CIDRMask(ones+1, bits)
+	var lowerHalf = &net.IPNet{IP: ipNet.IP.Mask(mask), Mask: mask}
+	var upperHalf = &net.IPNet{IP: make(net.IP, len(lowerHalf.IP)), Mask: mask}
+	copy(upperHalf.IP, lowerHalf.IP)
+	upperHalf.IP[ones/8] |= 0x80 >> uint(ones%8)
+
+	return append(subtractIPNets(lowerHalf, overlapExcepts), subtractIPNets(upperHalf, overlapExcepts)...)
+}
+
 func DeepCopyMap(theMap interface{}) interface{} {
 	maptype := reflect.TypeOf(theMap)
 
@@ -17,8 +17,10 @@ limitations under the License.
 package cache
 
 import (
-	"github.com/smartxworks/lynx/pkg/types"
+	"reflect"
 	"testing"
+
+	"github.com/smartxworks/lynx/pkg/types"
 )
 
 func TestUnmarshalPortRange(t *testing.T) {
@@ -84,6 +86,10 @@ func TestGetIPCidr(t *testing.T) {
 			ipAddr:     "fe80::10d4:3056:5621:a446",
 			expectCidr: "fe80::10d4:3056:5621:a446/128",
 		},
+		"should return cidr itself": {
+			ipAddr:     "10.0.0.0/24",
+			expectCidr: "10.0.0.0/24",
+		},
 	}
 
 	for name, tc := range testCases {
@@ -94,3 +100,66 @@ func TestGetIPCidr(t *testing.T) {
 		})
 	}
 }
+
+func TestSubtractCIDRs(t *testing.T) {
+	testCases := map[string]struct {
+		cidr    string
+		excepts []string
+
+		expectError bool
+		expectCidrs []string
+	}{
+		"should return cidr itself without excepts": {
+			cidr:        "10.0.0.0/8",
+			expectCidrs: []string{"10.0.0.0/8"},
+		},
+		"should subtract except from cidr": {
+			cidr:        "10.0.0.0/8",
+			excepts:     []string{"10.128.0.0/9"},
+			expectCidrs: []string{"10.0.0.0/9"},
+		},
+		"should split cidr around except": {
+			cidr:        "192.168.0.0/24",
+			excepts:     []string{"192.168.0.64/26"},
+			expectCidrs: []string{"192.168.0.0/26", "192.168.0.128/25"},
+		},
+		"should subtract multiple excepts": {
+			cidr:        "192.168.0.0/24",
+			excepts:     []string{"192.168.0.0/26", "192.168.0.192/26"},
+			expectCidrs: []string{"192.168.0.64/26", "192.168.0.128/26"},
+		},
+		"should return nothing when except covers cidr": {
+			cidr:        "192.168.1.0/24",
+			excepts:     []string{"192.168.0.0/16"},
+			expectCidrs: nil,
+		},
+		"should ignore except outside cidr": {
+			cidr:        "192.168.1.0/24",
+			excepts:     []string{"10.0.0.0/8"},
+			expectCidrs: []string{"192.168.1.0/24"},
+		},
+		"should subtract ipv6 except": {
+			cidr:        "fd00::/126",
+			excepts:     []string{"fd00::3/128"},
+			expectCidrs: []string{"fd00::/127", "fd00::2/128"},
+		},
+		"should not subtract except with wrong format": {
+			cidr:        "10.0.0.0/8",
+			excepts:     []string{"10.0.0.300/16"},
+			expectError: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cidrs, err := SubtractCIDRs(tc.cidr, tc.excepts)
+			if tc.expectError && err == nil || !tc.expectError && err != nil {
+				t.Fatalf("expect error: %t, but get error: %s", tc.expectError, err)
+			}
+
+			if !reflect.DeepEqual(cidrs, tc.expectCidrs) {
+				t.Fatalf("expect cidrs %v, get cidrs %v", tc.expectCidrs, cidrs)
+			}
+		})
+	}
+}
@@ -47,6 +47,12 @@ type CompleteRule struct {
 	// DefaultPolicyRule is true when the it's the default egress or ingress rule in policy.
 	DefaultPolicyRule bool
 
+	// MonitorOnly is true when the policy in monitor mode, rules only count matching traffic.
+	MonitorOnly bool
+
+	// Logging is true when traffic matching the rule should be logged.
+	Logging bool
+
 	// SrcGroups is a map of groupName and revision. Revision is used to determine whether
 	// a patch has been executed for this group.
 	SrcGroups map[string]int32
@@ -74,6 +80,11 @@ type RulePort struct {
 
 	// Protocol should set "" if want match all protocol.
 	Protocol securityv1alpha1.Protocol
+
+	// ICMPType is the icmp type to match, nil matches all icmp types. Only valid for ICMP protocol.
+	ICMPType *int32
+	// ICMPCode is the icmp code to match, nil matches all icmp codes. Only valid for ICMP protocol.
+	ICMPCode *int32
 }
 
 // ListRules return a list of security.lynx.smartx.com/v1alpha1 PolicyRule
@@ -119,7 +130,11 @@ func (rule *CompleteRule) generateRule(srcIPBlock, dstIPBlock string, direction
 			IpProtocol:        string(port.Protocol),
 			SrcPort:           port.SrcPort,
 			DstPort:           port.DstPort,
+			IcmpType:          port.ICMPType,
+			IcmpCode:          port.ICMPCode,
 			Action:            rule.Action,
+			MonitorOnly:       rule.MonitorOnly,
+			Logging:           rule.Logging,
 		},
 	}
 
@@ -132,6 +147,9 @@ func (rule *CompleteRule) generateRule(srcIPBlock, dstIPBlock string, direction
 	policyRule.Labels = map[string]string{
 		lynxctrl.OwnerPolicyLabel: policyName,
 	}
+	policyRule.Annotations = map[string]string{
+		lynxctrl.OwnerRuleAnnotation: ruleName,
+	}
 
 	return policyRule
 }
@@ -19,6 +19,7 @@ package policy
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"sync"
 	"time"
 
@@ -43,6 +44,7 @@ import (
 	policyv1alpha1 "github.com/smartxworks/lynx/pkg/apis/policyrule/v1alpha1"
 	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
 	lynxctrl "github.com/smartxworks/lynx/pkg/controller"
+	groupctrl "github.com/smartxworks/lynx/pkg/controller/group"
 	policycache "github.com/smartxworks/lynx/pkg/controller/policy/cache"
 )
 
@@ -276,7 +278,7 @@ func (r *PolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
-	return nil
+	return r.setupPolicyStatusController(mgr)
 }
 
 func (r *PolicyReconciler) addPatch(e event.CreateEvent, q workqueue.RateLimitingInterface) {
@@ -380,6 +382,12 @@ func (r *PolicyReconciler) processPolicyUpdate(ctx context.Context, policy *secu
 	// start a force full synchronization of policyrule
 	r.syncPolicyRulesUntilSuccess(ctx, oldRuleList, newRuleList)
 
+	// policyrules of current generation have been synced, update observed generation
+	err = r.syncPolicyStatus(ctx, policy, policy.Generation)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	return ctrl.Result{}, nil
 }
 
@@ -406,35 +414,58 @@ func (r *PolicyReconciler) calculateExpectedPolicyRules(policy *securityv1alpha1
 }
 
 func (r *PolicyReconciler) completePolicy(policy *securityv1alpha1.SecurityPolicy) ([]*policycache.CompleteRule, error) {
+	return CompletePolicy(r.Client, r.groupCache, policy)
+}
+
+// CompletePolicy flattens the policy into CompleteRules, members of EndpointGroups are read from
+// the groupCache, and Endpoints referenced by the policy are read from the reader. Policies in a
+// tenant namespace only reference EndpointGroups and Endpoints in the same namespace.
+func CompletePolicy(reader client.Reader, groupCache *policycache.GroupCache, policy *securityv1alpha1.SecurityPolicy) ([]*policycache.CompleteRule, error) {
 	var completeRules []*policycache.CompleteRule
+	var monitorOnly = policy.Spec.EnforcementMode == securityv1alpha1.EnforcementModeMonitor
+	var namespace = policy.Labels[lynxctrl.NamespaceLabel]
+	var ingressLimited, egressLimited = policyDirections(policy)
+
+	if namespace != "" {
+		// policies of tenants must in tenant tiers, which always evaluated after the other tiers
+		if err := checkTenantTier(reader, policy.Spec.Tier); err != nil {
+			return nil, fmt.Errorf("policy in namespace %s: %s", namespace, err)
+		}
+	}
 
 	appliedToPeer := securityv1alpha1.SecurityPolicyPeer{
-		IPBlocks:       nil,
-		EndpointGroups: policy.Spec.AppliedTo.EndpointGroups,
-		Endpoints:      policy.Spec.AppliedTo.Endpoints,
+		IPBlocks:         nil,
+		EndpointGroups:   policy.Spec.AppliedTo.EndpointGroups,
+		Endpoints:        policy.Spec.AppliedTo.Endpoints,
+		EndpointSelector: policy.Spec.AppliedTo.EndpointSelector,
 	}
-	appliedGroups, appliedIPBlocks, err := r.getPeerGroupsAndIPBlocks(&appliedToPeer)
+	appliedGroups, appliedIPBlocks, err := getPeerGroupsAndIPBlocks(reader, groupCache, namespace, &appliedToPeer)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, rule := range policy.Spec.IngressRules {
+		if !ingressLimited {
+			break
+		}
 		ingressRule := &policycache.CompleteRule{
 			RuleID:        fmt.Sprintf("%s/%s.%s", policy.Name, "ingress", rule.Name),
 			Priority:      policy.Spec.Priority,
 			Tier:          policy.Spec.Tier,
-			Action:        policyv1alpha1.RuleActionAllow,
+			Action:        getRuleAction(rule.Action),
 			Direction:     policyv1alpha1.RuleDirectionIn,
 			SymmetricMode: policy.Spec.SymmetricMode,
+			MonitorOnly:   monitorOnly,
+			Logging:       rule.Logging,
 			DstGroups:     policycache.DeepCopyMap(appliedGroups).(map[string]int32),
 			DstIPBlocks:   policycache.DeepCopyMap(appliedIPBlocks).(map[string]int),
 		}
 
-		if len(rule.From.IPBlocks)+len(rule.From.EndpointGroups) == 0 {
+		if isEmptyPeer(&rule.From) {
 			// empty From matches all sources
 			ingressRule.SrcIPBlocks = map[string]int{"": 1}
 		} else {
-			ingressRule.SrcGroups, ingressRule.SrcIPBlocks, err = r.getPeerGroupsAndIPBlocks(&rule.From)
+			ingressRule.SrcGroups, ingressRule.SrcIPBlocks, err = getPeerGroupsAndIPBlocks(reader, groupCache, namespace, &rule.From)
 			if err != nil {
 				return nil, err
 			}
@@ -454,22 +485,27 @@ func (r *PolicyReconciler) completePolicy(policy *securityv1alpha1.SecurityPolic
 	}
 
 	for _, rule := range policy.Spec.EgressRules {
+		if !egressLimited {
+			break
+		}
 		egressRule := &policycache.CompleteRule{
 			RuleID:        fmt.Sprintf("%s/%s.%s", policy.Name, "egress", rule.Name),
 			Priority:      policy.Spec.Priority,
 			Tier:          policy.Spec.Tier,
-			Action:        policyv1alpha1.RuleActionAllow,
+			Action:        getRuleAction(rule.Action),
 			Direction:     policyv1alpha1.RuleDirectionOut,
 			SymmetricMode: policy.Spec.SymmetricMode,
+			MonitorOnly:   monitorOnly,
+			Logging:       rule.Logging,
 			SrcGroups:     policycache.DeepCopyMap(appliedGroups).(map[string]int32),
 			SrcIPBlocks:   policycache.DeepCopyMap(appliedIPBlocks).(map[string]int),
 		}
 
-		if len(rule.To.IPBlocks)+len(rule.To.EndpointGroups) == 0 {
+		if isEmptyPeer(&rule.To) {
 			// empty From matches all sources
 			egressRule.DstIPBlocks = map[string]int{"": 1}
 		} else {
-			egressRule.DstGroups, egressRule.DstIPBlocks, err = r.getPeerGroupsAndIPBlocks(&rule.To)
+			egressRule.DstGroups, egressRule.DstIPBlocks, err = getPeerGroupsAndIPBlocks(reader, groupCache, namespace, &rule.To)
 			if err != nil {
 				return nil, err
 			}
@@ -496,6 +532,7 @@ func (r *PolicyReconciler) completePolicy(policy *securityv1alpha1.SecurityPolic
 		Direction:         policyv1alpha1.RuleDirectionIn,
 		SymmetricMode:     false, // never generate symmetric rule for default rule
 		DefaultPolicyRule: true,
+		MonitorOnly:       monitorOnly,
 		DstGroups:         policycache.DeepCopyMap(appliedGroups).(map[string]int32),
 		DstIPBlocks:       policycache.DeepCopyMap(appliedIPBlocks).(map[string]int),
 		SrcIPBlocks:       map[string]int{"": 1},      // matches all source IP
@@ -510,23 +547,117 @@ func (r *PolicyReconciler) completePolicy(policy *securityv1alpha1.SecurityPolic
 		Direction:         policyv1alpha1.RuleDirectionOut,
 		SymmetricMode:     false, // never generate symmetric rule for default rule
 		DefaultPolicyRule: true,
+		MonitorOnly:       monitorOnly,
 		SrcGroups:         policycache.DeepCopyMap(appliedGroups).(map[string]int32),
 		SrcIPBlocks:       policycache.DeepCopyMap(appliedIPBlocks).(map[string]int),
 		DstIPBlocks:       map[string]int{"": 1},      // matches all destination IP
 		Ports:             []policycache.RulePort{{}}, // has a port matches all ports
 	}
 
-	completeRules = append(completeRules, defaultEgressRule, defaultIngressRule)
+	if egressLimited {
+		completeRules = append(completeRules, defaultEgressRule)
+	}
+	if ingressLimited {
+		completeRules = append(completeRules, defaultIngressRule)
+	}
 	return completeRules, nil
 }
 
+// policyDirections returns the directions of traffic the policy limits, policy without
+// PolicyTypes limits both directions.
+func policyDirections(policy *securityv1alpha1.SecurityPolicy) (ingress bool, egress bool) {
+	if len(policy.Spec.PolicyTypes) == 0 {
+		return true, true
+	}
+	for _, policyType := range policy.Spec.PolicyTypes {
+		switch policyType {
+		case securityv1alpha1.PolicyTypeIngress:
+			ingress = true
+		case securityv1alpha1.PolicyTypeEgress:
+			egress = true
+		}
+	}
+	return ingress, egress
+}
+
+// getRuleAction returns the policy rule action of the security rule, empty action means allow.
+func getRuleAction(action securityv1alpha1.RuleAction) policyv1alpha1.RuleAction {
+	switch action {
+	case securityv1alpha1.RuleActionDrop:
+		return policyv1alpha1.RuleActionDrop
+	case securityv1alpha1.RuleActionReject:
+		return policyv1alpha1.RuleActionReject
+	default:
+		return policyv1alpha1.RuleActionAllow
+	}
+}
+
+// isEmptyPeer returns true if the peer selects nothing, empty peer matches all addresses.
+func isEmptyPeer(peer *securityv1alpha1.SecurityPolicyPeer) bool {
+	return len(peer.IPBlocks)+len(peer.EndpointGroups)+len(peer.Endpoints) == 0 && peer.EndpointSelector == nil
+}
+
+// checkTenantTier returns error if the tier is not a tenant tier.
+func checkTenantTier(reader client.Reader, tierName string) error {
+	var tier securityv1alpha1.Tier
+	if err := reader.Get(context.Background(), k8stypes.NamespacedName{Name: tierName}, &tier); err != nil {
+		return fmt.Errorf("unable to get tier %s: %s", tierName, err)
+	}
+	if tier.Labels[lynxctrl.TenantTierLabel] != "true" {
+		return fmt.Errorf("tier %s is not a tenant tier", tierName)
+	}
+	return nil
+}
+
+// inPolicyNamespace returns true if the object with the labels is in the tenant namespace of the
+// policy, empty namespace for policies could reference objects in all namespaces.
+func inPolicyNamespace(namespace string, objLabels map[string]string) bool {
+	return namespace == "" || objLabels[lynxctrl.NamespaceLabel] == namespace
+}
+
+// policyNamespaceGroups returns the groups in the tenant namespace of the policy, groups not found
+// are returned as well, they are handled the same as groups without members.
+func policyNamespaceGroups(reader client.Reader, namespace string, groupNames []string) (sets.String, error) {
+	var groups = sets.NewString()
+
+	for _, groupName := range groupNames {
+		if namespace == "" {
+			groups.Insert(groupName)
+			continue
+		}
+
+		var group groupv1alpha1.EndpointGroup
+		err := reader.Get(context.Background(), k8stypes.NamespacedName{Name: groupName}, &group)
+		if client.IgnoreNotFound(err) != nil {
+			return nil, fmt.Errorf("unable to get group %s: %s", groupName, err)
+		}
+		if err == nil && !inPolicyNamespace(namespace, group.Labels) {
+			klog.Warningf("ignore group %s out of tenant namespace %s", groupName, namespace)
+			continue
+		}
+		groups.Insert(groupName)
+	}
+
+	return groups, nil
+}
+
 // getPeerGroupsAndIPBlocks get ipBlocks from groups, return unique ipBlock list
-func (r *PolicyReconciler) getPeerGroupsAndIPBlocks(peer *securityv1alpha1.SecurityPolicyPeer) (map[string]int32, map[string]int, error) {
+func getPeerGroupsAndIPBlocks(reader client.Reader, groupCache *policycache.GroupCache, namespace string, peer *securityv1alpha1.SecurityPolicyPeer) (map[string]int32, map[string]int, error) {
 	var groups = make(map[string]int32)
 	var ipBlocks = make(map[string]int)
 
-	for group := range sets.NewString(peer.EndpointGroups...) {
-		revision, ipAddrs, exist := r.groupCache.ListGroupIPBlocks(group)
+	peerGroups, err := policyNamespaceGroups(reader, namespace, peer.EndpointGroups)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if peer.EndpointSelector != nil {
+		// members of the selector are maintained in the group managed by group controller
+		peerGroups.Insert(groupctrl.SelectorGroupName(namespace, peer.EndpointSelector))
+	}
+
+	for group := range peerGroups {
+		revision, ipAddrs, exist := groupCache.ListGroupIPBlocks(group)
 		if !exist {
 			return nil, nil, groupNotFound(fmt.Errorf("group %s members not found", group))
 		}
@@ -538,17 +669,33 @@ func (r *PolicyReconciler) getPeerGroupsAndIPBlocks(peer *securityv1alpha1.Secur
 	}
 
 	for _, ipBlock := range peer.IPBlocks {
-		ipBlocks[fmt.Sprintf("%s/%d", ipBlock.IP, ipBlock.PrefixLength)]++
+		cidr := fmt.Sprintf("%s/%d", ipBlock.IP, ipBlock.PrefixLength)
+		if len(ipBlock.Except) == 0 {
+			ipBlocks[cidr]++
+			continue
+		}
+
+		// split the ipBlock into cidrs not overlap with excepts
+		cidrs, err := policycache.SubtractCIDRs(cidr, ipBlock.Except)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, item := range cidrs {
+			ipBlocks[item]++
+		}
 	}
 
 	for _, ep := range peer.Endpoints {
 		var endpoint securityv1alpha1.Endpoint
 		ctx := context.Background()
-		err := r.Get(ctx, k8stypes.NamespacedName{Name: ep}, &endpoint)
+		err := reader.Get(ctx, k8stypes.NamespacedName{Name: ep}, &endpoint)
 		if client.IgnoreNotFound(err) != nil {
 			klog.Errorf("Failed to get endpoint: %v, error: %v", ep, err)
 			return nil, nil, err
 		}
+		if !inPolicyNamespace(namespace, endpoint.Labels) {
+			continue
+		}
 
 		for _, ip := range endpoint.Status.IPs {
 			ipBlocks[policycache.GetIPCidr(ip)]++
@@ -606,7 +753,7 @@ func (r *PolicyReconciler) compareAndApplyPolicyRulesChanges(ctx context.Context
 
 func ruleIsSame(r1, r2 *policyv1alpha1.PolicyRule) bool {
 	return r1 != nil && r2 != nil &&
-		r1.Name == r2.Name && r1.Spec == r2.Spec
+		r1.Name == r2.Name && reflect.DeepEqual(r1.Spec, r2.Spec)
 }
 
 func flattenPorts(ports []securityv1alpha1.SecurityPolicyPort) ([]policycache.RulePort, error) {
@@ -618,8 +765,12 @@ func flattenPorts(ports []securityv1alpha1.SecurityPolicyPort) ([]policycache.Ru
 			// ignore portrange when Protocol is ICMP
 			portItem := policycache.RulePort{
 				Protocol: port.Protocol,
+				ICMPType: port.ICMPType,
+				ICMPCode: port.ICMPCode,
+			}
+			if !containsRulePort(rulePortList, portItem) {
+				rulePortList = append(rulePortList, portItem)
 			}
-			rulePortMap[portItem] = struct{}{}
 			continue
 		}
 
@@ -648,6 +799,16 @@ func flattenPorts(ports []securityv1alpha1.SecurityPolicyPort) ([]policycache.Ru
 	return rulePortList, nil
 }
 
+// containsRulePort returns true if the port in the list, icmp type and code are compared by value.
+func containsRulePort(ports []policycache.RulePort, port policycache.RulePort) bool {
+	for _, item := range ports {
+		if reflect.DeepEqual(item, port) {
+			return true
+		}
+	}
+	return false
+}
+
 func toRuleMap(ruleList []policyv1alpha1.PolicyRule) map[string]*policyv1alpha1.PolicyRule {
 	var ruleMap = make(map[string]*policyv1alpha1.PolicyRule, len(ruleList))
 	for item, rule := range ruleList {
@@ -28,8 +28,10 @@ import (
 	k8stypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
 	storecache "k8s.io/client-go/tools/cache"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	agentv1alpha1 "github.com/smartxworks/lynx/pkg/apis/agent/v1alpha1"
 	groupv1alpha1 "github.com/smartxworks/lynx/pkg/apis/group/v1alpha1"
 	policyv1alpha1 "github.com/smartxworks/lynx/pkg/apis/policyrule/v1alpha1"
 	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
@@ -122,6 +124,54 @@ var _ = Describe("PolicyController", func() {
 				assertHasPolicyRule(ctx, policy, "Egress", "Drop", "192.168.1.1/32", 0, "", 0, "")
 			})
 
+			When("create an agentinfo host endpoint in applied group", func() {
+				var agentInfo *agentv1alpha1.AgentInfo
+
+				BeforeEach(func() {
+					agentInfo = newTestAgentInfo(ep1)
+
+					By("create agentinfo " + agentInfo.Name)
+					Expect(k8sClient.Create(ctx, agentInfo)).Should(Succeed())
+				})
+				AfterEach(func() {
+					Expect(k8sClient.Delete(ctx, agentInfo)).Should(Succeed())
+				})
+
+				It("should realizing policy on the agent", func() {
+					assertPolicyStatus(ctx, policy, securityv1alpha1.SecurityPolicyRealizing, 0, 1)
+				})
+
+				When("all the policy rules has been enforced on the agent", func() {
+					BeforeEach(func() {
+						assertPolicyRulesNum(ctx, policy, 4)
+						mustEnforcePolicyRules(ctx, policy, agentInfo.Name)
+					})
+
+					It("should realized policy on the agent", func() {
+						assertPolicyStatus(ctx, policy, securityv1alpha1.SecurityPolicyRealized, 1, 1)
+					})
+				})
+
+				When("the policy rules has been enforced on an agent removed from cluster", func() {
+					BeforeEach(func() {
+						assertPolicyRulesNum(ctx, policy, 4)
+						mustEnforcePolicyRules(ctx, policy, "removed-agent")
+					})
+
+					It("should prune statuses of the removed agent", func() {
+						Eventually(func() int {
+							policyRuleList := policyv1alpha1.PolicyRuleList{}
+							Expect(k8sClient.List(ctx, &policyRuleList, client.MatchingLabels{lynxctrl.OwnerPolicyLabel: policy.Name})).Should(Succeed())
+							var agentStatuses int
+							for _, policyRule := range policyRuleList.Items {
+								agentStatuses += len(policyRule.Status.AgentStatuses)
+							}
+							return agentStatuses
+						}, timeout, interval).Should(BeZero())
+					})
+				})
+			})
+
 			When("create a patch add member in applied group", func() {
 				var patch *groupv1alpha1.GroupMembersPatch
 				var addEp *securityv1alpha1.Endpoint
@@ -248,6 +298,103 @@ var _ = Describe("PolicyController", func() {
 					assertHasPolicyRule(ctx, policy, "Ingress", "Allow", "", 0, "192.168.1.1/32", 0, "ICMP")
 				})
 			})
+			When("add an new ingress rule from ipBlock with except", func() {
+				var newRule *securityv1alpha1.Rule
+				var updPolicy *securityv1alpha1.SecurityPolicy
+
+				BeforeEach(func() {
+					newRule = newTestRule(newTestPort("TCP", "22"), "", "")
+					newRule.From.IPBlocks = []securityv1alpha1.IPBlock{{
+						IP:           "10.10.0.0",
+						PrefixLength: 24,
+						Except:       []string{"10.10.0.64/26"},
+					}}
+					updPolicy = policy.DeepCopy()
+					updPolicy.Spec.IngressRules = append(updPolicy.Spec.IngressRules, *newRule)
+
+					By(fmt.Sprintf("update policy %s an new ingress rule %s from ipBlock with except", policy.Name, newRule.Name))
+					mustUpdatePolicy(ctx, updPolicy)
+				})
+				It("should add ingress policy rules from cidrs except the excluded one", func() {
+					assertHasPolicyRule(ctx, policy, "Ingress", "Allow", "10.10.0.0/26", 0, "192.168.1.1/32", 22, "TCP")
+					assertHasPolicyRule(ctx, policy, "Ingress", "Allow", "10.10.0.128/25", 0, "192.168.1.1/32", 22, "TCP")
+				})
+			})
+			When("add an new ingress rule with drop action", func() {
+				var newRule *securityv1alpha1.Rule
+				var updPolicy *securityv1alpha1.SecurityPolicy
+
+				BeforeEach(func() {
+					newRule = newTestRule(newTestPort("TCP", "3389"), "", "")
+					newRule.Action = securityv1alpha1.RuleActionDrop
+					updPolicy = policy.DeepCopy()
+					updPolicy.Spec.IngressRules = append(updPolicy.Spec.IngressRules, *newRule)
+
+					By(fmt.Sprintf("update policy %s an new ingress rule %s with drop action", policy.Name, newRule.Name))
+					mustUpdatePolicy(ctx, updPolicy)
+				})
+				It("should add an ingress policy rule drop the traffic", func() {
+					assertHasPolicyRule(ctx, policy, "Ingress", "Drop", "", 0, "192.168.1.1/32", 3389, "TCP")
+				})
+			})
+			When("add an new ingress rule with logging enabled", func() {
+				var newRule *securityv1alpha1.Rule
+				var updPolicy *securityv1alpha1.SecurityPolicy
+
+				BeforeEach(func() {
+					newRule = newTestRule(newTestPort("TCP", "8443"), "", "")
+					newRule.Logging = true
+					updPolicy = policy.DeepCopy()
+					updPolicy.Spec.IngressRules = append(updPolicy.Spec.IngressRules, *newRule)
+
+					By(fmt.Sprintf("update policy %s an new ingress rule %s with logging enabled", policy.Name, newRule.Name))
+					mustUpdatePolicy(ctx, updPolicy)
+				})
+				It("should add an ingress policy rule with logging and owner rule", func() {
+					Eventually(func() bool {
+						var policyRuleList = policyv1alpha1.PolicyRuleList{}
+						Expect(k8sClient.List(ctx, &policyRuleList, client.MatchingLabels{lynxctrl.OwnerPolicyLabel: policy.Name})).Should(Succeed())
+
+						for _, rule := range policyRuleList.Items {
+							if rule.Spec.DstPort == 8443 && rule.Spec.Logging &&
+								rule.Annotations[lynxctrl.OwnerRuleAnnotation] == fmt.Sprintf("ingress.%s", newRule.Name) {
+								return true
+							}
+						}
+						return false
+					}, timeout, interval).Should(BeTrue())
+				})
+			})
+			When("add an new ingress rule with icmp type and code", func() {
+				var newRule *securityv1alpha1.Rule
+				var updPolicy *securityv1alpha1.SecurityPolicy
+
+				BeforeEach(func() {
+					port := newTestPort("ICMP", "")
+					port.ICMPType, port.ICMPCode = pointer.Int32Ptr(3), pointer.Int32Ptr(4)
+					newRule = newTestRule(port, "", "")
+					updPolicy = policy.DeepCopy()
+					updPolicy.Spec.IngressRules = append(updPolicy.Spec.IngressRules, *newRule)
+
+					By(fmt.Sprintf("update policy %s an new ingress rule %s with icmp type and code", policy.Name, newRule.Name))
+					mustUpdatePolicy(ctx, updPolicy)
+				})
+				It("should add an ingress policy rule with icmp type and code", func() {
+					Eventually(func() bool {
+						var policyRuleList = policyv1alpha1.PolicyRuleList{}
+						Expect(k8sClient.List(ctx, &policyRuleList, client.MatchingLabels{lynxctrl.OwnerPolicyLabel: policy.Name})).Should(Succeed())
+
+						for _, rule := range policyRuleList.Items {
+							if rule.Spec.IpProtocol == "ICMP" && rule.Spec.Direction == "Ingress" &&
+								rule.Spec.IcmpType != nil && *rule.Spec.IcmpType == 3 &&
+								rule.Spec.IcmpCode != nil && *rule.Spec.IcmpCode == 4 {
+								return true
+							}
+						}
+						return false
+					}, timeout, interval).Should(BeTrue())
+				})
+			})
 			When("remove all egress rules", func() {
 				var updPolicy *securityv1alpha1.SecurityPolicy
 
@@ -343,6 +490,22 @@ var _ = Describe("PolicyController", func() {
 					assertHasPolicyRule(ctx, policy, "Ingress", "Allow", "192.168.2.1/32", 0, "192.168.1.1/32", 22, "UDP")
 				})
 			})
+			When("update ingress protocol to sctp", func() {
+				var updPolicy *securityv1alpha1.SecurityPolicy
+
+				BeforeEach(func() {
+					protocol := securityv1alpha1.ProtocolSCTP
+					updPolicy = policy.DeepCopy()
+					updPolicy.Spec.IngressRules[0].Ports[0].Protocol = protocol
+
+					By(fmt.Sprintf("update policy %s ingress rule with new protocol %s", policy.Name, protocol))
+					mustUpdatePolicy(ctx, updPolicy)
+				})
+				It("should replace ingress policy rule protocol", func() {
+					assertNoPolicyRule(ctx, policy, "Ingress", "Allow", "192.168.2.1/32", 0, "192.168.1.1/32", 22, "TCP")
+					assertHasPolicyRule(ctx, policy, "Ingress", "Allow", "192.168.2.1/32", 0, "192.168.1.1/32", 22, "SCTP")
+				})
+			})
 			When("update egress portrange", func() {
 				var updPolicy *securityv1alpha1.SecurityPolicy
 
@@ -362,6 +525,30 @@ var _ = Describe("PolicyController", func() {
 				})
 			})
 
+			When("update policy to monitor mode", func() {
+				var updPolicy *securityv1alpha1.SecurityPolicy
+
+				BeforeEach(func() {
+					updPolicy = policy.DeepCopy()
+					updPolicy.Spec.EnforcementMode = securityv1alpha1.EnforcementModeMonitor
+
+					By(fmt.Sprintf("update policy %s to monitor mode", policy.Name))
+					mustUpdatePolicy(ctx, updPolicy)
+				})
+				It("should replace all policy rules with monitor only rules", func() {
+					Eventually(func() bool {
+						var policyRuleList = policyv1alpha1.PolicyRuleList{}
+						Expect(k8sClient.List(ctx, &policyRuleList, client.MatchingLabels{lynxctrl.OwnerPolicyLabel: policy.Name})).Should(Succeed())
+
+						for _, rule := range policyRuleList.Items {
+							if !rule.Spec.MonitorOnly {
+								return false
+							}
+						}
+						return len(policyRuleList.Items) != 0
+					}, timeout, interval).Should(BeTrue())
+				})
+			})
 			When("remove security policy", func() {
 				BeforeEach(func() {
 					Expect(k8sClient.Delete(ctx, policy)).Should(Succeed())
@@ -642,6 +829,31 @@ func newTestEndpoint(ip types.IPAddress) *securityv1alpha1.Endpoint {
 	}
 }
 
+func newTestAgentInfo(endpoints ...*securityv1alpha1.Endpoint) *agentv1alpha1.AgentInfo {
+	name := "agent-test-" + rand.String(6)
+
+	var ifaces []agentv1alpha1.OVSInterface
+	for _, ep := range endpoints {
+		ifaces = append(ifaces, agentv1alpha1.OVSInterface{
+			Name:        ep.Name,
+			ExternalIDs: map[string]string{ep.Spec.Reference.ExternalIDName: ep.Spec.Reference.ExternalIDValue},
+		})
+	}
+
+	return &agentv1alpha1.AgentInfo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{TestLabelKey: TestLabelValue},
+		},
+		OVSInfo: agentv1alpha1.OVSInfo{
+			Bridges: []agentv1alpha1.OVSBridge{{
+				Name:  "bridge-test",
+				Ports: []agentv1alpha1.OVSPort{{Name: "port-test", Interfaces: ifaces}},
+			}},
+		},
+	}
+}
+
 func newTestGroupMembers(revision int32, members ...*groupv1alpha1.GroupMember) *groupv1alpha1.GroupMembers {
 	name := "members-test-" + rand.String(6)
 
@@ -783,3 +995,40 @@ func assertPolicyRulesNum(ctx context.Context, policy *securityv1alpha1.Security
 		return len(policyRuleList.Items)
 	}, timeout, interval).Should(Equal(numOfPolicyRules))
 }
+
+func mustEnforcePolicyRules(ctx context.Context, policy *securityv1alpha1.SecurityPolicy, agentName string) {
+	Eventually(func() error {
+		policyRuleList := policyv1alpha1.PolicyRuleList{}
+		err := k8sClient.List(ctx, &policyRuleList, client.MatchingLabels{lynxctrl.OwnerPolicyLabel: policy.Name})
+		if err != nil {
+			return err
+		}
+		for item := range policyRuleList.Items {
+			policyRule := policyRuleList.Items[item]
+			policyRule.Status.EnforceState = policyv1alpha1.PolicyRuleSuccessEnforced
+			policyRule.Status.AgentStatuses = []policyv1alpha1.AgentPolicyRuleStatus{{
+				AgentName:    agentName,
+				EnforceState: policyv1alpha1.PolicyRuleSuccessEnforced,
+			}}
+			if err = k8sClient.Status().Update(ctx, &policyRule); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, timeout, interval).Should(Succeed())
+}
+
+func assertPolicyStatus(ctx context.Context, policy *securityv1alpha1.SecurityPolicy,
+	phase securityv1alpha1.SecurityPolicyPhase, currentAgentsRealized, desiredAgentsRealized int32) {
+	Eventually(func() securityv1alpha1.SecurityPolicyStatus {
+		var currentPolicy securityv1alpha1.SecurityPolicy
+		Expect(k8sClient.Get(ctx, k8stypes.NamespacedName{Name: policy.Name}, &currentPolicy)).Should(Succeed())
+		// ignore observed generation in assert
+		currentPolicy.Status.ObservedGeneration = 0
+		return currentPolicy.Status
+	}, timeout, interval).Should(Equal(securityv1alpha1.SecurityPolicyStatus{
+		Phase:                 phase,
+		CurrentAgentsRealized: currentAgentsRealized,
+		DesiredAgentsRealized: desiredAgentsRealized,
+	}))
+}
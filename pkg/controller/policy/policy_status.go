@@ -0,0 +1,312 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	agentv1alpha1 "github.com/smartxworks/lynx/pkg/apis/agent/v1alpha1"
+	policyv1alpha1 "github.com/smartxworks/lynx/pkg/apis/policyrule/v1alpha1"
+	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	lynxctrl "github.com/smartxworks/lynx/pkg/controller"
+	groupctrl "github.com/smartxworks/lynx/pkg/controller/group"
+	ctrltypes "github.com/smartxworks/lynx/pkg/controller/types"
+)
+
+// ReconcilePolicyStatus calculates the realization of policy from the enforce
+// state of PolicyRules reported by agents, and updates it into policy status.
+func (r *PolicyReconciler) ReconcilePolicyStatus(req ctrl.Request) (ctrl.Result, error) {
+	var policy securityv1alpha1.SecurityPolicy
+	var ctx = context.Background()
+
+	err := r.Get(ctx, req.NamespacedName, &policy)
+	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if r.isNewPolicy(&policy) || r.isDeletingPolicy(&policy) {
+		// new policy would be requeue after finalizer added, and status of deleting policy is useless
+		return ctrl.Result{}, nil
+	}
+
+	err = r.pruneStaleAgentStatuses(ctx, &policy)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.syncPolicyStatus(ctx, &policy, policy.Status.ObservedGeneration)
+	return ctrl.Result{}, err
+}
+
+// pruneStaleAgentStatuses removes statuses reported by agents no longer exist from the policy
+// PolicyRules, agents could not remove their statuses after they have been removed from cluster.
+func (r *PolicyReconciler) pruneStaleAgentStatuses(ctx context.Context, policy *securityv1alpha1.SecurityPolicy) error {
+	var agentInfoList agentv1alpha1.AgentInfoList
+	if err := r.List(ctx, &agentInfoList); err != nil {
+		return fmt.Errorf("failed fetch agentinfos: %s", err)
+	}
+	var agents = sets.NewString()
+	for _, agentInfo := range agentInfoList.Items {
+		agents.Insert(agentInfo.Name)
+	}
+
+	var policyRuleList policyv1alpha1.PolicyRuleList
+	err := r.List(ctx, &policyRuleList, client.MatchingLabels{lynxctrl.OwnerPolicyLabel: policy.Name})
+	if err != nil {
+		return fmt.Errorf("failed fetch policy %s rules: %s", policy.Name, err)
+	}
+
+	for i := range policyRuleList.Items {
+		policyRule := &policyRuleList.Items[i]
+		if !policyRule.Status.RemoveAgentStatuses(func(agentName string) bool { return !agents.Has(agentName) }) {
+			continue
+		}
+		// conflict would retry with the latest PolicyRule in the next reconcile
+		if err = r.Status().Update(ctx, policyRule); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed prune policyRule %s agent statuses: %s", policyRule.Name, err)
+		}
+		klog.Infof("stale agent statuses of policyRule %s have been pruned", policyRule.Name)
+	}
+
+	return nil
+}
+
+func (r *PolicyReconciler) setupPolicyStatusController(mgr ctrl.Manager) error {
+	statusController, err := controller.New("policy-status-controller", mgr, controller.Options{
+		MaxConcurrentReconciles: lynxctrl.DefaultMaxConcurrentReconciles,
+		Reconciler:              reconcile.Func(r.ReconcilePolicyStatus),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = statusController.Watch(&source.Kind{Type: &securityv1alpha1.SecurityPolicy{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// enqueue the owner policy when agents report PolicyRule enforce state
+	err = statusController.Watch(&source.Kind{Type: &policyv1alpha1.PolicyRule{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(object handler.MapObject) []reconcile.Request {
+			policyName, ok := object.Meta.GetLabels()[lynxctrl.OwnerPolicyLabel]
+			if !ok {
+				return nil
+			}
+			return []reconcile.Request{{NamespacedName: k8stypes.NamespacedName{Name: policyName}}}
+		}),
+	})
+	if err != nil {
+		return err
+	}
+
+	// endpoints location may change when agentinfo changes, recalculate all policies desired agents
+	return statusController.Watch(&source.Kind{Type: &agentv1alpha1.AgentInfo{}}, &handler.Funcs{
+		CreateFunc: func(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			r.enqueueAllPolicies(q)
+		},
+		UpdateFunc: func(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			oldAgentInfo, oldOK := e.ObjectOld.(*agentv1alpha1.AgentInfo)
+			newAgentInfo, newOK := e.ObjectNew.(*agentv1alpha1.AgentInfo)
+			if oldOK && newOK && reflect.DeepEqual(oldAgentInfo.OVSInfo, newAgentInfo.OVSInfo) {
+				// ignore agentinfo heartbeat
+				return
+			}
+			r.enqueueAllPolicies(q)
+		},
+		DeleteFunc: func(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+			r.enqueueAllPolicies(q)
+		},
+	})
+}
+
+func (r *PolicyReconciler) enqueueAllPolicies(q workqueue.RateLimitingInterface) {
+	var policyList securityv1alpha1.SecurityPolicyList
+
+	err := r.List(context.Background(), &policyList)
+	if err != nil {
+		klog.Errorf("failed fetch policyList, error: %s", err)
+		return
+	}
+
+	for _, policy := range policyList.Items {
+		q.Add(ctrl.Request{NamespacedName: k8stypes.NamespacedName{
+			Namespace: policy.Namespace,
+			Name:      policy.Name,
+		}})
+	}
+}
+
+// syncPolicyStatus calculates and updates the policy status, observedGeneration is the
+// generation of policy which PolicyRules have been synced.
+func (r *PolicyReconciler) syncPolicyStatus(ctx context.Context, policy *securityv1alpha1.SecurityPolicy, observedGeneration int64) error {
+	status, err := r.calculatePolicyStatus(ctx, policy, observedGeneration)
+	if err != nil {
+		klog.Errorf("failed calculate policy %s status: %s", policy.Name, err)
+		return err
+	}
+
+	if policy.Status == *status {
+		return nil
+	}
+
+	policy.Status = *status
+	err = r.Status().Update(ctx, policy)
+	if err != nil {
+		klog.Errorf("failed update policy %s status: %s", policy.Name, err)
+		return err
+	}
+	klog.V(2).Infof("policy %s status has been update to %+v", policy.Name, policy.Status)
+
+	return nil
+}
+
+func (r *PolicyReconciler) calculatePolicyStatus(ctx context.Context, policy *securityv1alpha1.SecurityPolicy, observedGeneration int64) (*securityv1alpha1.SecurityPolicyStatus, error) {
+	var status = &securityv1alpha1.SecurityPolicyStatus{
+		ObservedGeneration: observedGeneration,
+	}
+
+	desiredAgents, err := r.getPolicyDesiredAgents(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+	status.DesiredAgentsRealized = int32(desiredAgents.Len())
+
+	if observedGeneration != policy.Generation {
+		// PolicyRules of the latest generation have not been generated
+		status.Phase = securityv1alpha1.SecurityPolicyPending
+		return status, nil
+	}
+
+	var policyRuleList policyv1alpha1.PolicyRuleList
+	err = r.List(ctx, &policyRuleList, client.MatchingLabels{lynxctrl.OwnerPolicyLabel: policy.Name})
+	if err != nil {
+		return nil, fmt.Errorf("failed fetch policy %s rules: %s", policy.Name, err)
+	}
+
+	// agent has realized the policy only if all the policy rules has been enforced on the agent
+	realizedAgents := sets.NewString(desiredAgents.UnsortedList()...)
+	for _, policyRule := range policyRuleList.Items {
+		realizedAgents = realizedAgents.Intersection(enforcedAgents(&policyRule))
+	}
+	status.CurrentAgentsRealized = int32(realizedAgents.Len())
+
+	if status.CurrentAgentsRealized == status.DesiredAgentsRealized {
+		status.Phase = securityv1alpha1.SecurityPolicyRealized
+	} else {
+		status.Phase = securityv1alpha1.SecurityPolicyRealizing
+	}
+
+	return status, nil
+}
+
+// getPolicyDesiredAgents returns agents which host endpoints the policy applied to.
+func (r *PolicyReconciler) getPolicyDesiredAgents(ctx context.Context, policy *securityv1alpha1.SecurityPolicy) (sets.String, error) {
+	var endpointIDs []ctrltypes.ExternalID
+	var desiredAgents = sets.NewString()
+
+	var namespace = policy.Labels[lynxctrl.NamespaceLabel]
+
+	appliedGroups, err := policyNamespaceGroups(r, namespace, policy.Spec.AppliedTo.EndpointGroups)
+	if err != nil {
+		return nil, err
+	}
+	if policy.Spec.AppliedTo.EndpointSelector != nil {
+		appliedGroups.Insert(groupctrl.SelectorGroupName(namespace, policy.Spec.AppliedTo.EndpointSelector))
+	}
+
+	for group := range appliedGroups {
+		// group not found means it has no members yet
+		members, _ := r.groupCache.ListGroupEndpoints(group)
+		for _, member := range members {
+			endpointIDs = append(endpointIDs, ctrltypes.ExternalID{
+				Name:  member.ExternalIDName,
+				Value: member.ExternalIDValue,
+			})
+		}
+	}
+
+	for _, ep := range policy.Spec.AppliedTo.Endpoints {
+		var endpoint securityv1alpha1.Endpoint
+		err := r.Get(ctx, k8stypes.NamespacedName{Name: ep}, &endpoint)
+		if client.IgnoreNotFound(err) != nil {
+			return nil, fmt.Errorf("failed fetch endpoint %s: %s", ep, err)
+		}
+		if err == nil && inPolicyNamespace(namespace, endpoint.Labels) {
+			endpointIDs = append(endpointIDs, ctrltypes.ExternalID{
+				Name:  endpoint.Spec.Reference.ExternalIDName,
+				Value: endpoint.Spec.Reference.ExternalIDValue,
+			})
+		}
+	}
+
+	if len(endpointIDs) == 0 {
+		return desiredAgents, nil
+	}
+
+	var agentInfoList agentv1alpha1.AgentInfoList
+	err = r.List(ctx, &agentInfoList)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetch agentinfos: %s", err)
+	}
+
+	for _, agentInfo := range agentInfoList.Items {
+		if agentHostEndpoints(&agentInfo, endpointIDs) {
+			desiredAgents.Insert(agentInfo.Name)
+		}
+	}
+
+	return desiredAgents, nil
+}
+
+// agentHostEndpoints returns true if any of the endpoints located on the agent.
+func agentHostEndpoints(agentInfo *agentv1alpha1.AgentInfo, endpointIDs []ctrltypes.ExternalID) bool {
+	for _, bridge := range agentInfo.OVSInfo.Bridges {
+		for _, port := range bridge.Ports {
+			for _, id := range endpointIDs {
+				if _, matches := id.MatchIface(port.Interfaces); matches {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// enforcedAgents returns agents which have enforced the PolicyRule successfully.
+func enforcedAgents(policyRule *policyv1alpha1.PolicyRule) sets.String {
+	var agents = sets.NewString()
+	for _, agentStatus := range policyRule.Status.AgentStatuses {
+		if agentStatus.EnforceState == policyv1alpha1.PolicyRuleSuccessEnforced {
+			agents.Insert(agentStatus.AgentName)
+		}
+	}
+	return agents
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package traceflow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	agentv1alpha1 "github.com/smartxworks/lynx/pkg/apis/agent/v1alpha1"
+	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	lynxctrl "github.com/smartxworks/lynx/pkg/controller"
+)
+
+const (
+	// traceflowTimeout is how long the traceflow could be pending or running before failed.
+	traceflowTimeout = 2 * time.Minute
+	// pendingRetryInterval is the interval of retrying assign the pending traceflow.
+	pendingRetryInterval = 5 * time.Second
+)
+
+// TraceflowReconciler assigns traceflows to the agent hosting the source endpoint, and fails
+// traceflows not completed in time.
+type TraceflowReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// Reconcile receive traceflow from work queue, assign it to the agent or fail it if timeout.
+func (r *TraceflowReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	klog.V(2).Infof("TraceflowReconciler received traceflow %s reconcile", req.NamespacedName)
+
+	traceflow := securityv1alpha1.Traceflow{}
+	if err := r.Get(ctx, req.NamespacedName, &traceflow); err != nil {
+		klog.Errorf("unable to fetch traceflow %s: %s", req.Name, err.Error())
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	switch traceflow.Status.Phase {
+	case securityv1alpha1.TraceflowSucceeded, securityv1alpha1.TraceflowFailed:
+		return ctrl.Result{}, nil
+	}
+
+	if expire := traceflow.CreationTimestamp.Add(traceflowTimeout); time.Now().After(expire) {
+		reason := fmt.Sprintf("traceflow not completed in %s", traceflowTimeout)
+		return ctrl.Result{}, r.updateStatus(ctx, &traceflow, securityv1alpha1.TraceflowFailed, traceflow.Status.Agent, reason)
+	}
+	timeoutAfter := time.Until(traceflow.CreationTimestamp.Add(traceflowTimeout))
+
+	if traceflow.Status.Phase == securityv1alpha1.TraceflowRunning {
+		// the agent is tracing the packet, check timeout later
+		return ctrl.Result{RequeueAfter: timeoutAfter}, nil
+	}
+
+	var source securityv1alpha1.Endpoint
+	err := r.Get(ctx, k8stypes.NamespacedName{Name: traceflow.Spec.Source}, &source)
+	if errors.IsNotFound(err) {
+		reason := fmt.Sprintf("source endpoint %s not found", traceflow.Spec.Source)
+		return ctrl.Result{}, r.updateStatus(ctx, &traceflow, securityv1alpha1.TraceflowFailed, "", reason)
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	agentName, err := r.findAgentOfEndpoint(ctx, &source)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if agentName == "" {
+		reason := fmt.Sprintf("source endpoint %s not found on any agent", source.Name)
+		if err = r.updateStatus(ctx, &traceflow, securityv1alpha1.TraceflowPending, "", reason); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: pendingRetryInterval}, nil
+	}
+
+	klog.Infof("assign traceflow %s to agent %s", traceflow.Name, agentName)
+	if err = r.updateStatus(ctx, &traceflow, securityv1alpha1.TraceflowRunning, agentName, ""); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: timeoutAfter}, nil
+}
+
+// SetupWithManager create and add Traceflow Controller to the manager.
+func (r *TraceflowReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if mgr == nil {
+		return fmt.Errorf("can't setup with nil manager")
+	}
+
+	c, err := controller.New("traceflow-controller", mgr, controller.Options{
+		MaxConcurrentReconciles: lynxctrl.DefaultMaxConcurrentReconciles,
+		Reconciler:              r,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.Watch(&source.Kind{Type: &securityv1alpha1.Traceflow{}}, &handler.EnqueueRequestForObject{})
+}
+
+// findAgentOfEndpoint returns name of the agent has the interface of the endpoint, returns empty
+// if the endpoint not found on any agent.
+func (r *TraceflowReconciler) findAgentOfEndpoint(ctx context.Context, endpoint *securityv1alpha1.Endpoint) (string, error) {
+	var agentInfoList agentv1alpha1.AgentInfoList
+	if err := r.List(ctx, &agentInfoList); err != nil {
+		return "", fmt.Errorf("unable list agentinfos: %s", err)
+	}
+
+	reference := endpoint.Spec.Reference
+	for _, agentInfo := range agentInfoList.Items {
+		for _, bridge := range agentInfo.OVSInfo.Bridges {
+			for _, port := range bridge.Ports {
+				for _, iface := range port.Interfaces {
+					if value, ok := iface.ExternalIDs[reference.ExternalIDName]; ok && value == reference.ExternalIDValue {
+						return agentInfo.Name, nil
+					}
+				}
+			}
+		}
+	}
+
+	return "", nil
+}
+
+func (r *TraceflowReconciler) updateStatus(ctx context.Context, traceflow *securityv1alpha1.Traceflow,
+	phase securityv1alpha1.TraceflowPhase, agentName, reason string) error {
+	if traceflow.Status.Phase == phase && traceflow.Status.Agent == agentName && traceflow.Status.Reason == reason {
+		return nil
+	}
+
+	traceflow.Status.Phase = phase
+	traceflow.Status.Agent = agentName
+	traceflow.Status.Reason = reason
+	if err := r.Status().Update(ctx, traceflow); err != nil {
+		return fmt.Errorf("unable update traceflow %s status: %s", traceflow.Name, err)
+	}
+	return nil
+}
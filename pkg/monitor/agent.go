@@ -47,6 +47,8 @@ import (
 const (
 	AgentNameConfigPath   = "/var/lib/lynx/agent/name"
 	LocalEndpointIdentity = "attached-mac"
+
+	openflowStatusCheckInterval = 5 * time.Second
 )
 
 type ovsdbEventHandler interface {
@@ -71,6 +73,18 @@ func (handler OvsdbEventHandlerFuncs) DeleteLocalEndpoint(portNo uint32) {
 	}
 }
 
+// OpenflowStatusFunc returns whether the openflow connection is up, and the reason if not.
+type OpenflowStatusFunc func() (bool, string)
+
+// RegisterOpenflowStatusFunc registers the func used to report the OpenflowConnectionUp condition.
+func (monitor *agentMonitor) RegisterOpenflowStatusFunc(openflowStatusFunc OpenflowStatusFunc) {
+	if openflowStatusFunc == nil {
+		klog.Fatalf("Failed to register openflowStatusFunc: register nil openflowStatusFunc not allow")
+	}
+
+	monitor.openflowStatusFunc = openflowStatusFunc
+}
+
 func (monitor *agentMonitor) RegisterOvsdbEventHandler(ovsdbEventHandler ovsdbEventHandler) {
 	if ovsdbEventHandler == nil {
 		klog.Fatalf("Failed to register ovsdbEventHandler: register nil ovsdbEventHandler not allow")
@@ -101,6 +115,12 @@ type agentMonitor struct {
 	ovsdbEventHandler              ovsdbEventHandler
 	localEndpointHardwareAddrCache sets.String
 
+	// openflowStatusFunc used to check openflow connection status, openflowConnectionUp is the
+	// last observed status, agentinfo would be synchronized when it changes.
+	openflowStatusFunc   OpenflowStatusFunc
+	openflowConnectionUp bool
+	openflowReason       string
+
 	// syncQueue used to notify agentMonitor synchronize AgentInfo
 	syncQueue workqueue.RateLimitingInterface
 }
@@ -119,7 +139,7 @@ func NewAgentMonitor(client client.Client, ofPortIPAddressMonitorChan chan map[u
 
 	var err error
 
-	monitor.agentName, err = readOrGenerateAgentName()
+	monitor.agentName, err = ReadOrGenerateAgentName()
 	if err != nil {
 		klog.Errorf("unable get agent name: %s", err)
 		return nil, err
@@ -157,6 +177,7 @@ func (monitor *agentMonitor) Run(stopChan <-chan struct{}) error {
 	go monitor.HandleOfPortIPAddressUpdate(monitor.ofPortIPAddressMonitorChan, stopChan)
 
 	go wait.Until(monitor.syncAgentInfoWorker, 0, stopChan)
+	go wait.Until(monitor.checkOpenflowStatus, openflowStatusCheckInterval, stopChan)
 	<-stopChan
 
 	return nil
@@ -220,6 +241,24 @@ func (monitor *agentMonitor) startOvsdbMonitor() error {
 	return nil
 }
 
+// checkOpenflowStatus notify agentMonitor synchronize AgentInfo when openflow status changes.
+func (monitor *agentMonitor) checkOpenflowStatus() {
+	if monitor.openflowStatusFunc == nil {
+		return
+	}
+
+	connectionUp, reason := monitor.openflowStatusFunc()
+
+	monitor.cacheLock.Lock()
+	defer monitor.cacheLock.Unlock()
+
+	if connectionUp != monitor.openflowConnectionUp || reason != monitor.openflowReason {
+		monitor.openflowConnectionUp = connectionUp
+		monitor.openflowReason = reason
+		monitor.syncQueue.Add(monitor.Name())
+	}
+}
+
 func (monitor *agentMonitor) syncAgentInfoWorker() {
 	item, shutdown := monitor.syncQueue.Get()
 	if shutdown {
@@ -301,6 +340,19 @@ func (monitor *agentMonitor) getAgentInfo() (*agentv1alpha1.AgentInfo, error) {
 	}
 	agentInfo.Conditions = []agentv1alpha1.AgentCondition{agentHealthCondition}
 
+	if monitor.openflowStatusFunc != nil {
+		openflowCondition := agentv1alpha1.AgentCondition{
+			Type:              agentv1alpha1.OpenflowConnectionUp,
+			Status:            corev1.ConditionFalse,
+			LastHeartbeatTime: metav1.NewTime(time.Now()),
+			Message:           monitor.openflowReason,
+		}
+		if monitor.openflowConnectionUp {
+			openflowCondition.Status = corev1.ConditionTrue
+		}
+		agentInfo.Conditions = append(agentInfo.Conditions, openflowCondition)
+	}
+
 	return agentInfo, nil
 }
 
@@ -459,6 +511,45 @@ func (monitor *agentMonitor) handleOvsUpdates(updates ovsdb.TableUpdates) {
 	monitor.syncQueue.Add(monitor.Name())
 }
 
+// GetInterfaceExternalIDs returns external ids of the ovs interface with the ofport, returns nil
+// if the interface not found.
+func (monitor *agentMonitor) GetInterfaceExternalIDs(ofport uint32) map[string]string {
+	return monitor.ListInterfaceExternalIDs()[ofport]
+}
+
+// GetOfportByExternalID returns ofport of the ovs interface has the external id.
+func (monitor *agentMonitor) GetOfportByExternalID(name, value string) (uint32, bool) {
+	for ofport, externalIDs := range monitor.ListInterfaceExternalIDs() {
+		if id, ok := externalIDs[name]; ok && id == value {
+			return ofport, true
+		}
+	}
+	return 0, false
+}
+
+// ListInterfaceExternalIDs returns external ids of ovs interfaces mapping by their ofports.
+func (monitor *agentMonitor) ListInterfaceExternalIDs() map[uint32]map[string]string {
+	monitor.cacheLock.RLock()
+	defer monitor.cacheLock.RUnlock()
+
+	interfaces := make(map[uint32]map[string]string)
+	for _, ovsIface := range monitor.ovsdbCache["Interface"] {
+		ofport, ok := ovsIface.Fields["ofport"].(float64)
+		if !ok || ofport < 0 {
+			continue
+		}
+		externalIDs := make(map[string]string)
+		if ovsExternalIDs, ok := ovsIface.Fields["external_ids"].(ovsdb.OvsMap); ok {
+			for name, value := range ovsExternalIDs.GoMap {
+				externalIDs[name.(string)] = value.(string)
+			}
+		}
+		interfaces[uint32(ofport)] = externalIDs
+	}
+
+	return interfaces
+}
+
 func (monitor *agentMonitor) Name() string {
 	return monitor.agentName
 }
@@ -592,7 +683,9 @@ func listUUID(uuidList interface{}) []ovsdb.UUID {
 	return idList
 }
 
-func readOrGenerateAgentName() (string, error) {
+// ReadOrGenerateAgentName returns the name of this agent, the name would be generated
+// and persisted into AgentNameConfigPath when first called.
+func ReadOrGenerateAgentName() (string, error) {
 	content, err := ioutil.ReadFile(AgentNameConfigPath)
 	if err == nil {
 		return string(content), nil
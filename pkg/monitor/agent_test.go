@@ -68,7 +68,7 @@ func TestMain(m *testing.M) {
 	k8sClient = fake.NewFakeClientWithScheme(scheme.Scheme)
 
 	// return new fake agentname instead of read/write from file
-	gomonkey.ApplyFunc(readOrGenerateAgentName, func() (string, error) {
+	gomonkey.ApplyFunc(ReadOrGenerateAgentName, func() (string, error) {
 		return `unit.test.agent.name`, nil
 	})
 
@@ -66,6 +66,13 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.Tier":                 schema_pkg_apis_security_v1alpha1_Tier(ref),
 		"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.TierList":             schema_pkg_apis_security_v1alpha1_TierList(ref),
 		"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.TierSpec":             schema_pkg_apis_security_v1alpha1_TierSpec(ref),
+		"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.Traceflow":            schema_pkg_apis_security_v1alpha1_Traceflow(ref),
+		"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.TraceflowDestination": schema_pkg_apis_security_v1alpha1_TraceflowDestination(ref),
+		"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.TraceflowList":        schema_pkg_apis_security_v1alpha1_TraceflowList(ref),
+		"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.TraceflowObservation": schema_pkg_apis_security_v1alpha1_TraceflowObservation(ref),
+		"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.TraceflowPacket":      schema_pkg_apis_security_v1alpha1_TraceflowPacket(ref),
+		"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.TraceflowSpec":        schema_pkg_apis_security_v1alpha1_TraceflowSpec(ref),
+		"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.TraceflowStatus":      schema_pkg_apis_security_v1alpha1_TraceflowStatus(ref),
 		"k8s.io/api/apps/v1.ControllerRevision":                                       schema_k8sio_api_apps_v1_ControllerRevision(ref),
 		"k8s.io/api/apps/v1.ControllerRevisionList":                                   schema_k8sio_api_apps_v1_ControllerRevisionList(ref),
 		"k8s.io/api/apps/v1.DaemonSet":                                                schema_k8sio_api_apps_v1_DaemonSet(ref),
@@ -722,6 +729,7 @@ func schema_pkg_apis_group_v1alpha1_EndpointGroup(ref common.ReferenceCallback)
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
+				Description: "EndpointGroup defines a set of Endpoints, selected by labels or listed explicitly.\n\nAn EndpointGroup labeled namespace.label.lynx.smartx.com belongs to the tenant namespace, it only selects Endpoints of the namespace, and tenants could only write EndpointGroups of their namespaces. EndpointGroup is still cluster scoped rather than namespaced: names are shared by all tenants, and RBAC on EndpointGroups is cluster wide, so reading is not isolated between tenants.",
 				Type: []string{"object"},
 				Properties: map[string]spec.Schema{
 					"kind": {
@@ -824,11 +832,52 @@ func schema_pkg_apis_group_v1alpha1_EndpointGroupSpec(ref common.ReferenceCallba
 							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"),
 						},
 					},
+					"endpointGroups": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EndpointGroups are names of other groups, members of the groups are also members of this group. Cyclic references are not allowed.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"endpoints": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Endpoints are names of the endpoints in this group.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"ipBlocks": {
+						SchemaProps: spec.SchemaProps{
+							Description: "IPBlocks are static CIDRs in this group, e.g. subnets of external appliances not managed as endpoints.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.IPBlock"),
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"},
+			"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.IPBlock", "k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"},
 	}
 }
 
@@ -872,7 +921,8 @@ func schema_pkg_apis_group_v1alpha1_GroupMember(ref common.ReferenceCallback) co
 					},
 					"ips": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
+							Description: "IPs of the Endpoint, for members from IPBlocks of the group, it's the CIDR of the IPBlock.",
+							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
@@ -1307,6 +1357,20 @@ func schema_pkg_apis_security_v1alpha1_IPBlock(ref common.ReferenceCallback) com
 							Format:      "int32",
 						},
 					},
+					"except": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Except is a list of CIDRs that should not be included within the IPBlock, e.g. \"10.20.0.0/16\". Except values will be rejected if they are outside the IPBlock.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"ip", "prefixLength"},
 			},
@@ -1352,6 +1416,20 @@ func schema_pkg_apis_security_v1alpha1_Rule(ref common.ReferenceCallback) common
 							Ref:         ref("github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.SecurityPolicyPeer"),
 						},
 					},
+					"action": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Action specifies the action to be applied on traffic matching the rule, Allow, Drop or Reject. If this field is empty or missing, the traffic would be allowed. Drop rules are useful in Blacklist tier, which allows all traffic by default.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"logging": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Logging enables logging of traffic matching the rule. The agent would write a log record for the first packet of each connection. Defaults to false.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 				},
 				Required: []string{"name", "ports"},
 			},
@@ -1365,6 +1443,7 @@ func schema_pkg_apis_security_v1alpha1_SecurityPolicy(ref common.ReferenceCallba
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
+				Description: "SecurityPolicy is a set of rules applied to the Endpoints selected by AppliedTo.\n\nA SecurityPolicy labeled namespace.label.lynx.smartx.com belongs to the tenant namespace, it only selects Endpoints and uses EndpointGroups of the namespace, and tenants could only write SecurityPolicies of their namespaces. SecurityPolicy is still cluster scoped rather than namespaced: names are shared by all tenants, and RBAC on SecurityPolicies is cluster wide, so reading is not isolated between tenants.",
 				Type: []string{"object"},
 				Properties: map[string]spec.Schema{
 					"kind": {
@@ -1484,11 +1563,30 @@ func schema_pkg_apis_security_v1alpha1_SecurityPolicyPeer(ref common.ReferenceCa
 							},
 						},
 					},
+					"endpoints": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"endpointSelector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EndpointSelector selects endpoints by labels without an EndpointGroup, an empty selector selects all endpoints.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.IPBlock"},
+			"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.IPBlock", "k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"},
 	}
 }
 
@@ -1501,7 +1599,7 @@ func schema_pkg_apis_security_v1alpha1_SecurityPolicyPort(ref common.ReferenceCa
 				Properties: map[string]spec.Schema{
 					"protocol": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The protocol (TCP, UDP or ICMP) which traffic must match.",
+							Description: "The protocol (TCP, UDP, ICMP or SCTP) which traffic must match.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
@@ -1513,6 +1611,20 @@ func schema_pkg_apis_security_v1alpha1_SecurityPolicyPort(ref common.ReferenceCa
 							Format:      "",
 						},
 					},
+					"icmpType": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ICMPType is the type of ICMP message to match, e.g. 8 for echo-request. If this field is empty or missing, all ICMP messages matches.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"icmpCode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ICMPCode is the code of ICMP message to match, e.g. 4 for fragmentation-needed of destination-unreachable. If this field is empty or missing, all codes of ICMPType matches.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
 				},
 				Required: []string{"protocol"},
 			},
@@ -1538,6 +1650,27 @@ func schema_pkg_apis_security_v1alpha1_SecurityPolicySpec(ref common.ReferenceCa
 							Format: "int32",
 						},
 					},
+					"enforcementMode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EnforcementMode of the policy, Enforce or Monitor. Rules of policy in Monitor mode only count the traffic they match, but never affect the traffic. Defaults to Enforce.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"policyTypes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PolicyTypes are the directions of traffic the policy limits, Ingress and/or Egress. Traffic in the direction not listed is not affected by the policy: the default drop rule of the direction is not installed, and rules of the direction are not allowed. Defaults to both directions.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
 					"appliedToEndpointGroups": {
 						SchemaProps: spec.SchemaProps{
 							Description: "List of groups which SecurityPolicy applied to. Each item in this list is combined using a logical OR. This field must not empty.",
@@ -1739,6 +1872,13 @@ func schema_pkg_apis_security_v1alpha1_TierSpec(ref common.ReferenceCallback) co
 							Format: "",
 						},
 					},
+					"defaultRuleLogging": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DefaultRuleLogging sends records of traffic matching default drop rules of policies in the tier to the flow logger. Only Whitelist tier has default drop rules. Defaults to false.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 				},
 				Required: []string{"priority", "tierMode"},
 			},
@@ -1746,6 +1886,323 @@ func schema_pkg_apis_security_v1alpha1_TierSpec(ref common.ReferenceCallback) co
 	}
 }
 
+func schema_pkg_apis_security_v1alpha1_Traceflow(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Traceflow injects a synthetic packet from the source endpoint into the datapath of the agent hosting it, and reports the tables and policy rules the packet traverses. The packet is marked, and captured by the agent before forwarded, so it never reaches the destination.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.TraceflowSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.TraceflowStatus"),
+						},
+					},
+				},
+				Required: []string{"spec"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.TraceflowSpec", "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.TraceflowStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_security_v1alpha1_TraceflowDestination(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"endpoint": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Endpoint is the name of the destination Endpoint.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"ip": {
+						SchemaProps: spec.SchemaProps{
+							Description: "IP is the destination IP address, used when Endpoint not specified. The IP must belong to an Endpoint, whose mac address would be the destination mac address of the packet.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_security_v1alpha1_TraceflowList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TraceflowList contains a list of Traceflow",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.Traceflow"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.Traceflow", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+	}
+}
+
+func schema_pkg_apis_security_v1alpha1_TraceflowObservation(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"table": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Table is the openflow table id of the flow.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"flow": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Flow is the match and priority of the flow.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"actions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Actions of the flow.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"policyRules": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PolicyRules installed as the flow, empty if the flow is not a policy rule.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"table"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_security_v1alpha1_TraceflowPacket(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"protocol": {
+						SchemaProps: spec.SchemaProps{
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
+						},
+					},
+					"srcPort": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SrcPort and DstPort of TCP, UDP or SCTP packet.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"dstPort": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"icmpType": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ICMPType and ICMPCode of ICMP packet, ICMPType defaults to echo request if missing.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"icmpCode": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+				},
+				Required: []string{"protocol"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_security_v1alpha1_TraceflowSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"source": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Source is the name of the Endpoint the packet sent from.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"destination": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Destination of the packet, either an Endpoint or an IP address.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.TraceflowDestination"),
+						},
+					},
+					"packet": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Packet describes the transport layer of the packet.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.TraceflowPacket"),
+						},
+					},
+				},
+				Required: []string{"source", "destination", "packet"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.TraceflowDestination", "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.TraceflowPacket"},
+	}
+}
+
+func schema_pkg_apis_security_v1alpha1_TraceflowStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"phase": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"agent": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Agent is the name of the agent hosting the source endpoint, which traces the packet.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"observations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Observations are the flows the packet matched in order, traced by ofproto/trace.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.TraceflowObservation"),
+									},
+								},
+							},
+						},
+					},
+					"verdict": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Verdict is what finally happened to the injected packet. Reason notes when it differs from what the observations predict.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"verdictPolicyRule": {
+						SchemaProps: spec.SchemaProps{
+							Description: "VerdictPolicyRule is the PolicyRule drops or rejects the packet, or the last PolicyRule allows the packet.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1.TraceflowObservation"},
+	}
+}
+
 func schema_k8sio_api_apps_v1_ControllerRevision(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	groupv1alpha1 "github.com/smartxworks/lynx/pkg/apis/group/v1alpha1"
+	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+)
+
+// snapshotReader is a client.Reader gets Tiers, EndpointGroups and Endpoints from the snapshot,
+// it's what policies flattening reads from.
+type snapshotReader struct {
+	tiers     map[string]securityv1alpha1.Tier
+	groups    map[string]groupv1alpha1.EndpointGroup
+	endpoints map[string]securityv1alpha1.Endpoint
+}
+
+func newSnapshotReader(tiers []securityv1alpha1.Tier, groups []groupv1alpha1.EndpointGroup, endpoints []securityv1alpha1.Endpoint) *snapshotReader {
+	reader := &snapshotReader{
+		tiers:     make(map[string]securityv1alpha1.Tier, len(tiers)),
+		groups:    make(map[string]groupv1alpha1.EndpointGroup, len(groups)),
+		endpoints: make(map[string]securityv1alpha1.Endpoint, len(endpoints)),
+	}
+	for _, tier := range tiers {
+		reader.tiers[tier.Name] = tier
+	}
+	for _, group := range groups {
+		reader.groups[group.Name] = group
+	}
+	for _, endpoint := range endpoints {
+		reader.endpoints[endpoint.Name] = endpoint
+	}
+	return reader
+}
+
+// Get implements client.Reader.
+func (r *snapshotReader) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	switch o := obj.(type) {
+	case *securityv1alpha1.Tier:
+		tier, ok := r.tiers[key.Name]
+		if !ok {
+			return errors.NewNotFound(securityv1alpha1.Resource("tier"), key.Name)
+		}
+		tier.DeepCopyInto(o)
+	case *groupv1alpha1.EndpointGroup:
+		group, ok := r.groups[key.Name]
+		if !ok {
+			return errors.NewNotFound(groupv1alpha1.Resource("endpointgroup"), key.Name)
+		}
+		group.DeepCopyInto(o)
+	case *securityv1alpha1.Endpoint:
+		endpoint, ok := r.endpoints[key.Name]
+		if !ok {
+			return errors.NewNotFound(securityv1alpha1.Resource("endpoint"), key.Name)
+		}
+		endpoint.DeepCopyInto(o)
+	default:
+		return fmt.Errorf("unsupported object %T in snapshot", obj)
+	}
+	return nil
+}
+
+// List implements client.Reader, listing is not needed by policies flattening.
+func (r *snapshotReader) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	return fmt.Errorf("unsupported list %T in snapshot", list)
+}
@@ -0,0 +1,286 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package simulator evaluates packets against a snapshot of policies without a cluster. Policies
+// are flattened the same way as the policy controller, and rules are ordered the same way as
+// they are installed into the agent datapath.
+package simulator
+
+import (
+	"fmt"
+	"net"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/smartxworks/lynx/pkg/agent/controller/policyrule"
+	groupv1alpha1 "github.com/smartxworks/lynx/pkg/apis/group/v1alpha1"
+	policyv1alpha1 "github.com/smartxworks/lynx/pkg/apis/policyrule/v1alpha1"
+	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	lynxctrl "github.com/smartxworks/lynx/pkg/controller"
+	groupctrl "github.com/smartxworks/lynx/pkg/controller/group"
+	policyctrl "github.com/smartxworks/lynx/pkg/controller/policy"
+	policycache "github.com/smartxworks/lynx/pkg/controller/policy/cache"
+)
+
+// Packet is the first packet of a connection to simulate.
+type Packet struct {
+	SrcIP    net.IP
+	DstIP    net.IP
+	Protocol securityv1alpha1.Protocol
+	SrcPort  uint16
+	DstPort  uint16
+	ICMPType uint8
+	ICMPCode uint8
+}
+
+// Flow is a datapath flow of the PolicyRules with the same match, priority and action.
+type Flow struct {
+	Direction policyv1alpha1.RuleDirection
+	Tier      string
+	// Table is the datapath tier table of the flow in the direction.
+	Table uint8
+	// Priority is the flow priority in the datapath tier table.
+	Priority int
+	Spec     policyv1alpha1.PolicyRuleSpec
+	// PolicyRules are the PolicyRules installed as the flow.
+	PolicyRules []policyv1alpha1.PolicyRule
+
+	flowKey string
+}
+
+// Result is the matching flows and verdict of the packet.
+type Result struct {
+	// Chain is the flows the packet matched, in the order datapath matches them.
+	Chain   []*Flow
+	Verdict securityv1alpha1.TraceflowVerdict
+}
+
+// Simulator evaluates packets against the flows calculated from a snapshot.
+type Simulator struct {
+	endpoints map[string]securityv1alpha1.Endpoint
+	// flows of each direction, ordered by table and then by priority from high to low.
+	flows map[policyv1alpha1.RuleDirection][]*Flow
+}
+
+// New calculates datapath flows of the snapshot.
+func New(snapshot *Snapshot) (*Simulator, error) {
+	var simulator = &Simulator{
+		endpoints: make(map[string]securityv1alpha1.Endpoint, len(snapshot.Endpoints)),
+		flows:     make(map[policyv1alpha1.RuleDirection][]*Flow),
+	}
+
+	for item := range snapshot.Endpoints {
+		simulator.endpoints[snapshot.Endpoints[item].Name] = snapshot.Endpoints[item]
+	}
+
+	// groups managed by group controller for endpoint selectors in policies
+	var groups = append([]groupv1alpha1.EndpointGroup{}, snapshot.EndpointGroups...)
+	for item := range snapshot.SecurityPolicies {
+		for _, group := range groupctrl.PolicySelectorGroups(&snapshot.SecurityPolicies[item]) {
+			groups = append(groups, *group)
+		}
+	}
+	reader := newSnapshotReader(snapshot.Tiers, groups, snapshot.Endpoints)
+
+	groupCache, err := buildGroupCache(groups, snapshot.Endpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	var flowMap = make(map[string]*Flow)
+	for item := range snapshot.SecurityPolicies {
+		policy := &snapshot.SecurityPolicies[item]
+		completeRules, err := policyctrl.CompletePolicy(reader, groupCache, policy)
+		if err != nil {
+			return nil, fmt.Errorf("flatten policy %s: %s", policy.Name, err)
+		}
+
+		for _, completeRule := range completeRules {
+			for _, rule := range completeRule.ListRules().Items {
+				if err = simulator.addPolicyRule(flowMap, snapshot.Tiers, rule); err != nil {
+					return nil, fmt.Errorf("policy %s: %s", policy.Name, err)
+				}
+			}
+		}
+	}
+
+	for _, flows := range simulator.flows {
+		sort.Slice(flows, func(i, j int) bool {
+			if flows[i].Table != flows[j].Table {
+				return flows[i].Table < flows[j].Table
+			}
+			if flows[i].Priority != flows[j].Priority {
+				return flows[i].Priority > flows[j].Priority
+			}
+			// datapath matches flows with the same priority in any order
+			return flows[i].flowKey < flows[j].flowKey
+		})
+	}
+
+	return simulator, nil
+}
+
+// addPolicyRule adds the rule into the flow with the same match, priority and action. Like the
+// agent, the same rules generated by different policies are installed as a single flow.
+func (s *Simulator) addPolicyRule(flowMap map[string]*Flow, tiers []securityv1alpha1.Tier, rule policyv1alpha1.PolicyRule) error {
+	table, priority, install, err := policyrule.RuleFlowPosition(tiers, &rule.Spec)
+	if err != nil || !install {
+		return err
+	}
+
+	flowKey := policycache.HashName(32, rule.Spec)
+	if flow, ok := flowMap[flowKey]; ok {
+		flow.PolicyRules = append(flow.PolicyRules, rule)
+		return nil
+	}
+
+	flow := &Flow{
+		Direction:   rule.Spec.Direction,
+		Tier:        rule.Spec.Tier,
+		Table:       table,
+		Priority:    priority,
+		Spec:        rule.Spec,
+		PolicyRules: []policyv1alpha1.PolicyRule{rule},
+		flowKey:     flowKey,
+	}
+	flowMap[flowKey] = flow
+	s.flows[flow.Direction] = append(s.flows[flow.Direction], flow)
+	return nil
+}
+
+// buildGroupCache calculates members of the groups the same way as the group controller.
+func buildGroupCache(groups []groupv1alpha1.EndpointGroup, endpoints []securityv1alpha1.Endpoint) (*policycache.GroupCache, error) {
+	var groupCache = policycache.NewGroupCache()
+
+	for item := range groups {
+		memberList, err := groupctrl.CalculateGroupMembers(&groups[item], groups, endpoints)
+		if err != nil {
+			return nil, err
+		}
+
+		groupCache.AddGroupMembership(&groupv1alpha1.GroupMembers{
+			ObjectMeta:   metav1.ObjectMeta{Name: groups[item].Name},
+			GroupMembers: memberList,
+		})
+	}
+
+	return groupCache, nil
+}
+
+// ResolveIP returns the address if it's an ip, otherwise the first ip of the endpoint with the name.
+func (s *Simulator) ResolveIP(address string) (net.IP, error) {
+	if ip := net.ParseIP(address); ip != nil {
+		return ip, nil
+	}
+
+	endpoint, ok := s.endpoints[address]
+	if !ok {
+		return nil, fmt.Errorf("%s is neither an ip nor an endpoint", address)
+	}
+	for _, ipAddr := range endpoint.Status.IPs {
+		if ip := net.ParseIP(string(ipAddr)); ip != nil {
+			return ip, nil
+		}
+	}
+	return nil, fmt.Errorf("endpoint %s has no ip", address)
+}
+
+// Flows returns the flows of the direction, in the order datapath matches them.
+func (s *Simulator) Flows(direction policyv1alpha1.RuleDirection) []*Flow {
+	return s.flows[direction]
+}
+
+// Simulate evaluates the packet through egress tier tables and then ingress tier tables. In each
+// table, the flow with the highest priority decides the packet: allow skips the remaining tables
+// of the direction, drop and reject stop the packet. Monitor flows count the packet and leave it
+// to the other flows in the table. Packets match no flows are forwarded.
+func (s *Simulator) Simulate(packet *Packet) *Result {
+	var result = &Result{Verdict: securityv1alpha1.TraceflowVerdictForwarded}
+
+	for _, direction := range []policyv1alpha1.RuleDirection{policyv1alpha1.RuleDirectionOut, policyv1alpha1.RuleDirectionIn} {
+		var monitoredTables = make(map[uint8]bool)
+
+	flowLoop:
+		for _, flow := range s.flows[direction] {
+			if !packet.matches(&flow.Spec) {
+				continue
+			}
+
+			if flow.Spec.MonitorOnly {
+				// packets only counted by the first monitor flow in the table
+				if !monitoredTables[flow.Table] {
+					monitoredTables[flow.Table] = true
+					result.Chain = append(result.Chain, flow)
+				}
+				continue
+			}
+
+			result.Chain = append(result.Chain, flow)
+
+			switch flow.Spec.Action {
+			case policyv1alpha1.RuleActionDrop:
+				result.Verdict = securityv1alpha1.TraceflowVerdictDropped
+				return result
+			case policyv1alpha1.RuleActionReject:
+				result.Verdict = securityv1alpha1.TraceflowVerdictRejected
+				return result
+			default:
+				// allowed packets skip the remaining tables of the direction
+				break flowLoop
+			}
+		}
+	}
+
+	return result
+}
+
+// PolicyRuleOwner returns the policy and rule name which generated the PolicyRule.
+func PolicyRuleOwner(rule *policyv1alpha1.PolicyRule) (policy, ruleName string) {
+	return rule.Labels[lynxctrl.OwnerPolicyLabel], rule.Annotations[lynxctrl.OwnerRuleAnnotation]
+}
+
+func (packet *Packet) matches(rule *policyv1alpha1.PolicyRuleSpec) bool {
+	if !ipMatches(rule.SrcIpAddr, packet.SrcIP) || !ipMatches(rule.DstIpAddr, packet.DstIP) {
+		return false
+	}
+	if rule.IpProtocol == "" {
+		return true
+	}
+	if rule.IpProtocol != string(packet.Protocol) {
+		return false
+	}
+
+	switch packet.Protocol {
+	case securityv1alpha1.ProtocolICMP:
+		return (rule.IcmpType == nil || *rule.IcmpType == int32(packet.ICMPType)) &&
+			(rule.IcmpCode == nil || *rule.IcmpCode == int32(packet.ICMPCode))
+	default:
+		return (rule.SrcPort == 0 || rule.SrcPort == packet.SrcPort) &&
+			(rule.DstPort == 0 || rule.DstPort == packet.DstPort)
+	}
+}
+
+// ipMatches returns true if the ip in the ipBlock, empty ipBlock matches all ips.
+func ipMatches(ipBlock string, ip net.IP) bool {
+	if ipBlock == "" {
+		return true
+	}
+	if _, ipNet, err := net.ParseCIDR(ipBlock); err == nil {
+		return ipNet.Contains(ip)
+	}
+	return net.ParseIP(ipBlock).Equal(ip)
+}
@@ -0,0 +1,386 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+)
+
+const testManifest = `
+apiVersion: security.lynx.smartx.com/v1alpha1
+kind: Tier
+metadata:
+  name: tier0
+spec:
+  priority: 0
+  tierMode: Whitelist
+---
+apiVersion: security.lynx.smartx.com/v1alpha1
+kind: Tier
+metadata:
+  name: tier1
+spec:
+  priority: 10
+  tierMode: Blacklist
+---
+apiVersion: security.lynx.smartx.com/v1alpha1
+kind: Tier
+metadata:
+  name: tenant-tier
+  labels:
+    tenanttier.label.lynx.smartx.com: "true"
+spec:
+  priority: 100
+  tierMode: Whitelist
+---
+apiVersion: security.lynx.smartx.com/v1alpha1
+kind: EndpointList
+items:
+- metadata:
+    name: web
+    labels:
+      app: web
+  spec:
+    reference:
+      externalIDName: iface-id
+      externalIDValue: web
+  status:
+    ips: ["10.0.0.1"]
+- metadata:
+    name: db
+    labels:
+      app: db
+  spec:
+    reference:
+      externalIDName: iface-id
+      externalIDValue: db
+  status:
+    ips: ["10.0.0.2"]
+- metadata:
+    name: other
+  spec:
+    reference:
+      externalIDName: iface-id
+      externalIDValue: other
+  status:
+    ips: ["10.0.0.3"]
+- metadata:
+    name: cache
+    labels:
+      app: cache
+  spec:
+    reference:
+      externalIDName: iface-id
+      externalIDValue: cache
+  status:
+    ips: ["10.0.0.5"]
+- metadata:
+    name: tenant-web
+    labels:
+      app: web
+      namespace.label.lynx.smartx.com: tenant-a
+  spec:
+    reference:
+      externalIDName: iface-id
+      externalIDValue: tenant-web
+  status:
+    ips: ["10.0.0.6"]
+- metadata:
+    name: gateway
+    labels:
+      app: gateway
+  spec:
+    reference:
+      externalIDName: iface-id
+      externalIDValue: gateway
+  status:
+    ips: ["10.0.0.7"]
+---
+apiVersion: group.lynx.smartx.com/v1alpha1
+kind: EndpointGroup
+metadata:
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+---
+apiVersion: group.lynx.smartx.com/v1alpha1
+kind: EndpointGroup
+metadata:
+  name: db
+spec:
+  selector:
+    matchLabels:
+      app: db
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ignored
+---
+apiVersion: security.lynx.smartx.com/v1alpha1
+kind: SecurityPolicy
+metadata:
+  name: db-policy
+spec:
+  tier: tier0
+  priority: 10
+  appliedTo:
+    endpointGroups: ["db"]
+  ingressRules:
+  - name: allow-web
+    from:
+      endpointGroups: ["web"]
+    ports:
+    - protocol: TCP
+      portRange: "3306"
+---
+apiVersion: security.lynx.smartx.com/v1alpha1
+kind: SecurityPolicy
+metadata:
+  name: block-other
+spec:
+  tier: tier1
+  priority: 10
+  appliedTo:
+    endpointGroups: ["web"]
+  ingressRules:
+  - name: reject-other
+    action: Reject
+    from:
+      endpoints: ["other"]
+    ports:
+    - protocol: TCP
+---
+apiVersion: security.lynx.smartx.com/v1alpha1
+kind: SecurityPolicy
+metadata:
+  name: audit
+spec:
+  tier: tier1
+  priority: 20
+  enforcementMode: Monitor
+  appliedTo:
+    endpoints: ["web"]
+  ingressRules:
+  - name: drop-all
+    action: Drop
+---
+apiVersion: security.lynx.smartx.com/v1alpha1
+kind: SecurityPolicy
+metadata:
+  name: cache-policy
+spec:
+  tier: tier0
+  priority: 20
+  appliedTo:
+    endpointSelector:
+      matchLabels:
+        app: cache
+  egressRules:
+  - name: allow-web
+    to:
+      endpointSelector:
+        matchExpressions:
+        - key: app
+          operator: In
+          values: ["web"]
+---
+apiVersion: security.lynx.smartx.com/v1alpha1
+kind: SecurityPolicy
+metadata:
+  name: tenant-policy
+  labels:
+    namespace.label.lynx.smartx.com: tenant-a
+spec:
+  tier: tenant-tier
+  priority: 10
+  appliedTo:
+    endpointSelector:
+      matchLabels:
+        app: web
+  ingressRules:
+  - name: allow-cache
+    from:
+      ipBlocks:
+      - ip: 10.0.0.5
+        prefixLength: 32
+---
+apiVersion: security.lynx.smartx.com/v1alpha1
+kind: SecurityPolicy
+metadata:
+  name: gateway-policy
+spec:
+  tier: tier0
+  priority: 10
+  appliedTo:
+    endpointSelector:
+      matchLabels:
+        app: gateway
+  egressRules:
+  - name: allow-private
+    to:
+      ipBlocks:
+      - ip: 10.0.0.0
+        prefixLength: 8
+  - name: drop-subnet
+    action: Drop
+    to:
+      ipBlocks:
+      - ip: 10.1.0.0
+        prefixLength: 16
+`
+
+func TestSimulate(t *testing.T) {
+	snapshot := &Snapshot{}
+	if err := snapshot.AddManifest([]byte(testManifest)); err != nil {
+		t.Fatalf("unable to read manifest: %s", err)
+	}
+	simulator, err := New(snapshot)
+	if err != nil {
+		t.Fatalf("unable to create simulator: %s", err)
+	}
+
+	testCases := map[string]struct {
+		src, dst      string
+		protocol      securityv1alpha1.Protocol
+		dstPort       uint16
+		expectVerdict securityv1alpha1.TraceflowVerdict
+		expectChain   []string
+	}{
+		"should allow web access db": {
+			src: "web", dst: "db", protocol: securityv1alpha1.ProtocolTCP, dstPort: 3306,
+			expectVerdict: securityv1alpha1.TraceflowVerdictForwarded,
+			expectChain:   []string{"db-policy/ingress.allow-web"},
+		},
+		"should drop web access db on other ports": {
+			src: "web", dst: "db", protocol: securityv1alpha1.ProtocolTCP, dstPort: 22,
+			expectVerdict: securityv1alpha1.TraceflowVerdictDropped,
+			expectChain:   []string{"db-policy/default.ingress"},
+		},
+		"should allow cache access web by selector": {
+			src: "cache", dst: "web", protocol: securityv1alpha1.ProtocolTCP, dstPort: 80,
+			expectVerdict: securityv1alpha1.TraceflowVerdictForwarded,
+			expectChain:   []string{"cache-policy/egress.allow-web", "audit/ingress.drop-all"},
+		},
+		"should drop cache access endpoints not selected": {
+			src: "cache", dst: "db", protocol: securityv1alpha1.ProtocolTCP, dstPort: 3306,
+			expectVerdict: securityv1alpha1.TraceflowVerdictDropped,
+			expectChain:   []string{"cache-policy/default.egress"},
+		},
+		"should allow cache access tenant endpoint by tenant policy": {
+			src: "cache", dst: "tenant-web", protocol: securityv1alpha1.ProtocolTCP, dstPort: 80,
+			expectVerdict: securityv1alpha1.TraceflowVerdictForwarded,
+			expectChain:   []string{"cache-policy/egress.allow-web", "tenant-policy/ingress.allow-cache"},
+		},
+		"should reject other access tenant endpoint by cluster tier first": {
+			src: "other", dst: "tenant-web", protocol: securityv1alpha1.ProtocolTCP, dstPort: 80,
+			expectVerdict: securityv1alpha1.TraceflowVerdictRejected,
+			expectChain:   []string{"block-other/ingress.reject-other"},
+		},
+		"should drop other access tenant endpoint by tenant policy": {
+			src: "other", dst: "tenant-web", protocol: securityv1alpha1.ProtocolUDP, dstPort: 53,
+			expectVerdict: securityv1alpha1.TraceflowVerdictDropped,
+			expectChain:   []string{"tenant-policy/default.ingress"},
+		},
+		"should drop egress traffic of db": {
+			src: "db", dst: "web", protocol: securityv1alpha1.ProtocolTCP, dstPort: 80,
+			expectVerdict: securityv1alpha1.TraceflowVerdictDropped,
+			expectChain:   []string{"db-policy/default.egress"},
+		},
+		"should monitor and reject other access web": {
+			src: "other", dst: "web", protocol: securityv1alpha1.ProtocolTCP, dstPort: 80,
+			expectVerdict: securityv1alpha1.TraceflowVerdictRejected,
+			expectChain:   []string{"audit/ingress.drop-all", "block-other/ingress.reject-other"},
+		},
+		"should monitor and forward other access web by udp": {
+			src: "other", dst: "10.0.0.1", protocol: securityv1alpha1.ProtocolUDP, dstPort: 53,
+			expectVerdict: securityv1alpha1.TraceflowVerdictForwarded,
+			expectChain:   []string{"audit/ingress.drop-all"},
+		},
+		"should drop by the drop rule overlaps allow rule in the same policy": {
+			src: "gateway", dst: "10.1.0.1", protocol: securityv1alpha1.ProtocolTCP, dstPort: 80,
+			expectVerdict: securityv1alpha1.TraceflowVerdictDropped,
+			expectChain:   []string{"gateway-policy/egress.drop-subnet"},
+		},
+		"should allow by the allow rule outside the drop rule": {
+			src: "gateway", dst: "10.2.0.1", protocol: securityv1alpha1.ProtocolTCP, dstPort: 80,
+			expectVerdict: securityv1alpha1.TraceflowVerdictForwarded,
+			expectChain:   []string{"gateway-policy/egress.allow-private"},
+		},
+		"should forward traffic not applied by any policy": {
+			src: "10.0.0.3", dst: "10.0.0.4", protocol: securityv1alpha1.ProtocolICMP,
+			expectVerdict: securityv1alpha1.TraceflowVerdictForwarded,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			srcIP, err := simulator.ResolveIP(tc.src)
+			if err != nil {
+				t.Fatalf("unexpect error: %s", err)
+			}
+			dstIP, err := simulator.ResolveIP(tc.dst)
+			if err != nil {
+				t.Fatalf("unexpect error: %s", err)
+			}
+
+			result := simulator.Simulate(&Packet{SrcIP: srcIP, DstIP: dstIP, Protocol: tc.protocol, SrcPort: 30000, DstPort: tc.dstPort})
+			if result.Verdict != tc.expectVerdict {
+				t.Errorf("expect verdict %s, got %s", tc.expectVerdict, result.Verdict)
+			}
+
+			var chain []string
+			for _, flow := range result.Chain {
+				policy, rule := PolicyRuleOwner(&flow.PolicyRules[0])
+				chain = append(chain, policy+"/"+rule)
+			}
+			if !reflect.DeepEqual(chain, tc.expectChain) {
+				t.Errorf("expect chain %v, got %v", tc.expectChain, chain)
+			}
+		})
+	}
+}
+
+func TestSnapshotReader(t *testing.T) {
+	snapshot := &Snapshot{}
+	if err := snapshot.AddManifest([]byte(testManifest)); err != nil {
+		t.Fatalf("unable to read manifest: %s", err)
+	}
+	reader := newSnapshotReader(snapshot.Tiers, snapshot.EndpointGroups, snapshot.Endpoints)
+
+	var endpoint securityv1alpha1.Endpoint
+	if err := reader.Get(context.Background(), client.ObjectKey{Name: "web"}, &endpoint); err != nil {
+		t.Fatalf("unable to get endpoint web: %s", err)
+	}
+	if endpoint.Name != "web" || len(endpoint.Status.IPs) != 1 {
+		t.Errorf("unexpect endpoint %+v", endpoint)
+	}
+
+	var tier securityv1alpha1.Tier
+	if err := reader.Get(context.Background(), client.ObjectKey{Name: "not-exist"}, &tier); !errors.IsNotFound(err) {
+		t.Errorf("expect not found error, got %v", err)
+	}
+}
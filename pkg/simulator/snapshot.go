@@ -0,0 +1,166 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	groupv1alpha1 "github.com/smartxworks/lynx/pkg/apis/group/v1alpha1"
+	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	_ = securityv1alpha1.AddToScheme(scheme)
+	_ = groupv1alpha1.AddToScheme(scheme)
+}
+
+// Snapshot contains the resources policies are calculated from.
+type Snapshot struct {
+	Tiers            []securityv1alpha1.Tier
+	SecurityPolicies []securityv1alpha1.SecurityPolicy
+	EndpointGroups   []groupv1alpha1.EndpointGroup
+	Endpoints        []securityv1alpha1.Endpoint
+}
+
+// SnapshotFromCluster reads Tiers, SecurityPolicies, EndpointGroups and Endpoints from the cluster.
+func SnapshotFromCluster(ctx context.Context, reader client.Reader) (*Snapshot, error) {
+	var snapshot Snapshot
+	var tierList securityv1alpha1.TierList
+	var policyList securityv1alpha1.SecurityPolicyList
+	var groupList groupv1alpha1.EndpointGroupList
+	var endpointList securityv1alpha1.EndpointList
+
+	for _, list := range []runtime.Object{&tierList, &policyList, &groupList, &endpointList} {
+		if err := reader.List(ctx, list); err != nil {
+			return nil, fmt.Errorf("unable to list %T: %s", list, err)
+		}
+		if err := snapshot.AddObject(list); err != nil {
+			return nil, err
+		}
+	}
+
+	return &snapshot, nil
+}
+
+// SnapshotFromFiles reads resources from the YAML or JSON files, directories in paths are
+// read recursively. Resources of other kinds in the files are ignored.
+func SnapshotFromFiles(paths ...string) (*Snapshot, error) {
+	var snapshot Snapshot
+
+	for _, path := range paths {
+		err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || (file != path && !isManifestFile(file)) {
+				return nil
+			}
+			raw, err := ioutil.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			if err = snapshot.AddManifest(raw); err != nil {
+				return fmt.Errorf("unable to read %s: %s", file, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &snapshot, nil
+}
+
+func isManifestFile(file string) bool {
+	switch filepath.Ext(file) {
+	case ".yaml", ".yml", ".json":
+		return true
+	default:
+		return false
+	}
+}
+
+// AddManifest decodes the YAML or JSON documents, and adds them into the snapshot.
+func (s *Snapshot) AddManifest(raw []byte) error {
+	var reader = yaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(raw)))
+	var decoder = serializer.NewCodecFactory(scheme).UniversalDeserializer()
+
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+
+		obj, gvk, err := decoder.Decode(doc, nil, nil)
+		if runtime.IsNotRegisteredError(err) {
+			// ignore the kinds simulator doesn't care
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err = s.AddObject(obj); err != nil {
+			return fmt.Errorf("unable to add %s: %s", gvk, err)
+		}
+	}
+}
+
+// AddObject adds the resource or list of resources into the snapshot.
+func (s *Snapshot) AddObject(obj runtime.Object) error {
+	switch o := obj.(type) {
+	case *securityv1alpha1.Tier:
+		s.Tiers = append(s.Tiers, *o)
+	case *securityv1alpha1.TierList:
+		s.Tiers = append(s.Tiers, o.Items...)
+	case *securityv1alpha1.SecurityPolicy:
+		s.SecurityPolicies = append(s.SecurityPolicies, *o)
+	case *securityv1alpha1.SecurityPolicyList:
+		s.SecurityPolicies = append(s.SecurityPolicies, o.Items...)
+	case *groupv1alpha1.EndpointGroup:
+		s.EndpointGroups = append(s.EndpointGroups, *o)
+	case *groupv1alpha1.EndpointGroupList:
+		s.EndpointGroups = append(s.EndpointGroups, o.Items...)
+	case *securityv1alpha1.Endpoint:
+		s.Endpoints = append(s.Endpoints, *o)
+	case *securityv1alpha1.EndpointList:
+		s.Endpoints = append(s.Endpoints, o.Items...)
+	default:
+		return fmt.Errorf("unsupport object type %T", obj)
+	}
+	return nil
+}
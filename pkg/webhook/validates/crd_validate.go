@@ -20,12 +20,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"regexp"
 	"strconv"
 	"strings"
 
 	admv1 "k8s.io/api/admission/v1"
 	authv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -39,6 +41,8 @@ import (
 
 	groupv1alpha1 "github.com/smartxworks/lynx/pkg/apis/group/v1alpha1"
 	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	lynxctrl "github.com/smartxworks/lynx/pkg/controller"
+	groupctrl "github.com/smartxworks/lynx/pkg/controller/group"
 	ctrltypes "github.com/smartxworks/lynx/pkg/controller/types"
 )
 
@@ -85,13 +89,32 @@ func NewCRDValidate(client client.Client, scheme *runtime.Scheme) *CRDValidate {
 		Kind:    "Tier",
 	}, &tierValidator{v.client})
 
+	// tenants could only manage EndpointGroups and SecurityPolicies in their namespaces
+	for _, kind := range []metav1.GroupVersionKind{
+		{Group: "security.lynx.smartx.com", Version: "v1alpha1", Kind: "Endpoint"},
+		{Group: "group.lynx.smartx.com", Version: "v1alpha1", Kind: "EndpointGroup"},
+		{Group: "security.lynx.smartx.com", Version: "v1alpha1", Kind: "SecurityPolicy"},
+		{Group: "security.lynx.smartx.com", Version: "v1alpha1", Kind: "Tier"},
+	} {
+		v.register(kind, &tenantValidator{v.client})
+	}
+
 	return v
 }
 
+// TenantGroupPrefix is the prefix of user groups for tenants. Users in group TenantGroupPrefix + namespace
+// are tenants of the namespace, they could only manage EndpointGroups and SecurityPolicies labeled with
+// the namespace. Users not in any tenant group are not limited here, RBAC decides what they could manage.
+//
+// The tenant namespace only guards writes. The resources are cluster scoped, so names are shared by all
+// tenants, and tenants could read resources of the other tenants unless RBAC denies it.
+const TenantGroupPrefix = "tenant.lynx.smartx.com:"
+
 const (
 	tierPriorityIndex        = "TierPriorityIndex"
 	policyTierIndex          = "PolicyTierIndex"
 	policyEndpointGroupIndex = "PolicyEndpointGroupIndex"
+	groupEndpointGroupIndex  = "GroupEndpointGroupIndex"
 
 	matchIPV4 = `^((([1]?\d)?\d|2[0-4]\d|25[0-5])\.){3}(([1]?\d)?\d|2[0-4]\d|25[0-5])$`
 )
@@ -120,9 +143,18 @@ func RegisterIndexFields(f client.FieldIndexer) error {
 			groups.Insert(rule.From.EndpointGroups...)
 			groups.Insert(rule.To.EndpointGroups...)
 		}
+		// groups managed for endpoint selectors are also used by the policy
+		for group := range groupctrl.PolicySelectorGroups(policy) {
+			groups.Insert(group)
+		}
 		return groups.List()
 	})
 
+	// index nested endpointGroup in EndpointGroup object
+	f.IndexField(ctx, &groupv1alpha1.EndpointGroup{}, groupEndpointGroupIndex, func(object runtime.Object) []string {
+		return object.(*groupv1alpha1.EndpointGroup).Spec.EndpointGroups
+	})
+
 	return nil
 }
 
@@ -280,32 +312,77 @@ type endpointGroupValidator resourceValidator
 
 func (v endpointGroupValidator) createValidate(curObj runtime.Object, userInfo authv1.UserInfo) (string, bool) {
 	var allErrs field.ErrorList
+	var group = curObj.(*groupv1alpha1.EndpointGroup)
 
 	// validate label selector
-	allErrs = v.validateSelector(curObj.(*groupv1alpha1.EndpointGroup).Spec.Selector)
+	allErrs = v.validateSelector(group.Spec.Selector)
 
 	if err := allErrs.ToAggregate(); err != nil {
 		return err.Error(), false
 	}
-	return "", true
-}
-
-func (v endpointGroupValidator) updateValidate(oldObj, curObj runtime.Object, userInfo authv1.UserInfo) (string, bool) {
-	var allErrs field.ErrorList
 
-	// validate label selector
-	allErrs = v.validateSelector(curObj.(*groupv1alpha1.EndpointGroup).Spec.Selector)
+	for _, ipBlock := range group.Spec.IPBlocks {
+		if err := validateIPBlock(ipBlock); err != nil {
+			return fmt.Sprintf("ipBlock %s/%d: %s", ipBlock.IP, ipBlock.PrefixLength, err), false
+		}
+	}
 
-	if err := allErrs.ToAggregate(); err != nil {
+	// nested groups must exists and not reference cyclically
+	if err := v.validateNestedGroups(group); err != nil {
 		return err.Error(), false
 	}
+
 	return "", true
 }
 
+func (v endpointGroupValidator) updateValidate(oldObj, curObj runtime.Object, userInfo authv1.UserInfo) (string, bool) {
+	// update endpoint group should limit as create endpoint group
+	return v.createValidate(curObj, userInfo)
+}
+
 func (v endpointGroupValidator) validateSelector(selector *metav1.LabelSelector) field.ErrorList {
 	return metav1validation.ValidateLabelSelector(selector, field.NewPath("selector"))
 }
 
+// validateNestedGroups validates nested groups of the group exist and in the same tenant
+// namespace, and the group not reference itself directly or indirectly.
+func (v endpointGroupValidator) validateNestedGroups(group *groupv1alpha1.EndpointGroup) error {
+	var visited = sets.NewString()
+	var queue = append([]string{}, group.Spec.EndpointGroups...)
+
+	for _, groupName := range group.Spec.EndpointGroups {
+		nestedGroup := groupv1alpha1.EndpointGroup{}
+		if err := v.Get(context.Background(), types.NamespacedName{Name: groupName}, &nestedGroup); err != nil {
+			return fmt.Errorf("endpointGroup must create first: %s", err)
+		}
+		if !inSameNamespace(group, &nestedGroup) {
+			return fmt.Errorf("endpointGroup %s not in namespace %s", groupName, group.Labels[lynxctrl.NamespaceLabel])
+		}
+	}
+
+	for len(queue) != 0 {
+		groupName := queue[0]
+		queue = queue[1:]
+
+		if groupName == group.Name {
+			return fmt.Errorf("endpointGroup %s references itself cyclically", group.Name)
+		}
+		if visited.Has(groupName) {
+			continue
+		}
+		visited.Insert(groupName)
+
+		nestedGroup := groupv1alpha1.EndpointGroup{}
+		err := v.Get(context.Background(), types.NamespacedName{Name: groupName}, &nestedGroup)
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		queue = append(queue, nestedGroup.Spec.EndpointGroups...)
+	}
+
+	return nil
+}
+
 func (v endpointGroupValidator) deleteValidate(oldObj runtime.Object, userInfo authv1.UserInfo) (string, bool) {
 	policyList := securityv1alpha1.SecurityPolicyList{}
 
@@ -318,6 +395,17 @@ func (v endpointGroupValidator) deleteValidate(oldObj runtime.Object, userInfo a
 	if len(policyList.Items) != 0 {
 		return "delete EndpointGroup used by security policy not allowed", false
 	}
+
+	groupList := groupv1alpha1.EndpointGroupList{}
+	if err := v.List(context.Background(), &groupList, client.MatchingFields{
+		groupEndpointGroupIndex: oldObj.(*groupv1alpha1.EndpointGroup).Name,
+	}); err != nil {
+		return err.Error(), false
+	}
+
+	if len(groupList.Items) != 0 {
+		return "delete EndpointGroup used by other EndpointGroup not allowed", false
+	}
 	return "", true
 }
 
@@ -335,16 +423,66 @@ func (t tierValidator) createValidate(curObj runtime.Object, userInfo authv1.Use
 	if len(tierList.Items) != 0 {
 		return "create tier with same priority not allowed", false
 	}
+
+	if err := t.validateTenantTierPriority(curObj.(*securityv1alpha1.Tier)); err != nil {
+		return err.Error(), false
+	}
 	return "", true
 }
 
 func (t tierValidator) updateValidate(oldObj, curObj runtime.Object, userInfo authv1.UserInfo) (string, bool) {
-	if oldObj.(*securityv1alpha1.Tier).Spec.Priority != curObj.(*securityv1alpha1.Tier).Spec.Priority {
+	oldTier := oldObj.(*securityv1alpha1.Tier)
+	curTier := curObj.(*securityv1alpha1.Tier)
+
+	if oldTier.Spec.Priority != curTier.Spec.Priority {
 		return "update tier priority not allowed", false
 	}
+
+	if isTenantTier(oldTier) != isTenantTier(curTier) {
+		if err := t.validateTenantTierPriority(curTier); err != nil {
+			return err.Error(), false
+		}
+		if isTenantTier(oldTier) {
+			policyList := securityv1alpha1.SecurityPolicyList{}
+			if err := t.List(context.Background(), &policyList, client.MatchingFields{policyTierIndex: oldTier.Name}); err != nil {
+				return err.Error(), false
+			}
+			for _, policy := range policyList.Items {
+				if policy.Labels[lynxctrl.NamespaceLabel] != "" {
+					return "unset tenant tier used by tenant security policy not allowed", false
+				}
+			}
+		}
+	}
 	return "", true
 }
 
+// validateTenantTierPriority validates tenant tiers have lower precedence (larger priority value)
+// than all the other tiers, so tiers of cluster admin always evaluated first.
+func (t tierValidator) validateTenantTierPriority(tier *securityv1alpha1.Tier) error {
+	tierList := securityv1alpha1.TierList{}
+	if err := t.List(context.Background(), &tierList); err != nil {
+		return err
+	}
+
+	for _, item := range tierList.Items {
+		if item.Name == tier.Name || isTenantTier(&item) == isTenantTier(tier) {
+			continue
+		}
+		if isTenantTier(tier) && item.Spec.Priority >= tier.Spec.Priority {
+			return fmt.Errorf("tenant tier priority must larger than priority %d of tier %s", item.Spec.Priority, item.Name)
+		}
+		if !isTenantTier(tier) && item.Spec.Priority <= tier.Spec.Priority {
+			return fmt.Errorf("tier priority must smaller than priority %d of tenant tier %s", item.Spec.Priority, item.Name)
+		}
+	}
+	return nil
+}
+
+func isTenantTier(tier *securityv1alpha1.Tier) bool {
+	return tier.Labels[lynxctrl.TenantTierLabel] == "true"
+}
+
 func (t tierValidator) deleteValidate(oldObj runtime.Object, userInfo authv1.UserInfo) (string, bool) {
 	policyList := securityv1alpha1.SecurityPolicyList{}
 
@@ -366,8 +504,13 @@ func (v securityPolicyValidator) createValidate(curObj runtime.Object, userInfo
 	policy := curObj.(*securityv1alpha1.SecurityPolicy)
 	groups := sets.NewString(policy.Spec.AppliedTo.EndpointGroups...)
 
-	if len(groups) == 0 && len(policy.Spec.AppliedTo.Endpoints) == 0 {
-		return "at least one group or endpoint should specified for policy applied to", false
+	if len(groups) == 0 && len(policy.Spec.AppliedTo.Endpoints) == 0 && policy.Spec.AppliedTo.EndpointSelector == nil {
+		return "at least one group, endpoint or endpointSelector should specified for policy applied to", false
+	}
+
+	// endpoint selectors must be validate label selectors
+	if err := v.validateEndpointSelectors(policy); err != nil {
+		return err.Error(), false
 	}
 
 	// all groups must exists before security policy create
@@ -380,6 +523,9 @@ func (v securityPolicyValidator) createValidate(curObj runtime.Object, userInfo
 		if err := v.Get(context.Background(), types.NamespacedName{Name: groupName}, &endpointGroup); err != nil {
 			return fmt.Sprintf("endpointGroup must create first: %s", err.Error()), false
 		}
+		if !inSameNamespace(policy, &endpointGroup) {
+			return fmt.Sprintf("endpointGroup %s not in namespace %s", groupName, policy.Labels[lynxctrl.NamespaceLabel]), false
+		}
 	}
 
 	// tier must exists before security policy create
@@ -388,6 +534,22 @@ func (v securityPolicyValidator) createValidate(curObj runtime.Object, userInfo
 		return fmt.Sprintf("tier must create first: %s", err.Error()), false
 	}
 
+	// policies in tenant namespace must in tenant tier
+	if policy.Labels[lynxctrl.NamespaceLabel] != "" && !isTenantTier(&tier) {
+		return fmt.Sprintf("policy in namespace %s must use tenant tier, tier %s is not", policy.Labels[lynxctrl.NamespaceLabel], tier.Name), false
+	}
+
+	switch policy.Spec.EnforcementMode {
+	case "", securityv1alpha1.EnforcementModeEnforce, securityv1alpha1.EnforcementModeMonitor:
+	default:
+		return fmt.Sprintf("unsupported enforcementMode %s", policy.Spec.EnforcementMode), false
+	}
+
+	// rules of the direction not limited by the policy would never take effect
+	if err := v.validatePolicyTypes(policy); err != nil {
+		return err.Error(), false
+	}
+
 	// should has difference rule name in egress and ingress, rule name must conforms RFC 1123
 	if err := v.validateRuleName(policy.Spec.IngressRules, policy.Spec.EgressRules); err != nil {
 		return fmt.Sprintf("policy %s, format error with rule.Name: %s", policy.Name, err), false
@@ -403,32 +565,121 @@ func (v securityPolicyValidator) createValidate(curObj runtime.Object, userInfo
 	return "", true
 }
 
+// validateEndpointSelectors validates endpoint selectors in appliedTo and rule peers.
+func (v *securityPolicyValidator) validateEndpointSelectors(policy *securityv1alpha1.SecurityPolicy) error {
+	var allErrs field.ErrorList
+	var specPath = field.NewPath("spec")
+
+	allErrs = append(allErrs, metav1validation.ValidateLabelSelector(policy.Spec.AppliedTo.EndpointSelector,
+		specPath.Child("appliedTo", "endpointSelector"))...)
+	for item, rule := range policy.Spec.IngressRules {
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(rule.From.EndpointSelector,
+			specPath.Child("ingressRules").Index(item).Child("from", "endpointSelector"))...)
+	}
+	for item, rule := range policy.Spec.EgressRules {
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(rule.To.EndpointSelector,
+			specPath.Child("egressRules").Index(item).Child("to", "endpointSelector"))...)
+	}
+
+	return allErrs.ToAggregate()
+}
+
 // validateRule validates if the rule with validate value
 func (v *securityPolicyValidator) validateRule(rule securityv1alpha1.Rule) error {
 	// match ip address
 	for _, ipBlock := range append(rule.From.IPBlocks, rule.To.IPBlocks...) {
-		ipv4 := false
-		if regexp.MustCompile(matchIPV4).Match([]byte(ipBlock.IP)) {
-			ipv4 = true
-		}
-		if ipv4 {
-			if !(ipBlock.PrefixLength <= 32 && ipBlock.PrefixLength >= 0) {
-				return fmt.Errorf("PrefixLength for ipv4 must between 0-32")
-			}
-		} else {
-			if !(ipBlock.PrefixLength <= 128 && ipBlock.PrefixLength >= 0) {
-				return fmt.Errorf("PrefixLength for ipv6 must between 0-128")
-			}
+		if err := validateIPBlock(ipBlock); err != nil {
+			return err
 		}
 	}
 
 	for _, port := range rule.Ports {
+		switch port.Protocol {
+		case securityv1alpha1.ProtocolTCP, securityv1alpha1.ProtocolUDP, securityv1alpha1.ProtocolICMP, securityv1alpha1.ProtocolSCTP:
+		default:
+			return fmt.Errorf("unsupported protocol %s", port.Protocol)
+		}
+
 		err := v.validatePortRange(port.PortRange)
 		if err != nil {
 			return fmt.Errorf("PortRange %s with error format: %s", port.PortRange, err)
 		}
+		err = v.validateICMPTypeCode(port)
+		if err != nil {
+			return err
+		}
 	}
 
+	switch rule.Action {
+	case "", securityv1alpha1.RuleActionAllow, securityv1alpha1.RuleActionDrop, securityv1alpha1.RuleActionReject:
+	default:
+		return fmt.Errorf("unsupported action %s", rule.Action)
+	}
+
+	return nil
+}
+
+// validateIPBlock validates prefix length and except of the ipBlock.
+func validateIPBlock(ipBlock securityv1alpha1.IPBlock) error {
+	ipv4 := false
+	if regexp.MustCompile(matchIPV4).Match([]byte(ipBlock.IP)) {
+		ipv4 = true
+	}
+	if ipv4 {
+		if !(ipBlock.PrefixLength <= 32 && ipBlock.PrefixLength >= 0) {
+			return fmt.Errorf("PrefixLength for ipv4 must between 0-32")
+		}
+	} else {
+		if !(ipBlock.PrefixLength <= 128 && ipBlock.PrefixLength >= 0) {
+			return fmt.Errorf("PrefixLength for ipv6 must between 0-128")
+		}
+	}
+	return validateIPBlockExcept(ipBlock)
+}
+
+// validateIPBlockExcept validates except of the ipBlock must be cidrs within the ipBlock.
+func validateIPBlockExcept(ipBlock securityv1alpha1.IPBlock) error {
+	if len(ipBlock.Except) == 0 {
+		return nil
+	}
+
+	_, ipNet, err := net.ParseCIDR(fmt.Sprintf("%s/%d", ipBlock.IP, ipBlock.PrefixLength))
+	if err != nil {
+		return fmt.Errorf("IPBlock %s/%d with error format: %s", ipBlock.IP, ipBlock.PrefixLength, err)
+	}
+	ones, bits := ipNet.Mask.Size()
+
+	for _, except := range ipBlock.Except {
+		_, exceptNet, err := net.ParseCIDR(except)
+		if err != nil {
+			return fmt.Errorf("Except %s with error format: %s", except, err)
+		}
+		exceptOnes, exceptBits := exceptNet.Mask.Size()
+		if exceptBits != bits || exceptOnes < ones || !ipNet.Contains(exceptNet.IP) {
+			return fmt.Errorf("Except %s must within IPBlock %s", except, ipNet)
+		}
+	}
+
+	return nil
+}
+
+// validateICMPTypeCode validates icmp type and code only used for ICMP, and ICMPCode must used with ICMPType.
+func (v *securityPolicyValidator) validateICMPTypeCode(port securityv1alpha1.SecurityPolicyPort) error {
+	if port.ICMPType == nil && port.ICMPCode == nil {
+		return nil
+	}
+	if port.Protocol != securityv1alpha1.ProtocolICMP {
+		return fmt.Errorf("ICMPType and ICMPCode only valid when protocol is ICMP")
+	}
+	if port.ICMPType == nil {
+		return fmt.Errorf("ICMPCode must specified with ICMPType")
+	}
+	if *port.ICMPType < 0 || *port.ICMPType > 255 {
+		return fmt.Errorf("ICMPType must between 0 and 255")
+	}
+	if port.ICMPCode != nil && (*port.ICMPCode < 0 || *port.ICMPCode > 255) {
+		return fmt.Errorf("ICMPCode must between 0 and 255")
+	}
 	return nil
 }
 
@@ -465,6 +716,36 @@ func (v *securityPolicyValidator) validatePortRange(portRange string) error {
 
 // validateRuleName validates if the name of each rule is unique within a policy and if rule name
 // conforms RFC 1123.
+// validatePolicyTypes validates policyTypes are Ingress or Egress without duplicates, and rules only
+// in the directions of policyTypes.
+func (v *securityPolicyValidator) validatePolicyTypes(policy *securityv1alpha1.SecurityPolicy) error {
+	var policyTypes = sets.NewString()
+
+	for _, policyType := range policy.Spec.PolicyTypes {
+		switch policyType {
+		case securityv1alpha1.PolicyTypeIngress, securityv1alpha1.PolicyTypeEgress:
+		default:
+			return fmt.Errorf("unsupported policyType %s", policyType)
+		}
+		if policyTypes.Has(string(policyType)) {
+			return fmt.Errorf("policyType %s appears more than once", policyType)
+		}
+		policyTypes.Insert(string(policyType))
+	}
+
+	if policyTypes.Len() == 0 {
+		// policy limits both directions
+		return nil
+	}
+	if !policyTypes.Has(string(securityv1alpha1.PolicyTypeIngress)) && len(policy.Spec.IngressRules) != 0 {
+		return fmt.Errorf("ingressRules not allowed without policyType %s", securityv1alpha1.PolicyTypeIngress)
+	}
+	if !policyTypes.Has(string(securityv1alpha1.PolicyTypeEgress)) && len(policy.Spec.EgressRules) != 0 {
+		return fmt.Errorf("egressRules not allowed without policyType %s", securityv1alpha1.PolicyTypeEgress)
+	}
+	return nil
+}
+
 func (v *securityPolicyValidator) validateRuleName(ingress, egress []securityv1alpha1.Rule) error {
 	var uniqueRuleName = sets.NewString()
 
@@ -492,3 +773,63 @@ func (v securityPolicyValidator) updateValidate(oldObj, curObj runtime.Object, u
 func (v securityPolicyValidator) deleteValidate(oldObj runtime.Object, userInfo authv1.UserInfo) (string, bool) {
 	return "", true
 }
+
+// tenantValidator limits tenants could only manage EndpointGroups and SecurityPolicies of their namespaces.
+type tenantValidator resourceValidator
+
+func (v tenantValidator) createValidate(curObj runtime.Object, userInfo authv1.UserInfo) (string, bool) {
+	return authorizeTenant(userInfo, curObj)
+}
+
+func (v tenantValidator) updateValidate(oldObj, curObj runtime.Object, userInfo authv1.UserInfo) (string, bool) {
+	// tenants could neither update objects of other namespaces, nor move objects out of their namespaces
+	return authorizeTenant(userInfo, oldObj, curObj)
+}
+
+func (v tenantValidator) deleteValidate(oldObj runtime.Object, userInfo authv1.UserInfo) (string, bool) {
+	return authorizeTenant(userInfo, oldObj)
+}
+
+// tenantNamespaces returns namespaces of the user from tenant groups.
+func tenantNamespaces(userInfo authv1.UserInfo) sets.String {
+	var namespaces = sets.NewString()
+	for _, group := range userInfo.Groups {
+		if strings.HasPrefix(group, TenantGroupPrefix) {
+			namespaces.Insert(strings.TrimPrefix(group, TenantGroupPrefix))
+		}
+	}
+	return namespaces
+}
+
+// authorizeTenant allows tenants manage EndpointGroups and SecurityPolicies in their namespaces.
+func authorizeTenant(userInfo authv1.UserInfo, objs ...runtime.Object) (string, bool) {
+	namespaces := tenantNamespaces(userInfo)
+	if namespaces.Len() == 0 {
+		// not a tenant, left to RBAC
+		return "", true
+	}
+
+	for _, obj := range objs {
+		switch obj.(type) {
+		case *groupv1alpha1.EndpointGroup, *securityv1alpha1.SecurityPolicy:
+		default:
+			return fmt.Sprintf("tenant %s not allowed to manage %s", userInfo.Username, obj.GetObjectKind().GroupVersionKind().Kind), false
+		}
+
+		objMeta, err := meta.Accessor(obj)
+		if err != nil {
+			return err.Error(), false
+		}
+		if namespace := objMeta.GetLabels()[lynxctrl.NamespaceLabel]; !namespaces.Has(namespace) {
+			return fmt.Sprintf("tenant %s not allowed to manage %s out of namespaces %v", userInfo.Username, objMeta.GetName(), namespaces.List()), false
+		}
+	}
+	return "", true
+}
+
+// inSameNamespace returns true if the referenced object in the tenant namespace of the object,
+// objects not in any tenant namespace could reference objects in all namespaces.
+func inSameNamespace(obj, referenced metav1.Object) bool {
+	namespace := obj.GetLabels()[lynxctrl.NamespaceLabel]
+	return namespace == "" || referenced.GetLabels()[lynxctrl.NamespaceLabel] == namespace
+}
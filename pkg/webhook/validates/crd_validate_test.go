@@ -27,10 +27,13 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	groupv1alpha1 "github.com/smartxworks/lynx/pkg/apis/group/v1alpha1"
 	securityv1alpha1 "github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	lynxctrl "github.com/smartxworks/lynx/pkg/controller"
+	"github.com/smartxworks/lynx/pkg/webhook/validates"
 )
 
 func init() {
@@ -288,6 +291,25 @@ func fakeAdmissionReview(newObject runtime.Object, oldObject runtime.Object, use
 	}
 }
 
+// fakeTenantAdmissionReview create AdmissionReview by giving newObject, oldObject and the user is tenant of namespace
+func fakeTenantAdmissionReview(newObject runtime.Object, oldObject runtime.Object, namespace string) *admv1.AdmissionReview {
+	review := fakeAdmissionReview(newObject, oldObject, "tenant-"+namespace)
+	review.Request.UserInfo.Groups = []string{validates.TenantGroupPrefix + namespace}
+	return review
+}
+
+// withNamespace returns copy of the object in the tenant namespace
+func withNamespace(obj runtime.Object, namespace string) runtime.Object {
+	obj = obj.DeepCopyObject()
+	objMeta := obj.(metav1.Object)
+	objLabels := map[string]string{lynxctrl.NamespaceLabel: namespace}
+	for key, value := range objMeta.GetLabels() {
+		objLabels[key] = value
+	}
+	objMeta.SetLabels(objLabels)
+	return obj
+}
+
 var _ = Describe("CRD Validate", func() {
 
 	Context("Validate On Tier", func() {
@@ -345,6 +367,54 @@ var _ = Describe("CRD Validate", func() {
 			endpointGroupC.Name = "endpoint03"
 			Expect(validate.Validate(fakeAdmissionReview(nil, endpointGroupC, "")).Allowed).Should(BeTrue())
 		})
+		It("Create EndpointGroup with nested groups, endpoints and ipBlocks should allowed", func() {
+			endpointGroup := endpointGroupA.DeepCopy()
+			endpointGroup.Name = "endpointgroup"
+			endpointGroup.Spec.EndpointGroups = []string{endpointGroupA.Name, endpointGroupB.Name}
+			endpointGroup.Spec.Endpoints = []string{endpointA.Name}
+			endpointGroup.Spec.IPBlocks = []securityv1alpha1.IPBlock{{IP: "10.0.0.0", PrefixLength: 8, Except: []string{"10.1.0.0/16"}}}
+			Expect(validate.Validate(fakeAdmissionReview(endpointGroup, nil, "")).Allowed).Should(BeTrue())
+		})
+		It("Create EndpointGroup with unexists nested group should not allowed", func() {
+			endpointGroup := endpointGroupA.DeepCopy()
+			endpointGroup.Name = "endpointgroup"
+			endpointGroup.Spec.EndpointGroups = []string{"UNExist-EndpointGroup-endpointName"}
+			Expect(validate.Validate(fakeAdmissionReview(endpointGroup, nil, "")).Allowed).Should(BeFalse())
+		})
+		It("Create EndpointGroup with error format of IPBlock should not allowed", func() {
+			endpointGroup := endpointGroupA.DeepCopy()
+			endpointGroup.Name = "endpointgroup"
+			endpointGroup.Spec.IPBlocks = []securityv1alpha1.IPBlock{{IP: "10.0.0.0", PrefixLength: 33}}
+			Expect(validate.Validate(fakeAdmissionReview(endpointGroup, nil, "")).Allowed).Should(BeFalse())
+		})
+		It("Update EndpointGroup contains itself should not allowed", func() {
+			endpointGroup := endpointGroupA.DeepCopy()
+			endpointGroup.Spec.EndpointGroups = []string{endpointGroupA.Name}
+			Expect(validate.Validate(fakeAdmissionReview(endpointGroup, endpointGroupA, "")).Allowed).Should(BeFalse())
+		})
+		It("Update EndpointGroup reference cyclically should not allowed", func() {
+			endpointGroupC := endpointGroupA.DeepCopy()
+			endpointGroupC.Name = "group03"
+			endpointGroupC.Spec.EndpointGroups = []string{endpointGroupB.Name}
+			createAndWait(k8sClient, endpointGroupC)
+
+			endpointGroup := endpointGroupB.DeepCopy()
+			endpointGroup.Spec.EndpointGroups = []string{endpointGroupC.Name}
+			Expect(validate.Validate(fakeAdmissionReview(endpointGroup, endpointGroupB, "")).Allowed).Should(BeFalse())
+		})
+		It("Delete EndpointGroup used by other EndpointGroup should not allowed", func() {
+			endpointGroupC := endpointGroupA.DeepCopy()
+			endpointGroupC.Name = "group04"
+			createAndWait(k8sClient, endpointGroupC)
+			endpointGroupD := endpointGroupA.DeepCopy()
+			endpointGroupD.Name = "group05"
+			endpointGroupD.Spec.EndpointGroups = []string{endpointGroupC.Name}
+			createAndWait(k8sClient, endpointGroupD)
+
+			Eventually(func() bool {
+				return validate.Validate(fakeAdmissionReview(nil, endpointGroupC, "")).Allowed
+			}, timeout, interval).Should(BeFalse())
+		})
 	})
 
 	Context("Validate On Endpoint", func() {
@@ -407,21 +477,231 @@ var _ = Describe("CRD Validate", func() {
 			policy.Spec.IngressRules[0].From.IPBlocks[0].PrefixLength = 231
 			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
 		})
+		It("Create priority with IPBlock except out of the block should not allowed", func() {
+			policy := securityPolicyIngress.DeepCopy()
+			policy.Name = "newPolicy"
+			policy.Spec.IngressRules[0].From.IPBlocks[0].Except = []string{"10.0.0.0/8"}
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+		})
+		It("Create priority with error format of IPBlock except should not allowed", func() {
+			policy := securityPolicyIngress.DeepCopy()
+			policy.Name = "newPolicy"
+			policy.Spec.IngressRules[0].From.IPBlocks[0].Except = []string{"192.168.1.0"}
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+		})
+		It("Create priority with IPBlock except within the block should allowed", func() {
+			policy := securityPolicyIngress.DeepCopy()
+			policy.Name = "newPolicy"
+			policy.Spec.IngressRules[0].From.IPBlocks[0].Except = []string{"192.168.1.0/24"}
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeTrue())
+		})
+		It("Create priority with ICMPType and non ICMP protocol should not allowed", func() {
+			policy := securityPolicyIngress.DeepCopy()
+			policy.Name = "newPolicy"
+			policy.Spec.IngressRules[0].Ports[0].ICMPType = pointer.Int32Ptr(8)
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+		})
+		It("Create priority with ICMPCode but no ICMPType should not allowed", func() {
+			policy := securityPolicyIngress.DeepCopy()
+			policy.Name = "newPolicy"
+			policy.Spec.IngressRules[0].Ports[0] = securityv1alpha1.SecurityPolicyPort{
+				Protocol: securityv1alpha1.ProtocolICMP,
+				ICMPCode: pointer.Int32Ptr(4),
+			}
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+		})
+		It("Create priority with ICMPType and ICMPCode should allowed", func() {
+			policy := securityPolicyIngress.DeepCopy()
+			policy.Name = "newPolicy"
+			policy.Spec.IngressRules[0].Ports[0] = securityv1alpha1.SecurityPolicyPort{
+				Protocol: securityv1alpha1.ProtocolICMP,
+				ICMPType: pointer.Int32Ptr(3),
+				ICMPCode: pointer.Int32Ptr(4),
+			}
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeTrue())
+		})
+		It("Create priority with SCTP port should allowed", func() {
+			policy := securityPolicyIngress.DeepCopy()
+			policy.Name = "newPolicy"
+			policy.Spec.IngressRules[0].Ports[0] = securityv1alpha1.SecurityPolicyPort{
+				Protocol:  securityv1alpha1.ProtocolSCTP,
+				PortRange: "3868",
+			}
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeTrue())
+		})
+		It("Create priority with unknown protocol should not allowed", func() {
+			policy := securityPolicyIngress.DeepCopy()
+			policy.Name = "newPolicy"
+			policy.Spec.IngressRules[0].Ports[0].Protocol = "GRE"
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+		})
+		It("Create priority with drop action rule should allowed", func() {
+			policy := securityPolicyIngress.DeepCopy()
+			policy.Name = "newPolicy"
+			policy.Spec.IngressRules[0].Action = securityv1alpha1.RuleActionDrop
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeTrue())
+		})
+		It("Create priority with reject action rule should allowed", func() {
+			policy := securityPolicyIngress.DeepCopy()
+			policy.Name = "newPolicy"
+			policy.Spec.IngressRules[0].Action = securityv1alpha1.RuleActionReject
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeTrue())
+		})
+		It("Create priority with unknown action rule should not allowed", func() {
+			policy := securityPolicyIngress.DeepCopy()
+			policy.Name = "newPolicy"
+			policy.Spec.IngressRules[0].Action = "Accept"
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+		})
+		It("Create priority in monitor mode should allowed", func() {
+			policy := securityPolicyIngress.DeepCopy()
+			policy.Name = "newPolicy"
+			policy.Spec.EnforcementMode = securityv1alpha1.EnforcementModeMonitor
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeTrue())
+		})
+		It("Create priority with unknown enforcement mode should not allowed", func() {
+			policy := securityPolicyIngress.DeepCopy()
+			policy.Name = "newPolicy"
+			policy.Spec.EnforcementMode = "Audit"
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+		})
+		It("Create priority limits only ingress direction should allowed", func() {
+			policy := securityPolicyIngress.DeepCopy()
+			policy.Name = "newPolicy"
+			policy.Spec.PolicyTypes = []securityv1alpha1.PolicyType{securityv1alpha1.PolicyTypeIngress}
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeTrue())
+		})
+		It("Create priority with rules of direction not in policy types should not allowed", func() {
+			policy := securityPolicyEgress.DeepCopy()
+			policy.Name = "newPolicy"
+			policy.Spec.PolicyTypes = []securityv1alpha1.PolicyType{securityv1alpha1.PolicyTypeIngress}
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+		})
+		It("Create priority with duplicate or unknown policy types should not allowed", func() {
+			policy := securityPolicyIngress.DeepCopy()
+			policy.Name = "newPolicy"
+			policy.Spec.PolicyTypes = []securityv1alpha1.PolicyType{securityv1alpha1.PolicyTypeIngress, securityv1alpha1.PolicyTypeIngress}
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+			policy.Spec.PolicyTypes = []securityv1alpha1.PolicyType{"Both"}
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+		})
 		It("Create priority with same rule name should not allowed", func() {
 			policy := securityPolicyEgress.DeepCopy()
 			policy.Name = "newPolicy"
 			policy.Spec.EgressRules[1].Name = policy.Spec.EgressRules[0].Name
 			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
 		})
+		It("Create priority applied to endpoint selector only should allowed", func() {
+			policy := securityPolicyIngress.DeepCopy()
+			policy.Name = "newPolicy"
+			policy.Spec.AppliedTo = securityv1alpha1.AppliedTo{
+				EndpointSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			}
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeTrue())
+		})
+		It("Create priority with empty endpoint selector peer should allowed", func() {
+			policy := securityPolicyIngress.DeepCopy()
+			policy.Name = "newPolicy"
+			policy.Spec.IngressRules[0].From.EndpointSelector = &metav1.LabelSelector{}
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeTrue())
+		})
+		It("Create priority with wrong endpoint selector should not allowed", func() {
+			policy := securityPolicyIngress.DeepCopy()
+			policy.Name = "newPolicy"
+			policy.Spec.IngressRules[0].From.EndpointSelector = &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Unknown"}},
+			}
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+		})
 		It("Create priority with wrong format rule name should not allowed", func() {
 			policy := securityPolicyEgress.DeepCopy()
 			policy.Name = "newPolicy"
 			policy.Spec.EgressRules[0].Name = "rule@name#"
 			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
 		})
+		It("Create priority in tenant namespace with not tenant tier should not allowed", func() {
+			policy := withNamespace(securityPolicyIngress, "tenant-a").(*securityv1alpha1.SecurityPolicy)
+			policy.Name = "newPolicy"
+			policy.Spec.AppliedTo.EndpointGroups = nil
+			policy.Spec.AppliedTo.EndpointSelector = &metav1.LabelSelector{}
+			policy.Spec.IngressRules[0].From.EndpointGroups = nil
+			Expect(validate.Validate(fakeAdmissionReview(policy, nil, "")).Allowed).Should(BeFalse())
+		})
 		It("Delete priority should allows allowed", func() {
 			Expect(validate.Validate(fakeAdmissionReview(nil, securityPolicyEgress, "")).Allowed).Should(BeTrue())
 			Expect(validate.Validate(fakeAdmissionReview(nil, securityPolicyIngress, "")).Allowed).Should(BeTrue())
 		})
 	})
+
+	Context("Validate On Tenant", func() {
+		var tenantTier *securityv1alpha1.Tier
+		var tenantGroup *groupv1alpha1.EndpointGroup
+		var tenantPolicy *securityv1alpha1.SecurityPolicy
+
+		BeforeEach(func() {
+			tenantTier = tierPri50.DeepCopy()
+			tenantTier.Name = "tenant-tier"
+			tenantTier.Labels[lynxctrl.TenantTierLabel] = "true"
+			tenantTier.Spec.Priority = 1000
+			tenantGroup = withNamespace(endpointGroupA, "tenant-a").(*groupv1alpha1.EndpointGroup)
+			tenantGroup.Name = "tenant-group"
+			tenantPolicy = withNamespace(securityPolicyIngress, "tenant-a").(*securityv1alpha1.SecurityPolicy)
+			tenantPolicy.Name = "tenant-policy"
+			tenantPolicy.Spec.Tier = tenantTier.Name
+			tenantPolicy.Spec.AppliedTo.EndpointGroups = []string{tenantGroup.Name}
+			tenantPolicy.Spec.IngressRules[0].From.EndpointGroups = nil
+		})
+		AfterEach(func() {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(context.Background(), tenantTier))).Should(Succeed())
+			Expect(client.IgnoreNotFound(k8sClient.Delete(context.Background(), tenantGroup))).Should(Succeed())
+		})
+
+		It("Tenant create EndpointGroup in its namespace should allowed", func() {
+			Expect(validate.Validate(fakeTenantAdmissionReview(tenantGroup, nil, "tenant-a")).Allowed).Should(BeTrue())
+		})
+		It("Tenant create EndpointGroup in other namespace should not allowed", func() {
+			Expect(validate.Validate(fakeTenantAdmissionReview(tenantGroup, nil, "tenant-b")).Allowed).Should(BeFalse())
+			Expect(validate.Validate(fakeTenantAdmissionReview(endpointGroupA, nil, "tenant-b")).Allowed).Should(BeFalse())
+		})
+		It("Tenant move EndpointGroup out of its namespace should not allowed", func() {
+			group := withNamespace(tenantGroup, "tenant-b")
+			Expect(validate.Validate(fakeTenantAdmissionReview(group, tenantGroup, "tenant-a")).Allowed).Should(BeFalse())
+		})
+		It("User not tenant create EndpointGroup should be left to RBAC", func() {
+			review := fakeAdmissionReview(tenantGroup, nil, "user")
+			review.Request.UserInfo.Groups = []string{"system:authenticated"}
+			Expect(validate.Validate(review).Allowed).Should(BeTrue())
+		})
+		It("Tenant create or delete Tier and Endpoint should not allowed", func() {
+			Expect(validate.Validate(fakeTenantAdmissionReview(tenantTier, nil, "tenant-a")).Allowed).Should(BeFalse())
+			Expect(validate.Validate(fakeTenantAdmissionReview(nil, withNamespace(endpointA, "tenant-a"), "tenant-a")).Allowed).Should(BeFalse())
+		})
+		It("Create EndpointGroup in tenant namespace nested group of other namespace should not allowed", func() {
+			tenantGroup.Spec.EndpointGroups = []string{endpointGroupA.Name}
+			Expect(validate.Validate(fakeTenantAdmissionReview(tenantGroup, nil, "tenant-a")).Allowed).Should(BeFalse())
+		})
+		It("Create tenant tier with higher precedence than other tiers should not allowed", func() {
+			tier := tenantTier.DeepCopy()
+			tier.Spec.Priority = 10
+			Expect(validate.Validate(fakeAdmissionReview(tier, nil, "")).Allowed).Should(BeFalse())
+		})
+		It("Tenant create policy in its namespace should allowed", func() {
+			createAndWait(k8sClient, tenantTier)
+			createAndWait(k8sClient, tenantGroup)
+			Expect(validate.Validate(fakeTenantAdmissionReview(tenantPolicy, nil, "tenant-a")).Allowed).Should(BeTrue())
+		})
+		It("Tenant create policy reference group of other namespace should not allowed", func() {
+			createAndWait(k8sClient, tenantTier)
+			createAndWait(k8sClient, tenantGroup)
+			tenantPolicy.Spec.IngressRules[0].From.EndpointGroups = []string{endpointGroupB.Name}
+			Expect(validate.Validate(fakeTenantAdmissionReview(tenantPolicy, nil, "tenant-a")).Allowed).Should(BeFalse())
+		})
+		It("Create tier with lower precedence than tenant tiers should not allowed", func() {
+			createAndWait(k8sClient, tenantTier)
+			tier := tierPri50.DeepCopy()
+			tier.Name = "tier"
+			tier.Spec.Priority = 2000
+			Expect(validate.Validate(fakeAdmissionReview(tier, nil, "")).Allowed).Should(BeFalse())
+		})
+	})
 })
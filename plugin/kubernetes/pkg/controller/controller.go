@@ -0,0 +1,378 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kubeerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubeinformers "k8s.io/client-go/informers"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	"github.com/smartxworks/lynx/pkg/client/clientset_generated/clientset"
+	crd "github.com/smartxworks/lynx/pkg/client/informers_generated/externalversions"
+	seclisters "github.com/smartxworks/lynx/pkg/client/listers_generated/security/v1alpha1"
+	lynxctrl "github.com/smartxworks/lynx/pkg/controller"
+)
+
+// Controller maintains an Endpoint for each Pod on the cluster, so policies could select Pods the
+// same way as other endpoints.
+type Controller struct {
+	// name of this controller
+	name string
+	// managePlaneID mark the endpoint source to be processed by the controller
+	managePlaneID string
+
+	crdClient clientset.Interface
+
+	podLister         corelisters.PodLister
+	podInformerSynced cache.InformerSynced
+
+	namespaceLister         corelisters.NamespaceLister
+	namespaceInformerSynced cache.InformerSynced
+
+	endpointLister         seclisters.EndpointLister
+	endpointInformerSynced cache.InformerSynced
+
+	endpointQueue workqueue.RateLimitingInterface
+}
+
+const (
+	// endpointNamePrefix distinguishes endpoints of Pods from endpoints of other sources.
+	endpointNamePrefix = "pod."
+
+	// externalIDName is the key of OVS interface external_ids the CNI sets for Pod interfaces,
+	// the value is "<pod name>.<pod namespace>".
+	externalIDName = "iface-id"
+
+	// NamespaceLabelPrefix prefixes labels of the Pod namespace on the endpoint, e.g. namespace label
+	// "team: a" is "ns.team: a" on endpoints of Pods in the namespace, so policies could select Pods
+	// by their namespaces.
+	NamespaceLabelPrefix = "ns."
+
+	// PodLabel marks endpoints of Pods with the Pod name, so policies could tell Pods apart from
+	// endpoints of other sources in the same tenant namespace.
+	PodLabel = "pod.label.lynx.smartx.com"
+)
+
+// New creates a new instance of controller.
+func New(kubeFactory kubeinformers.SharedInformerFactory, crdFactory crd.SharedInformerFactory, crdClient clientset.Interface, resyncPeriod time.Duration) *Controller {
+	podInformer := kubeFactory.Core().V1().Pods()
+	namespaceInformer := kubeFactory.Core().V1().Namespaces()
+	endpointInformer := crdFactory.Security().V1alpha1().Endpoints()
+
+	c := &Controller{
+		name:                    "PodEndpointController",
+		managePlaneID:           "lynx.plugin.kubernetes",
+		crdClient:               crdClient,
+		podLister:               podInformer.Lister(),
+		podInformerSynced:       podInformer.Informer().HasSynced,
+		namespaceLister:         namespaceInformer.Lister(),
+		namespaceInformerSynced: namespaceInformer.Informer().HasSynced,
+		endpointLister:          endpointInformer.Lister(),
+		endpointInformerSynced:  endpointInformer.Informer().HasSynced,
+		endpointQueue:           workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+	}
+
+	podInformer.Informer().AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.addPod,
+			UpdateFunc: c.updatePod,
+			DeleteFunc: c.deletePod,
+		},
+		resyncPeriod,
+	)
+
+	// namespace labels are copied to endpoints, so endpoints of Pods in the namespace must update
+	// when namespace labels changes.
+	namespaceInformer.Informer().AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.addNamespace,
+			UpdateFunc: c.updateNamespace,
+		},
+		resyncPeriod,
+	)
+
+	// endpoints are handled to remove endpoints of Pods deleted while controller not running,
+	// and to resync endpoints unexpectedly modified by other applications.
+	endpointInformer.Informer().AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.addEndpoint,
+			UpdateFunc: c.updateEndpoint,
+			DeleteFunc: c.deleteEndpoint,
+		},
+		resyncPeriod,
+	)
+
+	return c
+}
+
+// Run begins processing items, and will continue until a value is sent down stopCh or it is closed.
+func (c *Controller) Run(workers uint, stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+	defer c.endpointQueue.ShutDown()
+
+	if !cache.WaitForNamedCacheSync(c.name, stopCh, c.podInformerSynced, c.namespaceInformerSynced, c.endpointInformerSynced) {
+		return
+	}
+
+	for i := uint(0); i < workers; i++ {
+		go wait.Until(c.syncEndpointWorker, time.Second, stopCh)
+	}
+
+	<-stopCh
+}
+
+// EndpointName returns name of the endpoint for the Pod.
+func EndpointName(namespace, name string) string {
+	return endpointNamePrefix + namespace + "." + name
+}
+
+// podKeyFromEndpointName returns the Pod key "<namespace>/<name>" of the endpoint, namespace never
+// contains dot, so the first dot after the prefix separates namespace and name.
+func podKeyFromEndpointName(endpointName string) (string, bool) {
+	if !strings.HasPrefix(endpointName, endpointNamePrefix) {
+		return "", false
+	}
+	items := strings.SplitN(strings.TrimPrefix(endpointName, endpointNamePrefix), ".", 2)
+	if len(items) != 2 {
+		return "", false
+	}
+	return items[0] + "/" + items[1], true
+}
+
+func (c *Controller) addPod(new interface{}) {
+	c.enqueuePod(new.(*corev1.Pod))
+}
+
+func (c *Controller) updatePod(old interface{}, new interface{}) {
+	oldPod := old.(*corev1.Pod)
+	newPod := new.(*corev1.Pod)
+
+	if reflect.DeepEqual(oldPod.Labels, newPod.Labels) && oldPod.Status.Phase == newPod.Status.Phase {
+		return
+	}
+	c.enqueuePod(newPod)
+}
+
+func (c *Controller) deletePod(old interface{}) {
+	if d, ok := old.(cache.DeletedFinalStateUnknown); ok {
+		old = d.Obj
+	}
+	c.enqueuePod(old.(*corev1.Pod))
+}
+
+func (c *Controller) enqueuePod(pod *corev1.Pod) {
+	if pod.Spec.HostNetwork {
+		// pods in host network have no interface on the ovs bridge
+		return
+	}
+	c.endpointQueue.Add(pod.Namespace + "/" + pod.Name)
+}
+
+func (c *Controller) addNamespace(new interface{}) {
+	c.enqueueNamespacePods(new.(*corev1.Namespace))
+}
+
+func (c *Controller) updateNamespace(old interface{}, new interface{}) {
+	oldNamespace := old.(*corev1.Namespace)
+	newNamespace := new.(*corev1.Namespace)
+
+	if reflect.DeepEqual(oldNamespace.Labels, newNamespace.Labels) {
+		return
+	}
+	c.enqueueNamespacePods(newNamespace)
+}
+
+func (c *Controller) enqueueNamespacePods(namespace *corev1.Namespace) {
+	pods, err := c.podLister.Pods(namespace.Name).List(labels.Everything())
+	if err != nil {
+		klog.Errorf("unable list pods in namespace %s: %s", namespace.Name, err)
+		return
+	}
+	for _, pod := range pods {
+		c.enqueuePod(pod)
+	}
+}
+
+func (c *Controller) addEndpoint(new interface{}) {
+	c.enqueueEndpoint(new.(*v1alpha1.Endpoint))
+}
+
+func (c *Controller) updateEndpoint(old interface{}, new interface{}) {
+	c.enqueueEndpoint(old.(*v1alpha1.Endpoint))
+	c.enqueueEndpoint(new.(*v1alpha1.Endpoint))
+}
+
+func (c *Controller) deleteEndpoint(old interface{}) {
+	if d, ok := old.(cache.DeletedFinalStateUnknown); ok {
+		old = d.Obj
+	}
+	c.enqueueEndpoint(old.(*v1alpha1.Endpoint))
+}
+
+func (c *Controller) enqueueEndpoint(ep *v1alpha1.Endpoint) {
+	if ep.Spec.ManagePlaneID != c.managePlaneID {
+		return
+	}
+	if podKey, ok := podKeyFromEndpointName(ep.Name); ok {
+		c.endpointQueue.Add(podKey)
+	}
+}
+
+func (c *Controller) syncEndpointWorker() {
+	for {
+		key, quit := c.endpointQueue.Get()
+		if quit {
+			return
+		}
+
+		err := c.syncEndpoint(key.(string))
+		if err != nil {
+			c.endpointQueue.Done(key)
+			c.endpointQueue.AddRateLimited(key)
+			klog.Errorf("got error while sync endpoint of pod %s: %s", key.(string), err)
+			continue
+		}
+
+		// stop the rate limiter from tracking the key
+		c.endpointQueue.Done(key)
+		c.endpointQueue.Forget(key)
+	}
+}
+
+func (c *Controller) syncEndpoint(podKey string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(podKey)
+	if err != nil {
+		return err
+	}
+	endpointName := EndpointName(namespace, name)
+
+	pod, err := c.podLister.Pods(namespace).Get(name)
+	if kubeerror.IsNotFound(err) || (err == nil && !podHasEndpoint(pod)) {
+		return c.processEndpointDelete(endpointName)
+	}
+	if err != nil {
+		return err
+	}
+
+	ns, err := c.namespaceLister.Get(namespace)
+	if err != nil {
+		// namespace of the existing pod not synced yet
+		return fmt.Errorf("get namespace %s receive error: %s", namespace, err)
+	}
+
+	return c.processEndpointUpdate(pod, ns, endpointName)
+}
+
+// podHasEndpoint returns true if the Pod has an interface on the ovs bridge.
+func podHasEndpoint(pod *corev1.Pod) bool {
+	if pod.Spec.HostNetwork {
+		return false
+	}
+	// interfaces of terminated pods have been removed
+	return pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed
+}
+
+func (c *Controller) processEndpointDelete(endpointName string) error {
+	ep, err := c.endpointLister.Get(endpointName)
+	if kubeerror.IsNotFound(err) {
+		// object has been delete already
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if ep.Spec.ManagePlaneID != c.managePlaneID {
+		// never remove endpoints of other sources
+		return nil
+	}
+
+	err = c.crdClient.SecurityV1alpha1().Endpoints().Delete(context.Background(), endpointName, metav1.DeleteOptions{})
+	if err == nil || kubeerror.IsNotFound(err) {
+		klog.Infof("endpoint %s has been delete by %s", endpointName, c.name)
+		return nil
+	}
+	return err
+}
+
+func (c *Controller) processEndpointUpdate(pod *corev1.Pod, ns *corev1.Namespace, endpointName string) error {
+	obj, err := c.endpointLister.Get(endpointName)
+	if kubeerror.IsNotFound(err) {
+		ep := &v1alpha1.Endpoint{}
+		c.setEndpoint(ep, pod, ns)
+
+		klog.Infof("will add endpoint from pod %s/%s: %+v", pod.Namespace, pod.Name, ep)
+		_, err = c.crdClient.SecurityV1alpha1().Endpoints().Create(context.Background(), ep, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("get endpoint receive error: %s", err)
+	}
+
+	ep := obj.DeepCopy()
+	if c.setEndpoint(ep, pod, ns) {
+		klog.Infof("will update endpoint from pod %s/%s: %+v", pod.Namespace, pod.Name, ep)
+
+		_, err = c.crdClient.SecurityV1alpha1().Endpoints().Update(context.Background(), ep, metav1.UpdateOptions{})
+		return err
+	}
+
+	return nil
+}
+
+// set endpoint return false if endpoint not changes
+func (c *Controller) setEndpoint(ep *v1alpha1.Endpoint, pod *corev1.Pod, ns *corev1.Namespace) bool {
+	var epCopy = ep.DeepCopy()
+
+	// use pod labels and prefixed namespace labels as endpoint labels, with the pod namespace as tenant namespace
+	endpointLabels := make(map[string]string, len(pod.Labels)+len(ns.Labels)+2)
+	for key, value := range pod.Labels {
+		endpointLabels[key] = value
+	}
+	for key, value := range ns.Labels {
+		if errs := validation.IsQualifiedName(NamespaceLabelPrefix + key); len(errs) != 0 {
+			klog.Warningf("ignore label %s of namespace %s: %s", key, ns.Name, strings.Join(errs, ", "))
+			continue
+		}
+		endpointLabels[NamespaceLabelPrefix+key] = value
+	}
+	endpointLabels[lynxctrl.NamespaceLabel] = pod.Namespace
+	endpointLabels[PodLabel] = pod.Name
+
+	ep.Name = EndpointName(pod.Namespace, pod.Name)
+	ep.Labels = endpointLabels
+	ep.Spec.ManagePlaneID = c.managePlaneID
+	ep.Spec.Reference.ExternalIDName = externalIDName
+	ep.Spec.Reference.ExternalIDValue = pod.Name + "." + pod.Namespace
+
+	return !reflect.DeepEqual(ep, epCopy)
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	"github.com/smartxworks/lynx/pkg/client/clientset_generated/clientset"
+	"github.com/smartxworks/lynx/pkg/client/clientset_generated/clientset/fake"
+	"github.com/smartxworks/lynx/pkg/client/informers_generated/externalversions"
+	lynxctrl "github.com/smartxworks/lynx/pkg/controller"
+)
+
+var (
+	kubeClient kubernetes.Interface
+	crdClient  clientset.Interface
+	c          *Controller
+)
+
+const (
+	timeout  = 10 * time.Second
+	interval = 100 * time.Millisecond
+)
+
+func TestMain(m *testing.M) {
+	var stopCh = make(chan struct{})
+
+	kubeClient = kubefake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default", Labels: map[string]string{"team": "a"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+	)
+	crdClient = fake.NewSimpleClientset()
+	kubeFactory := kubeinformers.NewSharedInformerFactory(kubeClient, 0)
+	crdFactory := externalversions.NewSharedInformerFactory(crdClient, 0)
+
+	c = New(kubeFactory, crdFactory, crdClient, 0)
+	go c.Run(10, stopCh)
+
+	kubeFactory.Start(stopCh)
+	crdFactory.Start(stopCh)
+
+	os.Exit(m.Run())
+}
+
+func TestPodEndpoint(t *testing.T) {
+	RegisterTestingT(t)
+	ctx := context.Background()
+
+	pod := newTestPod("default", "web-0", map[string]string{"app": "web"})
+	_, err := kubeClient.CoreV1().Pods(pod.Namespace).Create(ctx, pod, metav1.CreateOptions{})
+	Expect(err).ShouldNot(HaveOccurred())
+
+	t.Run("should create endpoint for pod", func(t *testing.T) {
+		Eventually(func() map[string]string {
+			return getEndpointLabels(pod)
+		}, timeout, interval).Should(Equal(map[string]string{"app": "web", "ns.team": "a", lynxctrl.NamespaceLabel: "default", PodLabel: "web-0"}))
+
+		ep, err := crdClient.SecurityV1alpha1().Endpoints().Get(ctx, EndpointName(pod.Namespace, pod.Name), metav1.GetOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ep.Spec.Reference).Should(Equal(v1alpha1.EndpointReference{ExternalIDName: "iface-id", ExternalIDValue: "web-0.default"}))
+	})
+
+	t.Run("should update endpoint labels when pod labels changed", func(t *testing.T) {
+		pod.Labels = map[string]string{"app": "web", "version": "v2"}
+		_, err := kubeClient.CoreV1().Pods(pod.Namespace).Update(ctx, pod, metav1.UpdateOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Eventually(func() map[string]string {
+			return getEndpointLabels(pod)
+		}, timeout, interval).Should(Equal(map[string]string{"app": "web", "version": "v2", "ns.team": "a", lynxctrl.NamespaceLabel: "default", PodLabel: "web-0"}))
+	})
+
+	t.Run("should update endpoint labels when namespace labels changed", func(t *testing.T) {
+		ns, err := kubeClient.CoreV1().Namespaces().Get(ctx, pod.Namespace, metav1.GetOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+		ns.Labels = map[string]string{"team": "b", "app.kubernetes.io/part-of": "shop"}
+		_, err = kubeClient.CoreV1().Namespaces().Update(ctx, ns, metav1.UpdateOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Eventually(func() map[string]string {
+			return getEndpointLabels(pod)
+		}, timeout, interval).Should(Equal(map[string]string{"app": "web", "version": "v2", "ns.team": "b",
+			"ns.app.kubernetes.io/part-of": "shop", lynxctrl.NamespaceLabel: "default", PodLabel: "web-0"}))
+	})
+
+	t.Run("should remove endpoint when pod terminated", func(t *testing.T) {
+		pod.Status.Phase = corev1.PodSucceeded
+		_, err := kubeClient.CoreV1().Pods(pod.Namespace).Update(ctx, pod, metav1.UpdateOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Eventually(func() map[string]string {
+			return getEndpointLabels(pod)
+		}, timeout, interval).Should(BeNil())
+	})
+
+	t.Run("should remove endpoint when pod deleted", func(t *testing.T) {
+		pod := newTestPod("default", "web-1", nil)
+		_, err := kubeClient.CoreV1().Pods(pod.Namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+		Eventually(func() map[string]string {
+			return getEndpointLabels(pod)
+		}, timeout, interval).ShouldNot(BeNil())
+
+		Expect(kubeClient.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{})).Should(Succeed())
+		Eventually(func() map[string]string {
+			return getEndpointLabels(pod)
+		}, timeout, interval).Should(BeNil())
+	})
+
+	t.Run("should ignore pod in host network", func(t *testing.T) {
+		pod := newTestPod("kube-system", "host-0", nil)
+		pod.Spec.HostNetwork = true
+		_, err := kubeClient.CoreV1().Pods(pod.Namespace).Create(ctx, pod, metav1.CreateOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Consistently(func() map[string]string {
+			return getEndpointLabels(pod)
+		}, time.Second, interval).Should(BeNil())
+	})
+
+	t.Run("should not remove endpoint of other source", func(t *testing.T) {
+		ep := &v1alpha1.Endpoint{}
+		ep.Name = EndpointName("default", "other-source")
+		ep.Spec.ManagePlaneID = "lynx.plugin.static"
+		_, err := crdClient.SecurityV1alpha1().Endpoints().Create(ctx, ep, metav1.CreateOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Eventually(func() error {
+			_, err := c.endpointLister.Get(ep.Name)
+			return err
+		}, timeout, interval).Should(Succeed())
+
+		// the endpoint name is of a pod not exist, as if the pod has been deleted
+		Expect(c.processEndpointDelete(ep.Name)).Should(Succeed())
+		Consistently(func() error {
+			_, err := crdClient.SecurityV1alpha1().Endpoints().Get(ctx, ep.Name, metav1.GetOptions{})
+			return err
+		}, time.Second, interval).Should(Succeed())
+	})
+
+	t.Run("should remove endpoint of pod not exist", func(t *testing.T) {
+		ep := &v1alpha1.Endpoint{}
+		ep.Name = EndpointName("default", "not-exist")
+		ep.Spec.ManagePlaneID = "lynx.plugin.kubernetes"
+		_, err := crdClient.SecurityV1alpha1().Endpoints().Create(ctx, ep, metav1.CreateOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Eventually(func() bool {
+			_, err := crdClient.SecurityV1alpha1().Endpoints().Get(ctx, ep.Name, metav1.GetOptions{})
+			return err != nil
+		}, timeout, interval).Should(BeTrue())
+	})
+}
+
+// getEndpointLabels returns labels of the pod endpoint, and nil if the endpoint not exists.
+func getEndpointLabels(pod *corev1.Pod) map[string]string {
+	ep, err := crdClient.SecurityV1alpha1().Endpoints().Get(context.Background(), EndpointName(pod.Namespace, pod.Name), metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+	return ep.Labels
+}
+
+func newTestPod(namespace, name string, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Labels:    labels,
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+		},
+	}
+}
@@ -0,0 +1,366 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkpolicy
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	kubeerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubeinformers "k8s.io/client-go/informers"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	networkinglisters "k8s.io/client-go/listers/networking/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	"github.com/smartxworks/lynx/pkg/client/clientset_generated/clientset"
+	crd "github.com/smartxworks/lynx/pkg/client/informers_generated/externalversions"
+	seclisters "github.com/smartxworks/lynx/pkg/client/listers_generated/security/v1alpha1"
+)
+
+const (
+	// ManagedLabel marks the SecurityPolicies translated from NetworkPolicies by the controller.
+	ManagedLabel = "networkpolicy.label.lynx.smartx.com"
+
+	// OwnerAnnotation records the "<namespace>/<name>" of the NetworkPolicy the SecurityPolicy
+	// translated from. A cluster scoped object could not own by namespaced object with ownerReferences,
+	// the garbage collector would remove it immediately, so the controller removes orphans instead.
+	OwnerAnnotation = "networkpolicy.annotation.lynx.smartx.com"
+)
+
+// Controller translates each NetworkPolicy on the cluster into a SecurityPolicy in the dedicated tier,
+// and keeps them in sync.
+type Controller struct {
+	// name of this controller
+	name string
+	// tier holds SecurityPolicies translated from NetworkPolicies
+	tier         string
+	tierPriority int32
+
+	crdClient clientset.Interface
+
+	networkPolicyLister         networkinglisters.NetworkPolicyLister
+	networkPolicyInformerSynced cache.InformerSynced
+
+	namespaceLister         corelisters.NamespaceLister
+	namespaceInformerSynced cache.InformerSynced
+
+	policyLister         seclisters.SecurityPolicyLister
+	policyInformerSynced cache.InformerSynced
+
+	tierLister         seclisters.TierLister
+	tierInformerSynced cache.InformerSynced
+
+	policyQueue workqueue.RateLimitingInterface
+}
+
+// New creates a new instance of controller.
+func New(kubeFactory kubeinformers.SharedInformerFactory, crdFactory crd.SharedInformerFactory, crdClient clientset.Interface,
+	resyncPeriod time.Duration, tier string, tierPriority int32) *Controller {
+	networkPolicyInformer := kubeFactory.Networking().V1().NetworkPolicies()
+	namespaceInformer := kubeFactory.Core().V1().Namespaces()
+	policyInformer := crdFactory.Security().V1alpha1().SecurityPolicies()
+	tierInformer := crdFactory.Security().V1alpha1().Tiers()
+
+	c := &Controller{
+		name:                        "NetworkPolicyController",
+		tier:                        tier,
+		tierPriority:                tierPriority,
+		crdClient:                   crdClient,
+		networkPolicyLister:         networkPolicyInformer.Lister(),
+		networkPolicyInformerSynced: networkPolicyInformer.Informer().HasSynced,
+		namespaceLister:             namespaceInformer.Lister(),
+		namespaceInformerSynced:     namespaceInformer.Informer().HasSynced,
+		policyLister:                policyInformer.Lister(),
+		policyInformerSynced:        policyInformer.Informer().HasSynced,
+		tierLister:                  tierInformer.Lister(),
+		tierInformerSynced:          tierInformer.Informer().HasSynced,
+		policyQueue:                 workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+	}
+
+	networkPolicyInformer.Informer().AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.addNetworkPolicy,
+			UpdateFunc: c.updateNetworkPolicy,
+			DeleteFunc: c.deleteNetworkPolicy,
+		},
+		resyncPeriod,
+	)
+
+	// namespaceSelectors of peers are resolved with namespace labels, so policies must translate
+	// again when namespaces changes.
+	namespaceInformer.Informer().AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.addNamespace,
+			UpdateFunc: c.updateNamespace,
+			DeleteFunc: c.deleteNamespace,
+		},
+		resyncPeriod,
+	)
+
+	// policies are handled to remove policies of NetworkPolicies deleted while controller not running,
+	// and to resync policies unexpectedly modified by other applications.
+	policyInformer.Informer().AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.addPolicy,
+			UpdateFunc: c.updatePolicy,
+			DeleteFunc: c.deletePolicy,
+		},
+		resyncPeriod,
+	)
+
+	// informer of tiers must be registered before the factory starts
+	tierInformer.Informer()
+
+	return c
+}
+
+// Run begins processing items, and will continue until a value is sent down stopCh or it is closed.
+func (c *Controller) Run(workers uint, stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+	defer c.policyQueue.ShutDown()
+
+	if !cache.WaitForNamedCacheSync(c.name, stopCh, c.networkPolicyInformerSynced, c.namespaceInformerSynced,
+		c.policyInformerSynced, c.tierInformerSynced) {
+		return
+	}
+
+	for i := uint(0); i < workers; i++ {
+		go wait.Until(c.syncPolicyWorker, time.Second, stopCh)
+	}
+
+	<-stopCh
+}
+
+func (c *Controller) addNetworkPolicy(new interface{}) {
+	c.enqueueNetworkPolicy(new.(*networkingv1.NetworkPolicy))
+}
+
+func (c *Controller) updateNetworkPolicy(old interface{}, new interface{}) {
+	oldPolicy := old.(*networkingv1.NetworkPolicy)
+	newPolicy := new.(*networkingv1.NetworkPolicy)
+
+	if reflect.DeepEqual(oldPolicy.Spec, newPolicy.Spec) {
+		return
+	}
+	c.enqueueNetworkPolicy(newPolicy)
+}
+
+func (c *Controller) deleteNetworkPolicy(old interface{}) {
+	if d, ok := old.(cache.DeletedFinalStateUnknown); ok {
+		old = d.Obj
+	}
+	c.enqueueNetworkPolicy(old.(*networkingv1.NetworkPolicy))
+}
+
+func (c *Controller) enqueueNetworkPolicy(np *networkingv1.NetworkPolicy) {
+	c.policyQueue.Add(np.Namespace + "/" + np.Name)
+}
+
+func (c *Controller) addNamespace(new interface{}) {
+	c.enqueueAllNetworkPolicies()
+}
+
+func (c *Controller) updateNamespace(old interface{}, new interface{}) {
+	oldNamespace := old.(*corev1.Namespace)
+	newNamespace := new.(*corev1.Namespace)
+
+	if reflect.DeepEqual(oldNamespace.Labels, newNamespace.Labels) {
+		return
+	}
+	c.enqueueAllNetworkPolicies()
+}
+
+func (c *Controller) deleteNamespace(old interface{}) {
+	c.enqueueAllNetworkPolicies()
+}
+
+func (c *Controller) enqueueAllNetworkPolicies() {
+	networkPolicies, err := c.networkPolicyLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list networkpolicies: %s", err)
+		return
+	}
+	for _, np := range networkPolicies {
+		c.enqueueNetworkPolicy(np)
+	}
+}
+
+func (c *Controller) addPolicy(new interface{}) {
+	c.enqueuePolicy(new.(*v1alpha1.SecurityPolicy))
+}
+
+func (c *Controller) updatePolicy(old interface{}, new interface{}) {
+	c.enqueuePolicy(old.(*v1alpha1.SecurityPolicy))
+	c.enqueuePolicy(new.(*v1alpha1.SecurityPolicy))
+}
+
+func (c *Controller) deletePolicy(old interface{}) {
+	if d, ok := old.(cache.DeletedFinalStateUnknown); ok {
+		old = d.Obj
+	}
+	c.enqueuePolicy(old.(*v1alpha1.SecurityPolicy))
+}
+
+func (c *Controller) enqueuePolicy(policy *v1alpha1.SecurityPolicy) {
+	if policy.Labels[ManagedLabel] != "true" {
+		return
+	}
+	if owner, ok := policy.Annotations[OwnerAnnotation]; ok {
+		c.policyQueue.Add(owner)
+	}
+}
+
+func (c *Controller) syncPolicyWorker() {
+	for {
+		key, quit := c.policyQueue.Get()
+		if quit {
+			return
+		}
+
+		err := c.syncPolicy(key.(string))
+		if err != nil {
+			c.policyQueue.Done(key)
+			c.policyQueue.AddRateLimited(key)
+			klog.Errorf("got error while sync policy of networkpolicy %s: %s", key.(string), err)
+			continue
+		}
+
+		// stop the rate limiter from tracking the key
+		c.policyQueue.Done(key)
+		c.policyQueue.Forget(key)
+	}
+}
+
+func (c *Controller) syncPolicy(networkPolicyKey string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(networkPolicyKey)
+	if err != nil {
+		return err
+	}
+	policyName := PolicyName(namespace, name)
+
+	np, err := c.networkPolicyLister.NetworkPolicies(namespace).Get(name)
+	if kubeerror.IsNotFound(err) {
+		return c.processPolicyDelete(policyName)
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.processPolicyUpdate(np, networkPolicyKey)
+}
+
+func (c *Controller) processPolicyDelete(policyName string) error {
+	policy, err := c.policyLister.Get(policyName)
+	if kubeerror.IsNotFound(err) {
+		// object has been delete already
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if policy.Labels[ManagedLabel] != "true" {
+		// never remove policies not managed by the controller
+		return nil
+	}
+
+	err = c.crdClient.SecurityV1alpha1().SecurityPolicies().Delete(context.Background(), policyName, metav1.DeleteOptions{})
+	if err == nil || kubeerror.IsNotFound(err) {
+		klog.Infof("policy %s has been delete by %s", policyName, c.name)
+		return nil
+	}
+	return err
+}
+
+func (c *Controller) processPolicyUpdate(np *networkingv1.NetworkPolicy, networkPolicyKey string) error {
+	namespaces, err := c.namespaceLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("list namespaces: %s", err)
+	}
+
+	translated, err := Translate(np, namespaces, c.tier)
+	if err != nil {
+		// the policy could not translate until it has been updated, remove the previously translated
+		// policy instead of leaving it enforces stale rules
+		klog.Errorf("unable translate networkpolicy %s: %s", networkPolicyKey, err)
+		return c.processPolicyDelete(PolicyName(np.Namespace, np.Name))
+	}
+	translated.Labels = map[string]string{ManagedLabel: "true"}
+	translated.Annotations = map[string]string{OwnerAnnotation: networkPolicyKey}
+
+	if err = c.createTierIfNotExist(); err != nil {
+		return fmt.Errorf("create tier %s: %s", c.tier, err)
+	}
+
+	obj, err := c.policyLister.Get(translated.Name)
+	if kubeerror.IsNotFound(err) {
+		klog.Infof("will add policy from networkpolicy %s: %+v", networkPolicyKey, translated)
+		_, err = c.crdClient.SecurityV1alpha1().SecurityPolicies().Create(context.Background(), translated, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("get policy receive error: %s", err)
+	}
+
+	policy := obj.DeepCopy()
+	policy.Labels = translated.Labels
+	policy.Annotations = translated.Annotations
+	policy.Spec = translated.Spec
+
+	if !reflect.DeepEqual(policy, obj) {
+		klog.Infof("will update policy from networkpolicy %s: %+v", networkPolicyKey, policy)
+		_, err = c.crdClient.SecurityV1alpha1().SecurityPolicies().Update(context.Background(), policy, metav1.UpdateOptions{})
+		return err
+	}
+
+	return nil
+}
+
+// createTierIfNotExist creates the whitelist tier translated policies in. Policies in the whitelist tier
+// drop traffics not allowed by default, the same as isolated pods of NetworkPolicies.
+func (c *Controller) createTierIfNotExist() error {
+	_, err := c.tierLister.Get(c.tier)
+	if !kubeerror.IsNotFound(err) {
+		return err
+	}
+
+	tier := &v1alpha1.Tier{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: c.tier,
+		},
+		Spec: v1alpha1.TierSpec{
+			Description: "tier of policies translated from kubernetes networkpolicies",
+			Priority:    c.tierPriority,
+			TierMode:    v1alpha1.TierWhiteList,
+		},
+	}
+	_, err = c.crdClient.SecurityV1alpha1().Tiers().Create(context.Background(), tier, metav1.CreateOptions{})
+	if kubeerror.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkpolicy
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	"github.com/smartxworks/lynx/pkg/client/clientset_generated/clientset"
+	"github.com/smartxworks/lynx/pkg/client/clientset_generated/clientset/fake"
+	"github.com/smartxworks/lynx/pkg/client/informers_generated/externalversions"
+)
+
+var (
+	kubeClient kubernetes.Interface
+	crdClient  clientset.Interface
+)
+
+const (
+	timeout  = 10 * time.Second
+	interval = 100 * time.Millisecond
+
+	testTier = "tier-np"
+)
+
+func TestMain(m *testing.M) {
+	var stopCh = make(chan struct{})
+
+	kubeClient = kubefake.NewSimpleClientset()
+	crdClient = fake.NewSimpleClientset()
+	kubeFactory := kubeinformers.NewSharedInformerFactory(kubeClient, 0)
+	crdFactory := externalversions.NewSharedInformerFactory(crdClient, 0)
+
+	ctroller := New(kubeFactory, crdFactory, crdClient, 0, testTier, 50)
+	go ctroller.Run(10, stopCh)
+
+	kubeFactory.Start(stopCh)
+	crdFactory.Start(stopCh)
+
+	os.Exit(m.Run())
+}
+
+func TestNetworkPolicy(t *testing.T) {
+	RegisterTestingT(t)
+	ctx := context.Background()
+
+	_, err := kubeClient.CoreV1().Namespaces().Create(ctx, newTestNamespace("default", map[string]string{"env": "test"}), metav1.CreateOptions{})
+	Expect(err).ShouldNot(HaveOccurred())
+
+	np := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "deny-all"},
+	}
+	_, err = kubeClient.NetworkingV1().NetworkPolicies(np.Namespace).Create(ctx, np, metav1.CreateOptions{})
+	Expect(err).ShouldNot(HaveOccurred())
+
+	t.Run("should create tier and policy for networkpolicy", func(t *testing.T) {
+		Eventually(func() *v1alpha1.SecurityPolicy {
+			return getPolicy(np)
+		}, timeout, interval).ShouldNot(BeNil())
+
+		policy := getPolicy(np)
+		Expect(policy.Labels).Should(HaveKeyWithValue(ManagedLabel, "true"))
+		Expect(policy.Annotations).Should(HaveKeyWithValue(OwnerAnnotation, "default/deny-all"))
+		Expect(policy.Spec.Tier).Should(Equal(testTier))
+		Expect(policy.Spec.IngressRules).Should(BeEmpty())
+
+		tier, err := crdClient.SecurityV1alpha1().Tiers().Get(ctx, testTier, metav1.GetOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(tier.Spec.TierMode).Should(Equal(v1alpha1.TierWhiteList))
+		Expect(tier.Spec.Priority).Should(Equal(int32(50)))
+	})
+
+	t.Run("should update policy when networkpolicy changed", func(t *testing.T) {
+		np.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{{}}
+		_, err := kubeClient.NetworkingV1().NetworkPolicies(np.Namespace).Update(ctx, np, metav1.UpdateOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Eventually(func() []v1alpha1.Rule {
+			return getPolicy(np).Spec.IngressRules
+		}, timeout, interval).Should(Equal([]v1alpha1.Rule{{Name: "ingress-0"}}))
+	})
+
+	t.Run("should update policy when namespace labels changed", func(t *testing.T) {
+		np.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{{
+			From: []networkingv1.NetworkPolicyPeer{
+				{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}},
+			},
+		}}
+		_, err := kubeClient.NetworkingV1().NetworkPolicies(np.Namespace).Update(ctx, np, metav1.UpdateOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, err = kubeClient.CoreV1().Namespaces().Create(ctx, newTestNamespace("web", map[string]string{"env": "prod"}), metav1.CreateOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Eventually(func() *metav1.LabelSelector {
+			return getPolicy(np).Spec.IngressRules[0].From.EndpointSelector
+		}, timeout, interval).Should(Equal(selectorInNamespaces(nil, "web")))
+	})
+
+	t.Run("should remove policy when networkpolicy could not translate", func(t *testing.T) {
+		np.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{{
+			From: []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "invalid"}}},
+		}}
+		_, err := kubeClient.NetworkingV1().NetworkPolicies(np.Namespace).Update(ctx, np, metav1.UpdateOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Eventually(func() *v1alpha1.SecurityPolicy {
+			return getPolicy(np)
+		}, timeout, interval).Should(BeNil())
+	})
+
+	t.Run("should remove policy when networkpolicy deleted", func(t *testing.T) {
+		Expect(kubeClient.NetworkingV1().NetworkPolicies(np.Namespace).Delete(ctx, np.Name, metav1.DeleteOptions{})).Should(Succeed())
+
+		Eventually(func() *v1alpha1.SecurityPolicy {
+			return getPolicy(np)
+		}, timeout, interval).Should(BeNil())
+	})
+
+	t.Run("should remove managed policy of networkpolicy not exist", func(t *testing.T) {
+		policy := &v1alpha1.SecurityPolicy{}
+		policy.Name = PolicyName("default", "not-exist")
+		policy.Labels = map[string]string{ManagedLabel: "true"}
+		policy.Annotations = map[string]string{OwnerAnnotation: "default/not-exist"}
+		_, err := crdClient.SecurityV1alpha1().SecurityPolicies().Create(ctx, policy, metav1.CreateOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Eventually(func() bool {
+			_, err := crdClient.SecurityV1alpha1().SecurityPolicies().Get(ctx, policy.Name, metav1.GetOptions{})
+			return err != nil
+		}, timeout, interval).Should(BeTrue())
+	})
+}
+
+// getPolicy returns the policy translated from the networkpolicy, and nil if the policy not exists.
+func getPolicy(np *networkingv1.NetworkPolicy) *v1alpha1.SecurityPolicy {
+	policy, err := crdClient.SecurityV1alpha1().SecurityPolicies().Get(context.Background(), PolicyName(np.Namespace, np.Name), metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+	return policy
+}
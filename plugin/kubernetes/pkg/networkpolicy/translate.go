@@ -0,0 +1,281 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkpolicy
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+
+	"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	lynxctrl "github.com/smartxworks/lynx/pkg/controller"
+	"github.com/smartxworks/lynx/pkg/types"
+	podctrl "github.com/smartxworks/lynx/plugin/kubernetes/pkg/controller"
+)
+
+const (
+	// policyNamePrefix distinguishes policies translated from NetworkPolicies from other policies.
+	policyNamePrefix = "np."
+
+	// policyPriority is the priority of all translated policies, allowed traffics of NetworkPolicies
+	// are combined using a logical OR like kubernetes does.
+	policyPriority = 10
+)
+
+// PolicyName returns name of the SecurityPolicy translated from the NetworkPolicy.
+func PolicyName(namespace, name string) string {
+	return policyNamePrefix + namespace + "." + name
+}
+
+// Translate returns the SecurityPolicy in the tier equivalent to the NetworkPolicy. The tier must be
+// in whitelist mode. Namespaces are used to resolve namespaceSelectors of peers, so the policy must be
+// translated again when the namespaces or their labels changed.
+func Translate(np *networkingv1.NetworkPolicy, namespaces []*corev1.Namespace, tier string) (*v1alpha1.SecurityPolicy, error) {
+	var ingressIsolated, egressIsolated = policyIsolation(np)
+
+	appliedToSelector, err := scopedSelector(&np.Spec.PodSelector, namespaceRequirement(np.Namespace))
+	if err != nil {
+		return nil, fmt.Errorf("podSelector: %s", err)
+	}
+
+	policy := &v1alpha1.SecurityPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: PolicyName(np.Namespace, np.Name),
+		},
+		Spec: v1alpha1.SecurityPolicySpec{
+			Tier:      tier,
+			Priority:  policyPriority,
+			AppliedTo: v1alpha1.AppliedTo{EndpointSelector: appliedToSelector},
+		},
+	}
+
+	// the direction not isolated is left out of the policy, so that it never drops traffic
+	// allowed by other NetworkPolicies selecting the same pods
+	if ingressIsolated {
+		policy.Spec.PolicyTypes = append(policy.Spec.PolicyTypes, v1alpha1.PolicyTypeIngress)
+	}
+	if egressIsolated {
+		policy.Spec.PolicyTypes = append(policy.Spec.PolicyTypes, v1alpha1.PolicyTypeEgress)
+	}
+
+	for item, rule := range np.Spec.Ingress {
+		if !ingressIsolated {
+			break
+		}
+		rules, err := translateRule(fmt.Sprintf("ingress-%d", item), np.Namespace, rule.From, rule.Ports, namespaces)
+		if err != nil {
+			return nil, fmt.Errorf("ingress rule %d: %s", item, err)
+		}
+		for index := range rules {
+			rules[index].From, rules[index].To = rules[index].To, v1alpha1.SecurityPolicyPeer{}
+		}
+		policy.Spec.IngressRules = append(policy.Spec.IngressRules, rules...)
+	}
+
+	for item, rule := range np.Spec.Egress {
+		if !egressIsolated {
+			break
+		}
+		rules, err := translateRule(fmt.Sprintf("egress-%d", item), np.Namespace, rule.To, rule.Ports, namespaces)
+		if err != nil {
+			return nil, fmt.Errorf("egress rule %d: %s", item, err)
+		}
+		policy.Spec.EgressRules = append(policy.Spec.EgressRules, rules...)
+	}
+
+	return policy, nil
+}
+
+// policyIsolation returns the directions the NetworkPolicy isolates. Like kubernetes, policy without
+// policyTypes always isolates ingress, and isolates egress if it has any egress rules.
+func policyIsolation(np *networkingv1.NetworkPolicy) (ingress bool, egress bool) {
+	if len(np.Spec.PolicyTypes) == 0 {
+		return true, len(np.Spec.Egress) != 0
+	}
+	for _, policyType := range np.Spec.PolicyTypes {
+		switch policyType {
+		case networkingv1.PolicyTypeIngress:
+			ingress = true
+		case networkingv1.PolicyTypeEgress:
+			egress = true
+		}
+	}
+	return ingress, egress
+}
+
+// translateRule translates ingress or egress rule of NetworkPolicy into rules with peers in To. The lynx
+// rule has only one endpoint selector, so each pod or namespace peer is translated into a separate rule,
+// ipBlock peers are translated into a rule together.
+func translateRule(name string, namespace string, peers []networkingv1.NetworkPolicyPeer, ports []networkingv1.NetworkPolicyPort, namespaces []*corev1.Namespace) ([]v1alpha1.Rule, error) {
+	var rules []v1alpha1.Rule
+	var ipBlockRule = v1alpha1.Rule{Name: name + "-ipblocks"}
+
+	rulePorts, ok := translatePorts(ports)
+	if !ok {
+		// all of the ports are not supported, the rule allows nothing
+		return nil, nil
+	}
+
+	if len(peers) == 0 {
+		// empty peers matches all sources or destinations
+		return []v1alpha1.Rule{{Name: name, Ports: rulePorts}}, nil
+	}
+
+	for item, peer := range peers {
+		if peer.IPBlock != nil {
+			ipBlock, err := translateIPBlock(peer.IPBlock)
+			if err != nil {
+				return nil, err
+			}
+			ipBlockRule.To.IPBlocks = append(ipBlockRule.To.IPBlocks, *ipBlock)
+			continue
+		}
+
+		selector, err := peerSelector(namespace, &peer, namespaces)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, v1alpha1.Rule{
+			Name:  fmt.Sprintf("%s-%d", name, item),
+			Ports: rulePorts,
+			To:    v1alpha1.SecurityPolicyPeer{EndpointSelector: selector},
+		})
+	}
+
+	if len(ipBlockRule.To.IPBlocks) != 0 {
+		ipBlockRule.Ports = rulePorts
+		rules = append(rules, ipBlockRule)
+	}
+
+	return rules, nil
+}
+
+// translatePorts returns false if none of the ports supported. Named ports are not supported, because
+// endpoints don't know ports of the pod containers.
+func translatePorts(ports []networkingv1.NetworkPolicyPort) ([]v1alpha1.SecurityPolicyPort, bool) {
+	var rulePorts []v1alpha1.SecurityPolicyPort
+
+	for _, port := range ports {
+		rulePort := v1alpha1.SecurityPolicyPort{Protocol: v1alpha1.ProtocolTCP}
+		if port.Protocol != nil {
+			rulePort.Protocol = v1alpha1.Protocol(*port.Protocol)
+		}
+
+		if port.Port != nil {
+			if port.Port.Type == intstr.String {
+				if _, err := strconv.Atoi(port.Port.StrVal); err != nil {
+					klog.Warningf("named port %s is not supported, ignore it", port.Port.StrVal)
+					continue
+				}
+			}
+			rulePort.PortRange = port.Port.String()
+		}
+		rulePorts = append(rulePorts, rulePort)
+	}
+
+	return rulePorts, len(ports) == 0 || len(rulePorts) != 0
+}
+
+func translateIPBlock(ipBlock *networkingv1.IPBlock) (*v1alpha1.IPBlock, error) {
+	_, ipNet, err := net.ParseCIDR(ipBlock.CIDR)
+	if err != nil {
+		return nil, fmt.Errorf("ipBlock %s: %s", ipBlock.CIDR, err)
+	}
+	prefixLength, _ := ipNet.Mask.Size()
+
+	return &v1alpha1.IPBlock{
+		IP:           types.IPAddress(ipNet.IP.String()),
+		PrefixLength: int32(prefixLength),
+		Except:       ipBlock.Except,
+	}, nil
+}
+
+// peerSelector returns the endpoint selector selects pods of the peer. Peer without namespaceSelector
+// selects pods in the namespace of the policy.
+func peerSelector(namespace string, peer *networkingv1.NetworkPolicyPeer, namespaces []*corev1.Namespace) (*metav1.LabelSelector, error) {
+	var podSelector = peer.PodSelector
+	if podSelector == nil {
+		podSelector = &metav1.LabelSelector{}
+	}
+
+	if peer.NamespaceSelector == nil {
+		return scopedSelector(podSelector, namespaceRequirement(namespace))
+	}
+
+	nsSelector, err := metav1.LabelSelectorAsSelector(peer.NamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("namespaceSelector: %s", err)
+	}
+	if nsSelector.Empty() {
+		// empty namespaceSelector selects pods in all namespaces
+		return scopedSelector(podSelector, metav1.LabelSelectorRequirement{
+			Key:      lynxctrl.NamespaceLabel,
+			Operator: metav1.LabelSelectorOpExists,
+		})
+	}
+
+	var selectedNamespaces = sets.NewString()
+	for _, ns := range namespaces {
+		if nsSelector.Matches(labels.Set(ns.Labels)) {
+			selectedNamespaces.Insert(ns.Name)
+		}
+	}
+	if selectedNamespaces.Len() == 0 {
+		// no namespace selected, the selector must match nothing
+		return scopedSelector(podSelector, metav1.LabelSelectorRequirement{
+			Key:      lynxctrl.NamespaceLabel,
+			Operator: metav1.LabelSelectorOpExists,
+		}, metav1.LabelSelectorRequirement{
+			Key:      lynxctrl.NamespaceLabel,
+			Operator: metav1.LabelSelectorOpDoesNotExist,
+		})
+	}
+
+	return scopedSelector(podSelector, namespaceRequirement(selectedNamespaces.List()...))
+}
+
+func namespaceRequirement(namespaces ...string) metav1.LabelSelectorRequirement {
+	return metav1.LabelSelectorRequirement{
+		Key:      lynxctrl.NamespaceLabel,
+		Operator: metav1.LabelSelectorOpIn,
+		Values:   namespaces,
+	}
+}
+
+// scopedSelector returns copy of the pod selector with additional namespace requirements. The selector
+// only selects endpoints of pods, other endpoints in tenant namespaces are never selected.
+func scopedSelector(podSelector *metav1.LabelSelector, requirements ...metav1.LabelSelectorRequirement) (*metav1.LabelSelector, error) {
+	if _, err := metav1.LabelSelectorAsSelector(podSelector); err != nil {
+		return nil, err
+	}
+
+	selector := podSelector.DeepCopy()
+	selector.MatchExpressions = append(selector.MatchExpressions, requirements...)
+	selector.MatchExpressions = append(selector.MatchExpressions, metav1.LabelSelectorRequirement{
+		Key:      podctrl.PodLabel,
+		Operator: metav1.LabelSelectorOpExists,
+	})
+	return selector, nil
+}
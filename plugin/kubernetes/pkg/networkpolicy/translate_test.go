@@ -0,0 +1,289 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkpolicy
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	lynxctrl "github.com/smartxworks/lynx/pkg/controller"
+	"github.com/smartxworks/lynx/pkg/simulator"
+	"github.com/smartxworks/lynx/pkg/types"
+	podctrl "github.com/smartxworks/lynx/plugin/kubernetes/pkg/controller"
+)
+
+func TestTranslate(t *testing.T) {
+	var protocolUDP = corev1.ProtocolUDP
+	var port80 = intstr.FromInt(80)
+	var portNamed = intstr.FromString("http")
+	var namespaces = []*corev1.Namespace{
+		newTestNamespace("default", map[string]string{"env": "test"}),
+		newTestNamespace("web", map[string]string{"env": "prod"}),
+		newTestNamespace("db", map[string]string{"env": "prod"}),
+	}
+
+	testCases := map[string]struct {
+		spec         networkingv1.NetworkPolicySpec
+		expectSpec   v1alpha1.SecurityPolicySpec
+		expectFailed bool
+	}{
+		"should deny all ingress and not limit egress": {
+			spec: networkingv1.NetworkPolicySpec{},
+			expectSpec: v1alpha1.SecurityPolicySpec{
+				PolicyTypes: []v1alpha1.PolicyType{v1alpha1.PolicyTypeIngress},
+				AppliedTo:   v1alpha1.AppliedTo{EndpointSelector: selectorInNamespaces(nil, "default")},
+			},
+		},
+		"should translate peers with namespaceSelector and podSelector": {
+			spec: networkingv1.NetworkPolicySpec{
+				PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				Ingress: []networkingv1.NetworkPolicyIngressRule{{
+					From: []networkingv1.NetworkPolicyPeer{
+						{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "client"}}},
+						{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}},
+						{NamespaceSelector: &metav1.LabelSelector{}},
+						{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "none"}}},
+					},
+					Ports: []networkingv1.NetworkPolicyPort{{Port: &port80}},
+				}},
+			},
+			expectSpec: v1alpha1.SecurityPolicySpec{
+				PolicyTypes: []v1alpha1.PolicyType{v1alpha1.PolicyTypeIngress},
+				AppliedTo:   v1alpha1.AppliedTo{EndpointSelector: selectorInNamespaces(map[string]string{"app": "web"}, "default")},
+				IngressRules: []v1alpha1.Rule{
+					{
+						Name:  "ingress-0-0",
+						Ports: []v1alpha1.SecurityPolicyPort{{Protocol: v1alpha1.ProtocolTCP, PortRange: "80"}},
+						From:  v1alpha1.SecurityPolicyPeer{EndpointSelector: selectorInNamespaces(map[string]string{"app": "client"}, "default")},
+					},
+					{
+						Name:  "ingress-0-1",
+						Ports: []v1alpha1.SecurityPolicyPort{{Protocol: v1alpha1.ProtocolTCP, PortRange: "80"}},
+						From:  v1alpha1.SecurityPolicyPeer{EndpointSelector: selectorInNamespaces(nil, "db", "web")},
+					},
+					{
+						Name:  "ingress-0-2",
+						Ports: []v1alpha1.SecurityPolicyPort{{Protocol: v1alpha1.ProtocolTCP, PortRange: "80"}},
+						From: v1alpha1.SecurityPolicyPeer{EndpointSelector: &metav1.LabelSelector{
+							MatchExpressions: []metav1.LabelSelectorRequirement{
+								{Key: lynxctrl.NamespaceLabel, Operator: metav1.LabelSelectorOpExists},
+								{Key: podctrl.PodLabel, Operator: metav1.LabelSelectorOpExists},
+							},
+						}},
+					},
+					{
+						Name:  "ingress-0-3",
+						Ports: []v1alpha1.SecurityPolicyPort{{Protocol: v1alpha1.ProtocolTCP, PortRange: "80"}},
+						From: v1alpha1.SecurityPolicyPeer{EndpointSelector: &metav1.LabelSelector{
+							MatchExpressions: []metav1.LabelSelectorRequirement{
+								{Key: lynxctrl.NamespaceLabel, Operator: metav1.LabelSelectorOpExists},
+								{Key: lynxctrl.NamespaceLabel, Operator: metav1.LabelSelectorOpDoesNotExist},
+								{Key: podctrl.PodLabel, Operator: metav1.LabelSelectorOpExists},
+							},
+						}},
+					},
+				},
+			},
+		},
+		"should translate egress ipBlocks with except": {
+			spec: networkingv1.NetworkPolicySpec{
+				PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+				Egress: []networkingv1.NetworkPolicyEgressRule{{
+					To: []networkingv1.NetworkPolicyPeer{
+						{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.1/16", Except: []string{"10.0.1.0/24"}}},
+						{IPBlock: &networkingv1.IPBlock{CIDR: "192.168.0.0/24"}},
+					},
+					Ports: []networkingv1.NetworkPolicyPort{{Protocol: &protocolUDP}, {Port: &portNamed}},
+				}},
+			},
+			expectSpec: v1alpha1.SecurityPolicySpec{
+				PolicyTypes: []v1alpha1.PolicyType{v1alpha1.PolicyTypeEgress},
+				AppliedTo:   v1alpha1.AppliedTo{EndpointSelector: selectorInNamespaces(nil, "default")},
+				EgressRules: []v1alpha1.Rule{{
+					Name:  "egress-0-ipblocks",
+					Ports: []v1alpha1.SecurityPolicyPort{{Protocol: v1alpha1.ProtocolUDP}},
+					To: v1alpha1.SecurityPolicyPeer{IPBlocks: []v1alpha1.IPBlock{
+						{IP: "10.0.0.0", PrefixLength: 16, Except: []string{"10.0.1.0/24"}},
+						{IP: "192.168.0.0", PrefixLength: 24},
+					}},
+				}},
+			},
+		},
+		"should allow all traffic with empty rule": {
+			spec: networkingv1.NetworkPolicySpec{
+				PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+				Ingress:     []networkingv1.NetworkPolicyIngressRule{{}},
+			},
+			expectSpec: v1alpha1.SecurityPolicySpec{
+				PolicyTypes:  []v1alpha1.PolicyType{v1alpha1.PolicyTypeIngress, v1alpha1.PolicyTypeEgress},
+				AppliedTo:    v1alpha1.AppliedTo{EndpointSelector: selectorInNamespaces(nil, "default")},
+				IngressRules: []v1alpha1.Rule{{Name: "ingress-0"}},
+			},
+		},
+		"should drop rule with only named ports": {
+			spec: networkingv1.NetworkPolicySpec{
+				Ingress: []networkingv1.NetworkPolicyIngressRule{{
+					Ports: []networkingv1.NetworkPolicyPort{{Port: &portNamed}},
+				}},
+			},
+			expectSpec: v1alpha1.SecurityPolicySpec{
+				PolicyTypes: []v1alpha1.PolicyType{v1alpha1.PolicyTypeIngress},
+				AppliedTo:   v1alpha1.AppliedTo{EndpointSelector: selectorInNamespaces(nil, "default")},
+			},
+		},
+		"should failed with invalid ipBlock": {
+			spec: networkingv1.NetworkPolicySpec{
+				Ingress: []networkingv1.NetworkPolicyIngressRule{{
+					From: []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.1"}}},
+				}},
+			},
+			expectFailed: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			np := &networkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "np"},
+				Spec:       tc.spec,
+			}
+			policy, err := Translate(np, namespaces, "tier-np")
+			if tc.expectFailed {
+				if err == nil {
+					t.Fatalf("expect translate failed, but got policy %+v", policy)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpect error while translate: %s", err)
+			}
+
+			tc.expectSpec.Tier = "tier-np"
+			tc.expectSpec.Priority = policyPriority
+			if policy.Name != PolicyName("default", "np") {
+				t.Fatalf("expect policy name %s, but got %s", PolicyName("default", "np"), policy.Name)
+			}
+			if !reflect.DeepEqual(policy.Spec, tc.expectSpec) {
+				t.Fatalf("expect policy spec %+v, but got %+v", tc.expectSpec, policy.Spec)
+			}
+		})
+	}
+}
+
+func TestTranslateDirectionsUnion(t *testing.T) {
+	var namespaces = []*corev1.Namespace{newTestNamespace("default", nil)}
+	var webSelector = metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	var ingressOnly = &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ingress-only"},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: webSelector,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From: []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "client"}}}},
+			}},
+		},
+	}
+	var egressOnly = &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "egress-only"},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: webSelector,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress: []networkingv1.NetworkPolicyEgressRule{{
+				To: []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}}},
+			}},
+		},
+	}
+
+	snapshot := &simulator.Snapshot{}
+	tier := v1alpha1.Tier{ObjectMeta: metav1.ObjectMeta{Name: "tier-np"}}
+	tier.Spec.TierMode = v1alpha1.TierWhiteList
+	snapshot.Tiers = append(snapshot.Tiers, tier)
+	for _, np := range []*networkingv1.NetworkPolicy{ingressOnly, egressOnly} {
+		policy, err := Translate(np, namespaces, tier.Name)
+		if err != nil {
+			t.Fatalf("unexpect error while translate: %s", err)
+		}
+		snapshot.SecurityPolicies = append(snapshot.SecurityPolicies, *policy)
+	}
+	for index, app := range []string{"web", "client", "db", "other"} {
+		endpoint := v1alpha1.Endpoint{ObjectMeta: metav1.ObjectMeta{
+			Name:   app,
+			Labels: map[string]string{"app": app, lynxctrl.NamespaceLabel: "default", podctrl.PodLabel: app},
+		}}
+		endpoint.Status.IPs = []types.IPAddress{types.IPAddress(fmt.Sprintf("10.0.0.%d", index+1))}
+		snapshot.Endpoints = append(snapshot.Endpoints, endpoint)
+	}
+	// endpoint of other source in the same tenant namespace, with the same labels as the client pod
+	vm := v1alpha1.Endpoint{ObjectMeta: metav1.ObjectMeta{
+		Name:   "vm",
+		Labels: map[string]string{"app": "client", lynxctrl.NamespaceLabel: "default"},
+	}}
+	vm.Status.IPs = []types.IPAddress{"10.0.0.10"}
+	snapshot.Endpoints = append(snapshot.Endpoints, vm)
+
+	sim, err := simulator.New(snapshot)
+	if err != nil {
+		t.Fatalf("unable to create simulator: %s", err)
+	}
+
+	// each NetworkPolicy limits only its direction, the other one allows nothing more
+	testCases := map[string]struct {
+		src, dst      string
+		expectVerdict v1alpha1.TraceflowVerdict
+	}{
+		"should allow ingress from client":       {src: "client", dst: "web", expectVerdict: v1alpha1.TraceflowVerdictForwarded},
+		"should drop ingress from other":         {src: "other", dst: "web", expectVerdict: v1alpha1.TraceflowVerdictDropped},
+		"should allow egress to db":              {src: "web", dst: "db", expectVerdict: v1alpha1.TraceflowVerdictForwarded},
+		"should drop egress to other":            {src: "web", dst: "other", expectVerdict: v1alpha1.TraceflowVerdictDropped},
+		"should not limit traffic of the others": {src: "other", dst: "db", expectVerdict: v1alpha1.TraceflowVerdictForwarded},
+		"should not select endpoints not pod":    {src: "vm", dst: "web", expectVerdict: v1alpha1.TraceflowVerdictDropped},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			srcIP, _ := sim.ResolveIP(tc.src)
+			dstIP, _ := sim.ResolveIP(tc.dst)
+			result := sim.Simulate(&simulator.Packet{SrcIP: srcIP, DstIP: dstIP, Protocol: v1alpha1.ProtocolTCP, SrcPort: 30000, DstPort: 80})
+			if result.Verdict != tc.expectVerdict {
+				t.Errorf("expect verdict %s from %s to %s, got %s", tc.expectVerdict, tc.src, tc.dst, result.Verdict)
+			}
+		})
+	}
+}
+
+func newTestNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+	}
+}
+
+func selectorInNamespaces(matchLabels map[string]string, namespaces ...string) *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchLabels: matchLabels,
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: lynxctrl.NamespaceLabel, Operator: metav1.LabelSelectorOpIn, Values: namespaces},
+			{Key: podctrl.PodLabel, Operator: metav1.LabelSelectorOpExists},
+		},
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package register
+
+import (
+	"flag"
+	"time"
+
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/smartxworks/lynx/pkg/client/clientset_generated/clientset"
+	"github.com/smartxworks/lynx/pkg/client/informers_generated/externalversions"
+	"github.com/smartxworks/lynx/plugin/kubernetes/pkg/controller"
+	"github.com/smartxworks/lynx/plugin/kubernetes/pkg/networkpolicy"
+)
+
+type Options struct {
+	// will enable controller if "Enable" empty or true
+	Enable       *bool
+	ResyncPeriod time.Duration
+	WorkerNumber uint
+
+	// EnableNetworkPolicy enables translating NetworkPolicies into SecurityPolicies in NetworkPolicyTier.
+	EnableNetworkPolicy       bool
+	NetworkPolicyTier         string
+	NetworkPolicyTierPriority int
+}
+
+// InitFlags set and load options from flagset.
+func InitFlags(opts *Options, flagset *flag.FlagSet, flagPrefix string) {
+	if flagset == nil {
+		flagset = flag.CommandLine
+	}
+	if opts.Enable == nil {
+		opts.Enable = new(bool)
+	}
+	var withPrefix = func(name string) string { return flagPrefix + name }
+
+	flagset.BoolVar(opts.Enable, withPrefix("enable"), false, "If true, kubernetes plugin will start (default false)")
+	flagset.UintVar(&opts.WorkerNumber, withPrefix("worker-number"), 10, "Controller worker number")
+	flagset.DurationVar(&opts.ResyncPeriod, withPrefix("resync-period"), 10*time.Hour, "Controller resync period")
+	flagset.BoolVar(&opts.EnableNetworkPolicy, withPrefix("enable-networkpolicy"), false, "If true, networkpolicies will translate into securitypolicies (default false)")
+	flagset.StringVar(&opts.NetworkPolicyTier, withPrefix("networkpolicy-tier"), "kubernetes-networkpolicy", "Tier of securitypolicies translated from networkpolicies")
+	flagset.IntVar(&opts.NetworkPolicyTierPriority, withPrefix("networkpolicy-tier-priority"), 50, "Priority of the tier, used when create the tier")
+}
+
+// AddToManager allow you register controller to Manager.
+func AddToManager(opts *Options, mgr manager.Manager) error {
+	if opts.Enable != nil && !*opts.Enable {
+		return nil
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+	crdClient, err := clientset.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+
+	kubeFactory := kubeinformers.NewSharedInformerFactory(kubeClient, opts.ResyncPeriod)
+	crdFactory := externalversions.NewSharedInformerFactory(crdClient, opts.ResyncPeriod)
+	endpointController := controller.New(kubeFactory, crdFactory, crdClient, opts.ResyncPeriod)
+
+	var networkPolicyController *networkpolicy.Controller
+	if opts.EnableNetworkPolicy {
+		networkPolicyController = networkpolicy.New(kubeFactory, crdFactory, crdClient, opts.ResyncPeriod,
+			opts.NetworkPolicyTier, int32(opts.NetworkPolicyTierPriority))
+	}
+
+	err = mgr.Add(manager.RunnableFunc(func(stopChan <-chan struct{}) error {
+		kubeFactory.Start(stopChan)
+		crdFactory.Start(stopChan)
+		if networkPolicyController != nil {
+			go networkPolicyController.Run(opts.WorkerNumber, stopChan)
+		}
+		endpointController.Run(opts.WorkerNumber, stopChan)
+		return nil
+	}))
+
+	return err
+}
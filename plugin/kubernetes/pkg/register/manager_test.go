@@ -0,0 +1,81 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package register
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInitFlags(t *testing.T) {
+	var boolTrue = true
+	var boolFalse = false
+
+	testCases := map[string]struct {
+		flagPrefix    string
+		args          []string
+		expectOptions *Options
+	}{
+		"should prase default options": {
+			expectOptions: &Options{
+				Enable:                    &boolFalse,
+				ResyncPeriod:              10 * time.Hour,
+				WorkerNumber:              10,
+				NetworkPolicyTier:         "kubernetes-networkpolicy",
+				NetworkPolicyTierPriority: 50,
+			},
+		},
+		"should prase normal options with prefix": {
+			flagPrefix: "plugins.kubernetes.",
+			args: []string{
+				"--plugins.kubernetes.enable=true",
+				"--plugins.kubernetes.resync-period=1s",
+				"--plugins.kubernetes.worker-number=1",
+				"--plugins.kubernetes.enable-networkpolicy=true",
+				"--plugins.kubernetes.networkpolicy-tier=tier-np",
+				"--plugins.kubernetes.networkpolicy-tier-priority=20",
+			},
+			expectOptions: &Options{
+				Enable:                    &boolTrue,
+				ResyncPeriod:              time.Second,
+				WorkerNumber:              1,
+				EnableNetworkPolicy:       true,
+				NetworkPolicyTier:         "tier-np",
+				NetworkPolicyTierPriority: 20,
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var opts Options
+			var flagset flag.FlagSet
+
+			InitFlags(&opts, &flagset, tc.flagPrefix)
+
+			if err := flagset.Parse(tc.args); err != nil {
+				t.Fatalf("unexpect error will parse flags: %s", err)
+			}
+
+			if !reflect.DeepEqual(&opts, tc.expectOptions) {
+				t.Fatalf("expect parse options %+v from flags %+v, but got %+v", tc.expectOptions, tc.args, opts)
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	kubeerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	"github.com/smartxworks/lynx/pkg/client/clientset_generated/clientset"
+	crd "github.com/smartxworks/lynx/pkg/client/informers_generated/externalversions"
+	seclisters "github.com/smartxworks/lynx/pkg/client/listers_generated/security/v1alpha1"
+	"github.com/smartxworks/lynx/pkg/types"
+)
+
+// Controller maintains Endpoints defined in inventory files of a directory, so endpoints not managed by
+// any other plugins (e.g. physical appliances and bare-metal hosts) could be managed with files.
+type Controller struct {
+	// name of this controller
+	name string
+	// managePlaneID mark the endpoint source to be processed by the controller
+	managePlaneID string
+	// inventoryDir is the directory of inventory files
+	inventoryDir string
+	resyncPeriod time.Duration
+
+	crdClient clientset.Interface
+
+	endpointLister         seclisters.EndpointLister
+	endpointInformerSynced cache.InformerSynced
+
+	// inventory is entries loaded from inventoryDir, keyed by endpoint name
+	inventoryLock sync.RWMutex
+	inventory     map[string]*Entry
+	// inventoryLoaded is true once inventory has been loaded successfully, before that the inventory is
+	// unknown rather than empty, so no endpoints should be removed
+	inventoryLoaded bool
+
+	endpointQueue workqueue.RateLimitingInterface
+}
+
+// endpointNamePrefix distinguishes endpoints of inventory entries from endpoints of other sources.
+const endpointNamePrefix = "static."
+
+// New creates a new instance of controller.
+func New(crdFactory crd.SharedInformerFactory, crdClient clientset.Interface, inventoryDir string, resyncPeriod time.Duration) *Controller {
+	endpointInformer := crdFactory.Security().V1alpha1().Endpoints()
+
+	c := &Controller{
+		name:                   "StaticEndpointController",
+		managePlaneID:          "lynx.plugin.static",
+		inventoryDir:           inventoryDir,
+		resyncPeriod:           resyncPeriod,
+		crdClient:              crdClient,
+		endpointLister:         endpointInformer.Lister(),
+		endpointInformerSynced: endpointInformer.Informer().HasSynced,
+		inventory:              make(map[string]*Entry),
+		endpointQueue:          workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+	}
+
+	// endpoints are handled to remove endpoints of entries deleted while controller not running,
+	// and to resync endpoints unexpectedly modified by other applications.
+	endpointInformer.Informer().AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.addEndpoint,
+			UpdateFunc: c.updateEndpoint,
+			DeleteFunc: c.deleteEndpoint,
+		},
+		resyncPeriod,
+	)
+
+	return c
+}
+
+// Run begins processing items, and will continue until a value is sent down stopCh or it is closed.
+func (c *Controller) Run(workers uint, stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+	defer c.endpointQueue.ShutDown()
+
+	if !cache.WaitForNamedCacheSync(c.name, stopCh, c.endpointInformerSynced) {
+		return
+	}
+
+	// Watch inventory directory until stopped. The watcher would be created again with backoff on
+	// failures, e.g. the directory not exists, inventory is still loaded periodically in the meantime.
+	backoffManager := wait.NewExponentialBackoffManager(time.Second, 5*time.Minute, 10*time.Minute, 2.0, 1.0, clock.RealClock{})
+	go wait.BackoffUntil(func() { c.watchInventory(stopCh) }, backoffManager, true, stopCh)
+
+	// load inventory before workers start, so that workers would not sync endpoints against empty inventory
+	c.loadInventory()
+
+	// load inventory periodically in case of watch events lost
+	go wait.Until(c.loadInventory, c.resyncPeriod, stopCh)
+
+	for i := uint(0); i < workers; i++ {
+		go wait.Until(c.syncEndpointWorker, time.Second, stopCh)
+	}
+
+	<-stopCh
+}
+
+func (c *Controller) watchInventory(stopCh <-chan struct{}) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		klog.Errorf("unable create inventory watcher: %s", err)
+		return
+	}
+	defer watcher.Close()
+
+	// Watch the directory instead of the files, so files create, remove or rename would be noticed.
+	// It also works with files mounted from ConfigMap, which are updated by rename symlinks.
+	if err = watcher.Add(c.inventoryDir); err != nil {
+		klog.Errorf("unable watch inventory directory %s: %s", c.inventoryDir, err)
+		return
+	}
+	// load inventory again in case of changes before the watch starts
+	c.loadInventory()
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			klog.V(2).Infof("inventory directory %s received event %s", c.inventoryDir, event)
+			c.loadInventory()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			klog.Errorf("got error while watch inventory directory %s: %s", c.inventoryDir, err)
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// loadInventory loads inventory from files and enqueues endpoints of all entries. If any file is
+// invalid, the current inventory will be kept, e.g. a file is in the middle of writing, so that
+// endpoints would not be removed unexpectedly.
+func (c *Controller) loadInventory() {
+	entries, err := LoadInventory(c.inventoryDir)
+	if err != nil {
+		klog.Errorf("unable load inventory from %s, keep the current inventory: %s", c.inventoryDir, err)
+		return
+	}
+
+	inventory := make(map[string]*Entry, len(entries))
+	for name, entry := range entries {
+		inventory[entryEndpointName(name)] = entry
+	}
+
+	c.inventoryLock.Lock()
+	var endpointNames = sets.StringKeySet(c.inventory).Union(sets.StringKeySet(inventory))
+	c.inventory = inventory
+	c.inventoryLoaded = true
+	c.inventoryLock.Unlock()
+
+	for _, endpointName := range endpointNames.UnsortedList() {
+		c.endpointQueue.Add(endpointName)
+	}
+}
+
+// getEntry returns the entry of the endpoint, and whether the inventory has been loaded.
+func (c *Controller) getEntry(endpointName string) (*Entry, bool, bool) {
+	c.inventoryLock.RLock()
+	defer c.inventoryLock.RUnlock()
+
+	entry, ok := c.inventory[endpointName]
+	return entry, ok, c.inventoryLoaded
+}
+
+// entryEndpointName returns name of the endpoint for the entry.
+func entryEndpointName(entryName string) string {
+	return endpointNamePrefix + entryName
+}
+
+func (c *Controller) addEndpoint(new interface{}) {
+	c.enqueueEndpoint(new.(*v1alpha1.Endpoint))
+}
+
+func (c *Controller) updateEndpoint(old interface{}, new interface{}) {
+	c.enqueueEndpoint(old.(*v1alpha1.Endpoint))
+	c.enqueueEndpoint(new.(*v1alpha1.Endpoint))
+}
+
+func (c *Controller) deleteEndpoint(old interface{}) {
+	if d, ok := old.(cache.DeletedFinalStateUnknown); ok {
+		old = d.Obj
+	}
+	c.enqueueEndpoint(old.(*v1alpha1.Endpoint))
+}
+
+func (c *Controller) enqueueEndpoint(ep *v1alpha1.Endpoint) {
+	if ep.Spec.ManagePlaneID != c.managePlaneID || !strings.HasPrefix(ep.Name, endpointNamePrefix) {
+		return
+	}
+	c.endpointQueue.Add(ep.Name)
+}
+
+func (c *Controller) syncEndpointWorker() {
+	for {
+		key, quit := c.endpointQueue.Get()
+		if quit {
+			return
+		}
+
+		err := c.syncEndpoint(key.(string))
+		if err != nil {
+			c.endpointQueue.Done(key)
+			c.endpointQueue.AddRateLimited(key)
+			klog.Errorf("got error while sync endpoint %s: %s", key.(string), err)
+			continue
+		}
+
+		// stop the rate limiter from tracking the key
+		c.endpointQueue.Done(key)
+		c.endpointQueue.Forget(key)
+	}
+}
+
+func (c *Controller) syncEndpoint(endpointName string) error {
+	entry, ok, loaded := c.getEntry(endpointName)
+	if !loaded {
+		// retry later, never remove endpoints before inventory loaded
+		return fmt.Errorf("inventory from %s has not been loaded yet", c.inventoryDir)
+	}
+	if !ok {
+		return c.processEndpointDelete(endpointName)
+	}
+	return c.processEndpointUpdate(entry, endpointName)
+}
+
+func (c *Controller) processEndpointDelete(endpointName string) error {
+	ep, err := c.endpointLister.Get(endpointName)
+	if kubeerror.IsNotFound(err) {
+		// object has been delete already
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if ep.Spec.ManagePlaneID != c.managePlaneID {
+		// never remove endpoints of other sources
+		return nil
+	}
+
+	err = c.crdClient.SecurityV1alpha1().Endpoints().Delete(context.Background(), endpointName, metav1.DeleteOptions{})
+	if err == nil || kubeerror.IsNotFound(err) {
+		klog.Infof("endpoint %s has been delete by %s", endpointName, c.name)
+		return nil
+	}
+	return err
+}
+
+func (c *Controller) processEndpointUpdate(entry *Entry, endpointName string) error {
+	obj, err := c.endpointLister.Get(endpointName)
+	if kubeerror.IsNotFound(err) {
+		ep := &v1alpha1.Endpoint{}
+		c.setEndpoint(ep, entry)
+
+		klog.Infof("will add endpoint from entry %s: %+v", entry.Name, ep)
+		_, err = c.crdClient.SecurityV1alpha1().Endpoints().Create(context.Background(), ep, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("get endpoint receive error: %s", err)
+	}
+
+	if obj.Spec.ManagePlaneID != c.managePlaneID {
+		// the endpoint could not be processed until removed by its source
+		klog.Errorf("endpoint %s of entry %s has been managed by %s", endpointName, entry.Name, obj.Spec.ManagePlaneID)
+		return nil
+	}
+
+	ep := obj.DeepCopy()
+	if c.setEndpoint(ep, entry) {
+		klog.Infof("will update endpoint from entry %s: %+v", entry.Name, ep)
+
+		_, err = c.crdClient.SecurityV1alpha1().Endpoints().Update(context.Background(), ep, metav1.UpdateOptions{})
+		return err
+	}
+
+	return nil
+}
+
+// set endpoint return false if endpoint not changes
+func (c *Controller) setEndpoint(ep *v1alpha1.Endpoint, entry *Entry) bool {
+	var epCopy = ep.DeepCopy()
+	var expectedIPs []types.IPAddress
+
+	for _, ip := range entry.IPs {
+		expectedIPs = append(expectedIPs, types.IPAddress(ip))
+	}
+
+	ep.Name = entryEndpointName(entry.Name)
+	ep.Labels = entry.Labels
+	ep.Spec.ManagePlaneID = c.managePlaneID
+	ep.Spec.VID = entry.VID
+	ep.Spec.Reference.ExternalIDName = entry.ExternalIDName
+	ep.Spec.Reference.ExternalIDValue = entry.ExternalIDValue
+	ep.Spec.ExpectedIPs = expectedIPs
+
+	return !reflect.DeepEqual(ep, epCopy)
+}
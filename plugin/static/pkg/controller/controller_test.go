@@ -0,0 +1,174 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	"github.com/smartxworks/lynx/pkg/client/clientset_generated/clientset"
+	"github.com/smartxworks/lynx/pkg/client/clientset_generated/clientset/fake"
+	"github.com/smartxworks/lynx/pkg/client/informers_generated/externalversions"
+	"github.com/smartxworks/lynx/pkg/types"
+)
+
+var (
+	crdClient    clientset.Interface
+	inventoryDir string
+)
+
+const (
+	timeout  = 10 * time.Second
+	interval = 100 * time.Millisecond
+)
+
+func TestMain(m *testing.M) {
+	var stopCh = make(chan struct{})
+	var err error
+
+	inventoryDir, err = ioutil.TempDir("", "inventory")
+	if err != nil {
+		panic(err)
+	}
+
+	crdClient = fake.NewSimpleClientset()
+	crdFactory := externalversions.NewSharedInformerFactory(crdClient, 0)
+
+	ctroller := New(crdFactory, crdClient, inventoryDir, time.Hour)
+	go ctroller.Run(10, stopCh)
+
+	crdFactory.Start(stopCh)
+
+	exitCode := m.Run()
+	close(stopCh)
+	os.RemoveAll(inventoryDir)
+	os.Exit(exitCode)
+}
+
+func TestStaticEndpoint(t *testing.T) {
+	RegisterTestingT(t)
+	ctx := context.Background()
+
+	t.Run("should create endpoint for entry", func(t *testing.T) {
+		writeInventoryFile("hosts.csv", "name,externalIDName,externalIDValue,vid,labels,ips\nhost-01,iface-id,host-01,10,role=db,10.0.0.1\n")
+
+		Eventually(func() *v1alpha1.Endpoint {
+			return getEndpoint("host-01")
+		}, timeout, interval).ShouldNot(BeNil())
+
+		ep := getEndpoint("host-01")
+		Expect(ep.Labels).Should(Equal(map[string]string{"role": "db"}))
+		Expect(ep.Spec.ManagePlaneID).Should(Equal("lynx.plugin.static"))
+		Expect(ep.Spec.VID).Should(Equal(uint32(10)))
+		Expect(ep.Spec.Reference).Should(Equal(v1alpha1.EndpointReference{ExternalIDName: "iface-id", ExternalIDValue: "host-01"}))
+		Expect(ep.Spec.ExpectedIPs).Should(Equal([]types.IPAddress{"10.0.0.1"}))
+	})
+
+	t.Run("should update endpoint when entry changed", func(t *testing.T) {
+		writeInventoryFile("hosts.csv", "name,externalIDName,externalIDValue,vid,labels,ips\nhost-01,iface-id,host-01,10,role=web,10.0.0.1\n")
+
+		Eventually(func() map[string]string {
+			return getEndpoint("host-01").Labels
+		}, timeout, interval).Should(Equal(map[string]string{"role": "web"}))
+	})
+
+	t.Run("should keep endpoints when inventory invalid", func(t *testing.T) {
+		writeInventoryFile("invalid.yaml", "- {name: host-02}")
+		defer removeInventoryFile("invalid.yaml")
+
+		Consistently(func() *v1alpha1.Endpoint {
+			return getEndpoint("host-01")
+		}, time.Second, interval).ShouldNot(BeNil())
+	})
+
+	t.Run("should not remove endpoint of other sources", func(t *testing.T) {
+		ep := &v1alpha1.Endpoint{}
+		ep.Name = entryEndpointName("other")
+		ep.Spec.ManagePlaneID = "lynx.plugin.other"
+		_, err := crdClient.SecurityV1alpha1().Endpoints().Create(ctx, ep, metav1.CreateOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Consistently(func() *v1alpha1.Endpoint {
+			return getEndpoint("other")
+		}, time.Second, interval).ShouldNot(BeNil())
+	})
+
+	t.Run("should remove endpoint when entry removed", func(t *testing.T) {
+		removeInventoryFile("hosts.csv")
+
+		Eventually(func() *v1alpha1.Endpoint {
+			return getEndpoint("host-01")
+		}, timeout, interval).Should(BeNil())
+	})
+
+	t.Run("should remove endpoint of entry not exist", func(t *testing.T) {
+		ep := &v1alpha1.Endpoint{}
+		ep.Name = entryEndpointName("not-exist")
+		ep.Spec.ManagePlaneID = "lynx.plugin.static"
+		_, err := crdClient.SecurityV1alpha1().Endpoints().Create(ctx, ep, metav1.CreateOptions{})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Eventually(func() *v1alpha1.Endpoint {
+			return getEndpoint("not-exist")
+		}, timeout, interval).Should(BeNil())
+	})
+}
+
+// getEndpoint returns the endpoint of the entry, and nil if the endpoint not exists.
+func getEndpoint(entryName string) *v1alpha1.Endpoint {
+	ep, err := crdClient.SecurityV1alpha1().Endpoints().Get(context.Background(), entryEndpointName(entryName), metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+	return ep
+}
+
+func writeInventoryFile(name, content string) {
+	// write and rename the file, so the controller would never read a partial file
+	tmpFile := filepath.Join(inventoryDir, "."+name)
+	Expect(ioutil.WriteFile(tmpFile, []byte(content), 0644)).Should(Succeed())
+	Expect(os.Rename(tmpFile, filepath.Join(inventoryDir, name))).Should(Succeed())
+}
+
+func removeInventoryFile(name string) {
+	Expect(os.Remove(filepath.Join(inventoryDir, name))).Should(Succeed())
+}
+
+func TestInventoryNotLoaded(t *testing.T) {
+	RegisterTestingT(t)
+
+	ep := &v1alpha1.Endpoint{}
+	ep.Name = entryEndpointName("host-01")
+	ep.Spec.ManagePlaneID = "lynx.plugin.static"
+	client := fake.NewSimpleClientset(ep)
+
+	// inventory from a directory not exists could never be loaded
+	ctroller := New(externalversions.NewSharedInformerFactory(client, 0), client, filepath.Join(inventoryDir, "not-exist"), time.Hour)
+	ctroller.loadInventory()
+
+	Expect(ctroller.syncEndpoint(ep.Name)).ShouldNot(Succeed())
+	_, err := client.SecurityV1alpha1().Endpoints().Get(context.Background(), ep.Name, metav1.GetOptions{})
+	Expect(err).ShouldNot(HaveOccurred())
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Entry is an endpoint definition in the inventory files.
+//
+// In yaml files, entries are defined as a list:
+//
+//	# appliances.yaml
+//	- name: appliance-01
+//	  externalIDName: iface-id
+//	  externalIDValue: appliance-01
+//	  vid: 10
+//	  labels:
+//	    role: firewall
+//	  ips:
+//	  - 192.168.10.1
+//
+// In csv files, the first line must be the header, labels and ips are separated by semicolon:
+//
+//	name,externalIDName,externalIDValue,vid,labels,ips
+//	appliance-01,iface-id,appliance-01,10,role=firewall,192.168.10.1
+type Entry struct {
+	Name            string            `yaml:"name"`
+	ExternalIDName  string            `yaml:"externalIDName"`
+	ExternalIDValue string            `yaml:"externalIDValue"`
+	VID             uint32            `yaml:"vid,omitempty"`
+	Labels          map[string]string `yaml:"labels,omitempty"`
+	IPs             []string          `yaml:"ips,omitempty"`
+}
+
+const (
+	csvColumnName            = "name"
+	csvColumnExternalIDName  = "externalIDName"
+	csvColumnExternalIDValue = "externalIDValue"
+	csvColumnVID             = "vid"
+	csvColumnLabels          = "labels"
+	csvColumnIPs             = "ips"
+
+	csvListSeparator = ";"
+)
+
+// LoadInventory reads entries from yaml and csv files in the directory, other files and sub directories
+// are ignored. It returns entries keyed by entry name, or error if any file is invalid.
+func LoadInventory(dir string) (map[string]*Entry, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var inventory = make(map[string]*Entry)
+	var entryFile = make(map[string]string)
+
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+
+		var entries []*Entry
+		var path = filepath.Join(dir, file.Name())
+
+		switch strings.ToLower(filepath.Ext(file.Name())) {
+		case ".yaml", ".yml":
+			entries, err = readYamlFile(path)
+		case ".csv":
+			entries, err = readCsvFile(path)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("file %s: %s", path, err)
+		}
+
+		for _, entry := range entries {
+			if err = validateEntry(entry); err != nil {
+				return nil, fmt.Errorf("file %s: entry %s: %s", path, entry.Name, err)
+			}
+			if exist, ok := entryFile[entry.Name]; ok {
+				return nil, fmt.Errorf("file %s: entry %s has been defined in file %s", path, entry.Name, exist)
+			}
+			inventory[entry.Name] = entry
+			entryFile[entry.Name] = path
+		}
+	}
+
+	return inventory, nil
+}
+
+func readYamlFile(path string) ([]*Entry, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*Entry
+	if err = yaml.UnmarshalStrict(raw, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func readCsvFile(path string) ([]*Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, column := range header {
+		switch column {
+		case csvColumnName, csvColumnExternalIDName, csvColumnExternalIDValue, csvColumnVID, csvColumnLabels, csvColumnIPs:
+		default:
+			return nil, fmt.Errorf("unknown column %s", column)
+		}
+	}
+
+	var entries []*Entry
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		entry, err := parseCsvRecord(header, record)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %s", row, err)
+		}
+		entries = append(entries, entry)
+	}
+}
+
+func parseCsvRecord(header []string, record []string) (*Entry, error) {
+	var entry = &Entry{}
+
+	for index, column := range header {
+		value := strings.TrimSpace(record[index])
+		if value == "" {
+			continue
+		}
+
+		switch column {
+		case csvColumnName:
+			entry.Name = value
+		case csvColumnExternalIDName:
+			entry.ExternalIDName = value
+		case csvColumnExternalIDValue:
+			entry.ExternalIDValue = value
+		case csvColumnVID:
+			vid, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid vid %s: %s", value, err)
+			}
+			entry.VID = uint32(vid)
+		case csvColumnLabels:
+			entry.Labels = make(map[string]string)
+			for _, label := range strings.Split(value, csvListSeparator) {
+				items := strings.SplitN(label, "=", 2)
+				if len(items) != 2 {
+					return nil, fmt.Errorf("invalid label %s, must be key=value", label)
+				}
+				entry.Labels[strings.TrimSpace(items[0])] = strings.TrimSpace(items[1])
+			}
+		case csvColumnIPs:
+			for _, ip := range strings.Split(value, csvListSeparator) {
+				entry.IPs = append(entry.IPs, strings.TrimSpace(ip))
+			}
+		}
+	}
+
+	return entry, nil
+}
+
+func validateEntry(entry *Entry) error {
+	if errs := validation.IsDNS1123Subdomain(entryEndpointName(entry.Name)); len(errs) != 0 {
+		return fmt.Errorf("invalid name: %s", strings.Join(errs, ", "))
+	}
+	if entry.ExternalIDName == "" || entry.ExternalIDValue == "" {
+		return fmt.Errorf("externalIDName and externalIDValue must not be empty")
+	}
+	for key, value := range entry.Labels {
+		if errs := validation.IsQualifiedName(key); len(errs) != 0 {
+			return fmt.Errorf("invalid label key %s: %s", key, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) != 0 {
+			return fmt.Errorf("invalid label value %s: %s", value, strings.Join(errs, ", "))
+		}
+	}
+	for _, ip := range entry.IPs {
+		if net.ParseIP(ip) == nil {
+			return fmt.Errorf("invalid ip %s", ip)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadInventory(t *testing.T) {
+	testCases := map[string]struct {
+		files           map[string]string
+		expectInventory map[string]*Entry
+		expectFailed    bool
+	}{
+		"should load entries from yaml and csv files": {
+			files: map[string]string{
+				"appliances.yaml": `
+- name: appliance-01
+  externalIDName: iface-id
+  externalIDValue: appliance-01
+  vid: 10
+  labels:
+    role: firewall
+  ips:
+  - 192.168.10.1
+`,
+				"hosts.csv": "name,externalIDName,externalIDValue,vid,labels,ips\n" +
+					"host-01,iface-id,host-01,,role=db;env=prod,10.0.0.1;10.0.0.2\n" +
+					"host-02,iface-id,host-02,20,,\n",
+				"README.md": "ignored",
+			},
+			expectInventory: map[string]*Entry{
+				"appliance-01": {
+					Name: "appliance-01", ExternalIDName: "iface-id", ExternalIDValue: "appliance-01", VID: 10,
+					Labels: map[string]string{"role": "firewall"}, IPs: []string{"192.168.10.1"},
+				},
+				"host-01": {
+					Name: "host-01", ExternalIDName: "iface-id", ExternalIDValue: "host-01",
+					Labels: map[string]string{"role": "db", "env": "prod"}, IPs: []string{"10.0.0.1", "10.0.0.2"},
+				},
+				"host-02": {
+					Name: "host-02", ExternalIDName: "iface-id", ExternalIDValue: "host-02", VID: 20,
+				},
+			},
+		},
+		"should load empty inventory from empty files": {
+			files: map[string]string{
+				"empty.yaml": "",
+				"empty.csv":  "",
+			},
+			expectInventory: map[string]*Entry{},
+		},
+		"should failed with duplicate entry name": {
+			files: map[string]string{
+				"a.yaml": "- {name: host-01, externalIDName: iface-id, externalIDValue: host-01}",
+				"b.csv":  "name,externalIDName,externalIDValue\nhost-01,iface-id,host-01\n",
+			},
+			expectFailed: true,
+		},
+		"should failed with unknown yaml field": {
+			files: map[string]string{
+				"a.yaml": "- {name: host-01, externalIDName: iface-id, externalIDValue: host-01, unknown: value}",
+			},
+			expectFailed: true,
+		},
+		"should failed with unknown csv column": {
+			files: map[string]string{
+				"a.csv": "name,externalIDName,externalIDValue,unknown\nhost-01,iface-id,host-01,value\n",
+			},
+			expectFailed: true,
+		},
+		"should failed with invalid ip": {
+			files: map[string]string{
+				"a.csv": "name,externalIDName,externalIDValue,ips\nhost-01,iface-id,host-01,10.0.0.256\n",
+			},
+			expectFailed: true,
+		},
+		"should failed with invalid label": {
+			files: map[string]string{
+				"a.csv": "name,externalIDName,externalIDValue,labels\nhost-01,iface-id,host-01,role\n",
+			},
+			expectFailed: true,
+		},
+		"should failed without externalID": {
+			files: map[string]string{
+				"a.yaml": "- {name: host-01}",
+			},
+			expectFailed: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "inventory")
+			if err != nil {
+				t.Fatalf("unable create temp dir: %s", err)
+			}
+			defer os.RemoveAll(dir)
+
+			for fileName, content := range tc.files {
+				if err = ioutil.WriteFile(filepath.Join(dir, fileName), []byte(content), 0644); err != nil {
+					t.Fatalf("unable write file %s: %s", fileName, err)
+				}
+			}
+
+			inventory, err := LoadInventory(dir)
+			if tc.expectFailed {
+				if err == nil {
+					t.Fatalf("expect load inventory failed, but got %+v", inventory)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpect error while load inventory: %s", err)
+			}
+			if !reflect.DeepEqual(inventory, tc.expectInventory) {
+				t.Fatalf("expect inventory %+v, but got %+v", tc.expectInventory, inventory)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package register
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInitFlags(t *testing.T) {
+	var boolTrue = true
+	var boolFalse = false
+
+	testCases := map[string]struct {
+		flagPrefix    string
+		args          []string
+		expectOptions *Options
+	}{
+		"should prase default options": {
+			expectOptions: &Options{
+				Enable:       &boolFalse,
+				InventoryDir: "/etc/lynx/inventory",
+				ResyncPeriod: 10 * time.Hour,
+				WorkerNumber: 10,
+			},
+		},
+		"should prase normal options with prefix": {
+			flagPrefix: "plugins.static.",
+			args: []string{
+				"--plugins.static.enable=true",
+				"--plugins.static.resync-period=1s",
+				"--plugins.static.worker-number=1",
+				"--plugins.static.inventory-dir=/tmp/inventory",
+			},
+			expectOptions: &Options{
+				Enable:       &boolTrue,
+				InventoryDir: "/tmp/inventory",
+				ResyncPeriod: time.Second,
+				WorkerNumber: 1,
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var opts Options
+			var flagset flag.FlagSet
+
+			InitFlags(&opts, &flagset, tc.flagPrefix)
+
+			if err := flagset.Parse(tc.args); err != nil {
+				t.Fatalf("unexpect error will parse flags: %s", err)
+			}
+
+			if !reflect.DeepEqual(&opts, tc.expectOptions) {
+				t.Fatalf("expect parse options %+v from flags %+v, but got %+v", tc.expectOptions, tc.args, opts)
+			}
+		})
+	}
+}
+
+func TestAddToManager(t *testing.T) {
+	var boolTrue = true
+
+	inventoryFile, err := ioutil.TempFile("", "inventory")
+	if err != nil {
+		t.Fatalf("unable create inventory file: %s", err)
+	}
+	defer os.Remove(inventoryFile.Name())
+	inventoryFile.Close()
+
+	testCases := map[string]struct {
+		inventoryDir string
+	}{
+		"should return error when inventory directory not exist": {inventoryDir: inventoryFile.Name() + ".not-exist"},
+		"should return error when inventory directory is a file": {inventoryDir: inventoryFile.Name()},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			opts := &Options{Enable: &boolTrue, InventoryDir: tc.inventoryDir}
+			// the directory is validated before the manager used
+			if err := AddToManager(opts, nil); err == nil {
+				t.Fatalf("expect error while add to manager with inventory directory %s", tc.inventoryDir)
+			}
+		})
+	}
+}
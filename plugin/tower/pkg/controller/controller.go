@@ -20,11 +20,14 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
 	kubeerror "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
@@ -33,6 +36,7 @@ import (
 	"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
 	"github.com/smartxworks/lynx/pkg/client/clientset_generated/clientset"
 	crd "github.com/smartxworks/lynx/pkg/client/informers_generated/externalversions"
+	lynxctrl "github.com/smartxworks/lynx/pkg/controller"
 	"github.com/smartxworks/lynx/plugin/tower/pkg/informer"
 	"github.com/smartxworks/lynx/plugin/tower/pkg/schema"
 )
@@ -53,6 +57,10 @@ type Controller struct {
 	labelLister         informer.Lister
 	labelInformerSynced cache.InformerSynced
 
+	hostInformer       cache.SharedIndexInformer
+	hostLister         informer.Lister
+	hostInformerSynced cache.InformerSynced
+
 	endpointInformer       cache.SharedIndexInformer
 	endpointLister         informer.Lister
 	endpointInformerSynced cache.InformerSynced
@@ -63,14 +71,27 @@ type Controller struct {
 const (
 	vnicIndex = "vnicIndex"
 	vmIndex   = "vmIndex"
+	hostIndex = "hostIndex"
 
 	externalIDName = "iface-id"
+
+	// labelKeyPrefix prefixes keys of endpoint labels recording tower labels of the vm by label ids.
+	labelKeyPrefix = "label.tower.lynx.smartx.com/"
 )
 
+// LabelKey returns the endpoint label key of the tower label. A vm could have multiple labels with the
+// same key, which could not be represented by endpoint labels with the label keys, so each tower label
+// of the vm is recorded on its endpoints with an empty value, and policies translated from tower select
+// endpoints with these labels.
+func LabelKey(labelID string) string {
+	return labelKeyPrefix + labelID
+}
+
 // New creates a new instance of controller.
 func New(towerFactory informer.SharedInformerFactory, crdFactory crd.SharedInformerFactory, crdClient clientset.Interface, resyncPeriod time.Duration) *Controller {
 	vmInformer := towerFactory.VM()
 	labelInformer := towerFactory.Label()
+	hostInformer := towerFactory.Host()
 	endpointInforer := crdFactory.Security().V1alpha1().Endpoints().Informer()
 
 	c := &Controller{
@@ -83,6 +104,9 @@ func New(towerFactory informer.SharedInformerFactory, crdFactory crd.SharedInfor
 		labelInformer:          labelInformer,
 		labelLister:            labelInformer.GetIndexer(),
 		labelInformerSynced:    labelInformer.HasSynced,
+		hostInformer:           hostInformer,
+		hostLister:             hostInformer.GetIndexer(),
+		hostInformerSynced:     hostInformer.HasSynced,
 		endpointInformer:       endpointInforer,
 		endpointLister:         endpointInforer.GetIndexer(),
 		endpointInformerSynced: endpointInforer.HasSynced,
@@ -92,6 +116,7 @@ func New(towerFactory informer.SharedInformerFactory, crdFactory crd.SharedInfor
 	// ignore error, error only when informer has already started
 	_ = vmInformer.AddIndexers(cache.Indexers{
 		vnicIndex: c.vnicIndexFunc,
+		hostIndex: c.hostIndexFunc,
 	})
 
 	_ = labelInformer.AddIndexers(cache.Indexers{
@@ -116,6 +141,17 @@ func New(towerFactory informer.SharedInformerFactory, crdFactory crd.SharedInfor
 		resyncPeriod,
 	)
 
+	// endpoints are annotated with the name of the host vm running on, so endpoints must update
+	// when the host changes.
+	hostInformer.AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.addHost,
+			UpdateFunc: c.updateHost,
+			DeleteFunc: c.deleteHost,
+		},
+		resyncPeriod,
+	)
+
 	// Why we handle endpoint events ?
 	// 1. When controller restart, vm delete event may lost. The handler would enqueue all endpoints for synchronization.
 	// 2. If endpoints unexpectedly modified by other applications, the controller would found and resync them.
@@ -136,7 +172,7 @@ func (c *Controller) Run(workers uint, stopCh <-chan struct{}) {
 	defer runtime.HandleCrash()
 	defer c.endpointQueue.ShutDown()
 
-	if !cache.WaitForNamedCacheSync(c.name, stopCh, c.vmInformerSynced, c.labelInformerSynced, c.endpointInformerSynced) {
+	if !cache.WaitForNamedCacheSync(c.name, stopCh, c.vmInformerSynced, c.labelInformerSynced, c.hostInformerSynced, c.endpointInformerSynced) {
 		return
 	}
 
@@ -163,6 +199,14 @@ func (c *Controller) vnicIndexFunc(obj interface{}) ([]string, error) {
 	return vnics, nil
 }
 
+func (c *Controller) hostIndexFunc(obj interface{}) ([]string, error) {
+	vm := obj.(*schema.VM)
+	if vm.Host == nil {
+		return nil, nil
+	}
+	return []string{vm.Host.ID}, nil
+}
+
 func (c *Controller) addVM(new interface{}) {
 	newVM := new.(*schema.VM)
 
@@ -181,7 +225,7 @@ func (c *Controller) updateVM(old interface{}, new interface{}) {
 		// ignore vm that status has been updated to deleted
 		return
 	}
-	if reflect.DeepEqual(oldVM.VMNics, newVM.VMNics) {
+	if reflect.DeepEqual(oldVM.VMNics, newVM.VMNics) && reflect.DeepEqual(oldVM.Host, newVM.Host) {
 		// todo: compare vmnics by order
 		return
 	}
@@ -191,7 +235,7 @@ func (c *Controller) updateVM(old interface{}, new interface{}) {
 }
 
 func (c *Controller) deleteVM(old interface{}) {
-	unknown, ok := old.(*cache.DeletedFinalStateUnknown)
+	unknown, ok := old.(cache.DeletedFinalStateUnknown)
 	if ok {
 		old = unknown.Obj
 	}
@@ -212,13 +256,41 @@ func (c *Controller) updateLabel(old interface{}, new interface{}) {
 }
 
 func (c *Controller) deleteLabel(old interface{}) {
-	if d, ok := old.(*cache.DeletedFinalStateUnknown); ok {
+	if d, ok := old.(cache.DeletedFinalStateUnknown); ok {
 		old = d.Obj
 	}
 	label := old.(*schema.Label)
 	c.enqueueVMNicsByVMReference(label.VMs...)
 }
 
+func (c *Controller) addHost(new interface{}) {
+	c.enqueueVMNicsByHost(new.(*schema.Host).GetID())
+}
+
+func (c *Controller) updateHost(old interface{}, new interface{}) {
+	oldHost := old.(*schema.Host)
+	newHost := new.(*schema.Host)
+
+	if oldHost.Name == newHost.Name {
+		return
+	}
+	c.enqueueVMNicsByHost(newHost.GetID())
+}
+
+func (c *Controller) deleteHost(old interface{}) {
+	if d, ok := old.(cache.DeletedFinalStateUnknown); ok {
+		old = d.Obj
+	}
+	c.enqueueVMNicsByHost(old.(*schema.Host).GetID())
+}
+
+func (c *Controller) enqueueVMNicsByHost(hostID string) {
+	vms, _ := c.vmLister.ByIndex(hostIndex, hostID)
+	for _, vm := range vms {
+		c.enqueueVMNics(vm.(*schema.VM))
+	}
+}
+
 func (c *Controller) enqueueVMNicsByVMReference(references ...schema.ObjectReference) {
 	for _, reference := range references {
 		vm, exists, _ := c.vmLister.GetByKey(reference.ID)
@@ -252,7 +324,7 @@ func (c *Controller) updateEndpoint(old interface{}, new interface{}) {
 }
 
 func (c *Controller) deleteEndpoint(old interface{}) {
-	if d, ok := old.(*cache.DeletedFinalStateUnknown); ok {
+	if d, ok := old.(cache.DeletedFinalStateUnknown); ok {
 		old = d.Obj
 	}
 	obj := old.(*v1alpha1.Endpoint)
@@ -332,6 +404,12 @@ func (c *Controller) processEndpointUpdate(vm *schema.VM, vnicKey string) error
 		return fmt.Errorf("list labels for vm %s: %s", vm.ID, err)
 	}
 
+	// name of the host vm running on, empty if unknown
+	hostname, err := c.getVMHostname(vm)
+	if err != nil {
+		return fmt.Errorf("get host for vm %s: %s", vm.ID, err)
+	}
+
 	obj, exists, err := c.endpointLister.GetByKey(vnicKey)
 	if err != nil {
 		return fmt.Errorf("get endpoint receive error: %s", err)
@@ -339,7 +417,7 @@ func (c *Controller) processEndpointUpdate(vm *schema.VM, vnicKey string) error
 
 	if !exists {
 		ep := &v1alpha1.Endpoint{}
-		c.setEndpoint(ep, vnic, vmLabels)
+		c.setEndpoint(ep, vnic, vmLabels, hostname)
 
 		klog.Infof("will add endpoint from vm %s vnic %s: %+v", vm.ID, vnicKey, ep)
 		_, err = c.crdClient.SecurityV1alpha1().Endpoints().Create(context.Background(), ep, metav1.CreateOptions{})
@@ -347,7 +425,7 @@ func (c *Controller) processEndpointUpdate(vm *schema.VM, vnicKey string) error
 	}
 
 	ep := obj.(*v1alpha1.Endpoint).DeepCopy()
-	if c.setEndpoint(ep, vnic, vmLabels) {
+	if c.setEndpoint(ep, vnic, vmLabels, hostname) {
 		klog.Infof("will update endpoint from vm %s vnic %s: %+v", vm.ID, vnicKey, ep)
 
 		_, err = c.crdClient.SecurityV1alpha1().Endpoints().Update(context.Background(), ep, metav1.UpdateOptions{})
@@ -369,20 +447,58 @@ func (c *Controller) getVMLabels(vmID string) (map[string]string, error) {
 		return nil, nil
 	}
 
-	labelsMap := make(map[string]string, len(labels))
-	for _, label := range labels {
-		labelsMap[label.(*schema.Label).Key] = label.(*schema.Label).Value
+	labelsMap := make(map[string]string, len(labels)*2)
+	multiValuedKeys := sets.NewString()
+	for _, obj := range labels {
+		label := obj.(*schema.Label)
+		if errs := validation.IsQualifiedName(LabelKey(label.ID)); len(errs) != 0 {
+			klog.Warningf("ignore label %s of vm %s: %s", label.ID, vmID, strings.Join(errs, ", "))
+			continue
+		}
+		labelsMap[LabelKey(label.ID)] = ""
+
+		if value, ok := labelsMap[label.Key]; ok && value != label.Value {
+			multiValuedKeys.Insert(label.Key)
+		}
+		labelsMap[label.Key] = label.Value
+	}
+	// keys with multiple values are ambiguous, they are only recorded by the label ids
+	for key := range multiValuedKeys {
+		delete(labelsMap, key)
 	}
 
 	return labelsMap, nil
 }
 
+// getVMHostname returns name of the host the vm running on. Tower and agents share no identifier
+// of the host, so it assumes the host name in Tower equals the hostname reported by the agent.
+// Endpoints on hosts renamed in Tower would be reported as not placed on the expected host.
+func (c *Controller) getVMHostname(vm *schema.VM) (string, error) {
+	if vm.Host == nil {
+		return "", nil
+	}
+
+	obj, exists, err := c.hostLister.GetByKey(vm.Host.ID)
+	if err != nil || !exists {
+		return "", err
+	}
+	return obj.(*schema.Host).Name, nil
+}
+
 // set endpoint return false if endpoint not changes
-func (c *Controller) setEndpoint(ep *v1alpha1.Endpoint, vnic *schema.VMNic, labels map[string]string) bool {
+func (c *Controller) setEndpoint(ep *v1alpha1.Endpoint, vnic *schema.VMNic, labels map[string]string, hostname string) bool {
 	var epCopy = ep.DeepCopy()
 
 	ep.Name = vnic.ID
 	ep.Labels = labels
+	if hostname != "" {
+		if ep.Annotations == nil {
+			ep.Annotations = make(map[string]string)
+		}
+		ep.Annotations[lynxctrl.HostAnnotation] = hostname
+	} else {
+		delete(ep.Annotations, lynxctrl.HostAnnotation)
+	}
 	ep.Spec.ManagePlaneID = c.managePlaneID
 	ep.Spec.VID = uint32(vnic.Vlan.VlanID)
 	ep.Spec.Reference.ExternalIDName = externalIDName
@@ -30,6 +30,7 @@ import (
 	"github.com/smartxworks/lynx/pkg/client/clientset_generated/clientset"
 	"github.com/smartxworks/lynx/pkg/client/clientset_generated/clientset/fake"
 	"github.com/smartxworks/lynx/pkg/client/informers_generated/externalversions"
+	lynxctrl "github.com/smartxworks/lynx/pkg/controller"
 	"github.com/smartxworks/lynx/plugin/tower/pkg/informer"
 	"github.com/smartxworks/lynx/plugin/tower/pkg/schema"
 	fakeserver "github.com/smartxworks/lynx/plugin/tower/pkg/server/fake"
@@ -79,6 +80,89 @@ func TestHandlerEndpoint(t *testing.T) {
 	}, time.Minute, 100*time.Millisecond).Should(BeTrue())
 }
 
+func TestEndpointHost(t *testing.T) {
+	RegisterTestingT(t)
+	defer server.TrackerFactory().ResetAll()
+
+	host := &schema.Host{
+		ObjectMeta: schema.ObjectMeta{ID: rand.String(20)},
+		Name:       "node01",
+	}
+	server.TrackerFactory().Host().CreateOrUpdate(host)
+
+	vm := randVM()
+	vm.Host = &schema.ObjectReference{ID: host.ID}
+	server.TrackerFactory().VM().CreateOrUpdate(vm)
+
+	var endpointHost = func() string {
+		ep, err := crdClient.SecurityV1alpha1().Endpoints().Get(context.Background(), vm.VMNics[0].ID, metav1.GetOptions{})
+		if err != nil {
+			return ""
+		}
+		return ep.Annotations[lynxctrl.HostAnnotation]
+	}
+
+	t.Run("should annotate endpoint with host name", func(t *testing.T) {
+		Eventually(endpointHost, time.Minute, 100*time.Millisecond).Should(Equal("node01"))
+	})
+
+	t.Run("should update endpoint when host renamed", func(t *testing.T) {
+		host.Name = "node02"
+		server.TrackerFactory().Host().CreateOrUpdate(host)
+		Eventually(endpointHost, time.Minute, 100*time.Millisecond).Should(Equal("node02"))
+	})
+
+	t.Run("should remove annotation when vm host unknown", func(t *testing.T) {
+		vm.Host = nil
+		server.TrackerFactory().VM().CreateOrUpdate(vm)
+		Eventually(func() bool {
+			ep, err := crdClient.SecurityV1alpha1().Endpoints().Get(context.Background(), vm.VMNics[0].ID, metav1.GetOptions{})
+			if err != nil {
+				return false
+			}
+			_, ok := ep.Annotations[lynxctrl.HostAnnotation]
+			return !ok
+		}, time.Minute, 100*time.Millisecond).Should(BeTrue())
+	})
+}
+
+func TestEndpointLabels(t *testing.T) {
+	RegisterTestingT(t)
+	defer server.TrackerFactory().ResetAll()
+
+	vm := randVM()
+	server.TrackerFactory().VM().CreateOrUpdate(vm)
+
+	var newLabel = func(key, value string) *schema.Label {
+		label := &schema.Label{
+			ObjectMeta: schema.ObjectMeta{ID: rand.String(20)},
+			Key:        key,
+			Value:      value,
+			VMs:        []schema.ObjectReference{{ID: vm.ID}},
+		}
+		server.TrackerFactory().Label().CreateOrUpdate(label)
+		return label
+	}
+	webLabel := newLabel("app", "web")
+	prodLabel := newLabel("env", "prod")
+	testLabel := newLabel("env", "test")
+
+	t.Run("should record all labels of the same key by label ids", func(t *testing.T) {
+		Eventually(func() map[string]string {
+			ep, err := crdClient.SecurityV1alpha1().Endpoints().Get(context.Background(), vm.VMNics[0].ID, metav1.GetOptions{})
+			if err != nil {
+				return nil
+			}
+			return ep.Labels
+		}, time.Minute, 100*time.Millisecond).Should(Equal(map[string]string{
+			"app":                  "web",
+			LabelKey(webLabel.ID):  "",
+			LabelKey(prodLabel.ID): "",
+			LabelKey(testLabel.ID): "",
+		}))
+	})
+}
+
 func randVM() *schema.VM {
 	return &schema.VM{
 		ObjectMeta: schema.ObjectMeta{
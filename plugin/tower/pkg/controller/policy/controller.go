@@ -0,0 +1,612 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	kubeerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	"github.com/smartxworks/lynx/pkg/client/clientset_generated/clientset"
+	crd "github.com/smartxworks/lynx/pkg/client/informers_generated/externalversions"
+	seclisters "github.com/smartxworks/lynx/pkg/client/listers_generated/security/v1alpha1"
+	"github.com/smartxworks/lynx/plugin/tower/pkg/informer"
+	"github.com/smartxworks/lynx/plugin/tower/pkg/schema"
+)
+
+const (
+	// SecurityPolicyLabel records the tower security policy id the policy translated from.
+	SecurityPolicyLabel = "towersecuritypolicy.label.lynx.smartx.com"
+	// IsolationPolicyLabel records the tower isolation policy id the policy translated from.
+	IsolationPolicyLabel = "towerisolationpolicy.label.lynx.smartx.com"
+
+	labelIndex                = "labelIndex"
+	vmIndex                   = "vmIndex"
+	towerSecurityPolicyIndex  = "towerSecurityPolicyIndex"
+	towerIsolationPolicyIndex = "towerIsolationPolicyIndex"
+)
+
+// Controller translates tower security policies and isolation policies into SecurityPolicies
+// in the dedicated tier, and keeps them in sync. Labels in the tower policies are translated into
+// endpoint selectors, EndpointGroups of the selectors would be generated by lynx-controller.
+type Controller struct {
+	// name of this controller
+	name string
+	// tier holds SecurityPolicies translated from tower policies
+	tier         string
+	tierPriority int32
+
+	crdClient clientset.Interface
+
+	vmInformer       cache.SharedIndexInformer
+	vmLister         informer.Lister
+	vmInformerSynced cache.InformerSynced
+
+	labelInformer       cache.SharedIndexInformer
+	labelLister         informer.Lister
+	labelInformerSynced cache.InformerSynced
+
+	securityPolicyInformer       cache.SharedIndexInformer
+	securityPolicyLister         informer.Lister
+	securityPolicyInformerSynced cache.InformerSynced
+
+	isolationPolicyInformer       cache.SharedIndexInformer
+	isolationPolicyLister         informer.Lister
+	isolationPolicyInformerSynced cache.InformerSynced
+
+	policyInformer       cache.SharedIndexInformer
+	policyLister         informer.Lister
+	policyInformerSynced cache.InformerSynced
+
+	tierLister         seclisters.TierLister
+	tierInformerSynced cache.InformerSynced
+
+	securityPolicyQueue  workqueue.RateLimitingInterface
+	isolationPolicyQueue workqueue.RateLimitingInterface
+}
+
+// New creates a new instance of controller.
+func New(towerFactory informer.SharedInformerFactory, crdFactory crd.SharedInformerFactory, crdClient clientset.Interface,
+	resyncPeriod time.Duration, tier string, tierPriority int32) *Controller {
+	vmInformer := towerFactory.VM()
+	labelInformer := towerFactory.Label()
+	securityPolicyInformer := towerFactory.SecurityPolicy()
+	isolationPolicyInformer := towerFactory.IsolationPolicy()
+	policyInformer := crdFactory.Security().V1alpha1().SecurityPolicies().Informer()
+	tierInformer := crdFactory.Security().V1alpha1().Tiers()
+
+	c := &Controller{
+		name:                          "PolicyController",
+		tier:                          tier,
+		tierPriority:                  tierPriority,
+		crdClient:                     crdClient,
+		vmInformer:                    vmInformer,
+		vmLister:                      vmInformer.GetIndexer(),
+		vmInformerSynced:              vmInformer.HasSynced,
+		labelInformer:                 labelInformer,
+		labelLister:                   labelInformer.GetIndexer(),
+		labelInformerSynced:           labelInformer.HasSynced,
+		securityPolicyInformer:        securityPolicyInformer,
+		securityPolicyLister:          securityPolicyInformer.GetIndexer(),
+		securityPolicyInformerSynced:  securityPolicyInformer.HasSynced,
+		isolationPolicyInformer:       isolationPolicyInformer,
+		isolationPolicyLister:         isolationPolicyInformer.GetIndexer(),
+		isolationPolicyInformerSynced: isolationPolicyInformer.HasSynced,
+		policyInformer:                policyInformer,
+		policyLister:                  policyInformer.GetIndexer(),
+		policyInformerSynced:          policyInformer.HasSynced,
+		tierLister:                    tierInformer.Lister(),
+		tierInformerSynced:            tierInformer.Informer().HasSynced,
+		securityPolicyQueue:           workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		isolationPolicyQueue:          workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+	}
+
+	// ignore error, error only when informer has already started
+	_ = securityPolicyInformer.AddIndexers(cache.Indexers{
+		labelIndex: c.securityPolicyLabelIndexFunc,
+	})
+
+	_ = isolationPolicyInformer.AddIndexers(cache.Indexers{
+		labelIndex: c.isolationPolicyLabelIndexFunc,
+		vmIndex:    c.isolationPolicyVMIndexFunc,
+	})
+
+	_ = policyInformer.AddIndexers(cache.Indexers{
+		towerSecurityPolicyIndex:  c.ownerIndexFunc(SecurityPolicyLabel),
+		towerIsolationPolicyIndex: c.ownerIndexFunc(IsolationPolicyLabel),
+	})
+
+	securityPolicyInformer.AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.addSecurityPolicy,
+			UpdateFunc: c.updateSecurityPolicy,
+			DeleteFunc: c.deleteSecurityPolicy,
+		},
+		resyncPeriod,
+	)
+
+	isolationPolicyInformer.AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.addIsolationPolicy,
+			UpdateFunc: c.updateIsolationPolicy,
+			DeleteFunc: c.deleteIsolationPolicy,
+		},
+		resyncPeriod,
+	)
+
+	// policies could not translate until labels in them exist, so policies must translate again
+	// when labels changes.
+	labelInformer.AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.addLabel,
+			UpdateFunc: c.updateLabel,
+			DeleteFunc: c.deleteLabel,
+		},
+		resyncPeriod,
+	)
+
+	// isolation policies are applied to the vnics of the vm, so policies must translate again
+	// when vnics of the vm changes.
+	vmInformer.AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.addVM,
+			UpdateFunc: c.updateVM,
+			DeleteFunc: c.deleteVM,
+		},
+		resyncPeriod,
+	)
+
+	// policies are handled to remove policies of tower policies deleted while controller not running,
+	// and to resync policies unexpectedly modified by other applications.
+	policyInformer.AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.addPolicy,
+			UpdateFunc: c.updatePolicy,
+			DeleteFunc: c.deletePolicy,
+		},
+		resyncPeriod,
+	)
+
+	// informer of tiers must be registered before the factory starts
+	tierInformer.Informer()
+
+	return c
+}
+
+// Run begins processing items, and will continue until a value is sent down stopCh or it is closed.
+func (c *Controller) Run(workers uint, stopCh <-chan struct{}) {
+	defer runtime.HandleCrash()
+	defer c.securityPolicyQueue.ShutDown()
+	defer c.isolationPolicyQueue.ShutDown()
+
+	if !cache.WaitForNamedCacheSync(c.name, stopCh, c.vmInformerSynced, c.labelInformerSynced, c.securityPolicyInformerSynced,
+		c.isolationPolicyInformerSynced, c.policyInformerSynced, c.tierInformerSynced) {
+		return
+	}
+
+	for i := uint(0); i < workers; i++ {
+		go wait.Until(c.syncSecurityPolicyWorker, time.Second, stopCh)
+		go wait.Until(c.syncIsolationPolicyWorker, time.Second, stopCh)
+	}
+
+	<-stopCh
+}
+
+func (c *Controller) securityPolicyLabelIndexFunc(obj interface{}) ([]string, error) {
+	var labels []string
+	policy := obj.(*schema.SecurityPolicy)
+	for _, apply := range policy.ApplyTo {
+		labels = append(labels, referenceIDs(apply.Selector)...)
+	}
+	labels = append(labels, ruleLabelIDs(policy.Ingress)...)
+	labels = append(labels, ruleLabelIDs(policy.Egress)...)
+	return sets.NewString(labels...).List(), nil
+}
+
+func (c *Controller) isolationPolicyLabelIndexFunc(obj interface{}) ([]string, error) {
+	var labels []string
+	policy := obj.(*schema.IsolationPolicy)
+	labels = append(labels, ruleLabelIDs(policy.Ingress)...)
+	labels = append(labels, ruleLabelIDs(policy.Egress)...)
+	return sets.NewString(labels...).List(), nil
+}
+
+func (c *Controller) isolationPolicyVMIndexFunc(obj interface{}) ([]string, error) {
+	return []string{obj.(*schema.IsolationPolicy).VM.ID}, nil
+}
+
+func (c *Controller) ownerIndexFunc(ownerLabel string) cache.IndexFunc {
+	return func(obj interface{}) ([]string, error) {
+		owner, ok := obj.(*v1alpha1.SecurityPolicy).Labels[ownerLabel]
+		if !ok {
+			return nil, nil
+		}
+		return []string{owner}, nil
+	}
+}
+
+func (c *Controller) addSecurityPolicy(new interface{}) {
+	c.securityPolicyQueue.Add(new.(*schema.SecurityPolicy).GetID())
+}
+
+func (c *Controller) updateSecurityPolicy(old interface{}, new interface{}) {
+	oldPolicy := old.(*schema.SecurityPolicy)
+	newPolicy := new.(*schema.SecurityPolicy)
+
+	if reflect.DeepEqual(oldPolicy, newPolicy) {
+		return
+	}
+	c.securityPolicyQueue.Add(newPolicy.GetID())
+}
+
+func (c *Controller) deleteSecurityPolicy(old interface{}) {
+	if d, ok := old.(cache.DeletedFinalStateUnknown); ok {
+		old = d.Obj
+	}
+	c.securityPolicyQueue.Add(old.(*schema.SecurityPolicy).GetID())
+}
+
+func (c *Controller) addIsolationPolicy(new interface{}) {
+	c.isolationPolicyQueue.Add(new.(*schema.IsolationPolicy).GetID())
+}
+
+func (c *Controller) updateIsolationPolicy(old interface{}, new interface{}) {
+	oldPolicy := old.(*schema.IsolationPolicy)
+	newPolicy := new.(*schema.IsolationPolicy)
+
+	if reflect.DeepEqual(oldPolicy, newPolicy) {
+		return
+	}
+	c.isolationPolicyQueue.Add(newPolicy.GetID())
+}
+
+func (c *Controller) deleteIsolationPolicy(old interface{}) {
+	if d, ok := old.(cache.DeletedFinalStateUnknown); ok {
+		old = d.Obj
+	}
+	c.isolationPolicyQueue.Add(old.(*schema.IsolationPolicy).GetID())
+}
+
+func (c *Controller) addLabel(new interface{}) {
+	c.enqueuePoliciesByLabel(new.(*schema.Label).GetID())
+}
+
+func (c *Controller) updateLabel(old interface{}, new interface{}) {
+	oldLabel := old.(*schema.Label)
+	newLabel := new.(*schema.Label)
+
+	if oldLabel.Key == newLabel.Key && oldLabel.Value == newLabel.Value {
+		return
+	}
+	c.enqueuePoliciesByLabel(newLabel.GetID())
+}
+
+func (c *Controller) deleteLabel(old interface{}) {
+	if d, ok := old.(cache.DeletedFinalStateUnknown); ok {
+		old = d.Obj
+	}
+	c.enqueuePoliciesByLabel(old.(*schema.Label).GetID())
+}
+
+func (c *Controller) enqueuePoliciesByLabel(labelID string) {
+	securityPolicies, _ := c.securityPolicyLister.ByIndex(labelIndex, labelID)
+	for _, policy := range securityPolicies {
+		c.securityPolicyQueue.Add(policy.(*schema.SecurityPolicy).GetID())
+	}
+
+	isolationPolicies, _ := c.isolationPolicyLister.ByIndex(labelIndex, labelID)
+	for _, policy := range isolationPolicies {
+		c.isolationPolicyQueue.Add(policy.(*schema.IsolationPolicy).GetID())
+	}
+}
+
+func (c *Controller) addVM(new interface{}) {
+	c.enqueuePoliciesByVM(new.(*schema.VM).GetID())
+}
+
+func (c *Controller) updateVM(old interface{}, new interface{}) {
+	oldVM := old.(*schema.VM)
+	newVM := new.(*schema.VM)
+
+	if oldVM.Status != schema.VMStatusDeleted && newVM.Status != schema.VMStatusDeleted &&
+		reflect.DeepEqual(oldVM.VMNics, newVM.VMNics) {
+		return
+	}
+	c.enqueuePoliciesByVM(newVM.GetID())
+}
+
+func (c *Controller) deleteVM(old interface{}) {
+	if d, ok := old.(cache.DeletedFinalStateUnknown); ok {
+		old = d.Obj
+	}
+	c.enqueuePoliciesByVM(old.(*schema.VM).GetID())
+}
+
+func (c *Controller) enqueuePoliciesByVM(vmID string) {
+	isolationPolicies, _ := c.isolationPolicyLister.ByIndex(vmIndex, vmID)
+	for _, policy := range isolationPolicies {
+		c.isolationPolicyQueue.Add(policy.(*schema.IsolationPolicy).GetID())
+	}
+}
+
+func (c *Controller) addPolicy(new interface{}) {
+	c.enqueuePolicyOwner(new.(*v1alpha1.SecurityPolicy))
+}
+
+func (c *Controller) updatePolicy(old interface{}, new interface{}) {
+	c.enqueuePolicyOwner(old.(*v1alpha1.SecurityPolicy))
+	c.enqueuePolicyOwner(new.(*v1alpha1.SecurityPolicy))
+}
+
+func (c *Controller) deletePolicy(old interface{}) {
+	if d, ok := old.(cache.DeletedFinalStateUnknown); ok {
+		old = d.Obj
+	}
+	c.enqueuePolicyOwner(old.(*v1alpha1.SecurityPolicy))
+}
+
+func (c *Controller) enqueuePolicyOwner(policy *v1alpha1.SecurityPolicy) {
+	if owner, ok := policy.Labels[SecurityPolicyLabel]; ok {
+		c.securityPolicyQueue.Add(owner)
+	}
+	if owner, ok := policy.Labels[IsolationPolicyLabel]; ok {
+		c.isolationPolicyQueue.Add(owner)
+	}
+}
+
+func (c *Controller) syncSecurityPolicyWorker() {
+	for {
+		key, quit := c.securityPolicyQueue.Get()
+		if quit {
+			return
+		}
+
+		err := c.syncSecurityPolicy(key.(string))
+		if err != nil {
+			c.securityPolicyQueue.Done(key)
+			c.securityPolicyQueue.AddRateLimited(key)
+			klog.Errorf("got error while sync policies of security policy %s: %s", key.(string), err)
+			continue
+		}
+
+		// stop the rate limiter from tracking the key
+		c.securityPolicyQueue.Done(key)
+		c.securityPolicyQueue.Forget(key)
+	}
+}
+
+func (c *Controller) syncIsolationPolicyWorker() {
+	for {
+		key, quit := c.isolationPolicyQueue.Get()
+		if quit {
+			return
+		}
+
+		err := c.syncIsolationPolicy(key.(string))
+		if err != nil {
+			c.isolationPolicyQueue.Done(key)
+			c.isolationPolicyQueue.AddRateLimited(key)
+			klog.Errorf("got error while sync policies of isolation policy %s: %s", key.(string), err)
+			continue
+		}
+
+		// stop the rate limiter from tracking the key
+		c.isolationPolicyQueue.Done(key)
+		c.isolationPolicyQueue.Forget(key)
+	}
+}
+
+func (c *Controller) syncSecurityPolicy(key string) error {
+	obj, exists, err := c.securityPolicyLister.GetByKey(key)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		// remove all policies of the security policy
+		return c.applyPolicies(towerSecurityPolicyIndex, key, nil)
+	}
+
+	policies, err := translateSecurityPolicy(obj.(*schema.SecurityPolicy), c.tier, c.getLabel)
+	if err != nil {
+		// the policy could not translate until it or its labels has been updated
+		klog.Errorf("unable translate security policy %s: %s", key, err)
+		return nil
+	}
+	for _, policy := range policies {
+		policy.Labels = map[string]string{SecurityPolicyLabel: key}
+	}
+
+	return c.applyPolicies(towerSecurityPolicyIndex, key, policies)
+}
+
+func (c *Controller) syncIsolationPolicy(key string) error {
+	obj, exists, err := c.isolationPolicyLister.GetByKey(key)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		// remove all policies of the isolation policy
+		return c.applyPolicies(towerIsolationPolicyIndex, key, nil)
+	}
+
+	isolationPolicy := obj.(*schema.IsolationPolicy)
+	endpoints, err := c.getVMEndpoints(isolationPolicy.VM.ID)
+	if err != nil {
+		return err
+	}
+
+	policies, err := translateIsolationPolicy(isolationPolicy, endpoints, c.tier, c.getLabel)
+	if err != nil {
+		// the policy could not translate until it or its labels has been updated
+		klog.Errorf("unable translate isolation policy %s: %s", key, err)
+		return nil
+	}
+	for _, policy := range policies {
+		policy.Labels = map[string]string{IsolationPolicyLabel: key}
+	}
+
+	return c.applyPolicies(towerIsolationPolicyIndex, key, policies)
+}
+
+// applyPolicies creates or updates the expected policies of the owner, and removes the others
+// policies of the owner.
+func (c *Controller) applyPolicies(ownerIndex string, owner string, policies []*v1alpha1.SecurityPolicy) error {
+	if len(policies) != 0 {
+		if err := c.createTierIfNotExist(); err != nil {
+			return fmt.Errorf("create tier %s: %s", c.tier, err)
+		}
+	}
+
+	expectPolicies := sets.NewString()
+	for _, policy := range policies {
+		expectPolicies.Insert(policy.Name)
+		if err := c.processPolicyUpdate(policy); err != nil {
+			return err
+		}
+	}
+
+	currentPolicies, err := c.policyLister.ByIndex(ownerIndex, owner)
+	if err != nil {
+		return err
+	}
+	for _, obj := range currentPolicies {
+		policyName := obj.(*v1alpha1.SecurityPolicy).Name
+		if expectPolicies.Has(policyName) {
+			continue
+		}
+		err = c.crdClient.SecurityV1alpha1().SecurityPolicies().Delete(context.Background(), policyName, metav1.DeleteOptions{})
+		if err != nil && !kubeerror.IsNotFound(err) {
+			return err
+		}
+		klog.Infof("policy %s has been delete by %s", policyName, c.name)
+	}
+
+	return nil
+}
+
+func (c *Controller) processPolicyUpdate(expectPolicy *v1alpha1.SecurityPolicy) error {
+	obj, exists, err := c.policyLister.GetByKey(expectPolicy.Name)
+	if err != nil {
+		return fmt.Errorf("get policy receive error: %s", err)
+	}
+
+	if !exists {
+		klog.Infof("will add policy %+v", expectPolicy)
+		_, err = c.crdClient.SecurityV1alpha1().SecurityPolicies().Create(context.Background(), expectPolicy, metav1.CreateOptions{})
+		return err
+	}
+
+	policy := obj.(*v1alpha1.SecurityPolicy).DeepCopy()
+	policy.Labels = expectPolicy.Labels
+	policy.Spec = expectPolicy.Spec
+
+	if !reflect.DeepEqual(policy, obj) {
+		klog.Infof("will update policy %+v", policy)
+		_, err = c.crdClient.SecurityV1alpha1().SecurityPolicies().Update(context.Background(), policy, metav1.UpdateOptions{})
+		return err
+	}
+
+	return nil
+}
+
+// createTierIfNotExist creates the whitelist tier translated policies in. Policies in the whitelist tier
+// drop traffics not allowed by default, the same as vms applied tower security policies.
+func (c *Controller) createTierIfNotExist() error {
+	_, err := c.tierLister.Get(c.tier)
+	if !kubeerror.IsNotFound(err) {
+		return err
+	}
+
+	tier := &v1alpha1.Tier{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: c.tier,
+		},
+		Spec: v1alpha1.TierSpec{
+			Description: "tier of policies translated from tower security policies and isolation policies",
+			Priority:    c.tierPriority,
+			TierMode:    v1alpha1.TierWhiteList,
+		},
+	}
+	_, err = c.crdClient.SecurityV1alpha1().Tiers().Create(context.Background(), tier, metav1.CreateOptions{})
+	if kubeerror.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+func (c *Controller) getLabel(labelID string) (string, string, error) {
+	obj, exists, err := c.labelLister.GetByKey(labelID)
+	if err != nil {
+		return "", "", err
+	}
+	if !exists {
+		return "", "", fmt.Errorf("label %s not found", labelID)
+	}
+	label := obj.(*schema.Label)
+	return label.Key, label.Value, nil
+}
+
+// getVMEndpoints returns the endpoints of the vm, endpoints are named with vnic ids by the endpoint controller.
+func (c *Controller) getVMEndpoints(vmID string) ([]string, error) {
+	obj, exists, err := c.vmLister.GetByKey(vmID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists || obj.(*schema.VM).Status == schema.VMStatusDeleted {
+		return nil, nil
+	}
+
+	var endpoints []string
+	for _, vnic := range obj.(*schema.VM).VMNics {
+		if vnic.InterfaceID == "" {
+			// vnic without interfaceID would not sync into endpoint
+			continue
+		}
+		endpoints = append(endpoints, vnic.GetID())
+	}
+	sort.Strings(endpoints)
+
+	return endpoints, nil
+}
+
+func referenceIDs(references []schema.ObjectReference) []string {
+	var ids []string
+	for _, reference := range references {
+		ids = append(ids, reference.ID)
+	}
+	return ids
+}
+
+func ruleLabelIDs(rules []schema.NetworkPolicyRule) []string {
+	var ids []string
+	for _, rule := range rules {
+		ids = append(ids, referenceIDs(rule.Selector)...)
+	}
+	return ids
+}
@@ -0,0 +1,295 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+
+	"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	"github.com/smartxworks/lynx/pkg/client/clientset_generated/clientset"
+	"github.com/smartxworks/lynx/pkg/client/clientset_generated/clientset/fake"
+	"github.com/smartxworks/lynx/pkg/client/informers_generated/externalversions"
+	"github.com/smartxworks/lynx/plugin/tower/pkg/controller"
+	"github.com/smartxworks/lynx/plugin/tower/pkg/informer"
+	"github.com/smartxworks/lynx/plugin/tower/pkg/schema"
+	fakeserver "github.com/smartxworks/lynx/plugin/tower/pkg/server/fake"
+)
+
+const (
+	tier         = "tower-policy"
+	tierPriority = 40
+)
+
+var (
+	crdClient clientset.Interface
+	server    *fakeserver.Server
+)
+
+func TestMain(m *testing.M) {
+	var stopCh = make(chan struct{})
+
+	server = fakeserver.NewServer()
+	server.Serve()
+
+	crdClient = fake.NewSimpleClientset()
+	towerFactory := informer.NewSharedInformerFactory(server.NewClient(), 0)
+	crdFactory := externalversions.NewSharedInformerFactory(crdClient, 0)
+
+	ctroller := New(towerFactory, crdFactory, crdClient, 0, tier, tierPriority)
+	go ctroller.Run(10, stopCh)
+
+	towerFactory.Start(stopCh)
+	crdFactory.Start(stopCh)
+
+	os.Exit(m.Run())
+}
+
+func TestSecurityPolicy(t *testing.T) {
+	RegisterTestingT(t)
+
+	webLabel := randLabel("app", "web")
+	dbLabel := randLabel("app", "db")
+	server.TrackerFactory().Label().CreateOrUpdate(webLabel)
+	server.TrackerFactory().Label().CreateOrUpdate(dbLabel)
+
+	ipBlock := "192.168.1.0/24"
+	port := "22,8000-8080"
+	policy := &schema.SecurityPolicy{
+		ObjectMeta: schema.ObjectMeta{ID: rand.String(20)},
+		ApplyTo: []schema.SecurityPolicyApply{
+			{Communicable: true, Selector: []schema.ObjectReference{{ID: dbLabel.ID}}},
+		},
+		Ingress: []schema.NetworkPolicyRule{{
+			Type:     schema.NetworkPolicyRuleTypeSelector,
+			Selector: []schema.ObjectReference{{ID: webLabel.ID}},
+			Ports:    []schema.NetworkPolicyRulePort{{Port: &port, Protocol: schema.NetworkPolicyRulePortProtocolTCP}},
+		}},
+		Egress: []schema.NetworkPolicyRule{{
+			Type:    schema.NetworkPolicyRuleTypeIPBlock,
+			IPBlock: &ipBlock,
+			Ports:   []schema.NetworkPolicyRulePort{{Protocol: schema.NetworkPolicyRulePortProtocolAll}},
+		}},
+	}
+	server.TrackerFactory().SecurityPolicy().CreateOrUpdate(policy)
+
+	t.Run("should create tier and policy from security policy", func(t *testing.T) {
+		dbSelector := &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: controller.LabelKey(dbLabel.ID), Operator: metav1.LabelSelectorOpExists},
+		}}
+		webSelector := &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: controller.LabelKey(webLabel.ID), Operator: metav1.LabelSelectorOpExists},
+		}}
+		expectSpec := v1alpha1.SecurityPolicySpec{
+			Tier:      tier,
+			Priority:  securityPolicyPriority,
+			AppliedTo: v1alpha1.AppliedTo{EndpointSelector: dbSelector},
+			IngressRules: []v1alpha1.Rule{
+				{
+					Name: "ingress-0",
+					Ports: []v1alpha1.SecurityPolicyPort{
+						{Protocol: v1alpha1.ProtocolTCP, PortRange: "22"},
+						{Protocol: v1alpha1.ProtocolTCP, PortRange: "8000-8080"},
+					},
+					From: v1alpha1.SecurityPolicyPeer{EndpointSelector: webSelector},
+				},
+				{Name: "ingress-communicable", From: v1alpha1.SecurityPolicyPeer{EndpointSelector: dbSelector}},
+			},
+			EgressRules: []v1alpha1.Rule{
+				{
+					Name: "egress-0",
+					To:   v1alpha1.SecurityPolicyPeer{IPBlocks: []v1alpha1.IPBlock{{IP: "192.168.1.0", PrefixLength: 24}}},
+				},
+				{Name: "egress-communicable", To: v1alpha1.SecurityPolicyPeer{EndpointSelector: dbSelector}},
+			},
+		}
+
+		Eventually(func() *v1alpha1.SecurityPolicySpec {
+			return getPolicySpec(SecurityPolicyName(policy.ID, 0))
+		}, time.Minute, 100*time.Millisecond).Should(Equal(&expectSpec))
+
+		createdTier, err := crdClient.SecurityV1alpha1().Tiers().Get(context.Background(), tier, metav1.GetOptions{})
+		Expect(err).Should(Succeed())
+		Expect(createdTier.Spec.Priority).Should(Equal(int32(tierPriority)))
+		Expect(createdTier.Spec.TierMode).Should(Equal(v1alpha1.TierWhiteList))
+	})
+
+	t.Run("should select endpoints with all labels of the same key", func(t *testing.T) {
+		prodLabel := randLabel("env", "prod")
+		testLabel := randLabel("env", "test")
+		server.TrackerFactory().Label().CreateOrUpdate(prodLabel)
+		server.TrackerFactory().Label().CreateOrUpdate(testLabel)
+
+		updatePolicy := *policy
+		updatePolicy.Ingress = []schema.NetworkPolicyRule{{
+			Type:     schema.NetworkPolicyRuleTypeSelector,
+			Selector: []schema.ObjectReference{{ID: prodLabel.ID}, {ID: testLabel.ID}},
+		}}
+		server.TrackerFactory().SecurityPolicy().CreateOrUpdate(&updatePolicy)
+
+		expectRequirements := []metav1.LabelSelectorRequirement{
+			{Key: controller.LabelKey(prodLabel.ID), Operator: metav1.LabelSelectorOpExists},
+			{Key: controller.LabelKey(testLabel.ID), Operator: metav1.LabelSelectorOpExists},
+		}
+		sort.Slice(expectRequirements, func(i, j int) bool {
+			return expectRequirements[i].Key < expectRequirements[j].Key
+		})
+		Eventually(func() []metav1.LabelSelectorRequirement {
+			spec := getPolicySpec(SecurityPolicyName(policy.ID, 0))
+			if spec == nil || spec.IngressRules[0].From.EndpointSelector == nil {
+				return nil
+			}
+			return spec.IngressRules[0].From.EndpointSelector.MatchExpressions
+		}, time.Minute, 100*time.Millisecond).Should(Equal(expectRequirements))
+
+		server.TrackerFactory().SecurityPolicy().CreateOrUpdate(policy)
+	})
+
+	t.Run("should remove policy of removed apply_to", func(t *testing.T) {
+		updatePolicy := *policy
+		updatePolicy.ApplyTo = append(updatePolicy.ApplyTo, schema.SecurityPolicyApply{
+			Selector: []schema.ObjectReference{{ID: webLabel.ID}},
+		})
+		server.TrackerFactory().SecurityPolicy().CreateOrUpdate(&updatePolicy)
+		Eventually(func() *v1alpha1.SecurityPolicySpec {
+			return getPolicySpec(SecurityPolicyName(policy.ID, 1))
+		}, time.Minute, 100*time.Millisecond).ShouldNot(BeNil())
+
+		server.TrackerFactory().SecurityPolicy().CreateOrUpdate(policy)
+		Eventually(func() *v1alpha1.SecurityPolicySpec {
+			return getPolicySpec(SecurityPolicyName(policy.ID, 1))
+		}, time.Minute, 100*time.Millisecond).Should(BeNil())
+		Expect(getPolicySpec(SecurityPolicyName(policy.ID, 0))).ShouldNot(BeNil())
+	})
+
+	t.Run("should remove policies of removed security policy", func(t *testing.T) {
+		Expect(server.TrackerFactory().SecurityPolicy().Delete(policy.ID)).Should(Succeed())
+		Eventually(func() *v1alpha1.SecurityPolicySpec {
+			return getPolicySpec(SecurityPolicyName(policy.ID, 0))
+		}, time.Minute, 100*time.Millisecond).Should(BeNil())
+	})
+}
+
+func TestIsolationPolicy(t *testing.T) {
+	RegisterTestingT(t)
+
+	vm := randVM()
+	server.TrackerFactory().VM().CreateOrUpdate(vm)
+
+	policy := &schema.IsolationPolicy{
+		ObjectMeta: schema.ObjectMeta{ID: rand.String(20)},
+		VM:         schema.ObjectReference{ID: vm.ID},
+		Mode:       schema.IsolationModeAll,
+	}
+	server.TrackerFactory().IsolationPolicy().CreateOrUpdate(policy)
+
+	t.Run("should drop all traffics of the isolated vm", func(t *testing.T) {
+		expectSpec := v1alpha1.SecurityPolicySpec{
+			Tier:         tier,
+			Priority:     isolationPolicyPriority,
+			AppliedTo:    v1alpha1.AppliedTo{Endpoints: []string{vm.VMNics[0].ID}},
+			IngressRules: []v1alpha1.Rule{{Name: "ingress-isolate", Action: v1alpha1.RuleActionDrop}},
+			EgressRules:  []v1alpha1.Rule{{Name: "egress-isolate", Action: v1alpha1.RuleActionDrop}},
+		}
+		Eventually(func() *v1alpha1.SecurityPolicySpec {
+			return getPolicySpec(IsolationPolicyName(policy.ID))
+		}, time.Minute, 100*time.Millisecond).Should(Equal(&expectSpec))
+		Expect(getPolicySpec(IsolationAllowPolicyName(policy.ID))).Should(BeNil())
+	})
+
+	t.Run("should allow traffics of the partial isolated vm", func(t *testing.T) {
+		updatePolicy := *policy
+		updatePolicy.Mode = schema.IsolationModePartial
+		updatePolicy.Ingress = []schema.NetworkPolicyRule{{
+			Type:  schema.NetworkPolicyRuleTypeAll,
+			Ports: []schema.NetworkPolicyRulePort{{Protocol: schema.NetworkPolicyRulePortProtocolICMP}},
+		}}
+		server.TrackerFactory().IsolationPolicy().CreateOrUpdate(&updatePolicy)
+
+		expectSpec := v1alpha1.SecurityPolicySpec{
+			Tier:      tier,
+			Priority:  isolationAllowPolicyPriority,
+			AppliedTo: v1alpha1.AppliedTo{Endpoints: []string{vm.VMNics[0].ID}},
+			IngressRules: []v1alpha1.Rule{{
+				Name:  "ingress-0",
+				Ports: []v1alpha1.SecurityPolicyPort{{Protocol: v1alpha1.ProtocolICMP}},
+			}},
+		}
+		Eventually(func() *v1alpha1.SecurityPolicySpec {
+			return getPolicySpec(IsolationAllowPolicyName(policy.ID))
+		}, time.Minute, 100*time.Millisecond).Should(Equal(&expectSpec))
+	})
+
+	t.Run("should update policies when vnics of the vm changes", func(t *testing.T) {
+		updateVM := *vm
+		updateVM.VMNics = append(append([]schema.VMNic(nil), vm.VMNics...), randVMNic())
+		server.TrackerFactory().VM().CreateOrUpdate(&updateVM)
+
+		Eventually(func() int {
+			spec := getPolicySpec(IsolationPolicyName(policy.ID))
+			if spec == nil {
+				return 0
+			}
+			return len(spec.AppliedTo.Endpoints)
+		}, time.Minute, 100*time.Millisecond).Should(Equal(2))
+	})
+
+	t.Run("should remove policies of removed isolation policy", func(t *testing.T) {
+		Expect(server.TrackerFactory().IsolationPolicy().Delete(policy.ID)).Should(Succeed())
+		Eventually(func() bool {
+			return getPolicySpec(IsolationPolicyName(policy.ID)) == nil &&
+				getPolicySpec(IsolationAllowPolicyName(policy.ID)) == nil
+		}, time.Minute, 100*time.Millisecond).Should(BeTrue())
+	})
+}
+
+func getPolicySpec(name string) *v1alpha1.SecurityPolicySpec {
+	policy, err := crdClient.SecurityV1alpha1().SecurityPolicies().Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+	return &policy.Spec
+}
+
+func randLabel(key, value string) *schema.Label {
+	return &schema.Label{
+		ObjectMeta: schema.ObjectMeta{ID: rand.String(20)},
+		Key:        key,
+		Value:      value,
+	}
+}
+
+func randVM() *schema.VM {
+	return &schema.VM{
+		ObjectMeta: schema.ObjectMeta{ID: rand.String(20)},
+		VMNics:     []schema.VMNic{randVMNic()},
+	}
+}
+
+func randVMNic() schema.VMNic {
+	return schema.VMNic{
+		ObjectMeta:  schema.ObjectMeta{ID: rand.String(20)},
+		InterfaceID: rand.String(20),
+	}
+}
@@ -0,0 +1,299 @@
+/*
+Copyright 2021 The Lynx Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/smartxworks/lynx/pkg/apis/security/v1alpha1"
+	"github.com/smartxworks/lynx/pkg/types"
+	"github.com/smartxworks/lynx/plugin/tower/pkg/controller"
+	"github.com/smartxworks/lynx/plugin/tower/pkg/schema"
+)
+
+const (
+	// securityPolicyPriority is the priority of policies translated from security policies, allowed
+	// traffics of security policies are combined using a logical OR like tower does.
+	securityPolicyPriority = 10
+	// isolationPolicyPriority is the priority of policies drop traffics of isolated vms, it takes
+	// precedence over security policies.
+	isolationPolicyPriority = 50
+	// isolationAllowPolicyPriority is the priority of policies allow traffics of partial isolated
+	// vms, it takes precedence over isolation policies.
+	isolationAllowPolicyPriority = 60
+)
+
+// SecurityPolicyName returns name of the policy translated from the item of security policy apply_to.
+func SecurityPolicyName(policyID string, applyIndex int) string {
+	return fmt.Sprintf("tower.sp-%s-%d", policyID, applyIndex)
+}
+
+// IsolationPolicyName returns name of the policy drops traffics of the isolated vm.
+func IsolationPolicyName(policyID string) string {
+	return "tower.isolation-" + policyID
+}
+
+// IsolationAllowPolicyName returns name of the policy allows traffics of the partial isolated vm.
+func IsolationAllowPolicyName(policyID string) string {
+	return IsolationPolicyName(policyID) + "-allow"
+}
+
+// labelGetter returns key and value of the label.
+type labelGetter func(labelID string) (key string, value string, err error)
+
+// translateSecurityPolicy returns policies in the tier for each item of security policy apply_to.
+func translateSecurityPolicy(policy *schema.SecurityPolicy, tier string, getLabel labelGetter) ([]*v1alpha1.SecurityPolicy, error) {
+	var policies []*v1alpha1.SecurityPolicy
+
+	ingressRules, err := translateRules("ingress", policy.Ingress, getLabel)
+	if err != nil {
+		return nil, fmt.Errorf("ingress: %s", err)
+	}
+	egressRules, err := translateRules("egress", policy.Egress, getLabel)
+	if err != nil {
+		return nil, fmt.Errorf("egress: %s", err)
+	}
+
+	for index, apply := range policy.ApplyTo {
+		selector, err := labelSelector(apply.Selector, getLabel)
+		if err != nil {
+			return nil, fmt.Errorf("apply_to %d: %s", index, err)
+		}
+
+		lynxPolicy := &v1alpha1.SecurityPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: SecurityPolicyName(policy.GetID(), index),
+			},
+			Spec: v1alpha1.SecurityPolicySpec{
+				Tier:         tier,
+				Priority:     securityPolicyPriority,
+				AppliedTo:    v1alpha1.AppliedTo{EndpointSelector: selector},
+				IngressRules: copyRules(ingressRules),
+				EgressRules:  copyRules(egressRules),
+			},
+		}
+
+		if apply.Communicable {
+			// allows traffics between vms the policy applied to
+			lynxPolicy.Spec.IngressRules = append(lynxPolicy.Spec.IngressRules, v1alpha1.Rule{
+				Name: "ingress-communicable",
+				From: v1alpha1.SecurityPolicyPeer{EndpointSelector: selector.DeepCopy()},
+			})
+			lynxPolicy.Spec.EgressRules = append(lynxPolicy.Spec.EgressRules, v1alpha1.Rule{
+				Name: "egress-communicable",
+				To:   v1alpha1.SecurityPolicyPeer{EndpointSelector: selector.DeepCopy()},
+			})
+		}
+
+		policies = append(policies, lynxPolicy)
+	}
+
+	return policies, nil
+}
+
+// translateIsolationPolicy returns policies in the tier isolate the endpoints of the vm. Isolated vm
+// traffics are dropped by the isolation policy, except traffics allowed by the isolation allow policy
+// in partial mode. Policies takes precedence over policies translated from security policies.
+func translateIsolationPolicy(policy *schema.IsolationPolicy, endpoints []string, tier string, getLabel labelGetter) ([]*v1alpha1.SecurityPolicy, error) {
+	if len(endpoints) == 0 {
+		// the vm has no endpoints to isolate
+		return nil, nil
+	}
+
+	policies := []*v1alpha1.SecurityPolicy{{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: IsolationPolicyName(policy.GetID()),
+		},
+		Spec: v1alpha1.SecurityPolicySpec{
+			Tier:         tier,
+			Priority:     isolationPolicyPriority,
+			AppliedTo:    v1alpha1.AppliedTo{Endpoints: endpoints},
+			IngressRules: []v1alpha1.Rule{{Name: "ingress-isolate", Action: v1alpha1.RuleActionDrop}},
+			EgressRules:  []v1alpha1.Rule{{Name: "egress-isolate", Action: v1alpha1.RuleActionDrop}},
+		},
+	}}
+
+	if policy.Mode != schema.IsolationModePartial {
+		return policies, nil
+	}
+
+	ingressRules, err := translateRules("ingress", policy.Ingress, getLabel)
+	if err != nil {
+		return nil, fmt.Errorf("ingress: %s", err)
+	}
+	egressRules, err := translateRules("egress", policy.Egress, getLabel)
+	if err != nil {
+		return nil, fmt.Errorf("egress: %s", err)
+	}
+
+	policies = append(policies, &v1alpha1.SecurityPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: IsolationAllowPolicyName(policy.GetID()),
+		},
+		Spec: v1alpha1.SecurityPolicySpec{
+			Tier:         tier,
+			Priority:     isolationAllowPolicyPriority,
+			AppliedTo:    v1alpha1.AppliedTo{Endpoints: append([]string(nil), endpoints...)},
+			IngressRules: ingressRules,
+			EgressRules:  egressRules,
+		},
+	})
+
+	return policies, nil
+}
+
+// translateRules translates tower rules into rules with peers in To, the caller should move
+// the peers into From for ingress rules.
+func translateRules(direction string, rules []schema.NetworkPolicyRule, getLabel labelGetter) ([]v1alpha1.Rule, error) {
+	var lynxRules []v1alpha1.Rule
+
+	for index, rule := range rules {
+		ports, err := translatePorts(rule.Ports)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %s", index, err)
+		}
+
+		var peer v1alpha1.SecurityPolicyPeer
+		switch rule.Type {
+		case schema.NetworkPolicyRuleTypeAll:
+			// empty peer matches all sources or destinations
+		case schema.NetworkPolicyRuleTypeIPBlock:
+			if rule.IPBlock == nil {
+				return nil, fmt.Errorf("rule %d: ip_block must not be empty", index)
+			}
+			ipBlock, err := parseIPBlock(*rule.IPBlock)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %s", index, err)
+			}
+			peer.IPBlocks = []v1alpha1.IPBlock{*ipBlock}
+		case schema.NetworkPolicyRuleTypeSelector:
+			peer.EndpointSelector, err = labelSelector(rule.Selector, getLabel)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %s", index, err)
+			}
+		default:
+			return nil, fmt.Errorf("rule %d: unknown rule type %s", index, rule.Type)
+		}
+
+		lynxRule := v1alpha1.Rule{
+			Name:  fmt.Sprintf("%s-%d", direction, index),
+			Ports: ports,
+		}
+		if direction == "ingress" {
+			lynxRule.From = peer
+		} else {
+			lynxRule.To = peer
+		}
+		lynxRules = append(lynxRules, lynxRule)
+	}
+
+	return lynxRules, nil
+}
+
+// translatePorts returns nil if any of the ports matches all protocols. Tower port could be a single
+// port, a port range or a list of them separated by comma, e.g. "22,80,8000-8080".
+func translatePorts(ports []schema.NetworkPolicyRulePort) ([]v1alpha1.SecurityPolicyPort, error) {
+	var lynxPorts []v1alpha1.SecurityPolicyPort
+
+	for _, port := range ports {
+		var protocol v1alpha1.Protocol
+
+		switch port.Protocol {
+		case schema.NetworkPolicyRulePortProtocolAll:
+			return nil, nil
+		case schema.NetworkPolicyRulePortProtocolTCP:
+			protocol = v1alpha1.ProtocolTCP
+		case schema.NetworkPolicyRulePortProtocolUDP:
+			protocol = v1alpha1.ProtocolUDP
+		case schema.NetworkPolicyRulePortProtocolICMP:
+			lynxPorts = append(lynxPorts, v1alpha1.SecurityPolicyPort{Protocol: v1alpha1.ProtocolICMP})
+			continue
+		default:
+			return nil, fmt.Errorf("unknown protocol %s", port.Protocol)
+		}
+
+		if port.Port == nil || strings.TrimSpace(*port.Port) == "" {
+			lynxPorts = append(lynxPorts, v1alpha1.SecurityPolicyPort{Protocol: protocol})
+			continue
+		}
+		for _, portRange := range strings.Split(*port.Port, ",") {
+			lynxPorts = append(lynxPorts, v1alpha1.SecurityPolicyPort{
+				Protocol:  protocol,
+				PortRange: strings.TrimSpace(portRange),
+			})
+		}
+	}
+
+	return lynxPorts, nil
+}
+
+// parseIPBlock parses ip block in format of cidr or single ip.
+func parseIPBlock(ipBlock string) (*v1alpha1.IPBlock, error) {
+	if !strings.Contains(ipBlock, "/") {
+		ip := net.ParseIP(ipBlock)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid ip_block %s", ipBlock)
+		}
+		prefixLength := net.IPv6len * 8
+		if ip.To4() != nil {
+			prefixLength = net.IPv4len * 8
+		}
+		return &v1alpha1.IPBlock{IP: types.IPAddress(ip.String()), PrefixLength: int32(prefixLength)}, nil
+	}
+
+	_, ipNet, err := net.ParseCIDR(ipBlock)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ip_block %s: %s", ipBlock, err)
+	}
+	prefixLength, _ := ipNet.Mask.Size()
+	return &v1alpha1.IPBlock{IP: types.IPAddress(ipNet.IP.String()), PrefixLength: int32(prefixLength)}, nil
+}
+
+// labelSelector returns selector selects endpoints with all of the labels. A vm could have multiple labels
+// with the same key, endpoints record each label of their vms by the label id, so the selector selects
+// endpoints by the label ids instead of the label keys and values.
+func labelSelector(labels []schema.ObjectReference, getLabel labelGetter) (*metav1.LabelSelector, error) {
+	var selector = &metav1.LabelSelector{}
+
+	for _, label := range sets.NewString(referenceIDs(labels)...).List() {
+		if _, _, err := getLabel(label); err != nil {
+			return nil, err
+		}
+		selector.MatchExpressions = append(selector.MatchExpressions, metav1.LabelSelectorRequirement{
+			Key:      controller.LabelKey(label),
+			Operator: metav1.LabelSelectorOpExists,
+		})
+	}
+
+	return selector, nil
+}
+
+func copyRules(rules []v1alpha1.Rule) []v1alpha1.Rule {
+	if rules == nil {
+		return nil
+	}
+	rulesCopy := make([]v1alpha1.Rule, 0, len(rules))
+	for _, rule := range rules {
+		rulesCopy = append(rulesCopy, *rule.DeepCopy())
+	}
+	return rulesCopy
+}
@@ -42,6 +42,12 @@ type SharedInformerFactory interface {
 	VM() cache.SharedIndexInformer
 	// Label return informer for &schema.Label{}
 	Label() cache.SharedIndexInformer
+	// Host return informer for &schema.Host{}
+	Host() cache.SharedIndexInformer
+	// SecurityPolicy return informer for &schema.SecurityPolicy{}
+	SecurityPolicy() cache.SharedIndexInformer
+	// IsolationPolicy return informer for &schema.IsolationPolicy{}
+	IsolationPolicy() cache.SharedIndexInformer
 }
 
 // NewSharedInformerFactory constructs a new instance of sharedInformerFactory for all resources
@@ -113,6 +119,21 @@ func (f *sharedInformerFactory) Label() cache.SharedIndexInformer {
 	return f.InformerFor(&schema.Label{})
 }
 
+// Host implements SharedInformerFactory.Host
+func (f *sharedInformerFactory) Host() cache.SharedIndexInformer {
+	return f.InformerFor(&schema.Host{})
+}
+
+// SecurityPolicy implements SharedInformerFactory.SecurityPolicy
+func (f *sharedInformerFactory) SecurityPolicy() cache.SharedIndexInformer {
+	return f.InformerFor(&schema.SecurityPolicy{})
+}
+
+// IsolationPolicy implements SharedInformerFactory.IsolationPolicy
+func (f *sharedInformerFactory) IsolationPolicy() cache.SharedIndexInformer {
+	return f.InformerFor(&schema.IsolationPolicy{})
+}
+
 // InformerFor implements SharedInformerFactory.InformerFor
 func (f *sharedInformerFactory) InformerFor(obj schema.Object) cache.SharedIndexInformer {
 	f.lock.Lock()
@@ -26,6 +26,7 @@ import (
 	"github.com/smartxworks/lynx/pkg/client/informers_generated/externalversions"
 	"github.com/smartxworks/lynx/plugin/tower/pkg/client"
 	"github.com/smartxworks/lynx/plugin/tower/pkg/controller"
+	"github.com/smartxworks/lynx/plugin/tower/pkg/controller/policy"
 	"github.com/smartxworks/lynx/plugin/tower/pkg/informer"
 )
 
@@ -35,6 +36,11 @@ type Options struct {
 	Client       *client.Client
 	ResyncPeriod time.Duration
 	WorkerNumber uint
+	// EnablePolicy enables sync tower security policies and isolation policies into SecurityPolicies
+	EnablePolicy bool
+	// PolicyTier is the tier policies translated from tower policies in
+	PolicyTier         string
+	PolicyTierPriority int
 }
 
 // InitFlags set and load options from flagset.
@@ -59,6 +65,9 @@ func InitFlags(opts *Options, flagset *flag.FlagSet, flagPrefix string) {
 	flagset.StringVar(&opts.Client.UserInfo.Password, withPrefix("password"), "", "Tower user password for authenticate")
 	flagset.UintVar(&opts.WorkerNumber, withPrefix("worker-number"), 10, "Controller worker number")
 	flagset.DurationVar(&opts.ResyncPeriod, withPrefix("resync-period"), 10*time.Hour, "Controller resync period")
+	flagset.BoolVar(&opts.EnablePolicy, withPrefix("enable-policy"), false, "If true, sync tower security policies and isolation policies (default false)")
+	flagset.StringVar(&opts.PolicyTier, withPrefix("policy-tier"), "tower-policy", "Tier of policies translated from tower policies")
+	flagset.IntVar(&opts.PolicyTierPriority, withPrefix("policy-tier-priority"), 40, "Priority of tier the tower policies translated in")
 }
 
 // AddToManager allow you register controller to Manager.
@@ -76,9 +85,17 @@ func AddToManager(opts *Options, mgr manager.Manager) error {
 	crdFactory := externalversions.NewSharedInformerFactory(crdClient, opts.ResyncPeriod)
 	endpointController := controller.New(towerFactory, crdFactory, crdClient, opts.ResyncPeriod)
 
+	var policyController *policy.Controller
+	if opts.EnablePolicy {
+		policyController = policy.New(towerFactory, crdFactory, crdClient, opts.ResyncPeriod, opts.PolicyTier, int32(opts.PolicyTierPriority))
+	}
+
 	err = mgr.Add(manager.RunnableFunc(func(stopChan <-chan struct{}) error {
 		towerFactory.Start(stopChan)
 		crdFactory.Start(stopChan)
+		if policyController != nil {
+			go policyController.Run(opts.WorkerNumber, stopChan)
+		}
 		endpointController.Run(opts.WorkerNumber, stopChan)
 		return nil
 	}))
@@ -36,10 +36,12 @@ func TestInitFlags(t *testing.T) {
 	}{
 		"should prase default options": {
 			expectOptions: &Options{
-				Enable:       &boolFalse,
-				Client:       &client.Client{UserInfo: &client.UserInfo{}},
-				ResyncPeriod: 10 * time.Hour,
-				WorkerNumber: 10,
+				Enable:             &boolFalse,
+				Client:             &client.Client{UserInfo: &client.UserInfo{}},
+				ResyncPeriod:       10 * time.Hour,
+				WorkerNumber:       10,
+				PolicyTier:         "tower-policy",
+				PolicyTierPriority: 40,
 			},
 		},
 		"should prase normal options with prefix": {
@@ -49,6 +51,9 @@ func TestInitFlags(t *testing.T) {
 				"--plugins.tower.address=127.0.0.1:8800",
 				"--plugins.tower.resync-period=1s",
 				"--plugins.tower.worker-number=1",
+				"--plugins.tower.enable-policy=true",
+				"--plugins.tower.policy-tier=tower",
+				"--plugins.tower.policy-tier-priority=10",
 			},
 			expectOptions: &Options{
 				Enable: &boolTrue,
@@ -56,8 +61,11 @@ func TestInitFlags(t *testing.T) {
 					URL:      "127.0.0.1:8800",
 					UserInfo: &client.UserInfo{},
 				},
-				ResyncPeriod: time.Second,
-				WorkerNumber: 1,
+				ResyncPeriod:       time.Second,
+				WorkerNumber:       1,
+				EnablePolicy:       true,
+				PolicyTier:         "tower",
+				PolicyTierPriority: 10,
 			},
 		},
 	}
@@ -19,12 +19,13 @@ package schema
 type VM struct {
 	ObjectMeta
 
-	Name        string   `json:"name"`
-	Description string   `json:"description,omitempty"`
-	Vcpu        int      `json:"vcpu,omitempty"`
-	Memory      float64  `json:"memory,omitempty"`
-	Status      VMStatus `json:"status"`
-	VMNics      []VMNic  `json:"vm_nics,omitempty"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Vcpu        int              `json:"vcpu,omitempty"`
+	Memory      float64          `json:"memory,omitempty"`
+	Status      VMStatus         `json:"status"`
+	VMNics      []VMNic          `json:"vm_nics,omitempty"`
+	Host        *ObjectReference `json:"host,omitempty"`
 }
 
 // VMStatus is enumeration of vm status
@@ -80,6 +81,31 @@ type VMList struct {
 	VMS []VM `json:"vms,omitempty"`
 }
 
+type Host struct {
+	ObjectMeta
+
+	Name         string     `json:"name"`
+	ManagementIP string     `json:"management_ip,omitempty"`
+	Status       HostStatus `json:"status,omitempty"`
+}
+
+// HostStatus is enumeration of host status
+type HostStatus string
+
+const (
+	HostStatusConnectedHealthy HostStatus = "CONNECTED_HEALTHY"
+	HostStatusConnectedWarning HostStatus = "CONNECTED_WARNING"
+	HostStatusConnectedError   HostStatus = "CONNECTED_ERROR"
+	HostStatusConnecting       HostStatus = "CONNECTING"
+	HostStatusSessionExpired   HostStatus = "SESSION_EXPIRED"
+	HostStatusInitializing     HostStatus = "INITIALIZING"
+)
+
+// HostList is a list of hosts
+type HostList struct {
+	Hosts []Host `json:"hosts,omitempty"`
+}
+
 type Label struct {
 	ObjectMeta
 
@@ -92,3 +118,79 @@ type Label struct {
 type LabelList struct {
 	Labels []Label `json:"labels,omitempty"`
 }
+
+type SecurityPolicy struct {
+	ObjectMeta
+
+	Name    string                `json:"name"`
+	ApplyTo []SecurityPolicyApply `json:"apply_to"`
+	Ingress []NetworkPolicyRule   `json:"ingress,omitempty"`
+	Egress  []NetworkPolicyRule   `json:"egress,omitempty"`
+}
+
+// SecurityPolicyApply selects vms the policy applied to by labels
+type SecurityPolicyApply struct {
+	// Communicable allows traffics between the selected vms
+	Communicable bool              `json:"communicable"`
+	Selector     []ObjectReference `json:"selector"`
+}
+
+type NetworkPolicyRule struct {
+	Type     NetworkPolicyRuleType   `json:"type"`
+	IPBlock  *string                 `json:"ip_block,omitempty"`
+	Selector []ObjectReference       `json:"selector,omitempty"`
+	Ports    []NetworkPolicyRulePort `json:"ports,omitempty"`
+}
+
+// NetworkPolicyRuleType is enumeration of network policy rule types
+type NetworkPolicyRuleType string
+
+const (
+	NetworkPolicyRuleTypeAll      NetworkPolicyRuleType = "ALL"
+	NetworkPolicyRuleTypeIPBlock  NetworkPolicyRuleType = "IP_BLOCK"
+	NetworkPolicyRuleTypeSelector NetworkPolicyRuleType = "SELECTOR"
+)
+
+type NetworkPolicyRulePort struct {
+	Port     *string                       `json:"port,omitempty"`
+	Protocol NetworkPolicyRulePortProtocol `json:"protocol"`
+}
+
+// NetworkPolicyRulePortProtocol is enumeration of network policy rule port protocols
+type NetworkPolicyRulePortProtocol string
+
+const (
+	NetworkPolicyRulePortProtocolAll  NetworkPolicyRulePortProtocol = "ALL"
+	NetworkPolicyRulePortProtocolICMP NetworkPolicyRulePortProtocol = "ICMP"
+	NetworkPolicyRulePortProtocolTCP  NetworkPolicyRulePortProtocol = "TCP"
+	NetworkPolicyRulePortProtocolUDP  NetworkPolicyRulePortProtocol = "UDP"
+)
+
+// SecurityPolicyList is a list of security policies
+type SecurityPolicyList struct {
+	SecurityPolicies []SecurityPolicy `json:"securitypolicies,omitempty"`
+}
+
+type IsolationPolicy struct {
+	ObjectMeta
+
+	VM      ObjectReference     `json:"vm"`
+	Mode    IsolationMode       `json:"mode"`
+	Ingress []NetworkPolicyRule `json:"ingress,omitempty"`
+	Egress  []NetworkPolicyRule `json:"egress,omitempty"`
+}
+
+// IsolationMode is enumeration of vm isolation modes
+type IsolationMode string
+
+const (
+	// IsolationModeAll isolates all traffics of the vm
+	IsolationModeAll IsolationMode = "ALL"
+	// IsolationModePartial isolates traffics of the vm except the ingress and egress rules allowed
+	IsolationModePartial IsolationMode = "PARTIAL"
+)
+
+// IsolationPolicyList is a list of isolation policies
+type IsolationPolicyList struct {
+	IsolationPolicies []IsolationPolicy `json:"isolationpolicies,omitempty"`
+}
@@ -10,6 +10,16 @@ import (
 	"github.com/smartxworks/lynx/plugin/tower/pkg/schema"
 )
 
+type HostEvent struct {
+	Mutation MutationType `json:"mutation"`
+	Node     *schema.Host `json:"node"`
+}
+
+type IsolationPolicyEvent struct {
+	Mutation MutationType            `json:"mutation"`
+	Node     *schema.IsolationPolicy `json:"node"`
+}
+
 type LabelEvent struct {
 	Mutation MutationType  `json:"mutation"`
 	Node     *schema.Label `json:"node"`
@@ -25,6 +35,11 @@ type LoginInput struct {
 	Username string     `json:"username"`
 }
 
+type SecurityPolicyEvent struct {
+	Mutation MutationType           `json:"mutation"`
+	Node     *schema.SecurityPolicy `json:"node"`
+}
+
 type VMEvent struct {
 	Mutation MutationType `json:"mutation"`
 	Node     *schema.VM   `json:"node"`
@@ -39,6 +39,15 @@ func (r *queryResolver) Vms(ctx context.Context) ([]schema.VM, error) {
 	return vms, nil
 }
 
+func (r *queryResolver) Hosts(ctx context.Context) ([]schema.Host, error) {
+	hostList := r.TrackerFactory().Host().List()
+	hosts := make([]schema.Host, 0, len(hostList))
+	for _, host := range hostList {
+		hosts = append(hosts, *host.(*schema.Host))
+	}
+	return hosts, nil
+}
+
 func (r *queryResolver) Labels(ctx context.Context) ([]schema.Label, error) {
 	labelList := r.TrackerFactory().Label().List()
 	labels := make([]schema.Label, 0, len(labelList))
@@ -48,6 +57,24 @@ func (r *queryResolver) Labels(ctx context.Context) ([]schema.Label, error) {
 	return labels, nil
 }
 
+func (r *queryResolver) Securitypolicies(ctx context.Context) ([]schema.SecurityPolicy, error) {
+	policyList := r.TrackerFactory().SecurityPolicy().List()
+	policies := make([]schema.SecurityPolicy, 0, len(policyList))
+	for _, policy := range policyList {
+		policies = append(policies, *policy.(*schema.SecurityPolicy))
+	}
+	return policies, nil
+}
+
+func (r *queryResolver) Isolationpolicies(ctx context.Context) ([]schema.IsolationPolicy, error) {
+	policyList := r.TrackerFactory().IsolationPolicy().List()
+	policies := make([]schema.IsolationPolicy, 0, len(policyList))
+	for _, policy := range policyList {
+		policies = append(policies, *policy.(*schema.IsolationPolicy))
+	}
+	return policies, nil
+}
+
 func (r *subscriptionResolver) VM(ctx context.Context) (<-chan *model.VMEvent, error) {
 	var vmEventCh = make(chan *model.VMEvent, 100)
 
@@ -75,12 +102,40 @@ func (r *subscriptionResolver) VM(ctx context.Context) (<-chan *model.VMEvent, e
 	return vmEventCh, nil
 }
 
+func (r *subscriptionResolver) Host(ctx context.Context) (<-chan *model.HostEvent, error) {
+	var hostEventCh = make(chan *model.HostEvent, 100)
+
+	go func() {
+		eventCh, stopWatch := r.TrackerFactory().Host().Watch()
+		defer stopWatch()
+		defer close(hostEventCh)
+
+		for {
+			select {
+			case event, ok := <-eventCh:
+				if !ok {
+					return
+				}
+				hostEventCh <- &model.HostEvent{
+					Mutation: event.Type,
+					Node:     event.Object.(*schema.Host),
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return hostEventCh, nil
+}
+
 func (r *subscriptionResolver) Label(ctx context.Context) (<-chan *model.LabelEvent, error) {
 	var labelEventCh = make(chan *model.LabelEvent, 100)
 
 	go func() {
 		eventCh, stopWatch := r.TrackerFactory().Label().Watch()
 		defer stopWatch()
+		defer close(labelEventCh)
 
 		for {
 			select {
@@ -101,6 +156,60 @@ func (r *subscriptionResolver) Label(ctx context.Context) (<-chan *model.LabelEv
 	return labelEventCh, nil
 }
 
+func (r *subscriptionResolver) Securitypolicy(ctx context.Context) (<-chan *model.SecurityPolicyEvent, error) {
+	var policyEventCh = make(chan *model.SecurityPolicyEvent, 100)
+
+	go func() {
+		eventCh, stopWatch := r.TrackerFactory().SecurityPolicy().Watch()
+		defer stopWatch()
+		defer close(policyEventCh)
+
+		for {
+			select {
+			case event, ok := <-eventCh:
+				if !ok {
+					return
+				}
+				policyEventCh <- &model.SecurityPolicyEvent{
+					Mutation: event.Type,
+					Node:     event.Object.(*schema.SecurityPolicy),
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return policyEventCh, nil
+}
+
+func (r *subscriptionResolver) Isolationpolicy(ctx context.Context) (<-chan *model.IsolationPolicyEvent, error) {
+	var policyEventCh = make(chan *model.IsolationPolicyEvent, 100)
+
+	go func() {
+		eventCh, stopWatch := r.TrackerFactory().IsolationPolicy().Watch()
+		defer stopWatch()
+		defer close(policyEventCh)
+
+		for {
+			select {
+			case event, ok := <-eventCh:
+				if !ok {
+					return
+				}
+				policyEventCh <- &model.IsolationPolicyEvent{
+					Mutation: event.Type,
+					Node:     event.Object.(*schema.IsolationPolicy),
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return policyEventCh, nil
+}
+
 // Mutation returns generated.MutationResolver implementation.
 func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
 
@@ -17,6 +17,7 @@ limitations under the License.
 package resolver
 
 import (
+	"github.com/smartxworks/lynx/plugin/tower/pkg/schema"
 	"github.com/smartxworks/lynx/plugin/tower/pkg/server/fake/graph/resolver/tracker"
 )
 
@@ -33,3 +34,14 @@ func New() *Resolver {
 func (r *Resolver) TrackerFactory() *tracker.Factory {
 	return r.trackerFactory
 }
+
+// labelReferences returns labels with only ID of the references.
+func labelReferences(references []schema.ObjectReference) []schema.Label {
+	labelList := make([]schema.Label, 0, len(references))
+
+	for _, reference := range references {
+		labelList = append(labelList, schema.Label{ObjectMeta: schema.ObjectMeta(reference)})
+	}
+
+	return labelList
+}
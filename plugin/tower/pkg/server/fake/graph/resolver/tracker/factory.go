@@ -59,10 +59,22 @@ func (f *Factory) VM() *Tracker {
 	return f.TrackerFor(&schema.VM{}, nil, 0)
 }
 
+func (f *Factory) Host() *Tracker {
+	return f.TrackerFor(&schema.Host{}, nil, 0)
+}
+
 func (f *Factory) Label() *Tracker {
 	return f.TrackerFor(&schema.Label{}, nil, 0)
 }
 
+func (f *Factory) SecurityPolicy() *Tracker {
+	return f.TrackerFor(&schema.SecurityPolicy{}, nil, 0)
+}
+
+func (f *Factory) IsolationPolicy() *Tracker {
+	return f.TrackerFor(&schema.IsolationPolicy{}, nil, 0)
+}
+
 func (f *Factory) User() *Tracker {
 	var userNameFunc = func(obj interface{}) string {
 		return obj.(*model.User).Name
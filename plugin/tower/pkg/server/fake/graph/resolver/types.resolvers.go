@@ -10,6 +10,10 @@ import (
 	"github.com/smartxworks/lynx/plugin/tower/pkg/server/fake/graph/generated"
 )
 
+func (r *isolationPolicyResolver) VM(ctx context.Context, obj *schema.IsolationPolicy) (*schema.VM, error) {
+	return &schema.VM{ObjectMeta: schema.ObjectMeta(obj.VM)}, nil
+}
+
 func (r *labelResolver) Vms(ctx context.Context, obj *schema.Label) ([]schema.VM, error) {
 	vmList := make([]schema.VM, len(obj.VMs))
 
@@ -20,7 +24,44 @@ func (r *labelResolver) Vms(ctx context.Context, obj *schema.Label) ([]schema.VM
 	return vmList, nil
 }
 
+func (r *networkPolicyRuleResolver) Selector(ctx context.Context, obj *schema.NetworkPolicyRule) ([]schema.Label, error) {
+	return labelReferences(obj.Selector), nil
+}
+
+func (r *securityPolicyApplyResolver) Selector(ctx context.Context, obj *schema.SecurityPolicyApply) ([]schema.Label, error) {
+	return labelReferences(obj.Selector), nil
+}
+
+func (r *vMResolver) Host(ctx context.Context, obj *schema.VM) (*schema.Host, error) {
+	if obj.Host == nil {
+		return nil, nil
+	}
+	return &schema.Host{ObjectMeta: schema.ObjectMeta(*obj.Host)}, nil
+}
+
+// IsolationPolicy returns generated.IsolationPolicyResolver implementation.
+func (r *Resolver) IsolationPolicy() generated.IsolationPolicyResolver {
+	return &isolationPolicyResolver{r}
+}
+
 // Label returns generated.LabelResolver implementation.
 func (r *Resolver) Label() generated.LabelResolver { return &labelResolver{r} }
 
+// NetworkPolicyRule returns generated.NetworkPolicyRuleResolver implementation.
+func (r *Resolver) NetworkPolicyRule() generated.NetworkPolicyRuleResolver {
+	return &networkPolicyRuleResolver{r}
+}
+
+// SecurityPolicyApply returns generated.SecurityPolicyApplyResolver implementation.
+func (r *Resolver) SecurityPolicyApply() generated.SecurityPolicyApplyResolver {
+	return &securityPolicyApplyResolver{r}
+}
+
+// VM returns generated.VMResolver implementation.
+func (r *Resolver) VM() generated.VMResolver { return &vMResolver{r} }
+
+type isolationPolicyResolver struct{ *Resolver }
 type labelResolver struct{ *Resolver }
+type networkPolicyRuleResolver struct{ *Resolver }
+type securityPolicyApplyResolver struct{ *Resolver }
+type vMResolver struct{ *Resolver }